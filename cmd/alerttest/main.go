@@ -0,0 +1,93 @@
+// Command alerttest checks alert rules offline: it replays metric snapshots
+// through the rules with a simulated clock and compares the alerts with the
+// expected ones, see package alerttest.
+//
+// Usage:
+//
+//	alerttest -rules rules.json -expect expected.json [-interval 10s] snapshot...
+//
+// It prints a report and exits with a non-zero status if an expectation is
+// not met or the rules or inputs are invalid.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerttest"
+	"go.uber.org/zap"
+)
+
+// errorFailed is returned by run if an expectation is not met.
+var errorFailed = errors.New("alert rule test failed")
+
+// main runs the test with the command line arguments.
+func main() {
+	log.SetFlags(0)
+
+	exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// exit terminates the command with status 1 if err is not nil, after
+// printing it; it is the only place the command exits. Asking for help
+// with -h is not an error.
+func exit(err error) {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	log.Print(err)
+	os.Exit(1)
+}
+
+// run parses the arguments, runs the test and writes the report to w.
+// Usage and flag errors are written to errw.
+func run(args []string, w, errw io.Writer) error {
+	fs := flag.NewFlagSet("alerttest", flag.ContinueOnError)
+	fs.SetOutput(errw)
+
+	rulesPath := fs.String("rules", "", "JSON file with alert rules or the server JSON config")
+	expectPath := fs.String("expect", "", "JSON file with expected alerts")
+	interval := fs.Duration("interval", 10*time.Second, "rule evaluation interval")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *rulesPath == "" || *expectPath == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("rules, expectations and at least one snapshot are required")
+	}
+
+	rules, err := alerttest.LoadRules(*rulesPath)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := alerttest.LoadInputs(fs.Args(), *interval)
+	if err != nil {
+		return err
+	}
+
+	expectations, err := alerttest.LoadExpectations(*expectPath)
+	if err != nil {
+		return err
+	}
+
+	t := &alerttest.Test{Rules: rules, Inputs: inputs, Expectations: expectations, Interval: *interval}
+
+	ok, err := t.Run(context.Background(), w, zap.NewNop().Sugar())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errorFailed
+	}
+
+	return nil
+}
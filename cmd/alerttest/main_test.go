@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	rules := writeFile(t, dir, "rules.json",
+		`[{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "operator": ">", "threshold": 500e6}]`)
+	samples := writeFile(t, dir, "samples.txt", "0s HeapAlloc:gauge:600e6\n")
+	firing := writeFile(t, dir, "firing.json",
+		`[{"at": "0s", "alerts": [{"rule": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "state": "firing"}]}]`)
+	none := writeFile(t, dir, "none.json", `[{"at": "0s", "alerts": []}]`)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		errIs   error
+		wantOut string
+	}{
+		{"pass", []string{"-rules", rules, "-expect", firing, samples}, false, nil, "PASS: 1 expectations met\n"},
+		{"fail", []string{"-rules", rules, "-expect", none, samples}, true, errorFailed, "FAIL: 1 of 1 expectations not met\n"},
+		{"help", []string{"-h"}, true, flag.ErrHelp, ""},
+		{"unknown flag", []string{"-bogus"}, true, nil, ""},
+		{"bad interval", []string{"-interval", "soon"}, true, nil, ""},
+		{"missing rules", []string{"-expect", firing, samples}, true, nil, ""},
+		{"missing expectations", []string{"-rules", rules, samples}, true, nil, ""},
+		{"no snapshots", []string{"-rules", rules, "-expect", firing}, true, nil, ""},
+		{"missing rules file", []string{"-rules", filepath.Join(dir, "nope.json"), "-expect", firing, samples}, true, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+
+			err := run(tt.args, &out, &errOut)
+			if !tt.wantErr {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.Contains(t, out.String(), tt.wantOut)
+		})
+	}
+}
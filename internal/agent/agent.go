@@ -29,6 +29,9 @@ func NewMetricAgent(cfg *config.Config) (*MetricAgent, error) {
 	if err != nil {
 		return nil, err
 	}
+	sender.Labels = cfg.Labels
+	collector.Owner = cfg.Owner
+	sender.Metadata = &collector.Metadata
 
 	return &MetricAgent{
 		collector: collector,
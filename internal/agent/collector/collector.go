@@ -21,6 +21,8 @@ import (
 // Collector gathers and stores various runtime and system metrics.
 type Collector struct {
 	Data         sync.Map
+	Metadata     sync.Map // metadata of the collected metrics by name
+	Owner        string   // team owning the collected metrics, set in their metadata
 	PollInterval time.Duration
 }
 
@@ -36,10 +38,12 @@ func (c *Collector) updateGauge(metricName string, metricValue float64) {
 
 	if !exists {
 		val = metric.NewGauge(metricName)
+		c.storeMetadata(metricName)
 	}
 
 	if gauge, ok := val.(*metric.Gauge); ok {
 		gauge.Update(metricValue)
+		gauge.Timestamp = time.Now()
 	}
 	c.Data.Store(metricName, val)
 }
@@ -49,6 +53,7 @@ func (c *Collector) updateCounter(metricName string, metricValue int64) {
 
 	if !exists {
 		val = metric.NewCounter(metricName)
+		c.storeMetadata(metricName)
 	}
 
 	if counter, ok := val.(*metric.Counter); ok {
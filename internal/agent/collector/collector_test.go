@@ -36,6 +36,7 @@ func TestMetricAgent_updateGauge(t *testing.T) {
 
 			assert.Equal(t, m.GetType(), metric.MetricTypeGauge)
 			assert.Equal(t, m.GetValue(), tt.args.metricValue)
+			assert.False(t, m.GetTimestamp().IsZero(), "gauges are stamped with the poll time")
 		})
 	}
 }
@@ -224,3 +225,31 @@ func TestCollector_RunPSUtilMetricsUpdater(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestCollector_Metadata(t *testing.T) {
+	c := &Collector{Owner: "runtime"}
+	c.updateGauge("HeapAlloc", 1)
+	c.updateGauge("CPUutilization2", 1)
+	c.updateGauge("Custom", 1)
+
+	tests := []struct {
+		name string
+		want metric.Metadata
+	}{
+		{"HeapAlloc", metric.Metadata{Help: "Bytes of allocated heap objects.", Unit: metric.UnitBytes, Owner: "runtime"}},
+		{"CPUutilization2", metric.Metadata{Help: "Utilization of CPU 2.", Unit: metric.UnitPercent, Owner: "runtime"}},
+		{"Custom", metric.Metadata{Owner: "runtime"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := c.Metadata.Load(tt.name)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, v)
+		})
+	}
+
+	c = &Collector{}
+	c.updateGauge("Custom", 1)
+	_, ok := c.Metadata.Load("Custom")
+	assert.False(t, ok, "no metadata without help text, unit or owner")
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// collectedMetadata holds the help texts and units of the collected metrics,
+// as documented for runtime.MemStats and gopsutil.
+var collectedMetadata = map[string]metric.Metadata{
+	"Alloc":         {Help: "Bytes of allocated heap objects.", Unit: metric.UnitBytes},
+	"BuckHashSys":   {Help: "Bytes of memory in profiling bucket hash tables.", Unit: metric.UnitBytes},
+	"Frees":         {Help: "Cumulative count of heap objects freed."},
+	"GCCPUFraction": {Help: "Fraction of the available CPU time used by the GC since the program started."},
+	"GCSys":         {Help: "Bytes of memory in garbage collection metadata.", Unit: metric.UnitBytes},
+	"HeapAlloc":     {Help: "Bytes of allocated heap objects.", Unit: metric.UnitBytes},
+	"HeapIdle":      {Help: "Bytes in idle (unused) heap spans.", Unit: metric.UnitBytes},
+	"HeapInuse":     {Help: "Bytes in in-use heap spans.", Unit: metric.UnitBytes},
+	"HeapObjects":   {Help: "Number of allocated heap objects."},
+	"HeapReleased":  {Help: "Bytes of physical memory returned to the OS.", Unit: metric.UnitBytes},
+	"HeapSys":       {Help: "Bytes of heap memory obtained from the OS.", Unit: metric.UnitBytes},
+	"LastGC":        {Help: "Time the last garbage collection finished, as nanoseconds since the Unix epoch."},
+	"Lookups":       {Help: "Number of pointer lookups performed by the runtime."},
+	"MCacheInuse":   {Help: "Bytes of allocated mcache structures.", Unit: metric.UnitBytes},
+	"MCacheSys":     {Help: "Bytes of memory obtained from the OS for mcache structures.", Unit: metric.UnitBytes},
+	"MSpanInuse":    {Help: "Bytes of allocated mspan structures.", Unit: metric.UnitBytes},
+	"MSpanSys":      {Help: "Bytes of memory obtained from the OS for mspan structures.", Unit: metric.UnitBytes},
+	"Mallocs":       {Help: "Cumulative count of heap objects allocated."},
+	"NextGC":        {Help: "Target heap size of the next GC cycle.", Unit: metric.UnitBytes},
+	"NumForcedGC":   {Help: "Number of GC cycles forced by the application."},
+	"NumGC":         {Help: "Number of completed GC cycles."},
+	"OtherSys":      {Help: "Bytes of memory in miscellaneous off-heap runtime allocations.", Unit: metric.UnitBytes},
+	"PauseTotalNs":  {Help: "Cumulative nanoseconds in GC stop-the-world pauses."},
+	"StackInuse":    {Help: "Bytes in stack spans.", Unit: metric.UnitBytes},
+	"StackSys":      {Help: "Bytes of stack memory obtained from the OS.", Unit: metric.UnitBytes},
+	"Sys":           {Help: "Total bytes of memory obtained from the OS.", Unit: metric.UnitBytes},
+	"TotalAlloc":    {Help: "Cumulative bytes allocated for heap objects.", Unit: metric.UnitBytes},
+	"RandomValue":   {Help: "Random value between 0 and 1."},
+	"PollCount":     {Help: "Number of times the runtime metrics were collected."},
+	"TotalMemory":   {Help: "Total amount of RAM.", Unit: metric.UnitBytes},
+	"FreeMemory":    {Help: "Amount of RAM not in use.", Unit: metric.UnitBytes},
+}
+
+// metadataOf returns the metadata of the collected metric with the given name.
+func metadataOf(name string) (metric.Metadata, bool) {
+	if md, ok := collectedMetadata[name]; ok {
+		return md, true
+	}
+	if cpu, ok := strings.CutPrefix(name, "CPUutilization"); ok {
+		return metric.Metadata{Help: "Utilization of CPU " + cpu + ".", Unit: metric.UnitPercent}, true
+	}
+	return metric.Metadata{}, false
+}
+
+// storeMetadata stores the metadata of the collected metric with the given
+// name in Metadata, with the owner of the collector, if there is any.
+func (c *Collector) storeMetadata(name string) {
+	md, ok := metadataOf(name)
+	if !ok && c.Owner == "" {
+		return
+	}
+	md.Owner = c.Owner
+	c.Metadata.Store(name, md)
+}
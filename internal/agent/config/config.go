@@ -4,6 +4,8 @@ package config
 
 import (
 	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 )
 
 func NewConfig() *Config {
@@ -26,6 +28,8 @@ type Config struct {
 	SendRateLimit  int
 	CryptoKey      string
 	UseGRPC        bool
+	Labels         metric.Labels // labels attached to all sent metrics, e.g. the host
+	Owner          string        // team owning the sent metrics, sent in their metadata
 }
 
 func LoadConfig() *Config {
@@ -4,6 +4,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 )
 
 func parseEnv(config *Config) {
@@ -50,4 +52,12 @@ func parseEnv(config *Config) {
 		config.UseGRPC = val
 	}
 
+	if envVar, ok := os.LookupEnv("LABELS"); ok && envVar != "" {
+		labels, err := metric.ParseLabels(envVar)
+		if err != nil {
+			panic(err)
+		}
+		config.Labels = labels
+	}
+
 }
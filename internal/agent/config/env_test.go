@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -98,3 +99,18 @@ func TestParseEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEnv_Labels(t *testing.T) {
+	for _, name := range []string{"REPORT_INTERVAL", "POLL_INTERVAL"} {
+		t.Setenv(name, "")
+		require.NoError(t, os.Unsetenv(name))
+	}
+	t.Setenv("LABELS", `host=web1,dc="eu,west"`)
+
+	config := &Config{}
+	parseEnv(config)
+	assert.Equal(t, metric.Labels{"host": "web1", "dc": "eu,west"}, config.Labels)
+
+	t.Setenv("LABELS", "host=")
+	assert.Panics(t, func() { parseEnv(&Config{}) })
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +23,9 @@ func TestParseFlags(t *testing.T) {
 	}{
 		{name: "Test1 OK", args: []string{"cmd", "-a", "127.0.0.1:9090", "-r", "20", "-p", "5", "-k", "secretkey", "-l", "3", "-crypto-key", "some_file.pem"}, expectPanic: false,
 			expected: &Config{EndpointAddr: "127.0.0.1:9090", ReportInterval: 20 * time.Second, PollInterval: 5 * time.Second, Key: "secretkey", SendRateLimit: 3, CryptoKey: "some_file.pem"}},
+		{name: "Labels", args: []string{"cmd", "-labels", "host=web1,dc=eu"}, expectPanic: false,
+			expected: &Config{Labels: metric.Labels{"host": "web1", "dc": "eu"}}},
+		{name: "Invalid labels", args: []string{"cmd", "-labels", "host"}, expectPanic: true, expected: &Config{}},
 		{name: "Test2 incorrect report interval", args: []string{"cmd", "-a", "127.0.0.1:9090", "-r", "a", "-p", "5"}, expectPanic: true, expected: &Config{}},
 		{name: "Test3 incorrect poll interval", args: []string{"cmd", "-a", "127.0.0.1:9090", "-r", "20", "-p", "a"}, expectPanic: true, expected: &Config{}},
 	}
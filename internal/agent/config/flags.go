@@ -6,12 +6,13 @@ import (
 	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 )
 
 func parseFlags(config *Config) {
 
 	// filtering args to leave just values processed by parseFlags
-	args := common.FilterArgs(os.Args[1:], []string{"-a", "-r", "-p", "-k", "-l", "-crypto-key", "-g"})
+	args := common.FilterArgs(os.Args[1:], []string{"-a", "-r", "-p", "-k", "-l", "-crypto-key", "-g", "-labels"})
 
 	fs := flag.NewFlagSet("main", flag.ContinueOnError)
 
@@ -23,6 +24,14 @@ func parseFlags(config *Config) {
 	fs.StringVar(&config.CryptoKey, "crypto-key", config.CryptoKey, "crypto key")
 
 	fs.BoolVar(&config.UseGRPC, "g", config.UseGRPC, "use grpc")
+	fs.Func("labels", "labels of sent metrics, e.g. host=web1,dc=eu", func(s string) error {
+		labels, err := metric.ParseLabels(s)
+		if err != nil {
+			return err
+		}
+		config.Labels = labels
+		return nil
+	})
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 )
 
 // JsonConfig defines a configuration structure tailored for JSON unmarshalling.
@@ -23,6 +24,8 @@ type JsonConfig struct {
 	SendRateLimit  int             `json:"send_rate_limit"`
 	Key            string          `json:"key"`
 	UseGRPC        bool            `json:"use_grpc"`
+	Labels         metric.Labels   `json:"labels"`
+	Owner          string          `json:"owner"`
 }
 
 // parseJson loads configuration values from a JSON file into the provided
@@ -35,7 +38,7 @@ type JsonConfig struct {
 //
 // If the file path is found, parseJson attempts to read and unmarshal it
 // into a JsonConfig. The resulting values are copied into the target Config.
-// If the file cannot be read or contains invalid JSON, the function panics.
+// If the file cannot be read or contains invalid JSON or labels, the function panics.
 //
 // Fields populated:
 //   - EndpointAddr
@@ -44,6 +47,9 @@ type JsonConfig struct {
 //   - Key
 //   - SendRateLimit
 //   - CryptoKey
+//   - UseGRPC
+//   - Labels
+//   - Owner
 //
 // The caller is expected to merge these values with defaults, environment
 // variables, and command-line flags as part of the full configuration process.
@@ -71,6 +77,9 @@ func parseJson(config *Config) {
 	if err != nil {
 		panic(err)
 	}
+	if err := c.Labels.Validate(); err != nil {
+		panic(err)
+	}
 
 	config.EndpointAddr = c.Address
 	config.ReportInterval = time.Duration(c.ReportInterval.Duration)
@@ -79,4 +88,6 @@ func parseJson(config *Config) {
 	config.SendRateLimit = c.SendRateLimit
 	config.CryptoKey = c.CryptoKey
 	config.UseGRPC = c.UseGRPC
+	config.Labels = c.Labels
+	config.Owner = c.Owner
 }
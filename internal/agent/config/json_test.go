@@ -38,6 +38,7 @@ func Test_parseJson_SourcesAndPrecedence(t *testing.T) {
 		"crypto_key":      "/env/key.pem",
 		"send_rate_limit": 9,
 		"key":             "ENVKEY",
+		"owner":           "runtime",
 	})
 	pathFlag := writeTempJSON(t, dir, "flag.json", map[string]any{
 		"address":         "flag.example:8000",
@@ -61,6 +62,7 @@ func Test_parseJson_SourcesAndPrecedence(t *testing.T) {
 		assert.Equal(t, "/env/key.pem", cfg.CryptoKey)
 		assert.Equal(t, 9, cfg.SendRateLimit)
 		assert.Equal(t, "ENVKEY", cfg.Key)
+		assert.Equal(t, "runtime", cfg.Owner)
 	})
 
 	t.Run("loads from flags when ENV is empty", func(t *testing.T) {
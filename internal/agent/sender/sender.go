@@ -23,6 +23,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/dmitrijs2005/metric-alerting-service/internal/proto"
 )
@@ -40,6 +41,9 @@ type Sender struct {
 	SendRateLimit  int
 	PubKey         *rsa.PublicKey
 	UseGRPC        bool
+	Labels         metric.Labels // labels attached to all sent metrics
+	Metadata       *sync.Map     // metadata of the metrics by name, sent until the server accepts it
+	metadataSent   sync.Map      // names whose metadata the server has accepted
 	gRPCConn       *grpc.ClientConn
 }
 
@@ -117,6 +121,46 @@ func (s *Sender) worker(ind int, jobs <-chan metric.Metric) {
 
 }
 
+// labels returns the labels the metric is sent with: the labels of the sender
+// and of the metric itself, which take precedence.
+func (s *Sender) labels(m metric.Metric) metric.Labels {
+	if len(s.Labels) == 0 {
+		return m.GetLabels()
+	}
+
+	result := make(metric.Labels, len(s.Labels)+len(m.GetLabels()))
+	for k, v := range s.Labels {
+		result[k] = v
+	}
+	for k, v := range m.GetLabels() {
+		result[k] = v
+	}
+	return result
+}
+
+// metadata returns the metadata the metric is sent with: the metadata of its
+// name, until the server has accepted it once.
+func (s *Sender) metadata(m metric.Metric) (metric.Metadata, bool) {
+	if s.Metadata == nil {
+		return metric.Metadata{}, false
+	}
+	if _, sent := s.metadataSent.Load(m.GetName()); sent {
+		return metric.Metadata{}, false
+	}
+
+	v, ok := s.Metadata.Load(m.GetName())
+	if !ok {
+		return metric.Metadata{}, false
+	}
+	md, ok := v.(metric.Metadata)
+	return md, ok
+}
+
+// metadataDelivered records that the server has accepted the metadata of the metric with the given name.
+func (s *Sender) metadataDelivered(name string) {
+	s.metadataSent.Store(name, struct{}{})
+}
+
 // MetricToDto converts a metric.Metric into a DTO (Data Transfer Object) for JSON serialization.
 //
 // Parameters:
@@ -126,7 +170,13 @@ func (s *Sender) worker(ind int, jobs <-chan metric.Metric) {
 //   - *dto.Metrics: the converted DTO.
 //   - error: if the value type is invalid for its metric type.
 func (s *Sender) MetricToDto(m metric.Metric) (*dto.Metrics, error) {
-	data := &dto.Metrics{ID: m.GetName(), MType: string(m.GetType())}
+	data := &dto.Metrics{ID: m.GetName(), MType: string(m.GetType()), Labels: s.labels(m)}
+	if ts := m.GetTimestamp(); !ts.IsZero() {
+		data.Timestamp = &ts
+	}
+	if md, ok := s.metadata(m); ok {
+		data.Metadata = &dto.Metadata{Help: md.Help, Unit: string(md.Unit), Owner: md.Owner}
+	}
 
 	if m.GetType() == metric.MetricTypeCounter {
 		v, ok := m.GetValue().(int64)
@@ -142,6 +192,21 @@ func (s *Sender) MetricToDto(m metric.Metric) (*dto.Metrics, error) {
 		} else {
 			return nil, common.ErrorTypeConversion
 		}
+	} else if m.GetType() == metric.MetricTypeHistogram {
+		v, ok := m.GetValue().(metric.HistogramValue)
+		if ok {
+			data.Histogram = &dto.Histogram{Bounds: v.Bounds, Counts: v.Counts, Count: v.Count, Sum: v.Sum}
+		} else {
+			return nil, common.ErrorTypeConversion
+		}
+	} else if m.GetType() == metric.MetricTypeSummary {
+		v, ok := m.GetValue().(metric.SummaryValue)
+		if ok {
+			data.Summary = &dto.Summary{RelativeAccuracy: v.RelativeAccuracy, Positive: v.Positive, Negative: v.Negative,
+				Zero: v.Zero, Count: v.Count, Sum: v.Sum, Min: v.Min, Max: v.Max}
+		} else {
+			return nil, common.ErrorTypeConversion
+		}
 	}
 	return data, nil
 }
@@ -208,18 +273,37 @@ func (s *Sender) SendMetricGRPC(m metric.Metric) error {
 
 	client := pb.NewMetricServiceClient(s.gRPCConn)
 
-	req := &pb.UpdateMetricValueRequest{MetricType: string(m.GetType()), MetricName: m.GetName(), MetricValue: fmt.Sprintf("%v", m.GetValue())}
+	req := &pb.UpdateMetricValueRequest{MetricType: string(m.GetType()), MetricName: m.GetName(), MetricValue: fmt.Sprintf("%v", m.GetValue()),
+		Labels: s.labels(m)}
+	if v, ok := m.GetValue().(metric.HistogramValue); ok {
+		req.Histogram = &pb.Histogram{Bounds: v.Bounds, Counts: v.Counts, Count: v.Count, Sum: v.Sum}
+	}
+	if v, ok := m.GetValue().(metric.SummaryValue); ok {
+		req.Summary = &pb.Summary{RelativeAccuracy: v.RelativeAccuracy, Positive: v.Positive, Negative: v.Negative,
+			Zero: v.Zero, Count: v.Count, Sum: v.Sum, Min: v.Min, Max: v.Max}
+	}
+	if ts := m.GetTimestamp(); !ts.IsZero() {
+		req.Timestamp = timestamppb.New(ts)
+	}
+	if md, ok := s.metadata(m); ok {
+		req.Metadata = &pb.Metadata{Help: md.Help, Unit: string(md.Unit), Owner: md.Owner}
+	}
 
+	var err error
 	if s.PubKey != nil {
-		return s.SendMetricGRPCEncrypted(m, client, req)
+		err = s.SendMetricGRPCEncrypted(m, client, req)
+	} else {
+		fmt.Println(req)
+		_, err = client.UpdateMetricValue(context.Background(), req)
 	}
-
-	fmt.Println(req)
-	_, err := client.UpdateMetricValue(context.Background(), req)
 	if err != nil {
 		return err
 	}
 
+	if req.Metadata != nil {
+		s.metadataDelivered(m.GetName())
+	}
+
 	return nil
 }
 
@@ -299,6 +383,10 @@ func (s *Sender) SendMetric(m metric.Metric) error {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return fmt.Errorf("request failed with status: %d %s", resp.StatusCode, resp.Status)
 	}
+
+	if data.Metadata != nil {
+		s.metadataDelivered(m.GetName())
+	}
 	return nil
 }
 
@@ -384,6 +472,14 @@ func (s *Sender) SendAllMetricsInOneBatch() error {
 
 	common.WriteToConsole("reply received...")
 
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		for _, item := range data {
+			if item != nil && item.Metadata != nil {
+				s.metadataDelivered(item.ID)
+			}
+		}
+	}
+
 	return nil
 
 }
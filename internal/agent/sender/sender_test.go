@@ -40,6 +40,86 @@ func TestMetricToDto_ValidGauge(t *testing.T) {
 	require.Equal(t, "gauge", dto.MType)
 	require.NotNil(t, dto.Value)
 	require.Equal(t, 0.42, *dto.Value)
+	require.Nil(t, dto.Timestamp)
+}
+
+func TestMetricToDto_Timestamp(t *testing.T) {
+	s, err := NewSender(&sync.Map{}, time.Second, "http://localhost", "", 1, "", false)
+	require.NoError(t, err)
+
+	ts := time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC)
+	data, err := s.MetricToDto(&metric.Gauge{Name: "cpu_load", Value: 0.42, Timestamp: ts})
+	require.NoError(t, err)
+	require.NotNil(t, data.Timestamp)
+	require.Equal(t, ts, *data.Timestamp)
+}
+
+func TestSender_MetadataUntilDelivered(t *testing.T) {
+	status := http.StatusInternalServerError
+	received := make(chan dto.Metrics, 3)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gr, _ := gzip.NewReader(r.Body)
+		defer gr.Close()
+		var m dto.Metrics
+		_ = json.NewDecoder(gr).Decode(&m)
+		received <- m
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	s, err := NewSender(&sync.Map{}, time.Second, ts.URL, "", 1, "", false)
+	require.NoError(t, err)
+	s.Metadata = &sync.Map{}
+	s.Metadata.Store("HeapAlloc", metric.Metadata{Help: "Heap bytes.", Unit: metric.UnitBytes})
+
+	g := &metric.Gauge{Name: "HeapAlloc", Value: 1}
+	want := &dto.Metadata{Help: "Heap bytes.", Unit: "bytes"}
+
+	require.Error(t, s.SendMetric(g))
+	require.Equal(t, want, (<-received).Metadata, "sent again after a failure")
+
+	status = http.StatusOK
+	require.NoError(t, s.SendMetric(g))
+	require.Equal(t, want, (<-received).Metadata)
+
+	require.NoError(t, s.SendMetric(g))
+	require.Nil(t, (<-received).Metadata, "not sent once accepted")
+}
+
+func TestMetricToDto_Labels(t *testing.T) {
+	s, err := NewSender(&sync.Map{}, time.Second, "http://localhost", "", 1, "", false)
+	require.NoError(t, err)
+
+	dto, err := s.MetricToDto(metric.MustNewCounter("PollCount", 1))
+	require.NoError(t, err)
+	require.Nil(t, dto.Labels)
+
+	s.Labels = metric.Labels{"host": "web1", "dc": "eu"}
+	dto, err = s.MetricToDto(&metric.Counter{Name: "PollCount", Labels: metric.Labels{"dc": "us"}, Value: 1})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "web1", "dc": "us"}, dto.Labels)
+}
+
+func TestMetricToDto_Histogram(t *testing.T) {
+	s, err := NewSender(&sync.Map{}, time.Second, "http://localhost", "", 1, "", false)
+	require.NoError(t, err)
+
+	h := &metric.Histogram{Name: "latency", Value: metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{2, 1}, Count: 3, Sum: 4.5}}
+	data, err := s.MetricToDto(h)
+	require.NoError(t, err)
+	require.Equal(t, &dto.Histogram{Bounds: []float64{1}, Counts: []int64{2, 1}, Count: 3, Sum: 4.5}, data.Histogram)
+	require.Nil(t, data.Value)
+}
+
+func TestMetricToDto_Summary(t *testing.T) {
+	s, err := NewSender(&sync.Map{}, time.Second, "http://localhost", "", 1, "", false)
+	require.NoError(t, err)
+
+	sm := metric.NewSummary("latency")
+	require.NoError(t, sm.Update(0.2))
+	data, err := s.MetricToDto(sm)
+	require.NoError(t, err)
+	require.Equal(t, &dto.Summary{RelativeAccuracy: 0.01, Positive: map[int32]int64{-80: 1}, Count: 1, Sum: 0.2, Min: 0.2, Max: 0.2}, data.Summary)
 }
 
 func TestSendMetric_Success(t *testing.T) {
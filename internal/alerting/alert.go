@@ -0,0 +1,125 @@
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// State is a lifecycle state of an alert instance.
+//
+// An alert moves inactive → pending when its rule condition starts to hold,
+// pending → firing once the condition has held for the rule's "for" duration,
+// and firing → resolved once the condition has not held for the rule's
+// "keep_firing_for" duration. A pending alert whose condition stops holding
+// returns to inactive.
+type State string
+
+const (
+	StateInactive State = "inactive"
+	StatePending  State = "pending"
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+)
+
+// ResolvedRetention is how long resolved alerts are kept before they are forgotten.
+var ResolvedRetention = 15 * time.Minute
+
+// Alert is an instance of a rule evaluated for a particular metric.
+type Alert struct {
+	Rule            string            `json:"rule"`                      // Name of the rule that produced the alert
+	MetricType      metric.MetricType `json:"metric_type"`               // Type of the offending metric
+	MetricName      string            `json:"metric_name"`               // Name of the offending metric
+	MetricLabels    metric.Labels     `json:"metric_labels,omitempty"`   // Labels of the offending metric
+	State           State             `json:"state"`                     // Current lifecycle state
+	Value           float64           `json:"value"`                     // Metric value at the last evaluation
+	Threshold       float64           `json:"threshold"`                 // Threshold of the rule
+	Labels          map[string]string `json:"labels,omitempty"`          // Labels of the rule
+	ActiveAt        time.Time         `json:"active_at"`                 // Time the alert became pending
+	FiredAt         time.Time         `json:"fired_at"`                  // Time the alert started firing
+	ResolvedAt      time.Time         `json:"resolved_at"`               // Time the alert was resolved
+	LastTrueAt      time.Time         `json:"last_true_at"`              // Last time the condition held
+	LastEvaluatedAt time.Time         `json:"last_evaluated_at"`         // Time of the last evaluation
+	SilencedBy      []string          `json:"silenced_by,omitempty"`     // IDs of the silences muting the alert
+	InhibitedBy     []string          `json:"inhibited_by,omitempty"`    // Keys of the firing alerts inhibiting the alert
+	AcknowledgedBy  string            `json:"acknowledged_by,omitempty"` // Who acknowledged the firing alert
+	AcknowledgedAt  time.Time         `json:"acknowledged_at"`           // Time the firing alert was acknowledged
+}
+
+// Key returns a unique key of the alert instance.
+func (a *Alert) Key() string {
+	return alertKey(a.Rule, a.MetricType, a.MetricName, a.MetricLabels)
+}
+
+// muted reports whether notifications about the alert are silenced or inhibited.
+func (a *Alert) muted() bool {
+	return len(a.SilencedBy) > 0 || len(a.InhibitedBy) > 0
+}
+
+// alertKey builds a unique key of an alert instance. Keys of alerts about
+// unlabeled metrics are the same as before metrics had labels.
+func alertKey(rule string, metricType metric.MetricType, metricName string, metricLabels metric.Labels) string {
+	return fmt.Sprintf("%s|%s|%s%s", rule, metricType, metricName, metricLabels)
+}
+
+// Transition describes a change of an alert state.
+type Transition struct {
+	Alert Alert     `json:"alert"` // Snapshot of the alert after the transition
+	From  State     `json:"from"`  // Previous state
+	To    State     `json:"to"`    // New state
+	At    time.Time `json:"at"`    // Time of the transition
+}
+
+// conditionHeld advances the alert state machine when the rule condition holds.
+// It returns the previous state and whether the state has changed.
+func (a *Alert) conditionHeld(r *Rule, value float64, now time.Time) (State, bool) {
+	from := a.State
+
+	a.Value = value
+	a.LastTrueAt = now
+	a.LastEvaluatedAt = now
+
+	switch a.State {
+	case StatePending:
+		if now.Sub(a.ActiveAt) >= r.For.Duration {
+			a.State = StateFiring
+			a.FiredAt = now
+		}
+	case StateFiring:
+	default:
+		// inactive or resolved alert becomes active again
+		a.State = StatePending
+		a.ActiveAt = now
+		a.FiredAt = time.Time{}
+		a.ResolvedAt = time.Time{}
+		a.AcknowledgedBy = ""
+		a.AcknowledgedAt = time.Time{}
+		if r.For.Duration == 0 {
+			a.State = StateFiring
+			a.FiredAt = now
+		}
+	}
+
+	return from, from != a.State
+}
+
+// conditionNotHeld advances the alert state machine when the rule condition does not hold.
+// It returns the previous state and whether the state has changed.
+func (a *Alert) conditionNotHeld(r *Rule, now time.Time) (State, bool) {
+	from := a.State
+
+	a.LastEvaluatedAt = now
+
+	switch a.State {
+	case StatePending:
+		a.State = StateInactive
+	case StateFiring:
+		if now.Sub(a.LastTrueAt) >= r.KeepFiringFor.Duration {
+			a.State = StateResolved
+			a.ResolvedAt = now
+		}
+	}
+
+	return from, from != a.State
+}
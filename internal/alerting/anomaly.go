@@ -0,0 +1,180 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+const (
+	// DefaultAnomalyAlpha is the smoothing factor of anomaly rules without alpha.
+	// Every new value contributes 10% to the average, so the baseline follows
+	// gradual changes within a few tens of evaluations.
+	DefaultAnomalyAlpha = 0.1
+
+	// DefaultAnomalyWarmUp is the number of values observed before an anomaly
+	// rule without warm_up may fire for a metric.
+	DefaultAnomalyWarmUp = 10
+)
+
+// alpha returns the smoothing factor of an anomaly rule.
+func (r *Rule) alpha() float64 {
+	if r.Alpha > 0 {
+		return r.Alpha
+	}
+	return DefaultAnomalyAlpha
+}
+
+// warmUp returns the number of values observed before an anomaly rule may fire.
+func (r *Rule) warmUp() int {
+	if r.WarmUp > 0 {
+		return r.WarmUp
+	}
+	return DefaultAnomalyWarmUp
+}
+
+// Baseline holds the online statistics of a metric learned by an anomaly rule:
+// the exponentially weighted moving average and variance of the values
+// observed at evaluations.
+type Baseline struct {
+	Rule         string            `json:"rule"`
+	MetricType   metric.MetricType `json:"metric_type"`
+	MetricName   string            `json:"metric_name"`
+	MetricLabels metric.Labels     `json:"metric_labels,omitempty"`
+	Mean         float64           `json:"mean"`
+	Variance     float64           `json:"variance"`
+	Count        int               `json:"count"` // number of observed values
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// Key returns the identity of the baseline, which is the same as of its alert.
+func (b *Baseline) Key() string {
+	return alertKey(b.Rule, b.MetricType, b.MetricName, b.MetricLabels)
+}
+
+// observe scores the value against the baseline and then adds it to the baseline.
+// Returns the z-score of the value and whether it is meaningful, which it is
+// not during the warm-up and while all observed values were equal.
+func (b *Baseline) observe(value, alpha float64, warmUp int, now time.Time) (float64, bool) {
+	var z float64
+	scored := b.Count >= warmUp && b.Variance > 0
+	if scored {
+		z = (value - b.Mean) / math.Sqrt(b.Variance)
+	}
+
+	if b.Count == 0 {
+		b.Mean = value
+		b.Variance = 0
+	} else {
+		d := value - b.Mean
+		b.Mean += alpha * d
+		b.Variance = (1 - alpha) * (b.Variance + alpha*d*d)
+	}
+	b.Count++
+	b.UpdatedAt = now
+
+	return z, scored
+}
+
+// Baselines keeps the baselines of metrics matched by anomaly rules.
+// It is safe for concurrent use and can be persisted as a section of the
+// metric dump file, see DumpName, DumpState and RestoreState.
+type Baselines struct {
+	mu        sync.Mutex
+	baselines map[string]*Baseline
+}
+
+// NewBaselines creates an empty set of baselines.
+func NewBaselines() *Baselines {
+	return &Baselines{baselines: make(map[string]*Baseline)}
+}
+
+// observe scores the value of the metric against its baseline for the rule
+// and updates the baseline, creating it on the first observation.
+func (b *Baselines) observe(r *Rule, m metric.Metric, value float64, now time.Time) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := alertKey(r.Name, m.GetType(), m.GetName(), m.GetLabels())
+	bl, ok := b.baselines[key]
+	if !ok {
+		bl = &Baseline{Rule: r.Name, MetricType: m.GetType(), MetricName: m.GetName(), MetricLabels: m.GetLabels()}
+		b.baselines[key] = bl
+	}
+
+	return bl.observe(value, r.alpha(), r.warmUp(), now)
+}
+
+// prune removes the baselines not observed at the evaluation at now,
+// i.e. of removed or disabled rules and of metrics that are gone. Baselines
+// of the failed rules are kept, like their alerts, until they are evaluated again.
+func (b *Baselines) prune(now time.Time, failed map[string]bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, bl := range b.baselines {
+		if !bl.UpdatedAt.Equal(now) && !failed[bl.Rule] {
+			delete(b.baselines, key)
+		}
+	}
+}
+
+// List returns a snapshot of the baselines sorted by rule and metric name.
+func (b *Baselines) List() []Baseline {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]Baseline, 0, len(b.baselines))
+	for _, bl := range b.baselines {
+		result = append(result, *bl)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rule != result[j].Rule {
+			return result[i].Rule < result[j].Rule
+		}
+		if result[i].MetricName != result[j].MetricName {
+			return result[i].MetricName < result[j].MetricName
+		}
+		return result[i].MetricLabels.String() < result[j].MetricLabels.String()
+	})
+
+	return result
+}
+
+// Set replaces the baselines with the given ones.
+func (b *Baselines) Set(baselines []Baseline) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.baselines = make(map[string]*Baseline, len(baselines))
+	for i := range baselines {
+		bl := baselines[i]
+		b.baselines[bl.Key()] = &bl
+	}
+}
+
+// DumpName returns the name of the dump file section holding baselines.
+func (b *Baselines) DumpName() string {
+	return "baselines"
+}
+
+// DumpState serializes baselines for the dump file.
+func (b *Baselines) DumpState(ctx context.Context) ([]byte, error) {
+	return json.Marshal(b.List())
+}
+
+// RestoreState restores baselines from the dump file.
+func (b *Baselines) RestoreState(ctx context.Context, data []byte) error {
+	var baselines []Baseline
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return err
+	}
+	b.Set(baselines)
+	return nil
+}
@@ -0,0 +1,152 @@
+package alerting
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func anomalyRule() Rule {
+	return Rule{Name: "HeapAllocUnusual", Kind: RuleKindAnomaly, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Sigma: 3, WarmUp: 5}
+}
+
+func TestBaseline_Observe(t *testing.T) {
+	var b Baseline
+
+	// warm-up
+	for i, v := range []float64{10, 12, 10, 12} {
+		_, ok := b.observe(v, 0.5, 4, t0.Add(time.Duration(i)*time.Second))
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 4, b.Count)
+	assert.InDelta(t, 11.25, b.Mean, 1e-9)
+
+	mean, variance := b.Mean, b.Variance
+	z, ok := b.observe(20, 0.5, 4, t0.Add(time.Minute))
+	require.True(t, ok)
+	assert.InDelta(t, (20-mean)/math.Sqrt(variance), z, 1e-9)
+	assert.Equal(t, t0.Add(time.Minute), b.UpdatedAt)
+
+	// constant values have no deviation to compare with
+	var flat Baseline
+	for i := 0; i < 5; i++ {
+		_, ok := flat.observe(7, 0.5, 1, t0)
+		assert.False(t, ok)
+	}
+}
+
+func TestEvaluator_Anomaly(t *testing.T) {
+	ctx := context.Background()
+	e, s := newTestEvaluator(t, anomalyRule())
+
+	evaluate := func(i int, v float64) []Transition {
+		t.Helper()
+		setGauge(t, s, "HeapAlloc", v)
+		transitions, err := e.Evaluate(ctx, t0.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+		return transitions
+	}
+
+	// a fresh metric does not fire during the warm-up, however unusual it is
+	values := []float64{100e6, 900e6, 100e6, 110e6, 90e6}
+	for i, v := range values {
+		assert.Empty(t, evaluate(i, v))
+	}
+
+	// normal noise
+	i := len(values)
+	for ; i < 30; i++ {
+		assert.Empty(t, evaluate(i, 100e6+float64(i%3)*10e6))
+	}
+
+	transitions := evaluate(i, 5e9)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateFiring, transitions[0].To)
+	assert.Greater(t, transitions[0].Alert.Value, 3.0)
+	assert.Equal(t, 3.0, transitions[0].Alert.Threshold)
+
+	baselines := e.Baselines().List()
+	require.Len(t, baselines, 1)
+	assert.Equal(t, i+1, baselines[0].Count)
+
+	// baselines of disabled rules are dropped
+	e.rules[0].Disabled = true
+	_, err := e.Evaluate(ctx, t0.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, e.Baselines().List())
+}
+
+type memoryBaselineStore struct {
+	baselines []Baseline
+}
+
+func (m *memoryBaselineStore) SaveBaselines(ctx context.Context, baselines []Baseline) error {
+	m.baselines = baselines
+	return nil
+}
+
+func (m *memoryBaselineStore) LoadBaselines(ctx context.Context) ([]Baseline, error) {
+	return m.baselines, nil
+}
+
+func TestEvaluator_AnomalyBaselinePersisted(t *testing.T) {
+	ctx := context.Background()
+	e, s := newTestEvaluator(t, anomalyRule())
+	store := &memoryBaselineStore{}
+	e.SetBaselineStore(store)
+
+	for i := 0; i < 10; i++ {
+		setGauge(t, s, "HeapAlloc", 100e6+float64(i%2)*10e6)
+		_, err := e.Evaluate(ctx, t0.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+	require.Len(t, store.baselines, 1)
+
+	// after a restart the baseline is not learned again
+	restarted, s := newTestEvaluator(t, anomalyRule())
+	restarted.SetBaselineStore(store)
+	require.NoError(t, restarted.LoadState(ctx))
+
+	setGauge(t, s, "HeapAlloc", 5e9)
+	transitions, err := restarted.Evaluate(ctx, t0.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateFiring, transitions[0].To)
+}
+
+func TestBaselines_Dump(t *testing.T) {
+	ctx := context.Background()
+	b := NewBaselines()
+	b.Set([]Baseline{{Rule: "r", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Mean: 1, Variance: 2, Count: 3, UpdatedAt: t0}})
+
+	data, err := b.DumpState(ctx)
+	require.NoError(t, err)
+
+	restored := NewBaselines()
+	require.NoError(t, restored.RestoreState(ctx, data))
+	assert.Equal(t, "baselines", restored.DumpName())
+	assert.Equal(t, b.List(), restored.List())
+}
+
+func TestBaselines_Prune(t *testing.T) {
+	b := NewBaselines()
+	b.Set([]Baseline{
+		{Rule: "observed", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Count: 3, UpdatedAt: t0.Add(time.Minute)},
+		{Rule: "failed", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Count: 3, UpdatedAt: t0},
+		{Rule: "removed", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Count: 3, UpdatedAt: t0},
+	})
+
+	// a rule whose check failed keeps its warm-up and learned state
+	b.prune(t0.Add(time.Minute), map[string]bool{"failed": true})
+
+	baselines := b.List()
+	require.Len(t, baselines, 2)
+	assert.Equal(t, "failed", baselines[0].Rule)
+	assert.Equal(t, 3, baselines[0].Count)
+	assert.Equal(t, "observed", baselines[1].Rule)
+}
@@ -0,0 +1,168 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/expr"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+)
+
+// sample is the outcome of checking a rule against a single alert instance.
+type sample struct {
+	metricType   metric.MetricType
+	metricName   string
+	metricLabels metric.Labels
+	value        float64
+	threshold    float64
+	holds        bool
+}
+
+// retrieveMetrics reads all metrics from the storage, with their update times
+// if the storage tracks them (see storage.TimestampedStorage).
+func (e *Evaluator) retrieveMetrics(ctx context.Context) ([]storage.TimestampedMetric, error) {
+	if ts, ok := e.storage.(storage.TimestampedStorage); ok {
+		return ts.RetrieveAllTimestamped(ctx)
+	}
+
+	metrics, err := e.storage.RetrieveAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.TimestampedMetric, len(metrics))
+	for i, m := range metrics {
+		result[i] = storage.TimestampedMetric{Metric: m}
+	}
+	return result, nil
+}
+
+// check evaluates the rule against the metrics and returns a sample per alert instance.
+// An absent rule produces a single sample named after the rule's metric name
+// (or pattern) if no metric matches it, and no samples otherwise.
+// A windowed rule produces no sample for a metric observed less than twice,
+// an anomaly rule for a metric whose baseline is still warming up.
+func (r *Rule) check(metrics []storage.TimestampedMetric, series *seriesBuffer, baselines *Baselines, now time.Time) ([]sample, error) {
+	if r.Kind == RuleKindExpr {
+		return r.checkExpr(metrics)
+	}
+
+	var result []sample
+	matched := false
+
+	for _, tm := range metrics {
+		m := tm.Metric
+		if !r.Matches(m) {
+			continue
+		}
+
+		matched = true
+		if r.Kind == RuleKindAbsent {
+			break
+		}
+
+		s := sample{metricType: m.GetType(), metricName: m.GetName(), metricLabels: m.GetLabels()}
+
+		switch r.Kind {
+		case RuleKindStale:
+			if tm.UpdatedAt.IsZero() {
+				return nil, ErrorUpdateTimeUnknown
+			}
+			age := now.Sub(tm.UpdatedAt)
+			s.value = age.Seconds()
+			s.threshold = r.StaleAfter.Seconds()
+			s.holds = age >= r.StaleAfter.Duration
+		case RuleKindRate, RuleKindIncrease, RuleKindDelta, RuleKindDeriv:
+			points := series.window(m.GetType(), m.GetName(), m.GetLabels(), now.Add(-r.Window.Duration))
+			if len(points) < 2 {
+				continue
+			}
+			s.value = r.windowValue(points)
+			s.threshold = r.Threshold
+			s.holds = r.Check(s.value)
+		case RuleKindAnomaly:
+			value, err := metricValue(m)
+			if err != nil {
+				return nil, err
+			}
+			z, ok := baselines.observe(r, m, value, now)
+			if !ok {
+				continue
+			}
+			s.value = z
+			s.threshold = r.Sigma
+			s.holds = math.Abs(z) >= r.Sigma
+		default:
+			value, err := metricValue(m)
+			if err != nil {
+				return nil, err
+			}
+			s.value = value
+			s.threshold = r.Threshold
+			s.holds = r.Check(value)
+		}
+
+		result = append(result, s)
+	}
+
+	if r.Kind == RuleKindAbsent && !matched {
+		result = append(result, sample{metricType: r.MetricType, metricName: r.MetricName, holds: true})
+	}
+
+	return result, nil
+}
+
+// metricsEnv resolves metric references of expressions against the retrieved metrics.
+type metricsEnv []storage.TimestampedMetric
+
+func (env metricsEnv) Lookup(ref expr.Ref) ([]float64, error) {
+	var result []float64
+	for _, tm := range env {
+		if !ref.Matches(tm.Metric) {
+			continue
+		}
+		v, err := metricValue(tm.Metric)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// checkExpr evaluates an expr rule. It produces a single sample without a metric,
+// or no sample if the expression has no value because of missing metrics or
+// a division by zero.
+func (r *Rule) checkExpr(metrics []storage.TimestampedMetric) ([]sample, error) {
+	e, err := r.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	env := metricsEnv(metrics)
+
+	v, err := e.Eval(env)
+	if errors.Is(err, expr.ErrorNoData) || errors.Is(err, expr.ErrorDivisionByZero) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := sample{holds: v.Bool}
+
+	// report operands of a comparison as the value and threshold
+	if left, _, right, ok := e.Comparison(); ok {
+		if lv, err := left.Eval(env); err == nil {
+			s.value = lv.Num
+		}
+		if rv, err := right.Eval(env); err == nil {
+			s.threshold = rv.Num
+		}
+	}
+
+	return []sample{s}, nil
+}
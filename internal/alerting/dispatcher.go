@@ -0,0 +1,388 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+)
+
+// alertGroup is a group of alerts notified together to a receiver.
+type alertGroup struct {
+	receiver  Receiver
+	route     *Route // settings of the route the group belongs to
+	key       string
+	labels    map[string]string
+	alerts    map[string]Alert // current firing and resolved alerts of the group
+	notified  map[string]State // states of the alerts in the last notification
+	lastSent  string           // fingerprint of the last notification
+	sentAt    time.Time
+	nextFlush time.Time
+	sending   bool // a notification about the group is being sent
+}
+
+// delivery is a notification about a group, sent or queued in the outbox
+// after the dispatcher's lock is released, so that a slow receiver does not
+// block dispatching and reads of the routes.
+type delivery struct {
+	id           string
+	group        *alertGroup
+	notification *Notification
+
+	// state of the group before the notification, restored if it fails
+	lastSent string
+	sentAt   time.Time
+	notified map[string]State
+}
+
+// Dispatcher routes alerts to receivers, groups them and sends notifications
+// about the groups.
+//
+// Every receiver gets its own groups per route, so notifications are
+// deduplicated and throttled per receiver: a notification identical to the
+// last one sent about a group is not sent again until the repeat interval
+// has passed.
+type Dispatcher struct {
+	mu        sync.Mutex
+	tree      *routeNode
+	receivers []Receiver
+	groups    map[string]*alertGroup
+	outbox    *Outbox
+	logger    logger.Logger
+}
+
+// NewDispatcher creates a dispatcher with the given route, which must not
+// reference receivers, as none are registered yet; see SetRoute.
+func NewDispatcher(route Route, l logger.Logger) (*Dispatcher, error) {
+	d := &Dispatcher{groups: make(map[string]*alertGroup), logger: l}
+	if err := d.SetRoute(route); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SetRoute replaces the routing tree. Receivers referenced by the routes must
+// be registered. Existing groups are dropped.
+func (d *Dispatcher) SetRoute(route Route) error {
+	if err := route.Validate(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, name := range route.receivers() {
+		if d.receiver(name) == nil {
+			return fmt.Errorf("%w: %q", ErrorUnknownReceiver, name)
+		}
+	}
+
+	d.tree = newRouteTree(route)
+	d.groups = make(map[string]*alertGroup)
+	return nil
+}
+
+// AddReceiver registers a receiver. It is notified by routes naming it and
+// by routes without a receiver.
+func (d *Dispatcher) AddReceiver(r Receiver) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.receivers = append(d.receivers, r)
+}
+
+// SetOutbox makes the dispatcher queue notifications in the outbox instead of
+// sending them directly. The outbox delivers them to the registered receivers.
+func (d *Dispatcher) SetOutbox(o *Outbox) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.outbox = o
+	o.receivers = func(name string) Receiver {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.receiver(name)
+	}
+}
+
+// receiver returns the registered receiver with the given name or nil.
+// The caller must hold mu.
+func (d *Dispatcher) receiver(name string) Receiver {
+	for _, r := range d.receivers {
+		if r.Name() == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// target is a receiver an alert is routed to by a route.
+type target struct {
+	receiver      Receiver
+	route         *routeNode
+	escalation    int // index of the escalation in the route, -1 for the route's receivers
+	escalateAfter time.Duration
+}
+
+// groupID identifies the group of the target with the given key.
+func (t *target) groupID(key string) string {
+	return t.route.id + "|" + strconv.Itoa(t.escalation) + "|" + t.receiver.Name() + "|" + key
+}
+
+// targets returns the receivers the alert is routed to at now. Escalation
+// receivers are included only if the alert has reached the escalation; with
+// a zero now all escalations are included. The caller must hold mu.
+func (d *Dispatcher) targets(a *Alert, now time.Time) []target {
+	var result []target
+
+	for _, n := range d.tree.match(a) {
+		if n.route.Receiver == "" {
+			for _, r := range d.receivers {
+				result = append(result, target{receiver: r, route: n, escalation: -1})
+			}
+		} else if r := d.receiver(n.route.Receiver); r != nil {
+			result = append(result, target{receiver: r, route: n, escalation: -1})
+		}
+
+		for i := range n.route.Escalations {
+			e := &n.route.Escalations[i]
+			if !now.IsZero() && !e.escalated(a, now) {
+				continue
+			}
+			if r := d.receiver(e.Receiver); r != nil {
+				result = append(result, target{receiver: r, route: n, escalation: i, escalateAfter: e.After.Duration})
+			}
+		}
+	}
+
+	return result
+}
+
+// Routes returns the receivers the alert would be routed to, including all
+// escalation receivers, without notifying them.
+func (d *Dispatcher) Routes(a *Alert) []RouteTarget {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	targets := d.targets(a, time.Time{})
+	result := make([]RouteTarget, len(targets))
+	for i, t := range targets {
+		key, _ := t.route.route.groupKey(a)
+		result[i] = RouteTarget{Route: t.route.id, Receiver: t.receiver.Name(), GroupKey: key, EscalateAfter: t.escalateAfter}
+	}
+	return result
+}
+
+// Dispatch updates the groups with the current alerts and sends notifications
+// about the groups whose timers have expired. Alerts previously notified as
+// firing and missing from alerts, e.g. because their rule was deleted, are
+// notified as resolved. Delivery errors are logged and the notification is
+// retried after the group interval; with an outbox only errors queueing the
+// notification are, delivery is retried by the outbox, see SetOutbox.
+//
+// Notifications are sent after the groups are updated, without holding the
+// dispatcher's lock.
+func (d *Dispatcher) Dispatch(ctx context.Context, alerts []Alert, now time.Time) {
+	deliveries, outbox := d.update(alerts, now)
+
+	for i := range deliveries {
+		deliveries[i].send(ctx, d, outbox, now)
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, dl := range deliveries {
+		dl.group.sending = false
+		if dl.group.done() && d.groups[dl.id] == dl.group {
+			delete(d.groups, dl.id)
+		}
+	}
+}
+
+// update updates the groups with the current alerts and returns the
+// notifications to send along with the outbox to queue them in, if any.
+func (d *Dispatcher) update(alerts []Alert, now time.Time) ([]delivery, *Outbox) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deliveries []delivery
+	current := make(map[string]map[string]Alert)
+
+	for _, a := range alerts {
+		if a.State != StateFiring && a.State != StateResolved {
+			continue
+		}
+
+		for _, t := range d.targets(&a, now) {
+			route := &t.route.route
+			key, labels := route.groupKey(&a)
+			gk := t.groupID(key)
+
+			g, exists := d.groups[gk]
+			if !exists {
+				// only firing alerts open new groups
+				if a.State != StateFiring || a.muted() {
+					continue
+				}
+				g = &alertGroup{receiver: t.receiver, route: route, key: key, labels: labels, notified: make(map[string]State),
+					nextFlush: now.Add(route.GroupWait.Duration)}
+				d.groups[gk] = g
+			}
+
+			if current[gk] == nil {
+				current[gk] = make(map[string]Alert)
+			}
+			current[gk][a.Key()] = a
+		}
+	}
+
+	for gk, g := range d.groups {
+		groupAlerts := current[gk]
+		if groupAlerts == nil {
+			groupAlerts = make(map[string]Alert)
+		}
+
+		// notified alerts that disappeared are resolved
+		for key, state := range g.notified {
+			if _, ok := groupAlerts[key]; !ok && state == StateFiring {
+				if a, ok := g.alerts[key]; ok {
+					a.State = StateResolved
+					a.ResolvedAt = now
+					groupAlerts[key] = a
+				}
+			}
+		}
+		g.alerts = groupAlerts
+
+		if !now.Before(g.nextFlush) && !g.sending {
+			if dl := g.flush(now); dl != nil {
+				dl.id = gk
+				deliveries = append(deliveries, *dl)
+			}
+		}
+
+		if g.done() && !g.sending {
+			delete(d.groups, gk)
+		}
+	}
+
+	return deliveries, d.outbox
+}
+
+// send sends the notification, or queues it in the outbox if not nil.
+// If that fails, the error is logged and the state of the group is restored,
+// so that the notification is retried after the group interval.
+func (dl *delivery) send(ctx context.Context, d *Dispatcher, outbox *Outbox, now time.Time) {
+	g := dl.group
+	n := dl.notification
+
+	var err error
+	if outbox != nil {
+		if _, err = outbox.Enqueue(ctx, n, now); err != nil {
+			d.logger.Errorw("Alert notification enqueue error", "receiver", g.receiver.Name(), "group", g.key, "err", err)
+		}
+	} else if err = g.receiver.Notify(ctx, n); err != nil {
+		d.logger.Errorw("Alert notification error", "receiver", g.receiver.Name(), "group", g.key, "err", err)
+	}
+
+	if err != nil {
+		d.mu.Lock()
+		g.lastSent = dl.lastSent
+		g.sentAt = dl.sentAt
+		g.notified = dl.notified
+		d.mu.Unlock()
+	}
+}
+
+// done reports whether the group has neither firing alerts, including silenced
+// ones, nor alerts whose resolution has not been notified yet.
+func (g *alertGroup) done() bool {
+	for _, a := range g.alerts {
+		if a.State == StateFiring {
+			return false
+		}
+	}
+	for _, state := range g.notified {
+		if state == StateFiring {
+			return false
+		}
+	}
+	return true
+}
+
+// flush returns a notification about the group if its content has changed
+// since the last notification or the repeat interval has passed, nil
+// otherwise. The group is marked as notified and sending until the
+// notification is sent. The caller must hold the dispatcher's lock.
+func (g *alertGroup) flush(now time.Time) *delivery {
+	g.nextFlush = now.Add(g.route.GroupInterval.Duration)
+
+	var alerts []Alert
+	states := make(map[string]State)
+	firing := false
+
+	for key, a := range g.alerts {
+		switch {
+		case a.State == StateFiring && !a.muted():
+			firing = true
+		case a.State == StateResolved:
+			// only resolutions of notified alerts are of interest
+			if _, ok := g.notified[key]; !ok {
+				continue
+			}
+		default:
+			continue
+		}
+		states[key] = a.State
+		alerts = append(alerts, a)
+	}
+
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Key() < alerts[j].Key()
+	})
+
+	fingerprint := notificationFingerprint(alerts)
+	repeat := firing && g.route.RepeatInterval.Duration > 0 && now.Sub(g.sentAt) >= g.route.RepeatInterval.Duration
+
+	if fingerprint == g.lastSent && !repeat {
+		return nil
+	}
+
+	n := NewNotification(g.receiver.Name(), alerts)
+	n.GroupKey = g.key
+	n.GroupLabels = g.labels
+
+	dl := &delivery{group: g, notification: n, lastSent: g.lastSent, sentAt: g.sentAt, notified: g.notified}
+
+	g.lastSent = fingerprint
+	g.sentAt = now
+	g.notified = states
+	g.sending = true
+	return dl
+}
+
+// notificationFingerprint identifies the content of a notification about the sorted alerts.
+func notificationFingerprint(alerts []Alert) string {
+	var b strings.Builder
+	for i := range alerts {
+		b.WriteString(alerts[i].Key())
+		b.WriteByte('=')
+		b.WriteString(string(alerts[i].State))
+		b.WriteByte(';')
+	}
+	return b.String()
+}
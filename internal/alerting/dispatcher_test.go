@@ -0,0 +1,255 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cpuAlerts(n int, state State) []Alert {
+	alerts := make([]Alert, n)
+	for i := range alerts {
+		alerts[i] = Alert{Rule: "CPUHigh", MetricType: metric.MetricTypeGauge, MetricName: fmt.Sprintf("CPUutilization%d", i+1),
+			State: state, Labels: map[string]string{"severity": "warning"}}
+	}
+	return alerts
+}
+
+func newTestDispatcher(t *testing.T, route Route, receivers ...Receiver) *Dispatcher {
+	t.Helper()
+	d, err := NewDispatcher(DefaultRoute(), logger.GetLogger())
+	require.NoError(t, err)
+	for _, r := range receivers {
+		d.AddReceiver(r)
+	}
+	require.NoError(t, d.SetRoute(route))
+	return d
+}
+
+func TestDispatcher_Grouping(t *testing.T) {
+	ctx := context.Background()
+
+	rcv := &fakeReceiver{name: "ops"}
+	d := newTestDispatcher(t, Route{
+		GroupBy:       []string{MatcherAlertName},
+		GroupWait:     common.Duration{Duration: 30 * time.Second},
+		GroupInterval: common.Duration{Duration: 5 * time.Minute},
+	}, rcv)
+
+	alerts := cpuAlerts(16, StateFiring)
+
+	// group wait: nothing is sent yet
+	d.Dispatch(ctx, alerts[:10], t0)
+	d.Dispatch(ctx, alerts, t0.Add(10*time.Second))
+	assert.Empty(t, rcv.notifications)
+
+	// one notification about all 16 alerts
+	d.Dispatch(ctx, alerts, t0.Add(30*time.Second))
+	require.Len(t, rcv.notifications, 1)
+	n := rcv.notifications[0]
+	assert.Equal(t, StateFiring, n.Status)
+	assert.Len(t, n.Alerts, 16)
+	assert.Equal(t, `{alertname="CPUHigh"}`, n.GroupKey)
+	assert.Equal(t, map[string]string{MatcherAlertName: "CPUHigh"}, n.GroupLabels)
+
+	// a resolved alert waits for the group interval
+	alerts[0].State = StateResolved
+	d.Dispatch(ctx, alerts, t0.Add(time.Minute))
+	assert.Len(t, rcv.notifications, 1)
+
+	d.Dispatch(ctx, alerts, t0.Add(5*time.Minute+30*time.Second))
+	require.Len(t, rcv.notifications, 2)
+	assert.Equal(t, StateFiring, rcv.notifications[1].Status)
+	assert.Len(t, rcv.notifications[1].Alerts, 16)
+	assert.Equal(t, StateResolved, rcv.notifications[1].Alerts[0].State)
+
+	// unchanged group is not notified again
+	d.Dispatch(ctx, alerts, t0.Add(11*time.Minute))
+	assert.Len(t, rcv.notifications, 2)
+
+	// all resolved
+	alerts = cpuAlerts(16, StateResolved)
+	d.Dispatch(ctx, alerts, t0.Add(16*time.Minute))
+	require.Len(t, rcv.notifications, 3)
+	assert.Equal(t, StateResolved, rcv.notifications[2].Status)
+	assert.Len(t, rcv.notifications[2].Alerts, 16)
+
+	// the group is gone
+	d.Dispatch(ctx, alerts, t0.Add(30*time.Minute))
+	assert.Len(t, rcv.notifications, 3)
+	assert.Empty(t, d.groups)
+}
+
+func TestDispatcher_Default(t *testing.T) {
+	ctx := context.Background()
+
+	rcv := &fakeReceiver{name: "ops"}
+	d := newTestDispatcher(t, DefaultRoute(), rcv)
+
+	alerts := cpuAlerts(2, StateFiring)
+	d.Dispatch(ctx, alerts, t0)
+	require.Len(t, rcv.notifications, 2)
+	assert.ElementsMatch(t, []string{alerts[0].Key(), alerts[1].Key()},
+		[]string{rcv.notifications[0].GroupKey, rcv.notifications[1].GroupKey})
+
+	d.Dispatch(ctx, alerts, t0.Add(time.Hour))
+	assert.Len(t, rcv.notifications, 2)
+}
+
+func TestDispatcher_Repeat(t *testing.T) {
+	ctx := context.Background()
+
+	rcv := &fakeReceiver{name: "ops"}
+	d := newTestDispatcher(t, Route{
+		GroupBy:        []string{MatcherAlertName},
+		GroupInterval:  common.Duration{Duration: time.Minute},
+		RepeatInterval: common.Duration{Duration: time.Hour},
+	}, rcv)
+
+	alerts := cpuAlerts(1, StateFiring)
+	for m := 0; m <= 120; m++ {
+		d.Dispatch(ctx, alerts, t0.Add(time.Duration(m)*time.Minute))
+	}
+
+	// initial notification and two repeats
+	assert.Len(t, rcv.notifications, 3)
+}
+
+func TestDispatcher_DedupPerReceiver(t *testing.T) {
+	ctx := context.Background()
+
+	ok := &fakeReceiver{name: "ops"}
+	failing := &fakeReceiver{name: "broken", err: errors.New("forced")}
+	d := newTestDispatcher(t, Route{GroupBy: []string{MatcherAlertName}}, ok, failing)
+
+	alerts := cpuAlerts(3, StateFiring)
+	d.Dispatch(ctx, alerts, t0)
+	d.Dispatch(ctx, alerts, t0.Add(time.Minute))
+
+	// delivered once, retried until it succeeds
+	assert.Len(t, ok.notifications, 1)
+	assert.Len(t, failing.notifications, 2)
+
+	failing.err = nil
+	d.Dispatch(ctx, alerts, t0.Add(2*time.Minute))
+	d.Dispatch(ctx, alerts, t0.Add(3*time.Minute))
+	assert.Len(t, ok.notifications, 1)
+	assert.Len(t, failing.notifications, 3)
+}
+
+func TestDispatcher_SilencedAndRemoved(t *testing.T) {
+	ctx := context.Background()
+
+	rcv := &fakeReceiver{name: "ops"}
+	d := newTestDispatcher(t, Route{GroupBy: []string{MatcherAlertName}}, rcv)
+
+	alerts := cpuAlerts(2, StateFiring)
+	alerts[1].SilencedBy = []string{"s1"}
+
+	// silenced alerts are left out
+	d.Dispatch(ctx, alerts, t0)
+	require.Len(t, rcv.notifications, 1)
+	require.Len(t, rcv.notifications[0].Alerts, 1)
+	assert.Equal(t, "CPUutilization1", rcv.notifications[0].Alerts[0].MetricName)
+
+	// notified alert disappears, e.g. its rule was deleted: it is resolved
+	d.Dispatch(ctx, alerts[1:], t0.Add(time.Minute))
+	require.Len(t, rcv.notifications, 2)
+	n := rcv.notifications[1]
+	assert.Equal(t, StateResolved, n.Status)
+	require.Len(t, n.Alerts, 1)
+	assert.Equal(t, t0.Add(time.Minute), n.Alerts[0].ResolvedAt)
+}
+
+func TestEvaluator_SetRoute(t *testing.T) {
+	e, _ := newTestEvaluator(t, heapRule())
+
+	assert.NoError(t, e.SetRoute(Route{GroupBy: []string{MatcherAlertName}}))
+	assert.ErrorIs(t, e.SetRoute(Route{GroupBy: []string{""}}), ErrorInvalidRoute)
+}
+
+// blockingReceiver blocks in Notify until released.
+type blockingReceiver struct {
+	name    string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingReceiver) Name() string {
+	return b.name
+}
+
+func (b *blockingReceiver) Notify(ctx context.Context, n *Notification) error {
+	b.entered <- struct{}{}
+	<-b.release
+	return nil
+}
+
+func TestDispatcher_SlowReceiver(t *testing.T) {
+	ctx := context.Background()
+
+	slow := &blockingReceiver{name: "slow", entered: make(chan struct{}), release: make(chan struct{})}
+	d := newTestDispatcher(t, DefaultRoute(), slow)
+
+	alerts := cpuAlerts(1, StateFiring)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Dispatch(ctx, alerts, t0)
+	}()
+	<-slow.entered
+
+	// the dispatcher is not locked while the receiver is notified
+	routes := make(chan []RouteTarget)
+	go func() { routes <- d.Routes(&alerts[0]) }()
+	select {
+	case r := <-routes:
+		assert.Len(t, r, 1)
+	case <-time.After(time.Second):
+		t.Fatal("Routes blocked by a slow receiver")
+	}
+
+	// a dispatch meanwhile does not send the notification again
+	other := make(chan struct{})
+	go func() {
+		defer close(other)
+		d.Dispatch(ctx, alerts, t0.Add(time.Hour))
+	}()
+	select {
+	case <-other:
+	case <-slow.entered:
+		t.Fatal("notification sent twice")
+	case <-time.After(time.Second):
+		t.Fatal("Dispatch blocked by a slow receiver")
+	}
+
+	close(slow.release)
+	<-done
+}
+
+func TestDispatcher_RetryFailed(t *testing.T) {
+	ctx := context.Background()
+
+	rcv := &fakeReceiver{name: "ops", err: errors.New("forced")}
+	d := newTestDispatcher(t, Route{GroupInterval: common.Duration{Duration: time.Minute}}, rcv)
+
+	alerts := cpuAlerts(1, StateFiring)
+	d.Dispatch(ctx, alerts, t0)
+	require.Len(t, rcv.notifications, 1)
+
+	// a failed notification is sent again after the group interval
+	rcv.err = nil
+	d.Dispatch(ctx, alerts, t0.Add(time.Minute))
+	require.Len(t, rcv.notifications, 2)
+
+	d.Dispatch(ctx, alerts, t0.Add(2*time.Minute))
+	assert.Len(t, rcv.notifications, 2)
+}
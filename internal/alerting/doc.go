@@ -0,0 +1,93 @@
+// Package alerting evaluates alert rules against the metrics kept in storage.
+//
+// A Rule describes a condition on one or more stored metrics, for example
+//
+//	gauge HeapAlloc > 5e+08
+//	counter PollCount < 10
+//
+// The metric name of a rule may be a glob pattern (see path.Match), in which
+// case every matching metric produces its own alert instance. Likewise, every
+// label set of a labeled metric (see metric.Labels) produces its own alert
+// instance, whose MetricLabels can be matched by routes, silences and
+// inhibitions like the labels of the rule. The label matchers of a rule
+// restrict it to the label sets they match. A metric referenced in an
+// expression stands for all its label sets, so it is usually aggregated.
+//
+// Besides thresholds, rules can detect metrics that are not reported anymore:
+//
+//	stale(counter PollCount, 1m0s)
+//	absent(gauge HeapAlloc)
+//
+// and conditions over a window of values observed at previous evaluations:
+//
+//	rate(counter PollCount[5m0s]) < 0.5
+//	increase(counter PollCount[1m0s]) == 0
+//	delta(gauge HeapAlloc[10m0s]) > 1e+08
+//	deriv(gauge HeapAlloc[10m0s]) > 1e+06
+//
+// and arbitrary conditions over several metrics written in the expression
+// language of package expr:
+//
+//	(TotalMemory - FreeMemory) / TotalMemory > 0.9
+//	max(CPUutilization*) > 95
+//
+// Anomaly rules learn the normal range of gauges whose static thresholds
+// differ per host, and fire when a value deviates from it by a number of
+// standard deviations:
+//
+//	anomaly(gauge HeapAlloc) >= 3 sigma
+//
+// Their Baselines are kept in Postgres or in the metric dump file, so they
+// are not learned again after a restart.
+//
+// The Evaluator periodically reads all metrics through storage.Storage.RetrieveAll,
+// or storage.TimestampedStorage.RetrieveAllTimestamped if the storage tracks
+// update times, checks every rule and keeps track of the alerts whose
+// condition currently holds.
+//
+// Alerts are grouped into notifications by a Dispatcher according to the
+// route in the server JSON config, e.g. to get a single notification about
+// all CPUutilization alerts instead of one per core:
+//
+//	"route": {"group_by": ["alertname"], "group_wait": "30s", "group_interval": "5m", "repeat_interval": "4h"}
+//
+// Without a route every alert is notified separately as soon as it fires or
+// is resolved. Child routes send matching alerts to specific receivers and
+// escalate alerts that are not acknowledged in time to further receivers;
+// see Route. Receivers referenced by routes must be configured, otherwise
+// the route is rejected at startup.
+//
+// Notifications are queued in an Outbox before they are sent: a table in
+// Postgres or an append-only file next to the metric dump file. Failed
+// deliveries are retried with exponential backoff and jitter according to the
+// RetryPolicy ("notification_retry" in the server JSON config), e.g.
+//
+//	"notification_retry": {"initial_backoff": "1s", "max_backoff": "5m", "max_attempts": 10}
+//
+// Notifications that run out of attempts become dead letters, which can be
+// retried or deleted through the API. Notifications are removed from the
+// outbox only once they are delivered, so they are delivered at least once
+// even across restarts; receivers may see duplicates.
+//
+// Silences mute notifications about alerts matching their matchers for a
+// period of time. Silenced alerts are still evaluated; expired silences are
+// garbage-collected by the evaluator. Silences are kept in a SilenceStore, a
+// table in Postgres, or in the metric dump file.
+//
+// Inhibition rules ("inhibit_rules" in the server JSON config) mute
+// notifications about alerts while other alerts are firing, e.g. all alerts
+// of a host whose agent is absent; see InhibitRule.
+//
+// Every alert transition is appended to a HistoryStore: a table in Postgres or
+// a MemoryHistory ring buffer saved in the metric dump file. Old transitions
+// are removed according to the HistoryRetention
+// ("alert_history_max_age" and "alert_history_max_count" in the server JSON config).
+//
+// Typical usage:
+//
+//	e, err := alerting.NewEvaluator(storage, rules, 10*time.Second, logger)
+//	if err != nil {
+//	    return err
+//	}
+//	go e.Run(ctx)
+package alerting
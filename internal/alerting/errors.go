@@ -0,0 +1,28 @@
+package alerting
+
+import "errors"
+
+var (
+	ErrorInvalidRuleName     = errors.New("invalid rule name")
+	ErrorInvalidOperator     = errors.New("invalid operator")
+	ErrorInvalidMetricName   = errors.New("invalid metric name or pattern")
+	ErrorDuplicateRuleName   = errors.New("duplicate rule name")
+	ErrorValueNotComparable  = errors.New("metric value is not comparable")
+	ErrorInvalidDuration     = errors.New("invalid duration")
+	ErrorInvalidMatcher      = errors.New("invalid matcher")
+	ErrorInvalidSilence      = errors.New("invalid silence")
+	ErrorSilenceNotFound     = errors.New("silence not found")
+	ErrorInvalidRule         = errors.New("invalid rule")
+	ErrorRuleNotFound        = errors.New("rule not found")
+	ErrorRuleInConfig        = errors.New("rule is defined in the config file")
+	ErrorInvalidRuleKind     = errors.New("invalid rule kind")
+	ErrorUpdateTimeUnknown   = errors.New("metric update time is not tracked by the storage")
+	ErrorInvalidExpr         = errors.New("invalid expression")
+	ErrorInvalidRoute        = errors.New("invalid route")
+	ErrorInvalidInhibitRule  = errors.New("invalid inhibit rule")
+	ErrorUnknownReceiver     = errors.New("unknown receiver")
+	ErrorAlertNotFound       = errors.New("alert not found")
+	ErrorAlertNotFiring      = errors.New("alert is not firing")
+	ErrorInvalidRetryPolicy  = errors.New("invalid retry policy")
+	ErrorOutboxEntryNotFound = errors.New("outbox entry not found")
+)
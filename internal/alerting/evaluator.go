@@ -0,0 +1,476 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+)
+
+// Evaluator periodically evaluates alert rules against the storage
+// and drives the lifecycle of alert instances.
+//
+// Alert states can be persisted either through a StateStore (e.g. the database)
+// or as a section of the metric dump file, see DumpName, DumpState and RestoreState.
+//
+// Values of metrics used by windowed rules (rate, increase, delta, deriv) are
+// kept in memory between evaluations for the longest window. Baselines learned
+// by anomaly rules are persisted either through a BaselineStore or as a
+// section of the dump file, see Baselines.
+//
+// Rules can be added, updated, disabled and deleted at runtime; changes are
+// persisted through a RuleStore and picked up by the next evaluation.
+//
+// After every evaluation the alerts are passed to a Dispatcher, which groups
+// them and notifies receivers according to the route, see SetRoute, either
+// directly or through a durable Outbox retrying failed deliveries.
+// Transitions are also published to watchers, see Watch.
+type Evaluator struct {
+	storage     storage.Storage
+	rules       []Rule
+	ruleSet     ruleSet
+	interval    time.Duration
+	logger      logger.Logger
+	store       StateStore
+	dispatcher  *Dispatcher
+	silences    *Silences
+	inhibit     []InhibitRule
+	history     HistoryStore
+	retention   HistoryRetention
+	series      *seriesBuffer
+	baselines   *Baselines
+	bstore      BaselineStore
+	evaluations map[string]ruleEvaluation // last evaluation of each rule
+	watchers    watchers
+	outbox      *Outbox
+	rulesMu     sync.Mutex // serializes rule modifications
+	mu          sync.Mutex
+	alerts      map[string]*Alert
+}
+
+// NewEvaluator creates an Evaluator for the given rules.
+// Returns an error if any of the rules is invalid.
+func NewEvaluator(s storage.Storage, rules []Rule, interval time.Duration, l logger.Logger) (*Evaluator, error) {
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+
+	dispatcher, err := NewDispatcher(DefaultRoute(), l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Evaluator{
+		storage:     s,
+		rules:       rules,
+		ruleSet:     ruleSet{config: rules},
+		interval:    interval,
+		logger:      l,
+		dispatcher:  dispatcher,
+		silences:    NewSilences(),
+		history:     NewMemoryHistory(DefaultHistoryCapacity),
+		series:      newSeriesBuffer(),
+		baselines:   NewBaselines(),
+		evaluations: make(map[string]ruleEvaluation),
+		alerts:      make(map[string]*Alert),
+	}, nil
+}
+
+// SetHistoryStore sets the store every transition is appended to.
+// By default transitions are kept in memory, see MemoryHistory.
+func (e *Evaluator) SetHistoryStore(h HistoryStore) {
+	e.history = h
+}
+
+// SetHistoryRetention sets how long and how many transitions are kept in the
+// history. It is applied after every evaluation with transitions if the
+// history store implements HistoryPruner.
+func (e *Evaluator) SetHistoryRetention(r HistoryRetention) {
+	e.retention = r
+}
+
+// History returns the store of alert transitions.
+func (e *Evaluator) History() HistoryStore {
+	return e.history
+}
+
+// Silences returns the silences consulted before notifying about alerts.
+func (e *Evaluator) Silences() *Silences {
+	return e.silences
+}
+
+// Baselines returns the baselines learned by anomaly rules.
+func (e *Evaluator) Baselines() *Baselines {
+	return e.baselines
+}
+
+// SetStateStore sets the store used to persist alert states after every evaluation.
+func (e *Evaluator) SetStateStore(s StateStore) {
+	e.store = s
+}
+
+// SetBaselineStore sets the store used to persist baselines of anomaly rules
+// after every evaluation.
+func (e *Evaluator) SetBaselineStore(s BaselineStore) {
+	e.bstore = s
+}
+
+// AddReceiver registers a receiver notified when alerts start firing or get resolved.
+func (e *Evaluator) AddReceiver(r Receiver) {
+	e.dispatcher.AddReceiver(r)
+}
+
+// SetInhibitRules sets the rules muting notifications about alerts while
+// other alerts are firing. Returns an error if any of the rules is invalid.
+func (e *Evaluator) SetInhibitRules(rules []InhibitRule) error {
+	if err := ValidateInhibitRules(rules); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.inhibit = rules
+	return nil
+}
+
+// SetRoute sets the routing tree deciding which receivers are notified about
+// alerts, how alerts are grouped into notifications and how often
+// notifications are sent. Receivers must be added before. By default every
+// receiver is notified about every alert separately and immediately, see
+// DefaultRoute.
+func (e *Evaluator) SetRoute(r Route) error {
+	return e.dispatcher.SetRoute(r)
+}
+
+// Routes returns the receivers a hypothetical alert would be routed to,
+// including escalation receivers, without notifying them.
+func (e *Evaluator) Routes(a Alert) []RouteTarget {
+	return e.dispatcher.Routes(&a)
+}
+
+// SetOutbox makes notifications go through the outbox, which retries failed
+// deliveries; see Outbox. The outbox must be run, see Outbox.Run.
+func (e *Evaluator) SetOutbox(o *Outbox) {
+	e.dispatcher.SetOutbox(o)
+	e.outbox = o
+}
+
+// Outbox returns the outbox set by SetOutbox or nil.
+func (e *Evaluator) Outbox() *Outbox {
+	return e.outbox
+}
+
+// LoadState restores alert states and baselines from the state and baseline
+// stores, if they are set.
+func (e *Evaluator) LoadState(ctx context.Context) error {
+	if e.store != nil {
+		alerts, err := e.store.LoadAlerts(ctx)
+		if err != nil {
+			return err
+		}
+		e.setAlerts(alerts)
+	}
+
+	if e.bstore != nil {
+		baselines, err := e.bstore.LoadBaselines(ctx)
+		if err != nil {
+			return err
+		}
+		e.baselines.Set(baselines)
+	}
+
+	return nil
+}
+
+// setAlerts replaces the tracked alerts with the given ones.
+func (e *Evaluator) setAlerts(alerts []Alert) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.alerts = make(map[string]*Alert, len(alerts))
+	for i := range alerts {
+		a := alerts[i]
+		e.alerts[a.Key()] = &a
+	}
+}
+
+// rule returns the rule with the given name or nil.
+func (e *Evaluator) rule(name string) *Rule {
+	for i := range e.rules {
+		if e.rules[i].Name == name {
+			return &e.rules[i]
+		}
+	}
+	return nil
+}
+
+// Evaluate checks all rules against the current content of the storage,
+// advances alert states and returns the transitions that happened.
+// The now parameter is used as the evaluation timestamp; stale rules compare
+// it with the update times of metrics, so it must come from the same clock
+// as the storage's.
+//
+// A rule that cannot be evaluated, e.g. because its expression is ambiguous,
+// does not stop the evaluation of the other rules: its alerts keep their
+// states, the error is recorded as the rule's health (see RuleStatuses) and
+// returned together with the errors of other failed rules.
+func (e *Evaluator) Evaluate(ctx context.Context, now time.Time) ([]Transition, error) {
+
+	metrics, err := e.retrieveMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+
+	var transitions []Transition
+	record := func(a *Alert, from State, changed bool) {
+		if changed {
+			a.SilencedBy = e.silences.Silencing(a, now)
+			transitions = append(transitions, Transition{Alert: *a, From: from, To: a.State, At: now})
+		}
+	}
+
+	if err := e.series.record(e.rules, metrics, now); err != nil {
+		e.mu.Unlock()
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	evaluations := make(map[string]ruleEvaluation)
+	failed := make(map[string]bool)
+	var ruleErrs []error
+
+	for i := range e.rules {
+		r := &e.rules[i]
+		if r.Disabled {
+			continue
+		}
+
+		samples, err := r.check(metrics, e.series, e.baselines, now)
+		evaluations[r.Name] = ruleEvaluation{at: now, err: err}
+		if err != nil {
+			ruleErrs = append(ruleErrs, fmt.Errorf("rule %s: %w", r.Name, err))
+			failed[r.Name] = true
+			continue
+		}
+
+		for _, s := range samples {
+			key := alertKey(r.Name, s.metricType, s.metricName, s.metricLabels)
+			seen[key] = struct{}{}
+
+			a, exists := e.alerts[key]
+
+			if s.holds {
+				if !exists {
+					a = &Alert{Rule: r.Name, MetricType: s.metricType, MetricName: s.metricName, MetricLabels: s.metricLabels, State: StateInactive}
+					e.alerts[key] = a
+				}
+				a.Threshold = s.threshold
+				a.Labels = r.Labels
+				from, changed := a.conditionHeld(r, s.value, now)
+				record(a, from, changed)
+			} else if exists {
+				a.Value = s.value
+				a.Labels = r.Labels
+				from, changed := a.conditionNotHeld(r, now)
+				record(a, from, changed)
+			}
+		}
+	}
+
+	e.baselines.prune(now, failed)
+	e.recordEvaluations(evaluations)
+
+	for key, a := range e.alerts {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		// alerts of a failed rule keep their state until it is evaluated again
+		if ev, ok := evaluations[a.Rule]; ok && ev.err != nil {
+			continue
+		}
+
+		// rule was removed or disabled
+		r := e.rule(a.Rule)
+		if r == nil || r.Disabled {
+			delete(e.alerts, key)
+			continue
+		}
+
+		// metric is not present anymore or an absent metric has appeared
+		a.Labels = r.Labels
+		from, changed := a.conditionNotHeld(r, now)
+		record(a, from, changed)
+	}
+
+	for key, a := range e.alerts {
+		if a.State == StateInactive || (a.State == StateResolved && now.Sub(a.ResolvedAt) >= ResolvedRetention) {
+			delete(e.alerts, key)
+			continue
+		}
+		a.SilencedBy = e.silences.Silencing(a, now)
+	}
+
+	// inhibition depends on the final states of all alerts
+	for _, a := range e.alerts {
+		a.InhibitedBy = inhibitors(e.inhibit, e.alerts, a)
+	}
+	for i := range transitions {
+		if a, ok := e.alerts[transitions[i].Alert.Key()]; ok {
+			transitions[i].Alert.InhibitedBy = a.InhibitedBy
+		}
+	}
+
+	e.mu.Unlock()
+
+	if n := e.silences.GC(now); n > 0 {
+		e.logger.Infow("Expired silences removed", "count", n)
+	}
+
+	for _, t := range transitions {
+		e.logger.Infow("Alert state changed", "rule", t.Alert.Rule, "metric", t.Alert.MetricName,
+			"from", t.From, "to", t.To, "value", t.Alert.Value)
+	}
+
+	if len(transitions) > 0 {
+		e.watchers.publish(transitions)
+
+		if err := e.history.AppendHistory(ctx, transitions); err != nil {
+			e.logger.Errorw("Alert history error", "err", err)
+		}
+		e.pruneHistory(ctx, now)
+	}
+
+	e.dispatcher.Dispatch(ctx, e.Alerts(), now)
+
+	ruleErr := errors.Join(ruleErrs...)
+
+	if e.store != nil {
+		if err := e.store.SaveAlerts(ctx, e.Alerts()); err != nil {
+			return transitions, errors.Join(ruleErr, err)
+		}
+	}
+
+	if e.bstore != nil {
+		if err := e.bstore.SaveBaselines(ctx, e.baselines.List()); err != nil {
+			return transitions, errors.Join(ruleErr, err)
+		}
+	}
+
+	return transitions, ruleErr
+}
+
+// pruneHistory applies the history retention. Errors are logged.
+func (e *Evaluator) pruneHistory(ctx context.Context, now time.Time) {
+	pruner, ok := e.history.(HistoryPruner)
+	if !ok || (e.retention.MaxAge <= 0 && e.retention.MaxCount <= 0) {
+		return
+	}
+
+	var before time.Time
+	if e.retention.MaxAge > 0 {
+		before = now.Add(-e.retention.MaxAge)
+	}
+
+	n, err := pruner.PruneHistory(ctx, before, e.retention.MaxCount)
+	if err != nil {
+		e.logger.Errorw("Alert history retention error", "err", err)
+		return
+	}
+	if n > 0 {
+		e.logger.Infow("Old alert transitions removed", "count", n)
+	}
+}
+
+// Alerts returns a snapshot of tracked alerts sorted by rule and metric name.
+func (e *Evaluator) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := make([]Alert, 0, len(e.alerts))
+	for _, a := range e.alerts {
+		result = append(result, *a)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rule != result[j].Rule {
+			return result[i].Rule < result[j].Rule
+		}
+		if result[i].MetricName != result[j].MetricName {
+			return result[i].MetricName < result[j].MetricName
+		}
+		return result[i].MetricLabels.String() < result[j].MetricLabels.String()
+	})
+
+	return result
+}
+
+// Acknowledge marks the firing alert with the given key as acknowledged,
+// which stops escalations not reached yet, see Escalation. The
+// acknowledgement is cleared when the alert becomes active again; acknowledging
+// an acknowledged alert keeps the first acknowledgement.
+func (e *Evaluator) Acknowledge(key, by string, now time.Time) (Alert, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	a, ok := e.alerts[key]
+	if !ok {
+		return Alert{}, ErrorAlertNotFound
+	}
+	if a.State != StateFiring {
+		return Alert{}, ErrorAlertNotFiring
+	}
+
+	if a.AcknowledgedAt.IsZero() {
+		a.AcknowledgedBy = by
+		a.AcknowledgedAt = now
+	}
+	return *a, nil
+}
+
+// DumpName returns the name of the dump file section holding alert states.
+func (e *Evaluator) DumpName() string {
+	return "alerts"
+}
+
+// DumpState serializes alert states for the dump file.
+func (e *Evaluator) DumpState(ctx context.Context) ([]byte, error) {
+	return json.Marshal(e.Alerts())
+}
+
+// RestoreState restores alert states from the dump file.
+func (e *Evaluator) RestoreState(ctx context.Context, data []byte) error {
+	var alerts []Alert
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		return err
+	}
+	e.setAlerts(alerts)
+	return nil
+}
+
+// Run evaluates the rules every interval until the context is cancelled.
+func (e *Evaluator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Alert evaluator received cancellation signal. Exiting...")
+			return
+		case now := <-ticker.C:
+			if _, err := e.Evaluate(ctx, now); err != nil {
+				e.logger.Errorw("Alert evaluation error", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,743 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/expr"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type faultyStorage struct {
+	memory.MemStorage
+}
+
+func (f *faultyStorage) RetrieveAll(ctx context.Context) ([]metric.Metric, error) {
+	return nil, errors.New("forced error in RetrieveAll")
+}
+
+func (f *faultyStorage) RetrieveAllTimestamped(ctx context.Context) ([]storage.TimestampedMetric, error) {
+	return nil, errors.New("forced error in RetrieveAllTimestamped")
+}
+
+// untimedStorage hides the update times tracked by MemStorage.
+type untimedStorage struct {
+	storage.Storage
+}
+
+type memoryStateStore struct {
+	alerts []Alert
+	err    error
+}
+
+func (m *memoryStateStore) SaveAlerts(ctx context.Context, alerts []Alert) error {
+	m.alerts = alerts
+	return m.err
+}
+
+func (m *memoryStateStore) LoadAlerts(ctx context.Context) ([]Alert, error) {
+	return m.alerts, m.err
+}
+
+var t0 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func heapRule() Rule {
+	return Rule{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6}
+}
+
+func newTestEvaluator(t *testing.T, rules ...Rule) (*Evaluator, *memory.MemStorage) {
+	t.Helper()
+	s := memory.NewMemStorage()
+	e, err := NewEvaluator(s, rules, time.Second, logger.GetLogger())
+	require.NoError(t, err)
+	return e, s
+}
+
+func setGauge(t *testing.T, s *memory.MemStorage, name string, v float64) {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.Update(ctx, metric.NewGauge(name), v); err != nil {
+		require.NoError(t, s.Add(ctx, metric.MustNewGauge(name, v)))
+	}
+}
+
+func TestNewEvaluator_InvalidRule(t *testing.T) {
+	_, err := NewEvaluator(memory.NewMemStorage(), []Rule{{Name: "bad"}}, time.Second, logger.GetLogger())
+	require.Error(t, err)
+}
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t,
+		heapRule(),
+		Rule{Name: "PollCountLow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<", Threshold: 10},
+		Rule{Name: "CPUHigh", MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization*", Operator: ">", Threshold: 95},
+	)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	setGauge(t, s, "CPUutilization1", 99)
+	setGauge(t, s, "CPUutilization2", 10)
+	require.NoError(t, s.Add(ctx, metric.MustNewCounter("PollCount", 5)))
+
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, transitions, 3)
+	for _, tr := range transitions {
+		assert.Equal(t, StateInactive, tr.From)
+		assert.Equal(t, StateFiring, tr.To)
+	}
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 3)
+	assert.Equal(t, "CPUHigh", alerts[0].Rule)
+	assert.Equal(t, "CPUutilization1", alerts[0].MetricName)
+	assert.Equal(t, "HeapAllocHigh", alerts[1].Rule)
+	assert.Equal(t, 600e6, alerts[1].Value)
+	assert.Equal(t, t0, alerts[1].FiredAt)
+	assert.Equal(t, "PollCountLow", alerts[2].Rule)
+
+	// PollCount grows above the threshold, heap stays high
+	require.NoError(t, s.Update(ctx, metric.NewCounter("PollCount"), int64(10)))
+	setGauge(t, s, "HeapAlloc", 700e6)
+
+	t1 := t0.Add(time.Minute)
+	transitions, err = e.Evaluate(ctx, t1)
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, "PollCountLow", transitions[0].Alert.Rule)
+	assert.Equal(t, StateResolved, transitions[0].To)
+
+	alerts = e.Alerts()
+	require.Len(t, alerts, 3)
+	assert.Equal(t, 700e6, alerts[1].Value)
+	assert.Equal(t, t0, alerts[1].FiredAt, "firing time must not change while the alert keeps firing")
+	assert.Equal(t, StateResolved, alerts[2].State)
+	assert.Equal(t, t1, alerts[2].ResolvedAt)
+}
+
+func TestEvaluator_Lifecycle_For(t *testing.T) {
+	ctx := context.Background()
+
+	r := heapRule()
+	r.For = common.Duration{Duration: time.Minute}
+	e, s := newTestEvaluator(t, r)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StatePending, transitions[0].To)
+
+	// still pending before "for" elapsed
+	transitions, err = e.Evaluate(ctx, t0.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+	assert.Equal(t, StatePending, e.Alerts()[0].State)
+
+	// noisy value drops: pending alert goes back to inactive and is forgotten
+	setGauge(t, s, "HeapAlloc", 100)
+	transitions, err = e.Evaluate(ctx, t0.Add(40*time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateInactive, transitions[0].To)
+	assert.Empty(t, e.Alerts())
+
+	// condition holds long enough
+	setGauge(t, s, "HeapAlloc", 600e6)
+	start := t0.Add(50 * time.Second)
+	_, err = e.Evaluate(ctx, start)
+	require.NoError(t, err)
+
+	transitions, err = e.Evaluate(ctx, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StatePending, transitions[0].From)
+	assert.Equal(t, StateFiring, transitions[0].To)
+
+	a := e.Alerts()[0]
+	assert.Equal(t, start, a.ActiveAt)
+	assert.Equal(t, start.Add(time.Minute), a.FiredAt)
+}
+
+func TestEvaluator_Lifecycle_KeepFiringFor(t *testing.T) {
+	ctx := context.Background()
+
+	r := heapRule()
+	r.KeepFiringFor = common.Duration{Duration: time.Minute}
+	e, s := newTestEvaluator(t, r)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	assert.Equal(t, StateFiring, e.Alerts()[0].State)
+
+	setGauge(t, s, "HeapAlloc", 100)
+
+	transitions, err := e.Evaluate(ctx, t0.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+	assert.Equal(t, StateFiring, e.Alerts()[0].State)
+
+	transitions, err = e.Evaluate(ctx, t0.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateResolved, transitions[0].To)
+
+	// resolved alert is forgotten after retention
+	_, err = e.Evaluate(ctx, t0.Add(time.Minute+ResolvedRetention))
+	require.NoError(t, err)
+	assert.Empty(t, e.Alerts())
+}
+
+func TestEvaluator_Lifecycle_ResolvedRefires(t *testing.T) {
+	ctx := context.Background()
+	e, s := newTestEvaluator(t, heapRule())
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+
+	setGauge(t, s, "HeapAlloc", 100)
+	_, err = e.Evaluate(ctx, t0.Add(time.Minute))
+	require.NoError(t, err)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	transitions, err := e.Evaluate(ctx, t0.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateResolved, transitions[0].From)
+	assert.Equal(t, StateFiring, transitions[0].To)
+
+	a := e.Alerts()[0]
+	assert.Equal(t, t0.Add(2*time.Minute), a.FiredAt)
+	assert.True(t, a.ResolvedAt.IsZero())
+}
+
+func TestEvaluator_RemovedRule(t *testing.T) {
+	e, _ := newTestEvaluator(t)
+	e.setAlerts([]Alert{{Rule: "gone", MetricType: metric.MetricTypeGauge, MetricName: "x", State: StateFiring}})
+
+	_, err := e.Evaluate(context.Background(), t0)
+	require.NoError(t, err)
+	assert.Empty(t, e.Alerts())
+}
+
+func TestEvaluator_StateStore(t *testing.T) {
+	ctx := context.Background()
+	e, s := newTestEvaluator(t, heapRule())
+
+	store := &memoryStateStore{}
+	e.SetStateStore(store)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, store.alerts, 1)
+
+	// new evaluator picks up the saved state
+	e2, _ := newTestEvaluator(t, heapRule())
+	e2.SetStateStore(store)
+	require.NoError(t, e2.LoadState(ctx))
+	require.Len(t, e2.Alerts(), 1)
+	assert.Equal(t, t0, e2.Alerts()[0].FiredAt)
+
+	store.err = errors.New("forced")
+	_, err = e.Evaluate(ctx, t0.Add(time.Second))
+	require.Error(t, err)
+	require.Error(t, e2.LoadState(ctx))
+}
+
+func TestEvaluator_DumpSection(t *testing.T) {
+	ctx := context.Background()
+	e, s := newTestEvaluator(t, heapRule())
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "alerts", e.DumpName())
+
+	data, err := e.DumpState(ctx)
+	require.NoError(t, err)
+
+	e2, _ := newTestEvaluator(t, heapRule())
+	require.NoError(t, e2.RestoreState(ctx, data))
+	assert.Equal(t, e.Alerts(), e2.Alerts())
+
+	require.Error(t, e2.RestoreState(ctx, []byte("not json")))
+}
+
+func TestEvaluator_Evaluate_StorageError(t *testing.T) {
+	e, err := NewEvaluator(&faultyStorage{}, nil, time.Second, logger.GetLogger())
+	require.NoError(t, err)
+
+	_, err = e.Evaluate(context.Background(), time.Now())
+	require.Error(t, err)
+}
+
+func TestEvaluator_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := memory.NewMemStorage()
+	require.NoError(t, s.Add(ctx, metric.MustNewGauge("HeapAlloc", 600e6)))
+
+	e, err := NewEvaluator(s, []Rule{heapRule()}, 10*time.Millisecond, logger.GetLogger())
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(e.Alerts()) == 1 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("evaluator did not stop")
+	}
+}
+
+type fakeReceiver struct {
+	name          string
+	notifications []*Notification
+	err           error
+}
+
+func (f *fakeReceiver) Name() string {
+	return f.name
+}
+
+func (f *fakeReceiver) Notify(ctx context.Context, n *Notification) error {
+	f.notifications = append(f.notifications, n)
+	return f.err
+}
+
+func TestEvaluator_Notify(t *testing.T) {
+	ctx := context.Background()
+
+	r := heapRule()
+	r.For = common.Duration{Duration: time.Minute}
+	r.Labels = map[string]string{"severity": "page"}
+	e, s := newTestEvaluator(t, r)
+
+	ok := &fakeReceiver{name: "ops"}
+	failing := &fakeReceiver{name: "broken", err: errors.New("forced")}
+	e.AddReceiver(failing)
+	e.AddReceiver(ok)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+
+	// pending is not notified
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	assert.Empty(t, ok.notifications)
+
+	_, err = e.Evaluate(ctx, t0.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, ok.notifications, 1)
+	assert.Len(t, failing.notifications, 1)
+
+	n := ok.notifications[0]
+	assert.Equal(t, "ops", n.Receiver)
+	assert.Equal(t, StateFiring, n.Status)
+	require.Len(t, n.Alerts, 1)
+	assert.Equal(t, "HeapAllocHigh", n.Alerts[0].Rule)
+	assert.Equal(t, map[string]string{"severity": "page"}, n.Alerts[0].Labels)
+
+	// still firing: nothing new
+	_, err = e.Evaluate(ctx, t0.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, ok.notifications, 1)
+
+	setGauge(t, s, "HeapAlloc", 100)
+	_, err = e.Evaluate(ctx, t0.Add(3*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, ok.notifications, 2)
+	assert.Equal(t, StateResolved, ok.notifications[1].Status)
+}
+
+func TestEvaluator_Silenced(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t, heapRule())
+	rcv := &fakeReceiver{name: "ops"}
+	e.AddReceiver(rcv)
+
+	silence, err := e.Silences().Add(ctx, Silence{
+		Matchers:  []Matcher{{Name: MatcherAlertName, Value: "HeapAllocHigh"}},
+		EndsAt:    t0.Add(time.Hour),
+		CreatedBy: "jane",
+	}, t0)
+	require.NoError(t, err)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+
+	// silenced alert still fires but is not notified
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateFiring, transitions[0].To)
+	assert.Equal(t, []string{silence.ID}, transitions[0].Alert.SilencedBy)
+	assert.Empty(t, rcv.notifications)
+
+	// silence expires: the still firing alert is notified, then its resolution
+	_, err = e.Silences().Expire(ctx, silence.ID, t0.Add(time.Minute))
+	require.NoError(t, err)
+
+	_, err = e.Evaluate(ctx, t0.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, e.Alerts()[0].SilencedBy)
+	require.Len(t, rcv.notifications, 1)
+	assert.Equal(t, StateFiring, rcv.notifications[0].Status)
+
+	setGauge(t, s, "HeapAlloc", 100)
+	_, err = e.Evaluate(ctx, t0.Add(3*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, rcv.notifications, 2)
+	assert.Equal(t, StateResolved, rcv.notifications[1].Status)
+
+	// expired silence is garbage-collected
+	_, err = e.Evaluate(ctx, t0.Add(time.Minute+ExpiredSilenceRetention))
+	require.NoError(t, err)
+	assert.Empty(t, e.Silences().List())
+}
+
+func TestEvaluator_History(t *testing.T) {
+	ctx := context.Background()
+
+	r := heapRule()
+	r.For = common.Duration{Duration: time.Minute}
+	e, s := newTestEvaluator(t, r)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	for i := 0; i < 3; i++ {
+		_, err := e.Evaluate(ctx, t0.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+
+	items, total, err := e.History().QueryHistory(ctx, HistoryQuery{Rule: "HeapAllocHigh"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, StateFiring, items[0].To)
+	assert.Equal(t, StatePending, items[1].To)
+
+	h := NewMemoryHistory(10)
+	e.SetHistoryStore(h)
+	assert.Same(t, h, e.History())
+}
+
+func TestEvaluator_Stale(t *testing.T) {
+	ctx := context.Background()
+
+	clock := t0
+	s := memory.NewMemStorage()
+	s.Clock = func() time.Time { return clock }
+
+	r := Rule{Name: "AgentDown", Kind: RuleKindStale, MetricType: metric.MetricTypeCounter, MetricName: "PollCount",
+		StaleAfter: common.Duration{Duration: time.Minute}}
+	e, err := NewEvaluator(s, []Rule{r}, time.Second, logger.GetLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Add(ctx, metric.MustNewCounter("PollCount", 1)))
+
+	transitions, err := e.Evaluate(ctx, t0.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+
+	transitions, err = e.Evaluate(ctx, t0.Add(90*time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateFiring, transitions[0].To)
+	assert.Equal(t, 90.0, transitions[0].Alert.Value)
+	assert.Equal(t, 60.0, transitions[0].Alert.Threshold)
+
+	// the agent is back
+	clock = t0.Add(100 * time.Second)
+	require.NoError(t, s.Update(ctx, metric.NewCounter("PollCount"), int64(1)))
+
+	transitions, err = e.Evaluate(ctx, t0.Add(110*time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, StateResolved, transitions[0].To)
+}
+
+func TestEvaluator_Stale_UpdateTimeUnknown(t *testing.T) {
+	s := memory.NewMemStorage()
+	require.NoError(t, s.Add(context.Background(), metric.MustNewCounter("PollCount", 1)))
+
+	r := Rule{Name: "AgentDown", Kind: RuleKindStale, MetricType: metric.MetricTypeCounter, MetricName: "PollCount",
+		StaleAfter: common.Duration{Duration: time.Minute}}
+	e, err := NewEvaluator(&untimedStorage{s}, []Rule{r}, time.Second, logger.GetLogger())
+	require.NoError(t, err)
+
+	_, err = e.Evaluate(context.Background(), t0)
+	require.ErrorIs(t, err, ErrorUpdateTimeUnknown)
+}
+
+func TestEvaluator_Absent(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t,
+		Rule{Name: "NoHeap", Kind: RuleKindAbsent, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc"},
+		Rule{Name: "NoCPU", Kind: RuleKindAbsent, MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization*"},
+	)
+
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "NoCPU", alerts[0].Rule)
+	assert.Equal(t, "CPUutilization*", alerts[0].MetricName)
+	assert.Equal(t, StateFiring, alerts[0].State)
+	assert.Equal(t, "NoHeap", alerts[1].Rule)
+	assert.Equal(t, "HeapAlloc", alerts[1].MetricName)
+
+	setGauge(t, s, "HeapAlloc", 1)
+	setGauge(t, s, "CPUutilization3", 1)
+
+	transitions, err = e.Evaluate(ctx, t0.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	for _, tr := range transitions {
+		assert.Equal(t, StateResolved, tr.To)
+	}
+}
+
+func TestEvaluator_Rate(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t,
+		Rule{Name: "PollSlow", Kind: RuleKindRate, MetricType: metric.MetricTypeCounter, MetricName: "PollCount",
+			Operator: "<", Threshold: 0.5, Window: common.Duration{Duration: time.Minute}},
+		Rule{Name: "HeapGrowing", Kind: RuleKindDeriv, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc",
+			Operator: ">", Threshold: 1000, Window: common.Duration{Duration: time.Minute}},
+	)
+
+	require.NoError(t, s.Add(ctx, metric.MustNewCounter("PollCount", 100)))
+	setGauge(t, s, "HeapAlloc", 0)
+
+	// a single observation is not enough
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+
+	// 10 polls in 10s, heap grows by 100k/s
+	require.NoError(t, s.Update(ctx, metric.NewCounter("PollCount"), int64(10)))
+	setGauge(t, s, "HeapAlloc", 1e6)
+
+	transitions, err = e.Evaluate(ctx, t0.Add(10*time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, "HeapGrowing", transitions[0].Alert.Rule)
+	assert.Equal(t, 1e5, transitions[0].Alert.Value)
+
+	// the server restarted without restoring the counter: no negative rate
+	s.Data["counter|PollCount"] = metric.MustNewCounter("PollCount", 5)
+	transitions, err = e.Evaluate(ctx, t0.Add(20*time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "HeapGrowing", alerts[0].Rule)
+
+	// the counter stops growing: (10 + 5) / 60s over the window
+	transitions, err = e.Evaluate(ctx, t0.Add(60*time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, "PollSlow", transitions[0].Alert.Rule)
+	assert.Equal(t, StateFiring, transitions[0].To)
+	assert.InDelta(t, 0.25, transitions[0].Alert.Value, 1e-9)
+}
+
+func TestEvaluator_Expr(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t,
+		Rule{Name: "MemoryLow", Kind: RuleKindExpr, Expr: "(TotalMemory - FreeMemory) / TotalMemory > 0.9"},
+		Rule{Name: "CPUHigh", Kind: RuleKindExpr, Expr: "max(CPUutilization*) > 95 && count(CPUutilization*) >= 2"},
+	)
+
+	// no metrics yet: no data, no alerts
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+
+	setGauge(t, s, "TotalMemory", 1000)
+	setGauge(t, s, "FreeMemory", 50)
+	setGauge(t, s, "CPUutilization1", 99)
+	setGauge(t, s, "CPUutilization2", 10)
+
+	transitions, err = e.Evaluate(ctx, t0.Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "CPUHigh", alerts[0].Rule)
+	assert.Equal(t, StateFiring, alerts[0].State)
+	assert.Equal(t, "MemoryLow", alerts[1].Rule)
+	assert.Empty(t, alerts[1].MetricName)
+	assert.InDelta(t, 0.95, alerts[1].Value, 1e-9)
+	assert.Equal(t, 0.9, alerts[1].Threshold)
+
+	setGauge(t, s, "FreeMemory", 500)
+	// division by zero means no data, the alert resolves
+	setGauge(t, s, "TotalMemory", 0)
+
+	transitions, err = e.Evaluate(ctx, t0.Add(2*time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, "MemoryLow", transitions[0].Alert.Rule)
+	assert.Equal(t, StateResolved, transitions[0].To)
+}
+
+func TestEvaluator_Expr_Ambiguous(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t, Rule{Name: "Dup", Kind: RuleKindExpr, Expr: "Dup > 1"})
+	setGauge(t, s, "Dup", 2)
+	require.NoError(t, s.Add(ctx, metric.MustNewCounter("Dup", 2)))
+
+	_, err := e.Evaluate(ctx, t0)
+	require.ErrorIs(t, err, expr.ErrorAmbiguousRef)
+}
+
+func TestEvaluator_Labels(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t, heapRule())
+	for _, host := range []string{"web2", "web1"} {
+		require.NoError(t, s.Add(ctx, &metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": host}, Value: 600e6}))
+	}
+	setGauge(t, s, "HeapAlloc", 100)
+
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, metric.Labels{"host": "web1"}, alerts[0].MetricLabels)
+	assert.Equal(t, metric.Labels{"host": "web2"}, alerts[1].MetricLabels)
+	assert.Equal(t, `HeapAllocHigh|gauge|HeapAlloc{host="web1"}`, alerts[0].Key())
+
+	// metric labels can be matched like rule labels
+	assert.Equal(t, "web2", alertField(&alerts[1], "host"))
+
+	require.NoError(t, s.Update(ctx, &metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": "web1"}}, float64(100)))
+	transitions, err = e.Evaluate(ctx, t0.Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, metric.Labels{"host": "web1"}, transitions[0].Alert.MetricLabels)
+	assert.Equal(t, StateResolved, transitions[0].To)
+}
+
+func TestEvaluator_LabelMatchers(t *testing.T) {
+	ctx := context.Background()
+
+	r := heapRule()
+	r.LabelMatchers = []Matcher{{Name: "host", Value: "web.*", Type: MatchRegexp}}
+	e, s := newTestEvaluator(t, r)
+	for _, host := range []string{"web1", "db1"} {
+		require.NoError(t, s.Add(ctx, &metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": host}, Value: 600e6}))
+	}
+	setGauge(t, s, "HeapAlloc", 600e6)
+
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, metric.Labels{"host": "web1"}, alerts[0].MetricLabels)
+}
+
+func TestEvaluator_RuleStatuses(t *testing.T) {
+	ctx := context.Background()
+
+	disabled := heapRule()
+	disabled.Name = "Disabled"
+	disabled.Disabled = true
+
+	e, s := newTestEvaluator(t, heapRule(), Rule{Name: "Dup", Kind: RuleKindExpr, Expr: "Dup > 1"}, disabled)
+	setGauge(t, s, "HeapAlloc", 600e6)
+	setGauge(t, s, "Dup", 2)
+
+	statuses := e.RuleStatuses()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, RuleHealthUnknown, statuses[0].Health)
+	assert.True(t, statuses[0].LastEvaluatedAt.IsZero())
+
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+
+	// the failing rule does not stop the other ones and its alert keeps its state
+	require.NoError(t, s.Add(ctx, metric.MustNewCounter("Dup", 2)))
+	_, err = e.Evaluate(ctx, t0.Add(time.Second))
+	require.ErrorIs(t, err, expr.ErrorAmbiguousRef)
+	assert.Len(t, e.Alerts(), 2)
+
+	statuses = e.RuleStatuses()
+	assert.Equal(t, RuleHealthOK, statuses[0].Health)
+	assert.Equal(t, t0.Add(time.Second), statuses[0].LastEvaluatedAt)
+	assert.Empty(t, statuses[0].LastError)
+
+	assert.Equal(t, RuleHealthError, statuses[1].Health)
+	assert.Contains(t, statuses[1].LastError, expr.ErrorAmbiguousRef.Error())
+
+	assert.Equal(t, RuleHealthUnknown, statuses[2].Health)
+}
+
+func TestEvaluator_Watch(t *testing.T) {
+	ctx := context.Background()
+	e, s := newTestEvaluator(t, heapRule())
+
+	ch, cancel := e.Watch()
+	setGauge(t, s, "HeapAlloc", 600e6)
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+
+	tr := <-ch
+	assert.Equal(t, StateFiring, tr.To)
+	assert.Equal(t, "HeapAlloc", tr.Alert.MetricName)
+
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+	cancel()
+
+	t.Run("falling behind", func(t *testing.T) {
+		old := WatchBuffer
+		WatchBuffer = 1
+		defer func() { WatchBuffer = old }()
+
+		ch, cancel := e.Watch()
+		defer cancel()
+
+		setGauge(t, s, "HeapAlloc", 100e6)
+		_, err := e.Evaluate(ctx, t0.Add(time.Second))
+		require.NoError(t, err)
+		setGauge(t, s, "HeapAlloc", 600e6)
+		_, err = e.Evaluate(ctx, t0.Add(2*time.Second))
+		require.NoError(t, err)
+
+		tr := <-ch
+		assert.Equal(t, StateResolved, tr.To)
+		_, ok := <-ch
+		assert.False(t, ok)
+	})
+}
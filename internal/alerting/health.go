@@ -0,0 +1,64 @@
+package alerting
+
+import "time"
+
+// RuleHealth is the outcome of the last evaluation of a rule.
+type RuleHealth string
+
+const (
+	RuleHealthUnknown RuleHealth = "unknown" // not evaluated yet or disabled
+	RuleHealthOK      RuleHealth = "ok"
+	RuleHealthError   RuleHealth = "err"
+)
+
+// RuleStatus is a rule with the outcome of its last evaluation.
+type RuleStatus struct {
+	Rule
+	Health          RuleHealth
+	LastError       string    // error of the last evaluation, empty if it succeeded
+	LastEvaluatedAt time.Time // zero if the rule has not been evaluated yet
+}
+
+// ruleEvaluation is the outcome of an evaluation of a rule.
+type ruleEvaluation struct {
+	at  time.Time
+	err error
+}
+
+// RuleStatuses returns the rules in evaluation order with the outcome of
+// their last evaluation. Disabled rules have unknown health.
+func (e *Evaluator) RuleStatuses() []RuleStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := make([]RuleStatus, len(e.rules))
+	for i, r := range e.rules {
+		result[i] = RuleStatus{Rule: r, Health: RuleHealthUnknown}
+
+		ev, ok := e.evaluations[r.Name]
+		if !ok || r.Disabled {
+			continue
+		}
+
+		result[i].LastEvaluatedAt = ev.at
+		result[i].Health = RuleHealthOK
+		if ev.err != nil {
+			result[i].Health = RuleHealthError
+			result[i].LastError = ev.err.Error()
+		}
+	}
+	return result
+}
+
+// recordEvaluations keeps the outcomes of the evaluation of the current rules
+// and forgets removed rules. The caller must hold mu.
+func (e *Evaluator) recordEvaluations(evaluations map[string]ruleEvaluation) {
+	for name, ev := range evaluations {
+		e.evaluations[name] = ev
+	}
+	for name := range e.evaluations {
+		if e.rule(name) == nil {
+			delete(e.evaluations, name)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// DefaultHistoryCapacity is the number of transitions kept by the in-memory history.
+const DefaultHistoryCapacity = 10000
+
+// HistoryQuery filters and paginates alert history.
+// Zero values of the fields mean no filtering.
+type HistoryQuery struct {
+	Rule       string
+	MetricType metric.MetricType
+	MetricName string
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+	Offset     int
+	Limit      int // zero means no limit
+}
+
+// Matches reports whether the transition passes the filters of the query.
+func (q *HistoryQuery) Matches(t *Transition) bool {
+	if q.Rule != "" && t.Alert.Rule != q.Rule {
+		return false
+	}
+	if q.MetricType != "" && t.Alert.MetricType != q.MetricType {
+		return false
+	}
+	if q.MetricName != "" && t.Alert.MetricName != q.MetricName {
+		return false
+	}
+	if !q.From.IsZero() && t.At.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !t.At.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// HistoryRetention limits how long and how many transitions are kept in the history.
+// Zero values mean no limit; the in-memory history is always bounded by its capacity.
+type HistoryRetention struct {
+	MaxAge   time.Duration
+	MaxCount int
+}
+
+// MemoryHistory keeps the latest transitions in a fixed-size ring buffer.
+//
+// The history can be persisted as a section of the metric dump file,
+// see DumpName, DumpState and RestoreState.
+type MemoryHistory struct {
+	mu    sync.Mutex
+	items []Transition
+	start int // index of the oldest transition
+	count int
+}
+
+// NewMemoryHistory creates an in-memory history keeping at most capacity transitions.
+func NewMemoryHistory(capacity int) *MemoryHistory {
+	if capacity <= 0 {
+		capacity = DefaultHistoryCapacity
+	}
+	return &MemoryHistory{items: make([]Transition, capacity)}
+}
+
+// AppendHistory adds transitions, overwriting the oldest ones when the buffer is full.
+func (h *MemoryHistory) AppendHistory(ctx context.Context, transitions []Transition) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, t := range transitions {
+		idx := (h.start + h.count) % len(h.items)
+		h.items[idx] = t
+		if h.count < len(h.items) {
+			h.count++
+		} else {
+			h.start = (h.start + 1) % len(h.items)
+		}
+	}
+
+	return nil
+}
+
+// QueryHistory returns the matching transitions, newest first,
+// and the total number of matching transitions before pagination.
+func (h *MemoryHistory) QueryHistory(ctx context.Context, q HistoryQuery) ([]Transition, int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var result []Transition
+	total := 0
+
+	for i := h.count - 1; i >= 0; i-- {
+		t := &h.items[(h.start+i)%len(h.items)]
+		if !q.Matches(t) {
+			continue
+		}
+
+		if total >= q.Offset && (q.Limit == 0 || len(result) < q.Limit) {
+			result = append(result, *t)
+		}
+		total++
+	}
+
+	return result, total, nil
+}
+
+// PruneHistory removes transitions older than before, unless before is zero,
+// and the oldest transitions exceeding maxCount, unless maxCount is zero.
+// Returns the number of removed transitions.
+func (h *MemoryHistory) PruneHistory(ctx context.Context, before time.Time, maxCount int) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	removed := 0
+	for h.count > 0 {
+		oldest := &h.items[h.start]
+		tooMany := maxCount > 0 && h.count > maxCount
+		tooOld := !before.IsZero() && oldest.At.Before(before)
+		if !tooMany && !tooOld {
+			break
+		}
+		*oldest = Transition{}
+		h.start = (h.start + 1) % len(h.items)
+		h.count--
+		removed++
+	}
+
+	return removed, nil
+}
+
+// transitions returns all transitions, oldest first. The caller must hold the lock.
+func (h *MemoryHistory) transitions() []Transition {
+	result := make([]Transition, h.count)
+	for i := range result {
+		result[i] = h.items[(h.start+i)%len(h.items)]
+	}
+	return result
+}
+
+// DumpName returns the name of the dump file section holding the history.
+func (h *MemoryHistory) DumpName() string {
+	return "history"
+}
+
+// DumpState serializes the transitions, oldest first, for the dump file.
+func (h *MemoryHistory) DumpState(ctx context.Context) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return json.Marshal(h.transitions())
+}
+
+// RestoreState replaces the history with the transitions from the dump file.
+// If there are more transitions than the capacity, the newest ones are kept.
+func (h *MemoryHistory) RestoreState(ctx context.Context, data []byte) error {
+	var transitions []Transition
+	if err := json.Unmarshal(data, &transitions); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.items = make([]Transition, len(h.items))
+	h.start, h.count = 0, 0
+	h.mu.Unlock()
+
+	return h.AppendHistory(ctx, transitions)
+}
+
+// TransitionToDTO converts a transition into its transfer representation.
+func TransitionToDTO(t *Transition) dto.AlertTransition {
+	return dto.AlertTransition{
+		Alert: AlertToDTO(&t.Alert),
+		From:  string(t.From),
+		To:    string(t.To),
+		At:    t.At,
+	}
+}
@@ -0,0 +1,185 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func transition(rule, metricName string, at time.Time) Transition {
+	return Transition{
+		Alert: Alert{Rule: rule, MetricType: metric.MetricTypeGauge, MetricName: metricName, State: StateFiring},
+		From:  StateInactive,
+		To:    StateFiring,
+		At:    at,
+	}
+}
+
+func TestMemoryHistory_Ring(t *testing.T) {
+	ctx := context.Background()
+	h := NewMemoryHistory(3)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, h.AppendHistory(ctx, []Transition{transition("r", "m", t0.Add(time.Duration(i)*time.Minute))}))
+	}
+
+	items, total, err := h.QueryHistory(ctx, HistoryQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, items, 3)
+	assert.Equal(t, t0.Add(4*time.Minute), items[0].At, "newest first")
+	assert.Equal(t, t0.Add(2*time.Minute), items[2].At, "oldest transitions are overwritten")
+}
+
+func TestMemoryHistory_Query(t *testing.T) {
+	ctx := context.Background()
+	h := NewMemoryHistory(0)
+
+	require.NoError(t, h.AppendHistory(ctx, []Transition{
+		transition("HeapAllocHigh", "HeapAlloc", t0),
+		transition("CPUHigh", "CPUutilization1", t0.Add(time.Minute)),
+		transition("CPUHigh", "CPUutilization2", t0.Add(2*time.Minute)),
+		transition("CPUHigh", "CPUutilization1", t0.Add(3*time.Minute)),
+	}))
+
+	tests := []struct {
+		name      string
+		q         HistoryQuery
+		wantTotal int
+		wantAt    []time.Time
+	}{
+		{name: "all", q: HistoryQuery{}, wantTotal: 4, wantAt: []time.Time{t0.Add(3 * time.Minute), t0.Add(2 * time.Minute), t0.Add(time.Minute), t0}},
+		{name: "by rule", q: HistoryQuery{Rule: "HeapAllocHigh"}, wantTotal: 1, wantAt: []time.Time{t0}},
+		{name: "by metric", q: HistoryQuery{MetricName: "CPUutilization1"}, wantTotal: 2, wantAt: []time.Time{t0.Add(3 * time.Minute), t0.Add(time.Minute)}},
+		{name: "by metric type", q: HistoryQuery{MetricType: metric.MetricTypeCounter}, wantTotal: 0},
+		{name: "time range", q: HistoryQuery{From: t0.Add(time.Minute), To: t0.Add(3 * time.Minute)}, wantTotal: 2, wantAt: []time.Time{t0.Add(2 * time.Minute), t0.Add(time.Minute)}},
+		{name: "page", q: HistoryQuery{Offset: 1, Limit: 2}, wantTotal: 4, wantAt: []time.Time{t0.Add(2 * time.Minute), t0.Add(time.Minute)}},
+		{name: "offset past the end", q: HistoryQuery{Offset: 10, Limit: 2}, wantTotal: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, total, err := h.QueryHistory(ctx, tt.q)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+
+			var at []time.Time
+			for _, item := range items {
+				at = append(at, item.At)
+			}
+			assert.Equal(t, tt.wantAt, at)
+		})
+	}
+}
+
+func TestMemoryHistory_Prune(t *testing.T) {
+	ctx := context.Background()
+
+	newHistory := func(t *testing.T) *MemoryHistory {
+		h := NewMemoryHistory(10)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, h.AppendHistory(ctx, []Transition{transition("r", "m", t0.Add(time.Duration(i)*time.Minute))}))
+		}
+		return h
+	}
+
+	tests := []struct {
+		name        string
+		before      time.Time
+		maxCount    int
+		wantRemoved int
+		wantOldest  time.Time
+	}{
+		{name: "no limits", wantRemoved: 0, wantOldest: t0},
+		{name: "by age", before: t0.Add(2 * time.Minute), wantRemoved: 2, wantOldest: t0.Add(2 * time.Minute)},
+		{name: "by count", maxCount: 4, wantRemoved: 1, wantOldest: t0.Add(time.Minute)},
+		{name: "both", before: t0.Add(time.Minute), maxCount: 2, wantRemoved: 3, wantOldest: t0.Add(3 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistory(t)
+			removed, err := h.PruneHistory(ctx, tt.before, tt.maxCount)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRemoved, removed)
+
+			items, total, err := h.QueryHistory(ctx, HistoryQuery{})
+			require.NoError(t, err)
+			assert.Equal(t, 5-tt.wantRemoved, total)
+			assert.Equal(t, tt.wantOldest, items[len(items)-1].At)
+		})
+	}
+
+	t.Run("everything", func(t *testing.T) {
+		h := newHistory(t)
+		removed, err := h.PruneHistory(ctx, t0.Add(time.Hour), 0)
+		require.NoError(t, err)
+		assert.Equal(t, 5, removed)
+
+		// the buffer is still usable
+		require.NoError(t, h.AppendHistory(ctx, []Transition{transition("r", "m", t0.Add(time.Hour))}))
+		_, total, err := h.QueryHistory(ctx, HistoryQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+	})
+}
+
+func TestMemoryHistory_Dump(t *testing.T) {
+	ctx := context.Background()
+
+	h := NewMemoryHistory(3)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, h.AppendHistory(ctx, []Transition{transition("r", "m", t0.Add(time.Duration(i)*time.Minute))}))
+	}
+
+	assert.Equal(t, "history", h.DumpName())
+	data, err := h.DumpState(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "\n")
+
+	// restoring into a smaller buffer keeps the newest transitions
+	restored := NewMemoryHistory(2)
+	require.NoError(t, restored.RestoreState(ctx, data))
+
+	items, total, err := restored.QueryHistory(ctx, HistoryQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, t0.Add(3*time.Minute), items[0].At)
+	assert.Equal(t, t0.Add(2*time.Minute), items[1].At)
+	assert.Equal(t, "r", items[0].Alert.Rule)
+
+	require.Error(t, restored.RestoreState(ctx, []byte("{")))
+}
+
+func TestEvaluator_HistoryRetention(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t, heapRule())
+	e.SetHistoryRetention(HistoryRetention{MaxAge: 90 * time.Second})
+
+	for i := 0; i < 4; i++ {
+		v := 600e6
+		if i%2 == 1 {
+			v = 100
+		}
+		setGauge(t, s, "HeapAlloc", v)
+		_, err := e.Evaluate(ctx, t0.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+
+	items, total, err := e.History().QueryHistory(ctx, HistoryQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, t0.Add(2*time.Minute), items[1].At)
+}
+
+func TestTransitionToDTO(t *testing.T) {
+	tr := transition("HeapAllocHigh", "HeapAlloc", t0)
+	d := TransitionToDTO(&tr)
+	assert.Equal(t, "inactive", d.From)
+	assert.Equal(t, "firing", d.To)
+	assert.Equal(t, t0, d.At)
+	assert.Equal(t, "HeapAllocHigh", d.Alert.Name)
+}
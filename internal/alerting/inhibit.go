@@ -0,0 +1,94 @@
+package alerting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// InhibitRule mutes notifications about target alerts while a source alert is firing.
+//
+// An alert matching all TargetMatchers is inhibited by a firing alert matching
+// all SourceMatchers if both have the same values of all Equal fields and
+// labels, e.g. an absent PollCount alert of a host can inhibit all other
+// alerts with the same "host" label:
+//
+//	{
+//	  "source_matchers": [{"name": "alertname", "value": "AgentDown"}],
+//	  "target_matchers": [{"name": "alertname", "value": "AgentDown", "type": "!="}],
+//	  "equal": ["host"]
+//	}
+//
+// An alert never inhibits itself. Inhibited alerts are still evaluated.
+type InhibitRule struct {
+	SourceMatchers []Matcher `json:"source_matchers"`
+	TargetMatchers []Matcher `json:"target_matchers"`
+	Equal          []string  `json:"equal,omitempty"`
+}
+
+// Validate checks the rule and compiles its matchers.
+func (r *InhibitRule) Validate() error {
+	if len(r.SourceMatchers) == 0 {
+		return fmt.Errorf("%w: no source matchers", ErrorInvalidInhibitRule)
+	}
+	if len(r.TargetMatchers) == 0 {
+		return fmt.Errorf("%w: no target matchers", ErrorInvalidInhibitRule)
+	}
+	if err := ValidateMatchers(r.SourceMatchers); err != nil {
+		return err
+	}
+	if err := ValidateMatchers(r.TargetMatchers); err != nil {
+		return err
+	}
+	for _, name := range r.Equal {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: empty equal label", ErrorInvalidInhibitRule)
+		}
+	}
+	return nil
+}
+
+// ValidateInhibitRules validates all inhibition rules.
+func ValidateInhibitRules(rules []InhibitRule) error {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inhibits reports whether the source alert inhibits the target alert.
+func (r *InhibitRule) inhibits(source, target *Alert) bool {
+	if source.State != StateFiring || source.Key() == target.Key() {
+		return false
+	}
+	if !matchAll(r.TargetMatchers, target) || !matchAll(r.SourceMatchers, source) {
+		return false
+	}
+	for _, name := range r.Equal {
+		if alertField(source, name) != alertField(target, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// inhibitors returns the sorted keys of the alerts inhibiting the target alert.
+func inhibitors(rules []InhibitRule, alerts map[string]*Alert, target *Alert) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var result []string
+	for key, source := range alerts {
+		for i := range rules {
+			if rules[i].inhibits(source, target) {
+				result = append(result, key)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,117 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInhibitRule_Validate(t *testing.T) {
+	source := []Matcher{{Name: MatcherAlertName, Value: "AgentDown"}}
+	target := []Matcher{{Name: "severity", Value: "warning"}}
+
+	tests := []struct {
+		name    string
+		rule    InhibitRule
+		wantErr error
+	}{
+		{"valid", InhibitRule{SourceMatchers: source, TargetMatchers: target, Equal: []string{"host"}}, nil},
+		{"no source", InhibitRule{TargetMatchers: target}, ErrorInvalidInhibitRule},
+		{"no target", InhibitRule{SourceMatchers: source}, ErrorInvalidInhibitRule},
+		{"invalid matcher", InhibitRule{SourceMatchers: source, TargetMatchers: []Matcher{{Name: "x", Value: "(", Type: MatchRegexp}}}, ErrorInvalidMatcher},
+		{"empty equal", InhibitRule{SourceMatchers: source, TargetMatchers: target, Equal: []string{""}}, ErrorInvalidInhibitRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestInhibitRule_Inhibits(t *testing.T) {
+	r := InhibitRule{
+		SourceMatchers: []Matcher{{Name: MatcherAlertName, Value: "AgentDown"}},
+		TargetMatchers: []Matcher{{Name: "severity", Value: "warning"}},
+		Equal:          []string{"host"},
+	}
+	require.NoError(t, r.Validate())
+
+	source := Alert{Rule: "AgentDown", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", State: StateFiring,
+		Labels: map[string]string{"host": "a", "severity": "warning"}}
+	target := Alert{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", State: StateFiring,
+		Labels: map[string]string{"host": "a", "severity": "warning"}}
+
+	assert.True(t, r.inhibits(&source, &target))
+
+	// an alert never inhibits itself
+	assert.False(t, r.inhibits(&source, &source))
+
+	otherHost := target
+	otherHost.Labels = map[string]string{"host": "b", "severity": "warning"}
+	assert.False(t, r.inhibits(&source, &otherHost))
+
+	critical := target
+	critical.Labels = map[string]string{"host": "a", "severity": "critical"}
+	assert.False(t, r.inhibits(&source, &critical))
+
+	pending := source
+	pending.State = StatePending
+	assert.False(t, r.inhibits(&pending, &target))
+}
+
+func TestEvaluator_Inhibit(t *testing.T) {
+	ctx := context.Background()
+
+	down := Rule{Name: "AgentDown", Kind: RuleKindAbsent, MetricType: metric.MetricTypeCounter, MetricName: "PollCount",
+		Labels: map[string]string{"host": "a"}}
+	heap := heapRule()
+	heap.Labels = map[string]string{"host": "a"}
+
+	e, s := newTestEvaluator(t, down, heap)
+	require.NoError(t, e.SetInhibitRules([]InhibitRule{{
+		SourceMatchers: []Matcher{{Name: MatcherAlertName, Value: "AgentDown"}},
+		TargetMatchers: []Matcher{{Name: MatcherAlertName, Value: "AgentDown", Type: MatchNotEqual}},
+		Equal:          []string{"host"},
+	}}))
+
+	rcv := &fakeReceiver{name: "ops"}
+	e.AddReceiver(rcv)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+
+	transitions, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "AgentDown", alerts[0].Rule)
+	assert.Empty(t, alerts[0].InhibitedBy)
+	assert.Equal(t, []string{alerts[0].Key()}, alerts[1].InhibitedBy)
+	assert.Equal(t, alerts[1].InhibitedBy, transitions[1].Alert.InhibitedBy)
+
+	// only the source alert is notified
+	require.Len(t, rcv.notifications, 1)
+	assert.Equal(t, "AgentDown", rcv.notifications[0].Alerts[0].Rule)
+
+	// source alert resolves: the target alert is not inhibited anymore and is notified
+	require.NoError(t, s.Add(ctx, metric.MustNewCounter("PollCount", 1)))
+	_, err = e.Evaluate(ctx, t0.Add(time.Minute))
+	require.NoError(t, err)
+
+	alerts = e.Alerts()
+	assert.Empty(t, alerts[1].InhibitedBy)
+	require.Len(t, rcv.notifications, 3)
+
+	assert.ErrorIs(t, e.SetInhibitRules([]InhibitRule{{}}), ErrorInvalidInhibitRule)
+}
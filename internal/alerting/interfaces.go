@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"context"
+	"time"
+)
+
+// StateStore persists alert states so that they survive server restarts.
+type StateStore interface {
+	// SaveAlerts replaces the stored alert states with the given ones.
+	SaveAlerts(ctx context.Context, alerts []Alert) error
+
+	// LoadAlerts returns previously saved alert states.
+	LoadAlerts(ctx context.Context) ([]Alert, error)
+}
+
+// BaselineStore persists baselines of anomaly rules so that they survive server restarts.
+type BaselineStore interface {
+	// SaveBaselines replaces the stored baselines with the given ones.
+	SaveBaselines(ctx context.Context, baselines []Baseline) error
+
+	// LoadBaselines returns previously saved baselines.
+	LoadBaselines(ctx context.Context) ([]Baseline, error)
+}
+
+// SilenceStore persists silences so that they survive server restarts.
+type SilenceStore interface {
+	// SaveSilences replaces the stored silences with the given ones.
+	SaveSilences(ctx context.Context, silences []Silence) error
+
+	// LoadSilences returns previously saved silences.
+	LoadSilences(ctx context.Context) ([]Silence, error)
+}
+
+// Notifier delivers alert notifications to an external system.
+type Notifier interface {
+	// Notify sends the notification.
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// Receiver is a named destination of notifications, e.g. a set of webhooks of a team.
+type Receiver interface {
+	Notifier
+
+	// Name returns the unique name of the receiver.
+	Name() string
+}
+
+// HistoryStore keeps an append-only history of alert transitions.
+type HistoryStore interface {
+	AppendHistory(ctx context.Context, transitions []Transition) error
+	QueryHistory(ctx context.Context, q HistoryQuery) ([]Transition, int, error)
+}
+
+// HistoryPruner is implemented by history stores supporting retention.
+type HistoryPruner interface {
+	// PruneHistory removes transitions older than before, unless before is zero,
+	// and the oldest transitions exceeding maxCount, unless maxCount is zero.
+	// Returns the number of removed transitions.
+	PruneHistory(ctx context.Context, before time.Time, maxCount int) (int, error)
+}
+
+// RuleStore persists alert rules managed at runtime.
+type RuleStore interface {
+	SaveRules(ctx context.Context, rules []Rule) error
+	LoadRules(ctx context.Context) ([]Rule, error)
+}
+
+// OutboxStore persists notifications queued for delivery, see Outbox.
+type OutboxStore interface {
+	// SaveOutboxEntry inserts the entry or replaces the entry with the same ID.
+	SaveOutboxEntry(ctx context.Context, e OutboxEntry) error
+
+	// DeleteOutboxEntry removes the entry with the given ID, if it exists.
+	DeleteOutboxEntry(ctx context.Context, id string) error
+
+	// LoadOutbox returns the stored entries.
+	LoadOutbox(ctx context.Context) ([]OutboxEntry, error)
+}
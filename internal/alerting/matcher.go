@@ -0,0 +1,187 @@
+package alerting
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Names matched against alert fields rather than labels.
+const (
+	MatcherAlertName  = "alertname"   // name of the rule
+	MatcherMetricName = "metric_name" // name of the metric
+	MatcherMetricType = "metric_type" // type of the metric
+)
+
+// MatchType is the way a Matcher compares values.
+type MatchType string
+
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher matches a field or a label of an alert against a value.
+//
+// Name is either one of MatcherAlertName, MatcherMetricName and MatcherMetricType
+// or a label name, looked up in the labels of the rule and then in the labels
+// of the metric; a missing label matches as an empty string.
+// Regular expressions are anchored at both ends. An empty Type means MatchEqual.
+type Matcher struct {
+	Name  string    `json:"name"`
+	Value string    `json:"value"`
+	Type  MatchType `json:"type,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Validate checks the matcher and compiles its regular expression.
+func (m *Matcher) Validate() error {
+	if strings.TrimSpace(m.Name) == "" {
+		return fmt.Errorf("%w: empty name", ErrorInvalidMatcher)
+	}
+
+	switch m.Type {
+	case "", MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		re, err := compileMatcherRegexp(m.Value)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrorInvalidMatcher, err)
+		}
+		m.re = re
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrorInvalidMatcher, m.Type)
+	}
+
+	return nil
+}
+
+// compileMatcherRegexp compiles an anchored regular expression.
+func compileMatcherRegexp(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Matches reports whether the alert matches.
+// An invalid regular expression matches nothing.
+func (m *Matcher) Matches(a *Alert) bool {
+	return m.matchesValue(alertField(a, m.Name))
+}
+
+// matchesValue reports whether the value of the named field or label matches.
+func (m *Matcher) matchesValue(v string) bool {
+	switch m.Type {
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexp, MatchNotRegexp:
+		re := m.re
+		if re == nil {
+			var err error
+			if re, err = compileMatcherRegexp(m.Value); err != nil {
+				return false
+			}
+		}
+		return re.MatchString(v) == (m.Type == MatchRegexp)
+	default:
+		return v == m.Value
+	}
+}
+
+// String returns the matcher in the form name=value.
+func (m *Matcher) String() string {
+	t := m.Type
+	if t == "" {
+		t = MatchEqual
+	}
+	return fmt.Sprintf("%s%s%q", m.Name, t, m.Value)
+}
+
+// alertField returns the value of the named alert field, rule label or metric label.
+func alertField(a *Alert, name string) string {
+	switch name {
+	case MatcherAlertName:
+		return a.Rule
+	case MatcherMetricName:
+		return a.MetricName
+	case MatcherMetricType:
+		return string(a.MetricType)
+	default:
+		if v, ok := a.Labels[name]; ok {
+			return v
+		}
+		return a.MetricLabels[name]
+	}
+}
+
+// ValidateMatchers validates all matchers.
+func ValidateMatchers(matchers []Matcher) error {
+	for i := range matchers {
+		if err := matchers[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseMatchers parses a comma-separated list of matchers in the form
+// returned by Matcher.String, e.g. `alertname="CPUHigh", severity=~"page|warning"`.
+// Quotes around a value are optional unless it contains a comma.
+// The matchers are validated.
+func ParseMatchers(s string) ([]Matcher, error) {
+	var result []Matcher
+
+	for rest := strings.TrimSpace(s); rest != ""; rest = strings.TrimSpace(rest) {
+		i := strings.IndexAny(rest, "=!")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrorInvalidMatcher, rest)
+		}
+		m := Matcher{Name: strings.TrimSpace(rest[:i])}
+		rest = rest[i:]
+
+		for _, t := range []MatchType{MatchRegexp, MatchNotRegexp, MatchNotEqual, MatchEqual} {
+			if strings.HasPrefix(rest, string(t)) {
+				m.Type = t
+				break
+			}
+		}
+		if m.Type == "" {
+			return nil, fmt.Errorf("%w: %s", ErrorInvalidMatcher, rest)
+		}
+		rest = strings.TrimSpace(rest[len(m.Type):])
+
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrorInvalidMatcher, rest, err)
+			}
+			m.Value, _ = strconv.Unquote(quoted)
+			rest = strings.TrimSpace(rest[len(quoted):])
+			if rest != "" && rest[0] != ',' {
+				return nil, fmt.Errorf("%w: unexpected %s", ErrorInvalidMatcher, rest)
+			}
+			rest = strings.TrimPrefix(rest, ",")
+		} else {
+			m.Value, rest, _ = strings.Cut(rest, ",")
+			m.Value = strings.TrimSpace(m.Value)
+		}
+
+		result = append(result, m)
+	}
+
+	if err := ValidateMatchers(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// matchAll reports whether the alert matches all matchers.
+func matchAll(matchers []Matcher, a *Alert) bool {
+	for i := range matchers {
+		if !matchers[i].Matches(a) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package alerting
+
+import (
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Matcher
+		wantErr bool
+	}{
+		{name: "equal", m: Matcher{Name: "alertname", Value: "x"}},
+		{name: "explicit equal", m: Matcher{Name: "alertname", Value: "x", Type: MatchEqual}},
+		{name: "regexp", m: Matcher{Name: "metric_name", Value: "CPU.*", Type: MatchRegexp}},
+		{name: "empty name", m: Matcher{Name: " ", Value: "x"}, wantErr: true},
+		{name: "bad regexp", m: Matcher{Name: "metric_name", Value: "(", Type: MatchNotRegexp}, wantErr: true},
+		{name: "unknown type", m: Matcher{Name: "metric_name", Value: "x", Type: "~"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.m.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrorInvalidMatcher)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMatcher_Matches(t *testing.T) {
+	a := &Alert{Rule: "CPUHigh", MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization12", Labels: map[string]string{"team": "infra"}}
+
+	tests := []struct {
+		name string
+		m    Matcher
+		want bool
+	}{
+		{name: "alert name", m: Matcher{Name: MatcherAlertName, Value: "CPUHigh"}, want: true},
+		{name: "metric type", m: Matcher{Name: MatcherMetricType, Value: "counter"}, want: false},
+		{name: "metric name not equal", m: Matcher{Name: MatcherMetricName, Value: "HeapAlloc", Type: MatchNotEqual}, want: true},
+		{name: "regexp is anchored", m: Matcher{Name: MatcherMetricName, Value: "CPU", Type: MatchRegexp}, want: false},
+		{name: "regexp", m: Matcher{Name: MatcherMetricName, Value: "CPUutilization[0-9]+", Type: MatchRegexp}, want: true},
+		{name: "not regexp", m: Matcher{Name: MatcherMetricName, Value: "CPU.*", Type: MatchNotRegexp}, want: false},
+		{name: "label", m: Matcher{Name: "team", Value: "infra"}, want: true},
+		{name: "missing label is empty", m: Matcher{Name: "severity", Value: ""}, want: true},
+		{name: "invalid regexp matches nothing", m: Matcher{Name: MatcherMetricName, Value: "(", Type: MatchRegexp}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.m.Matches(a))
+		})
+	}
+}
+
+func TestMatcher_String(t *testing.T) {
+	assert.Equal(t, `alertname="CPUHigh"`, (&Matcher{Name: "alertname", Value: "CPUHigh"}).String())
+	assert.Equal(t, `metric_name=~"CPU.*"`, (&Matcher{Name: "metric_name", Value: "CPU.*", Type: MatchRegexp}).String())
+}
+
+func TestParseMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []string
+		wantErr bool
+	}{
+		{name: "quoted", s: `alertname="CPUHigh", severity=~"page|warning"`, want: []string{`alertname="CPUHigh"`, `severity=~"page|warning"`}},
+		{name: "unquoted", s: `metric_name!~CPU.* ,team != infra`, want: []string{`metric_name!~"CPU.*"`, `team!="infra"`}},
+		{name: "comma in quotes", s: `comment="a, b",`, want: []string{`comment="a, b"`}},
+		{name: "empty value", s: `severity=`, want: []string{`severity=""`}},
+		{name: "empty", s: " ", want: nil},
+		{name: "no operator", s: `alertname`, wantErr: true},
+		{name: "no name", s: `="x"`, wantErr: true},
+		{name: "unterminated quote", s: `alertname="x`, wantErr: true},
+		{name: "text after quote", s: `alertname="x" y`, wantErr: true},
+		{name: "bad regexp", s: `metric_name=~(`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matchers, err := ParseMatchers(tt.s)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrorInvalidMatcher)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for i := range matchers {
+				got = append(got, matchers[i].String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package alerting
+
+import (
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+)
+
+// Notification is a message about alerts sent to a Notifier.
+type Notification struct {
+	Receiver    string            `json:"receiver"`               // Name of the receiver
+	Status      State             `json:"status"`                 // StateFiring if any of the alerts is firing, StateResolved otherwise
+	Alerts      []Alert           `json:"alerts"`                 // Alerts included in the notification
+	GroupKey    string            `json:"group_key"`              // Key of the alert group, see Route
+	GroupLabels map[string]string `json:"group_labels,omitempty"` // Values of the group_by labels shared by the alerts
+}
+
+// NewNotification creates a notification about the given alerts
+// and derives its status from the alert states.
+func NewNotification(receiver string, alerts []Alert) *Notification {
+	n := &Notification{Receiver: receiver, Status: StateResolved, Alerts: alerts}
+	for _, a := range alerts {
+		if a.State == StateFiring {
+			n.Status = StateFiring
+			break
+		}
+	}
+	return n
+}
+
+// DTO converts the notification into its transfer representation.
+func (n *Notification) DTO() *dto.AlertNotification {
+	o := &dto.AlertNotification{
+		Receiver:    n.Receiver,
+		Status:      string(n.Status),
+		GroupKey:    n.GroupKey,
+		GroupLabels: n.GroupLabels,
+		Alerts:      make([]dto.Alert, len(n.Alerts)),
+	}
+	for i := range n.Alerts {
+		o.Alerts[i] = AlertToDTO(&n.Alerts[i])
+	}
+	return o
+}
+
+// AlertToDTO converts an alert into its transfer representation.
+func AlertToDTO(a *Alert) dto.Alert {
+	return dto.Alert{
+		Name:           a.Rule,
+		MetricName:     a.MetricName,
+		MetricType:     string(a.MetricType),
+		MetricLabels:   a.MetricLabels,
+		State:          string(a.State),
+		Value:          a.Value,
+		Threshold:      a.Threshold,
+		Labels:         a.Labels,
+		ActiveAt:       timePtr(a.ActiveAt),
+		FiredAt:        timePtr(a.FiredAt),
+		ResolvedAt:     timePtr(a.ResolvedAt),
+		SilencedBy:     a.SilencedBy,
+		InhibitedBy:    a.InhibitedBy,
+		AcknowledgedBy: a.AcknowledgedBy,
+		AcknowledgedAt: timePtr(a.AcknowledgedAt),
+	}
+}
+
+// RouteTargetToDTO converts a route target into its transfer representation.
+func RouteTargetToDTO(t *RouteTarget) dto.RouteTarget {
+	o := dto.RouteTarget{Route: t.Route, Receiver: t.Receiver, GroupKey: t.GroupKey}
+	if t.EscalateAfter > 0 {
+		o.EscalateAfter = t.EscalateAfter.String()
+	}
+	return o
+}
+
+// timePtr returns nil for a zero time and a pointer to t otherwise.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// OutboxEntryToDTO converts an outbox entry into its transfer representation.
+func OutboxEntryToDTO(e *OutboxEntry) dto.OutboxEntry {
+	o := dto.OutboxEntry{
+		ID:           e.ID,
+		Notification: *e.Notification.DTO(),
+		CreatedAt:    e.CreatedAt,
+		Attempts:     e.Attempts,
+		LastError:    e.LastError,
+		DeadAt:       timePtr(e.DeadAt),
+	}
+	if !e.Dead() {
+		o.NextAttemptAt = timePtr(e.NextAttemptAt)
+	}
+	return o
+}
@@ -0,0 +1,54 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotification(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []State
+		want   State
+	}{
+		{name: "all firing", states: []State{StateFiring, StateFiring}, want: StateFiring},
+		{name: "mixed", states: []State{StateResolved, StateFiring}, want: StateFiring},
+		{name: "all resolved", states: []State{StateResolved}, want: StateResolved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := make([]Alert, len(tt.states))
+			for i, s := range tt.states {
+				alerts[i] = Alert{State: s}
+			}
+			assert.Equal(t, tt.want, NewNotification("ops", alerts).Status)
+		})
+	}
+}
+
+func TestNotification_DTO(t *testing.T) {
+	a := Alert{
+		Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc",
+		State: StateResolved, Value: 100, Threshold: 500e6,
+		ActiveAt: t0, FiredAt: t0, ResolvedAt: t0.Add(time.Minute),
+	}
+
+	d := NewNotification("ops", []Alert{a}).DTO()
+	assert.Equal(t, "ops", d.Receiver)
+	assert.Equal(t, "resolved", d.Status)
+	require.Len(t, d.Alerts, 1)
+	assert.Equal(t, "HeapAllocHigh", d.Alerts[0].Name)
+	assert.Equal(t, "gauge", d.Alerts[0].MetricType)
+	require.NotNil(t, d.Alerts[0].ResolvedAt)
+	assert.Equal(t, t0.Add(time.Minute), *d.Alerts[0].ResolvedAt)
+
+	a.ResolvedAt = time.Time{}
+	assert.Nil(t, AlertToDTO(&a).ResolvedAt)
+
+	a.InhibitedBy = []string{"AgentDown|counter|PollCount"}
+	assert.Equal(t, a.InhibitedBy, AlertToDTO(&a).InhibitedBy)
+}
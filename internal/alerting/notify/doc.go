@@ -0,0 +1,45 @@
+// Package notify implements delivery of alert notifications.
+//
+// Notifications are delivered to receivers. A receiver has a unique name and
+// a set of notifiers, webhooks and emails, all of which get every notification
+// sent to the receiver.
+//
+// Receivers are configured in the server JSON config:
+//
+//	"receivers": [
+//	  {
+//	    "name": "ops",
+//	    "webhooks": [
+//	      {"url": "https://hooks.example.com/alerts", "key": "secret", "timeout": "5s"}
+//	    ],
+//	    "emails": [
+//	      {
+//	        "smtp_address": "smtp.example.com:587",
+//	        "from": "alerts@example.com",
+//	        "to": ["oncall@example.com"],
+//	        "username": "alerts",
+//	        "password": "secret",
+//	        "starttls": true
+//	      }
+//	    ]
+//	  }
+//	]
+//
+// Webhook payloads are JSON encoded dto.AlertNotification objects. If a key is
+// configured, the payload is signed with secure.CreateAes256Signature and the
+// base64 encoded signature is sent in the HashSHA256 header, the same way
+// agents sign metrics sent to the server.
+//
+// Emails are sent as multipart messages with plain-text and HTML bodies.
+// The subject and the bodies are rendered from text/template and html/template
+// definitions executed with *alerting.Notification as data. A template is set
+// inline ("subject", "text", "html") or read from a file ("subject_file",
+// "text_file", "html_file"); templates not set default to the ones embedded in
+// assets.NotifyTemplates. Templates can use the helpers humanizeBytes,
+// humanizeDuration, humanizePercent and valueURL, see TemplateFuncs; links
+// point to "external_url" of the server config.
+//
+// Templates are parsed and executed with a sample notification when the
+// receiver is created, so invalid templates fail at startup.
+// STARTTLS is used if "starttls" is set, AUTH PLAIN if "username" is set.
+package notify
@@ -0,0 +1,274 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+)
+
+// DefaultEmailTimeout is used when no timeout is configured for an email notifier.
+const DefaultEmailTimeout = 30 * time.Second
+
+// EmailConfig describes an email notifier in the JSON config.
+type EmailConfig struct {
+	SMTPAddress        string          `json:"smtp_address"` // host:port of the SMTP server
+	From               string          `json:"from"`
+	To                 []string        `json:"to"`
+	Username           string          `json:"username"` // AUTH PLAIN is used if set
+	Password           string          `json:"password"`
+	StartTLS           bool            `json:"starttls"`
+	InsecureSkipVerify bool            `json:"insecure_skip_verify"`
+	Subject            string          `json:"subject"`      // text/template of the subject
+	Text               string          `json:"text"`         // text/template of the plain-text body
+	HTML               string          `json:"html"`         // html/template of the HTML body
+	SubjectFile        string          `json:"subject_file"` // file with the subject template
+	TextFile           string          `json:"text_file"`    // file with the plain-text body template
+	HTMLFile           string          `json:"html_file"`    // file with the HTML body template
+	Timeout            common.Duration `json:"timeout"`
+}
+
+// Email sends notifications as multipart plain-text and HTML emails over SMTP.
+type Email struct {
+	addr     string
+	host     string
+	from     string
+	to       []string
+	username string
+	password string
+	tls      *tls.Config
+	timeout  time.Duration
+	subject  *template.Template
+	text     *template.Template
+	html     *htmltemplate.Template
+}
+
+// NewEmail creates an email notifier from its config.
+// Addresses are validated and templates are parsed and test-executed here,
+// so configuration errors are reported before any alert is sent.
+// externalURL is the base URL of the server used in links to metric values.
+func NewEmail(cfg EmailConfig, externalURL string) (*Email, error) {
+	host, _, err := net.SplitHostPort(cfg.SMTPAddress)
+	if err != nil || host == "" {
+		return nil, ErrorInvalidSMTPAddress
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrorInvalidEmailAddress, cfg.From)
+	}
+
+	if len(cfg.To) == 0 {
+		return nil, ErrorNoEmailRecipients
+	}
+
+	to := make([]string, len(cfg.To))
+	for i, addr := range cfg.To {
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrorInvalidEmailAddress, addr)
+		}
+		to[i] = a.Address
+	}
+
+	e := &Email{
+		addr:     cfg.SMTPAddress,
+		host:     host,
+		from:     from.Address,
+		to:       to,
+		username: cfg.Username,
+		password: cfg.Password,
+		timeout:  cfg.Timeout.Duration,
+	}
+
+	if e.timeout <= 0 {
+		e.timeout = DefaultEmailTimeout
+	}
+
+	if cfg.StartTLS {
+		e.tls = &tls.Config{ServerName: host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+	}
+
+	if err := e.parseTemplates(cfg, TemplateFuncs(externalURL)); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// parseTemplates loads, parses and checks the subject and body templates.
+func (e *Email) parseTemplates(cfg EmailConfig, funcs map[string]any) error {
+	subject, err := loadTemplate(cfg.Subject, cfg.SubjectFile, DefaultEmailSubjectTemplate)
+	if err != nil {
+		return err
+	}
+	text, err := loadTemplate(cfg.Text, cfg.TextFile, DefaultEmailTextTemplate)
+	if err != nil {
+		return err
+	}
+	html, err := loadTemplate(cfg.HTML, cfg.HTMLFile, DefaultEmailHTMLTemplate)
+	if err != nil {
+		return err
+	}
+
+	if e.subject, err = template.New("subject").Funcs(funcs).Parse(subject); err != nil {
+		return fmt.Errorf("%w: %w", ErrorInvalidTemplate, err)
+	}
+	if e.text, err = template.New("text").Funcs(funcs).Parse(text); err != nil {
+		return fmt.Errorf("%w: %w", ErrorInvalidTemplate, err)
+	}
+	if e.html, err = htmltemplate.New("html").Funcs(funcs).Parse(html); err != nil {
+		return fmt.Errorf("%w: %w", ErrorInvalidTemplate, err)
+	}
+
+	for _, t := range []struct {
+		name string
+		t    executor
+	}{{"subject", e.subject}, {"text", e.text}, {"html", e.html}} {
+		if err := checkTemplate(t.name, t.t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Notify renders the notification and sends it to all recipients.
+func (e *Email) Notify(ctx context.Context, n *alerting.Notification) error {
+	msg, err := e.message(n, time.Now())
+	if err != nil {
+		return err
+	}
+	return e.send(ctx, msg)
+}
+
+// message renders a MIME multipart/alternative message with plain-text and HTML parts.
+func (e *Email) message(n *alerting.Notification, now time.Time) ([]byte, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := e.subject.Execute(&subject, n); err != nil {
+		return nil, common.NewWrappedError("Error rendering email subject", err)
+	}
+	if err := e.text.Execute(&text, n); err != nil {
+		return nil, common.NewWrappedError("Error rendering email text", err)
+	}
+	if err := e.html.Execute(&html, n); err != nil {
+		return nil, common.NewWrappedError("Error rendering email html", err)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	header := func(k, v string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, v)
+	}
+	header("From", e.from)
+	header("To", strings.Join(e.to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=UTF-8", text.Bytes()},
+		{"text/html; charset=UTF-8", html.Bytes()},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write(part.body); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// send delivers the message through the configured SMTP server.
+func (e *Email) send(ctx context.Context, msg []byte) error {
+	d := net.Dialer{Timeout: e.timeout}
+	conn, err := d.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return common.NewWrappedError("Error connecting to SMTP server", err)
+	}
+
+	deadline := time.Now().Add(e.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return common.NewWrappedError("Error starting SMTP session", err)
+	}
+	defer c.Close()
+
+	if e.tls != nil {
+		if err := c.StartTLS(e.tls); err != nil {
+			return common.NewWrappedError("Error starting TLS", err)
+		}
+	}
+
+	if e.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return common.NewWrappedError("Error authenticating", err)
+		}
+	}
+
+	if err := c.Mail(e.from); err != nil {
+		return common.NewWrappedError("Error sending MAIL command", err)
+	}
+	for _, addr := range e.to {
+		if err := c.Rcpt(addr); err != nil {
+			return common.NewWrappedError("Error sending RCPT command", err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return common.NewWrappedError("Error sending DATA command", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return common.NewWrappedError("Error writing message", err)
+	}
+	if err := w.Close(); err != nil {
+		return common.NewWrappedError("Error writing message", err)
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,226 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseEmail parses a received message and returns its subject and
+// decoded bodies keyed by content type.
+func parseEmail(t *testing.T, data string) (string, map[string]string) {
+	t.Helper()
+
+	m, err := mail.ReadMessage(strings.NewReader(data))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := map[string]string{}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(p)
+		require.NoError(t, err)
+
+		ct, _, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		bodies[ct] = string(body)
+	}
+
+	return subject, bodies
+}
+
+func TestNewEmail(t *testing.T) {
+	valid := func() EmailConfig {
+		return EmailConfig{SMTPAddress: "localhost:25", From: "alerts@example.com", To: []string{"oncall@example.com"}}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *EmailConfig)
+		wantErr error
+	}{
+		{name: "valid", modify: func(c *EmailConfig) {}},
+		{name: "named addresses", modify: func(c *EmailConfig) { c.From = "Alerts <alerts@example.com>" }},
+		{name: "no port", modify: func(c *EmailConfig) { c.SMTPAddress = "localhost" }, wantErr: ErrorInvalidSMTPAddress},
+		{name: "bad from", modify: func(c *EmailConfig) { c.From = "alerts" }, wantErr: ErrorInvalidEmailAddress},
+		{name: "no recipients", modify: func(c *EmailConfig) { c.To = nil }, wantErr: ErrorNoEmailRecipients},
+		{name: "bad recipient", modify: func(c *EmailConfig) { c.To = []string{"oncall"} }, wantErr: ErrorInvalidEmailAddress},
+		{name: "bad subject template", modify: func(c *EmailConfig) { c.Subject = "{{ .Status" }, wantErr: ErrorInvalidTemplate},
+		{name: "bad text template", modify: func(c *EmailConfig) { c.Text = "{{ end }}" }, wantErr: ErrorInvalidTemplate},
+		{name: "bad html template", modify: func(c *EmailConfig) { c.HTML = "{{ range }}" }, wantErr: ErrorInvalidTemplate},
+		{name: "unknown field", modify: func(c *EmailConfig) { c.Text = "{{ .Missing }}" }, wantErr: ErrorInvalidTemplate},
+		{name: "bad helper argument", modify: func(c *EmailConfig) { c.Subject = "{{ humanizeBytes .Receiver }}" }, wantErr: ErrorInvalidTemplate},
+		{name: "unknown helper", modify: func(c *EmailConfig) { c.HTML = "{{ humanize .Status }}" }, wantErr: ErrorInvalidTemplate},
+		{name: "inline and file", modify: func(c *EmailConfig) { c.Text, c.TextFile = "x", "text.tmpl" }, wantErr: ErrorInvalidTemplate},
+		{name: "missing file", modify: func(c *EmailConfig) { c.HTMLFile = "/nonexistent/html.tmpl" }, wantErr: ErrorInvalidTemplate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			_, err := NewEmail(cfg, "")
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEmail_Notify(t *testing.T) {
+	srv := newTestSMTPServer(t, false)
+
+	e, err := NewEmail(EmailConfig{
+		SMTPAddress: srv.Addr,
+		From:        "Alerts <alerts@example.com>",
+		To:          []string{"oncall@example.com", "team@example.com"},
+		Username:    "user",
+		Password:    "pass",
+	}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, e.Notify(context.Background(), testNotification()))
+
+	msg := <-srv.Messages
+	assert.Equal(t, "alerts@example.com", msg.From)
+	assert.Equal(t, []string{"oncall@example.com", "team@example.com"}, msg.To)
+	assert.Equal(t, "\x00user\x00pass", msg.Auth)
+	assert.False(t, msg.TLS)
+
+	subject, bodies := parseEmail(t, msg.Data)
+	assert.Equal(t, "[firing] 1 alert(s) HeapAllocHigh", subject)
+	assert.Contains(t, bodies["text/plain"], "HeapAllocHigh: gauge HeapAlloc is firing")
+	assert.Contains(t, bodies["text/plain"], "value: 6e+08, threshold: 5e+08")
+	assert.Contains(t, bodies["text/plain"], "severity: page")
+	assert.Contains(t, bodies["text/html"], "<b>HeapAllocHigh</b>")
+	assert.Contains(t, bodies["text/html"], `<a href="/value/gauge/HeapAlloc">HeapAlloc</a>`)
+}
+
+func TestEmail_Notify_TemplateFiles(t *testing.T) {
+	srv := newTestSMTPServer(t, false)
+
+	dir := t.TempDir()
+	subject := filepath.Join(dir, "subject.tmpl")
+	text := filepath.Join(dir, "text.tmpl")
+	require.NoError(t, os.WriteFile(subject, []byte(`{{ (index .Alerts 0).MetricName }} is {{ humanizeBytes (index .Alerts 0).Value }}`), 0o600))
+	require.NoError(t, os.WriteFile(text, []byte(`{{ range .Alerts }}{{ valueURL .MetricType .MetricName }}{{ end }}`), 0o600))
+
+	e, err := NewEmail(EmailConfig{
+		SMTPAddress: srv.Addr,
+		From:        "alerts@example.com",
+		To:          []string{"oncall@example.com"},
+		SubjectFile: subject,
+		TextFile:    text,
+	}, "http://metrics.example.com:8080/")
+	require.NoError(t, err)
+
+	require.NoError(t, e.Notify(context.Background(), testNotification()))
+
+	subj, bodies := parseEmail(t, (<-srv.Messages).Data)
+	assert.Equal(t, "HeapAlloc is 572.2MiB", subj)
+	assert.Equal(t, "http://metrics.example.com:8080/value/gauge/HeapAlloc", bodies["text/plain"])
+	assert.Contains(t, bodies["text/html"], `<a href="http://metrics.example.com:8080/value/gauge/HeapAlloc">`, "default html template")
+}
+
+func TestEmail_Notify_StartTLS(t *testing.T) {
+	srv := newTestSMTPServer(t, true)
+
+	e, err := NewEmail(EmailConfig{
+		SMTPAddress:        srv.Addr,
+		From:               "alerts@example.com",
+		To:                 []string{"oncall@example.com"},
+		Username:           "user",
+		Password:           "pass",
+		StartTLS:           true,
+		InsecureSkipVerify: true,
+	}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, e.Notify(context.Background(), testNotification()))
+
+	msg := <-srv.Messages
+	assert.True(t, msg.TLS)
+	assert.Equal(t, "\x00user\x00pass", msg.Auth)
+}
+
+func TestEmail_Notify_CustomTemplates(t *testing.T) {
+	srv := newTestSMTPServer(t, false)
+
+	e, err := NewEmail(EmailConfig{
+		SMTPAddress: srv.Addr,
+		From:        "alerts@example.com",
+		To:          []string{"oncall@example.com"},
+		Subject:     "{{ .Receiver }}: {{ (index .Alerts 0).Rule }}",
+		Text:        "{{ range .Alerts }}{{ .MetricName }}={{ .Value }}{{ end }}",
+		HTML:        "<p>{{ range .Alerts }}{{ .MetricName }} {{ .Labels.team }}{{ end }}</p>",
+	}, "")
+	require.NoError(t, err)
+
+	n := testNotification()
+	n.Alerts[0].Labels = map[string]string{"team": "<ops>"}
+	require.NoError(t, e.Notify(context.Background(), n))
+
+	subject, bodies := parseEmail(t, (<-srv.Messages).Data)
+	assert.Equal(t, "ops: HeapAllocHigh", subject)
+	assert.Equal(t, "HeapAlloc=6e+08", bodies["text/plain"])
+	assert.Equal(t, "<p>HeapAlloc &lt;ops&gt;</p>", bodies["text/html"], "html body must be escaped")
+}
+
+func TestEmail_Notify_Errors(t *testing.T) {
+	t.Run("template execution", func(t *testing.T) {
+		// the failing branch is not taken by the startup check
+		e, err := NewEmail(EmailConfig{SMTPAddress: "127.0.0.1:1", From: "a@example.com", To: []string{"b@example.com"},
+			Text: `{{ if eq .Receiver "ops" }}{{ .Missing }}{{ end }}`}, "")
+		require.NoError(t, err)
+		require.ErrorContains(t, e.Notify(context.Background(), testNotification()), "Error rendering email text")
+	})
+
+	t.Run("recipient rejected", func(t *testing.T) {
+		srv := newTestSMTPServer(t, false)
+		srv.reject = "RCPT"
+
+		e, err := NewEmail(EmailConfig{SMTPAddress: srv.Addr, From: "a@example.com", To: []string{"b@example.com"}}, "")
+		require.NoError(t, err)
+		require.ErrorContains(t, e.Notify(context.Background(), testNotification()), "550")
+	})
+
+	t.Run("starttls not supported", func(t *testing.T) {
+		srv := newTestSMTPServer(t, false)
+
+		e, err := NewEmail(EmailConfig{SMTPAddress: srv.Addr, From: "a@example.com", To: []string{"b@example.com"}, StartTLS: true}, "")
+		require.NoError(t, err)
+		require.Error(t, e.Notify(context.Background(), testNotification()))
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		srv := newTestSMTPServer(t, false)
+		addr := srv.Addr
+		srv.ln.Close()
+
+		e, err := NewEmail(EmailConfig{SMTPAddress: addr, From: "a@example.com", To: []string{"b@example.com"}}, "")
+		require.NoError(t, err)
+		require.ErrorContains(t, e.Notify(context.Background(), testNotification()), "Error connecting to SMTP server")
+	})
+}
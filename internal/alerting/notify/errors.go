@@ -0,0 +1,13 @@
+package notify
+
+import "errors"
+
+var (
+	ErrorInvalidReceiverName   = errors.New("invalid receiver name")
+	ErrorDuplicateReceiverName = errors.New("duplicate receiver name")
+	ErrorInvalidWebhookURL     = errors.New("invalid webhook url")
+	ErrorInvalidSMTPAddress    = errors.New("invalid smtp address")
+	ErrorInvalidEmailAddress   = errors.New("invalid email address")
+	ErrorNoEmailRecipients     = errors.New("no email recipients")
+	ErrorInvalidTemplate       = errors.New("invalid template")
+)
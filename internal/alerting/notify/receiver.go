@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+)
+
+// ReceiverConfig describes a receiver in the JSON config.
+type ReceiverConfig struct {
+	Name     string          `json:"name"`
+	Webhooks []WebhookConfig `json:"webhooks"`
+	Emails   []EmailConfig   `json:"emails"`
+}
+
+// Receiver is a named set of notifiers. It implements alerting.Receiver.
+type Receiver struct {
+	name      string
+	notifiers []alerting.Notifier
+}
+
+// NewReceiver creates a receiver from its config.
+// externalURL is the base URL of the server used in links in notifications.
+func NewReceiver(cfg ReceiverConfig, externalURL string) (*Receiver, error) {
+	if strings.TrimSpace(cfg.Name) == "" {
+		return nil, ErrorInvalidReceiverName
+	}
+
+	r := &Receiver{name: cfg.Name}
+
+	for _, wc := range cfg.Webhooks {
+		w, err := NewWebhook(wc)
+		if err != nil {
+			return nil, fmt.Errorf("receiver %q: %w", cfg.Name, err)
+		}
+		r.notifiers = append(r.notifiers, w)
+	}
+
+	for _, ec := range cfg.Emails {
+		e, err := NewEmail(ec, externalURL)
+		if err != nil {
+			return nil, fmt.Errorf("receiver %q: %w", cfg.Name, err)
+		}
+		r.notifiers = append(r.notifiers, e)
+	}
+
+	return r, nil
+}
+
+// NewReceivers creates receivers from their configs and checks that names are unique.
+func NewReceivers(cfgs []ReceiverConfig, externalURL string) ([]*Receiver, error) {
+	names := make(map[string]struct{}, len(cfgs))
+	result := make([]*Receiver, 0, len(cfgs))
+
+	for _, cfg := range cfgs {
+		if _, exists := names[cfg.Name]; exists {
+			return nil, fmt.Errorf("receiver %q: %w", cfg.Name, ErrorDuplicateReceiverName)
+		}
+		names[cfg.Name] = struct{}{}
+
+		r, err := NewReceiver(cfg, externalURL)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+
+	return result, nil
+}
+
+// Name returns the name of the receiver.
+func (r *Receiver) Name() string {
+	return r.name
+}
+
+// Notify sends the notification through every notifier of the receiver.
+// All notifiers are tried, the returned error joins errors of the failed ones.
+func (r *Receiver) Notify(ctx context.Context, n *alerting.Notification) error {
+	var errs []error
+	for _, notifier := range r.notifiers {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
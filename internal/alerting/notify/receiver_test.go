@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotifier struct {
+	calls int
+	err   error
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n *alerting.Notification) error {
+	f.calls++
+	return f.err
+}
+
+func TestNewReceivers(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		rs, err := NewReceivers([]ReceiverConfig{
+			{Name: "ops", Webhooks: []WebhookConfig{{URL: "http://localhost/a"}, {URL: "http://localhost/b"}}},
+			{Name: "dev", Emails: []EmailConfig{{SMTPAddress: "localhost:25", From: "alerts@example.com", To: []string{"dev@example.com"}}}},
+		}, "")
+		require.NoError(t, err)
+		require.Len(t, rs, 2)
+		assert.Equal(t, "ops", rs[0].Name())
+		assert.Len(t, rs[0].notifiers, 2)
+		assert.IsType(t, &Email{}, rs[1].notifiers[0])
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		_, err := NewReceivers([]ReceiverConfig{{Name: "ops"}, {Name: "ops"}}, "")
+		require.ErrorIs(t, err, ErrorDuplicateReceiverName)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		_, err := NewReceivers([]ReceiverConfig{{Name: " "}}, "")
+		require.ErrorIs(t, err, ErrorInvalidReceiverName)
+	})
+
+	t.Run("bad webhook", func(t *testing.T) {
+		_, err := NewReceivers([]ReceiverConfig{{Name: "ops", Webhooks: []WebhookConfig{{URL: "nope"}}}}, "")
+		require.ErrorIs(t, err, ErrorInvalidWebhookURL)
+	})
+
+	t.Run("bad email", func(t *testing.T) {
+		_, err := NewReceivers([]ReceiverConfig{{Name: "ops", Emails: []EmailConfig{{SMTPAddress: "localhost:25", From: "alerts"}}}}, "")
+		require.ErrorIs(t, err, ErrorInvalidEmailAddress)
+	})
+}
+
+func TestReceiver_Notify(t *testing.T) {
+	ok := &fakeNotifier{}
+	failing := &fakeNotifier{err: errors.New("forced")}
+	r := &Receiver{name: "ops", notifiers: []alerting.Notifier{failing, ok}}
+
+	err := r.Notify(context.Background(), testNotification())
+	require.ErrorContains(t, err, "forced")
+	assert.Equal(t, 1, ok.calls, "remaining notifiers must be called after a failure")
+
+	srv, ch := newTestReceiver(t, http.StatusOK)
+	r, err = NewReceiver(ReceiverConfig{Name: "ops", Webhooks: []WebhookConfig{{URL: srv.URL}}}, "")
+	require.NoError(t, err)
+	require.NoError(t, r.Notify(context.Background(), testNotification()))
+	<-ch
+}
@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// smtpMessage is a message received by the test SMTP server.
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+	Auth string // decoded AUTH PLAIN credentials
+	TLS  bool   // message was received after STARTTLS
+}
+
+// testSMTPServer is a tiny in-process SMTP server understanding just enough
+// of the protocol for net/smtp: EHLO, STARTTLS, AUTH PLAIN, MAIL, RCPT, DATA and QUIT.
+type testSMTPServer struct {
+	Addr     string
+	Messages chan smtpMessage
+
+	ln     net.Listener
+	tls    *tls.Config
+	reject string // command rejected with 550
+	wg     sync.WaitGroup
+}
+
+func newTestSMTPServer(t *testing.T, withTLS bool) *testSMTPServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &testSMTPServer{Addr: ln.Addr().String(), Messages: make(chan smtpMessage, 10), ln: ln}
+	if withTLS {
+		s.tls = &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}}
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+
+	t.Cleanup(func() {
+		ln.Close()
+		s.wg.Wait()
+	})
+
+	return s
+}
+
+func (s *testSMTPServer) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		for _, l := range lines {
+			conn.Write([]byte(l + "\r\n"))
+		}
+	}
+
+	msg := smtpMessage{}
+	reply("220 localhost test SMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		if s.reject != "" && cmd == s.reject {
+			reply("550 rejected")
+			continue
+		}
+
+		switch cmd {
+		case "EHLO", "HELO":
+			if s.tls != nil && !msg.TLS {
+				reply("250-localhost", "250-STARTTLS", "250 AUTH PLAIN")
+			} else {
+				reply("250-localhost", "250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			if s.tls == nil {
+				reply("454 TLS not available")
+				continue
+			}
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, msg.TLS = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			parts := strings.Fields(line)
+			if len(parts) != 3 || parts[1] != "PLAIN" {
+				reply("504 unsupported")
+				continue
+			}
+			cred, _ := base64.StdEncoding.DecodeString(parts[2])
+			msg.Auth = string(cred)
+			reply("235 authenticated")
+		case "MAIL":
+			msg.From = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 ok")
+		case "RCPT":
+			msg.To = append(msg.To, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			msg.Data = data.String()
+			s.Messages <- msg
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
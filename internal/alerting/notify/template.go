@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/assets"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// Names of the default templates in assets.NotifyTemplates.
+const (
+	DefaultEmailSubjectTemplate = "notify/email_subject.tmpl"
+	DefaultEmailTextTemplate    = "notify/email_text.tmpl"
+	DefaultEmailHTMLTemplate    = "notify/email_html.tmpl"
+)
+
+// TemplateFuncs returns the helper functions available in notification templates.
+// externalURL is the base URL of the server used in links, e.g. "http://localhost:8080".
+//
+//   - humanizeBytes 1536 → "1.5KiB"
+//   - humanizeDuration 90 → "1m30s" (seconds or time.Duration)
+//   - humanizePercent 0.953 → "95.3%"
+//   - valueURL "gauge" "HeapAlloc" → "http://localhost:8080/value/gauge/HeapAlloc"
+func TemplateFuncs(externalURL string) map[string]any {
+	return map[string]any{
+		"humanizeBytes":    humanizeBytes,
+		"humanizeDuration": humanizeDuration,
+		"humanizePercent":  humanizePercent,
+		"valueURL": func(metricType metric.MetricType, name string) string {
+			return valueURL(externalURL, metricType, name)
+		},
+	}
+}
+
+// toFloat converts a numeric template argument to float64.
+func toFloat(v any) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("cannot convert %T to a number", v)
+	}
+}
+
+// humanizeBytes formats a number of bytes with binary prefixes, e.g. 1.5KiB.
+func humanizeBytes(v any) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	return metric.UnitBytes.Format(f), nil
+}
+
+// humanizeDuration formats a time.Duration or a number of seconds, e.g. 1m30s.
+// Durations are rounded to milliseconds below a second and to seconds otherwise.
+func humanizeDuration(v any) (string, error) {
+	if d, ok := v.(time.Duration); ok {
+		return metric.UnitSeconds.Format(d.Seconds()), nil
+	}
+
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	return metric.UnitSeconds.Format(f), nil
+}
+
+// humanizePercent formats a ratio as a percentage, e.g. 0.953 as 95.3%.
+func humanizePercent(v any) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(math.Round(f*1000)/10, 'f', -1, 64) + "%", nil
+}
+
+// valueURL returns the link to the value page of a metric.
+func valueURL(externalURL string, metricType metric.MetricType, name string) string {
+	return strings.TrimRight(externalURL, "/") + "/value/" + url.PathEscape(string(metricType)) + "/" + url.PathEscape(name)
+}
+
+// loadTemplate returns the template text configured inline, read from a file,
+// or the embedded default, in this order. Setting both inline text and
+// a file is an error.
+func loadTemplate(inline, file, defaultName string) (string, error) {
+	switch {
+	case inline != "" && file != "":
+		return "", fmt.Errorf("%w: both inline template and file %s are set", ErrorInvalidTemplate, file)
+	case inline != "":
+		return inline, nil
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrorInvalidTemplate, err)
+		}
+		return string(data), nil
+	default:
+		data, err := assets.NotifyTemplates.ReadFile(defaultName)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrorInvalidTemplate, err)
+		}
+		return string(data), nil
+	}
+}
+
+// executor is a parsed text or HTML template.
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// checkTemplate executes the template with a sample notification, so that
+// references to unknown fields and wrong helper arguments are reported at
+// startup rather than when an alert is sent.
+func checkTemplate(name string, t executor) error {
+	if err := t.Execute(io.Discard, sampleNotification()); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrorInvalidTemplate, name, err)
+	}
+	return nil
+}
+
+// sampleNotification returns a notification with a firing and a resolved alert
+// with all fields set.
+func sampleNotification() *alerting.Notification {
+	now := time.Now()
+	firing := alerting.Alert{
+		Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc",
+		State: alerting.StateFiring, Value: 612345678, Threshold: 500e6,
+		Labels:   map[string]string{"severity": "page"},
+		ActiveAt: now.Add(-2 * time.Minute), FiredAt: now.Add(-time.Minute), LastTrueAt: now, LastEvaluatedAt: now,
+	}
+	resolved := alerting.Alert{
+		Rule: "PollCountLow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount",
+		State: alerting.StateResolved, Value: 20, Threshold: 10,
+		ActiveAt: now.Add(-time.Hour), FiredAt: now.Add(-time.Hour), ResolvedAt: now, LastEvaluatedAt: now,
+	}
+
+	n := alerting.NewNotification("sample", []alerting.Alert{firing, resolved})
+	n.GroupKey = `{alertname="HeapAllocHigh"}`
+	n.GroupLabels = map[string]string{alerting.MatcherAlertName: "HeapAllocHigh"}
+	return n
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(v any) (string, error)
+		arg  any
+		want string
+	}{
+		{"bytes", humanizeBytes, 512.0, "512B"},
+		{"kibibytes", humanizeBytes, int64(1536), "1.5KiB"},
+		{"mebibytes", humanizeBytes, 600e6, "572.2MiB"},
+		{"gibibytes", humanizeBytes, uint64(3 << 30), "3GiB"},
+		{"bytes from string", humanizeBytes, "2048", "2KiB"},
+		{"seconds", humanizeDuration, 90, "1m30s"},
+		{"fractional seconds", humanizeDuration, 0.25, "250ms"},
+		{"duration", humanizeDuration, 26*time.Hour + 1500*time.Millisecond, "26h0m2s"},
+		{"percent", humanizePercent, 0.953, "95.3%"},
+		{"percent rounding", humanizePercent, 0.12345, "12.3%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(tt.arg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := humanizeBytes(struct{}{})
+	assert.Error(t, err)
+	_, err = humanizePercent("abc")
+	assert.Error(t, err)
+}
+
+func TestValueURL(t *testing.T) {
+	valueURL := TemplateFuncs("http://localhost:8080/")["valueURL"].(func(metric.MetricType, string) string)
+	assert.Equal(t, "http://localhost:8080/value/gauge/HeapAlloc", valueURL(metric.MetricTypeGauge, "HeapAlloc"))
+	assert.Equal(t, "http://localhost:8080/value/counter/a%20b", valueURL(metric.MetricTypeCounter, "a b"))
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	e := &Email{}
+	require.NoError(t, e.parseTemplates(EmailConfig{}, TemplateFuncs("")))
+}
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/secure"
+)
+
+// DefaultWebhookTimeout is used when no timeout is configured for a webhook.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookConfig describes a webhook in the JSON config.
+type WebhookConfig struct {
+	URL     string          `json:"url"`
+	Key     string          `json:"key"`
+	Timeout common.Duration `json:"timeout"`
+}
+
+// Webhook posts notifications as JSON to a URL.
+type Webhook struct {
+	URL    string
+	Key    string
+	client *http.Client
+}
+
+// NewWebhook creates a webhook notifier from its config.
+func NewWebhook(cfg WebhookConfig) (*Webhook, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrorInvalidWebhookURL
+	}
+
+	timeout := cfg.Timeout.Duration
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+
+	return &Webhook{URL: cfg.URL, Key: cfg.Key, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Notify posts the notification to the webhook URL.
+// Any response status other than 2xx is reported as an error.
+func (w *Webhook) Notify(ctx context.Context, n *alerting.Notification) error {
+
+	body, err := json.Marshal(n.DTO())
+	if err != nil {
+		return common.ErrorMarshallingJSON
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return common.NewWrappedError("Error creating request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if w.Key != "" {
+		sign, err := secure.CreateAes256Signature(body, w.Key)
+		if err != nil {
+			return common.NewWrappedError("Error signing request", err)
+		}
+		req.Header.Set("HashSHA256", base64.RawStdEncoding.EncodeToString(sign))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return common.NewWrappedError("Error sending request", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with status: %d", w.URL, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/secure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var firedAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testNotification() *alerting.Notification {
+	return alerting.NewNotification("ops", []alerting.Alert{{
+		Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc",
+		State: alerting.StateFiring, Value: 600e6, Threshold: 500e6,
+		Labels: map[string]string{"severity": "page"}, ActiveAt: firedAt, FiredAt: firedAt,
+	}})
+}
+
+type receivedRequest struct {
+	body   []byte
+	header http.Header
+}
+
+func newTestReceiver(t *testing.T, status int) (*httptest.Server, chan receivedRequest) {
+	t.Helper()
+	ch := make(chan receivedRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		ch <- receivedRequest{body: body, header: r.Header}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func TestNewWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "http", url: "http://localhost:9000/hook"},
+		{name: "https", url: "https://hooks.example.com/alerts"},
+		{name: "no scheme", url: "localhost:9000", wantErr: true},
+		{name: "ftp", url: "ftp://example.com", wantErr: true},
+		{name: "empty", url: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := NewWebhook(WebhookConfig{URL: tt.url})
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrorInvalidWebhookURL)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, DefaultWebhookTimeout, w.client.Timeout)
+		})
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	srv, ch := newTestReceiver(t, http.StatusOK)
+
+	w, err := NewWebhook(WebhookConfig{URL: srv.URL})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Notify(context.Background(), testNotification()))
+
+	req := <-ch
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+	assert.Empty(t, req.header.Get("HashSHA256"))
+
+	var payload dto.AlertNotification
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+
+	assert.Equal(t, "ops", payload.Receiver)
+	assert.Equal(t, "firing", payload.Status)
+	require.Len(t, payload.Alerts, 1)
+
+	a := payload.Alerts[0]
+	assert.Equal(t, "HeapAllocHigh", a.Name)
+	assert.Equal(t, "HeapAlloc", a.MetricName)
+	assert.Equal(t, "gauge", a.MetricType)
+	assert.Equal(t, "firing", a.State)
+	assert.Equal(t, 600e6, a.Value)
+	assert.Equal(t, 500e6, a.Threshold)
+	assert.Equal(t, map[string]string{"severity": "page"}, a.Labels)
+	require.NotNil(t, a.FiredAt)
+	assert.True(t, firedAt.Equal(*a.FiredAt))
+	assert.Nil(t, a.ResolvedAt)
+}
+
+func TestWebhook_Notify_Signed(t *testing.T) {
+	srv, ch := newTestReceiver(t, http.StatusOK)
+
+	w, err := NewWebhook(WebhookConfig{URL: srv.URL, Key: "secret"})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Notify(context.Background(), testNotification()))
+
+	req := <-ch
+	expected, err := secure.CreateAes256Signature(req.body, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, base64.RawStdEncoding.EncodeToString(expected), req.header.Get("HashSHA256"))
+}
+
+func TestWebhook_Notify_Errors(t *testing.T) {
+	t.Run("non-2xx status", func(t *testing.T) {
+		srv, ch := newTestReceiver(t, http.StatusInternalServerError)
+
+		w, err := NewWebhook(WebhookConfig{URL: srv.URL})
+		require.NoError(t, err)
+
+		err = w.Notify(context.Background(), testNotification())
+		<-ch
+		require.ErrorContains(t, err, "500")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL
+		srv.Close()
+
+		w, err := NewWebhook(WebhookConfig{URL: url})
+		require.NoError(t, err)
+
+		require.Error(t, w.Notify(context.Background(), testNotification()))
+	})
+}
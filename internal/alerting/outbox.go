@@ -0,0 +1,410 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+)
+
+// OutboxPollInterval is how often the outbox checks for entries whose
+// backoff has expired, see Outbox.Run.
+var OutboxPollInterval = time.Second
+
+// RetryPolicy controls how often delivery of a notification is attempted.
+//
+// After a failed attempt the delivery is retried with exponential backoff:
+// the delay starts at InitialBackoff, doubles with every attempt up to
+// MaxBackoff and is randomized by up to half of it, so receivers recovering
+// from an outage are not hit by all notifications at once. After MaxAttempts
+// failed attempts the notification is moved to the dead letters.
+type RetryPolicy struct {
+	InitialBackoff common.Duration `json:"initial_backoff"`
+	MaxBackoff     common.Duration `json:"max_backoff"`
+	MaxAttempts    int             `json:"max_attempts"`
+}
+
+// DefaultRetryPolicy returns the policy used if none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialBackoff: common.Duration{Duration: time.Second},
+		MaxBackoff:     common.Duration{Duration: 5 * time.Minute},
+		MaxAttempts:    10,
+	}
+}
+
+// withDefaults returns the policy with zero fields taken from DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if p.InitialBackoff.Duration == 0 {
+		p.InitialBackoff = d.InitialBackoff
+	}
+	if p.MaxBackoff.Duration == 0 {
+		p.MaxBackoff = d.MaxBackoff
+	}
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	return p
+}
+
+// Validate checks that the backoffs are positive, the initial backoff does not
+// exceed the maximum one and at least one attempt is allowed.
+func (p *RetryPolicy) Validate() error {
+	if p.InitialBackoff.Duration <= 0 || p.MaxBackoff.Duration < p.InitialBackoff.Duration {
+		return fmt.Errorf("%w: backoff must be positive and not exceed max_backoff", ErrorInvalidRetryPolicy)
+	}
+	if p.MaxAttempts <= 0 {
+		return fmt.Errorf("%w: max_attempts must be positive", ErrorInvalidRetryPolicy)
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt after the given number of
+// failed attempts. jitter in [0, 1) selects a delay between half and the full
+// exponential backoff.
+func (p *RetryPolicy) backoff(attempts int, jitter float64) time.Duration {
+	d := p.InitialBackoff.Duration
+	for i := 1; i < attempts && d < p.MaxBackoff.Duration; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxBackoff.Duration)
+	return d/2 + time.Duration(jitter*float64(d/2))
+}
+
+// OutboxEntry is a notification queued for delivery.
+type OutboxEntry struct {
+	ID            string        `json:"id"`
+	Notification  *Notification `json:"notification"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Attempts      int           `json:"attempts"`        // Failed delivery attempts
+	NextAttemptAt time.Time     `json:"next_attempt_at"` // Earliest time of the next attempt
+	LastError     string        `json:"last_error,omitempty"`
+	DeadAt        time.Time     `json:"dead_at"` // Time the entry became a dead letter, zero if it is pending
+}
+
+// Dead reports whether delivery of the entry has been given up.
+func (e *OutboxEntry) Dead() bool {
+	return !e.DeadAt.IsZero()
+}
+
+// Outbox queues notifications and delivers them to receivers with retries.
+//
+// Entries are persisted through an OutboxStore before they are delivered and
+// removed from it only after a successful delivery, so notifications are
+// delivered at least once even if the server restarts in between. Without a
+// store entries are kept in memory only.
+type Outbox struct {
+	store     OutboxStore
+	policy    RetryPolicy
+	logger    logger.Logger
+	receivers func(name string) Receiver
+	jitter    func() float64
+	wake      chan struct{}
+	mu        sync.Mutex
+	entries   map[string]*OutboxEntry
+	busy      map[string]bool // receivers being delivered to
+}
+
+// NewOutbox creates an outbox persisting entries to the store, which may be nil.
+// Zero fields of the policy are taken from DefaultRetryPolicy.
+func NewOutbox(store OutboxStore, policy RetryPolicy, l logger.Logger) (*Outbox, error) {
+	policy = policy.withDefaults()
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Outbox{
+		store:     store,
+		policy:    policy,
+		logger:    l,
+		receivers: func(string) Receiver { return nil },
+		jitter:    rand.Float64,
+		wake:      make(chan struct{}, 1),
+		entries:   make(map[string]*OutboxEntry),
+		busy:      make(map[string]bool),
+	}, nil
+}
+
+// Load restores the entries from the store, if it is set.
+func (o *Outbox) Load(ctx context.Context) error {
+	if o.store == nil {
+		return nil
+	}
+
+	entries, err := o.store.LoadOutbox(ctx)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := range entries {
+		e := entries[i]
+		o.entries[e.ID] = &e
+	}
+	return nil
+}
+
+// Enqueue persists a notification for delivery at now. A nil error means the
+// notification will be delivered or end up in the dead letters.
+func (o *Outbox) Enqueue(ctx context.Context, n *Notification, now time.Time) (OutboxEntry, error) {
+	id, err := newID()
+	if err != nil {
+		return OutboxEntry{}, err
+	}
+
+	e := OutboxEntry{ID: id, Notification: n, CreatedAt: now, NextAttemptAt: now}
+
+	if o.store != nil {
+		if err := o.store.SaveOutboxEntry(ctx, e); err != nil {
+			return OutboxEntry{}, err
+		}
+	}
+
+	o.mu.Lock()
+	o.entries[e.ID] = &e
+	o.mu.Unlock()
+
+	o.notify()
+	return e, nil
+}
+
+// notify wakes up Run without blocking.
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// due returns copies of the pending entries due at now, oldest first, grouped
+// by receiver. Receivers already being delivered to are skipped and the
+// returned ones are marked busy until release is called.
+func (o *Outbox) due(now time.Time) map[string][]OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var entries []OutboxEntry
+	for _, e := range o.entries {
+		if !e.Dead() && !now.Before(e.NextAttemptAt) && !o.busy[e.Notification.Receiver] {
+			entries = append(entries, *e)
+		}
+	}
+	sortOutboxEntries(entries)
+
+	result := make(map[string][]OutboxEntry)
+	for _, e := range entries {
+		result[e.Notification.Receiver] = append(result[e.Notification.Receiver], e)
+		o.busy[e.Notification.Receiver] = true
+	}
+	return result
+}
+
+// release marks the receiver as no longer being delivered to.
+func (o *Outbox) release(receiver string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.busy, receiver)
+}
+
+// Deliver attempts to deliver every pending entry due at now. Delivered
+// entries are removed. Failed ones are retried after a backoff, see
+// RetryPolicy, or become dead letters once they have run out of attempts or
+// if their receiver does not exist.
+//
+// Each receiver gets its entries in order, but receivers are delivered to
+// concurrently, so a slow one does not delay the others. Receivers still
+// being delivered to by an earlier call are skipped.
+func (o *Outbox) Deliver(ctx context.Context, now time.Time) {
+	var wg sync.WaitGroup
+	for name, entries := range o.due(now) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer o.release(name)
+			o.deliverTo(ctx, name, entries, now)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliverTo delivers the entries of a single receiver one after another.
+func (o *Outbox) deliverTo(ctx context.Context, name string, entries []OutboxEntry, now time.Time) {
+	r := o.receivers(name)
+	for _, e := range entries {
+		var err error
+		dead := false
+
+		if r != nil {
+			err = r.Notify(ctx, e.Notification)
+		} else {
+			err = fmt.Errorf("%w: %q", ErrorUnknownReceiver, name)
+			dead = true
+		}
+
+		if err != nil && ctx.Err() != nil {
+			// interrupted by shutdown, not a failed attempt
+			return
+		}
+
+		o.delivered(ctx, e.ID, err, dead, now)
+	}
+}
+
+// delivered records the outcome of an attempt to deliver the entry.
+func (o *Outbox) delivered(ctx context.Context, id string, err error, dead bool, now time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e, ok := o.entries[id]
+	if !ok {
+		// deleted during the attempt
+		return
+	}
+
+	if err == nil {
+		delete(o.entries, id)
+		if o.store != nil {
+			if err := o.store.DeleteOutboxEntry(ctx, id); err != nil {
+				// the entry will be delivered again after a restart
+				o.logger.Errorw("Outbox entry delete error", "id", id, "err", err)
+			}
+		}
+		return
+	}
+
+	e.Attempts++
+	e.LastError = err.Error()
+	if dead || e.Attempts >= o.policy.MaxAttempts {
+		e.DeadAt = now
+		o.logger.Errorw("Alert notification moved to dead letters", "id", id, "receiver", e.Notification.Receiver,
+			"attempts", e.Attempts, "err", err)
+	} else {
+		e.NextAttemptAt = now.Add(o.policy.backoff(e.Attempts, o.jitter()))
+		o.logger.Errorw("Alert notification error", "id", id, "receiver", e.Notification.Receiver,
+			"attempts", e.Attempts, "retry_at", e.NextAttemptAt, "err", err)
+	}
+
+	if o.store != nil {
+		if err := o.store.SaveOutboxEntry(ctx, *e); err != nil {
+			o.logger.Errorw("Outbox entry save error", "id", id, "err", err)
+		}
+	}
+}
+
+// Pending returns the entries waiting for delivery, oldest first.
+func (o *Outbox) Pending() []OutboxEntry {
+	return o.list(false)
+}
+
+// DeadLetters returns the entries whose delivery has been given up, oldest first.
+func (o *Outbox) DeadLetters() []OutboxEntry {
+	return o.list(true)
+}
+
+func (o *Outbox) list(dead bool) []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result := []OutboxEntry{}
+	for _, e := range o.entries {
+		if e.Dead() == dead {
+			result = append(result, *e)
+		}
+	}
+	sortOutboxEntries(result)
+	return result
+}
+
+// Retry resets the attempts of the entry and makes it due at now. A dead
+// letter becomes pending again.
+func (o *Outbox) Retry(ctx context.Context, id string, now time.Time) (OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e, ok := o.entries[id]
+	if !ok {
+		return OutboxEntry{}, ErrorOutboxEntryNotFound
+	}
+
+	updated := *e
+	updated.Attempts = 0
+	updated.NextAttemptAt = now
+	updated.DeadAt = time.Time{}
+
+	if o.store != nil {
+		if err := o.store.SaveOutboxEntry(ctx, updated); err != nil {
+			return OutboxEntry{}, err
+		}
+	}
+
+	*e = updated
+	o.notify()
+	return updated, nil
+}
+
+// Delete removes the entry without delivering it.
+func (o *Outbox) Delete(ctx context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.entries[id]; !ok {
+		return ErrorOutboxEntryNotFound
+	}
+
+	if o.store != nil {
+		if err := o.store.DeleteOutboxEntry(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	delete(o.entries, id)
+	return nil
+}
+
+// Run delivers due entries as they are enqueued and every OutboxPollInterval
+// until the context is cancelled. It returns once the deliveries in progress
+// have finished.
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(OutboxPollInterval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			o.logger.Info("Notification outbox received cancellation signal. Exiting...")
+			return
+		case <-o.wake:
+		case <-ticker.C:
+		}
+
+		// not waited for, so a slow receiver does not hold up the retries
+		// to the others
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.Deliver(ctx, time.Now())
+		}()
+	}
+}
+
+// sortOutboxEntries sorts entries by creation time and ID.
+func sortOutboxEntries(entries []OutboxEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
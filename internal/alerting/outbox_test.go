@@ -0,0 +1,243 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutboxStore is an OutboxStore keeping entries in a map.
+type memoryOutboxStore map[string]OutboxEntry
+
+func (m memoryOutboxStore) SaveOutboxEntry(ctx context.Context, e OutboxEntry) error {
+	m[e.ID] = e
+	return nil
+}
+
+func (m memoryOutboxStore) DeleteOutboxEntry(ctx context.Context, id string) error {
+	delete(m, id)
+	return nil
+}
+
+func (m memoryOutboxStore) LoadOutbox(ctx context.Context) ([]OutboxEntry, error) {
+	var result []OutboxEntry
+	for _, e := range m {
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := DefaultRetryPolicy()
+
+	tests := []struct {
+		name     string
+		attempts int
+		jitter   float64
+		want     time.Duration
+	}{
+		{"first, no jitter", 1, 0, 500 * time.Millisecond},
+		{"first, half jitter", 1, 0.5, 750 * time.Millisecond},
+		{"third", 3, 0, 2 * time.Second},
+		{"third, max jitter", 3, 0.999, 3998 * time.Millisecond},
+		{"capped", 30, 0, 150 * time.Second},
+		{"capped, half jitter", 30, 0.5, 225 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.backoff(tt.attempts, tt.jitter))
+		})
+	}
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		wantErr bool
+	}{
+		{"default", DefaultRetryPolicy(), false},
+		{"negative backoff", RetryPolicy{InitialBackoff: common.Duration{Duration: -time.Second}, MaxBackoff: common.Duration{Duration: time.Second}, MaxAttempts: 1}, true},
+		{"max below initial", RetryPolicy{InitialBackoff: common.Duration{Duration: time.Minute}, MaxBackoff: common.Duration{Duration: time.Second}, MaxAttempts: 1}, true},
+		{"no attempts", RetryPolicy{InitialBackoff: common.Duration{Duration: time.Second}, MaxBackoff: common.Duration{Duration: time.Second}, MaxAttempts: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrorInvalidRetryPolicy)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func newTestOutbox(t *testing.T, store OutboxStore, maxAttempts int) *Outbox {
+	t.Helper()
+	o, err := NewOutbox(store, RetryPolicy{MaxAttempts: maxAttempts}, logger.GetLogger())
+	require.NoError(t, err)
+	o.jitter = func() float64 { return 0 }
+	return o
+}
+
+func TestOutbox_Retries(t *testing.T) {
+	ctx := context.Background()
+
+	rcv := &fakeReceiver{name: "ops", err: errors.New("connection refused")}
+	store := memoryOutboxStore{}
+	o := newTestOutbox(t, store, 3)
+	d := newTestDispatcher(t, DefaultRoute(), rcv)
+	d.SetOutbox(o)
+
+	// the notification is queued, not sent
+	d.Dispatch(ctx, cpuAlerts(1, StateFiring), t0)
+	assert.Empty(t, rcv.notifications)
+	require.Len(t, o.Pending(), 1)
+	assert.Len(t, store, 1)
+	id := o.Pending()[0].ID
+
+	// and considered sent by the dispatcher
+	d.Dispatch(ctx, cpuAlerts(1, StateFiring), t0.Add(time.Second))
+	assert.Len(t, o.Pending(), 1)
+
+	o.Deliver(ctx, t0)
+	require.Len(t, rcv.notifications, 1)
+	e := o.Pending()[0]
+	assert.Equal(t, 1, e.Attempts)
+	assert.Equal(t, "connection refused", e.LastError)
+	assert.Equal(t, t0.Add(500*time.Millisecond), e.NextAttemptAt)
+	assert.Equal(t, 1, store[id].Attempts)
+
+	// backoff has not expired
+	o.Deliver(ctx, t0.Add(100*time.Millisecond))
+	assert.Len(t, rcv.notifications, 1)
+
+	o.Deliver(ctx, t0.Add(500*time.Millisecond))
+	assert.Equal(t, t0.Add(1500*time.Millisecond), o.Pending()[0].NextAttemptAt)
+
+	// out of attempts
+	o.Deliver(ctx, t0.Add(2*time.Second))
+	assert.Len(t, rcv.notifications, 3)
+	assert.Empty(t, o.Pending())
+	dead := o.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, t0.Add(2*time.Second), dead[0].DeadAt)
+	assert.False(t, store[id].DeadAt.IsZero())
+
+	o.Deliver(ctx, t0.Add(time.Hour))
+	assert.Len(t, rcv.notifications, 3)
+
+	// retried once the receiver has recovered
+	rcv.err = nil
+	e, err := o.Retry(ctx, id, t0.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, e.Attempts)
+	assert.False(t, e.Dead())
+	assert.Len(t, o.Pending(), 1)
+
+	o.Deliver(ctx, t0.Add(time.Hour))
+	assert.Len(t, rcv.notifications, 4)
+	assert.Empty(t, o.Pending())
+	assert.Empty(t, o.DeadLetters())
+	assert.Empty(t, store)
+
+	_, err = o.Retry(ctx, id, t0)
+	assert.ErrorIs(t, err, ErrorOutboxEntryNotFound)
+	assert.ErrorIs(t, o.Delete(ctx, id), ErrorOutboxEntryNotFound)
+}
+
+func TestOutbox_UnknownReceiver(t *testing.T) {
+	ctx := context.Background()
+
+	o := newTestOutbox(t, nil, 10)
+	e, err := o.Enqueue(ctx, NewNotification("gone", cpuAlerts(1, StateFiring)), t0)
+	require.NoError(t, err)
+
+	o.Deliver(ctx, t0)
+	dead := o.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, 1, dead[0].Attempts)
+	assert.Contains(t, dead[0].LastError, "unknown receiver")
+
+	require.NoError(t, o.Delete(ctx, e.ID))
+	assert.Empty(t, o.DeadLetters())
+}
+
+func TestOutbox_SlowReceiver(t *testing.T) {
+	ctx := context.Background()
+
+	slow := &blockingReceiver{name: "slow", entered: make(chan struct{}), release: make(chan struct{})}
+	fast := &fakeReceiver{name: "fast"}
+	o := newTestOutbox(t, nil, 10)
+	o.receivers = func(name string) Receiver {
+		return map[string]Receiver{"slow": slow, "fast": fast}[name]
+	}
+
+	_, err := o.Enqueue(ctx, NewNotification("slow", cpuAlerts(1, StateFiring)), t0)
+	require.NoError(t, err)
+	_, err = o.Enqueue(ctx, NewNotification("fast", cpuAlerts(1, StateFiring)), t0.Add(time.Second))
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		o.Deliver(ctx, t0.Add(time.Second))
+	}()
+	<-slow.entered
+
+	// the entry queued after the slow one is delivered meanwhile
+	assert.Eventually(t, func() bool { return len(o.Pending()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, "slow", o.Pending()[0].Notification.Receiver)
+
+	// and the slow receiver is not delivered to twice
+	again := make(chan struct{})
+	go func() {
+		defer close(again)
+		o.Deliver(ctx, t0.Add(2*time.Second))
+	}()
+	select {
+	case <-again:
+	case <-time.After(time.Second):
+		t.Fatal("Deliver blocked by a slow receiver")
+	}
+
+	close(slow.release)
+	<-done
+	assert.Empty(t, o.Pending())
+	assert.Len(t, fast.notifications, 1)
+}
+
+func TestOutbox_Restart(t *testing.T) {
+	ctx := context.Background()
+
+	store := memoryOutboxStore{}
+	o := newTestOutbox(t, store, 10)
+	_, err := o.Enqueue(ctx, NewNotification("ops", cpuAlerts(2, StateFiring)), t0)
+	require.NoError(t, err)
+
+	// cancelled deliveries do not count as attempts
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	o.receivers = func(string) Receiver { return &fakeReceiver{name: "ops", err: context.Canceled} }
+	o.Deliver(cancelled, t0)
+	assert.Zero(t, store[o.Pending()[0].ID].Attempts)
+
+	// the server restarts before the notification is delivered
+	rcv := &fakeReceiver{name: "ops"}
+	restarted := newTestOutbox(t, store, 10)
+	restarted.receivers = func(string) Receiver { return rcv }
+	require.NoError(t, restarted.Load(ctx))
+	require.Len(t, restarted.Pending(), 1)
+
+	restarted.Deliver(ctx, t0.Add(time.Minute))
+	require.Len(t, rcv.notifications, 1)
+	assert.Len(t, rcv.notifications[0].Alerts, 2)
+	assert.Empty(t, store)
+}
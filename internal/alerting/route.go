@@ -0,0 +1,257 @@
+package alerting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+)
+
+// GroupByAll in Route.GroupBy puts every alert into its own group.
+const GroupByAll = "..."
+
+// RootRouteID is the ID of the root of a routing tree, see RouteTarget.
+const RootRouteID = "root"
+
+// Route configures which receivers are notified about alerts, how alerts are
+// grouped into notifications and how often notifications about a group are sent.
+//
+// Example JSON:
+//
+//	{
+//	  "receiver": "ops",
+//	  "group_by": ["alertname"],
+//	  "group_wait": "30s",
+//	  "group_interval": "5m",
+//	  "repeat_interval": "4h",
+//	  "routes": [
+//	    {
+//	      "matchers": [{"name": "metric_name", "value": "(Heap|Stack).*", "type": "=~"}],
+//	      "receiver": "runtime-team",
+//	      "continue": true,
+//	      "escalations": [{"after": "15m", "receiver": "runtime-lead"}]
+//	    },
+//	    {"matchers": [{"name": "severity", "value": "page"}], "receiver": "pager"}
+//	  ]
+//	}
+//
+// Routes form a tree. The root route matches every alert; a child route
+// matches an alert matching all its Matchers. An alert is passed to the
+// first matching child route, and to the following matching siblings too if
+// the matching child has Continue set. An alert matching no child route is
+// handled by the route itself. In the example, heap alerts go to the runtime
+// team and, if they have the "page" severity, to the pager as well; other
+// alerts go to ops.
+//
+// Receiver is the name of the receiver notified by the route; a route without
+// a receiver notifies all receivers. Escalations notify further receivers
+// about alerts firing for longer than the escalation's After without being
+// acknowledged, see Evaluator.Acknowledge.
+//
+// GroupBy lists alert fields ("alertname", "metric_name", "metric_type") and
+// labels whose values form the group of an alert; alerts of a group are sent
+// in a single notification. Empty GroupBy puts all alerts into one group,
+// GroupByAll puts every alert into its own group.
+//
+// GroupWait is how long to wait for more alerts of a new group before the
+// first notification. GroupInterval is how long to wait before notifying
+// about changes of a group, e.g. new firing or resolved alerts.
+// RepeatInterval is how long to wait before repeating a notification about
+// alerts that keep firing; zero means no repeats.
+//
+// Child routes inherit Receiver, GroupBy and the intervals of their parent
+// unless they are set, i.e. GroupBy is null and intervals are zero.
+//
+// Timers are checked at every evaluation, so the effective resolution is the
+// evaluation interval.
+type Route struct {
+	Receiver       string          `json:"receiver,omitempty"`
+	Matchers       []Matcher       `json:"matchers,omitempty"`
+	Continue       bool            `json:"continue,omitempty"`
+	GroupBy        []string        `json:"group_by"`
+	GroupWait      common.Duration `json:"group_wait"`
+	GroupInterval  common.Duration `json:"group_interval"`
+	RepeatInterval common.Duration `json:"repeat_interval"`
+	Escalations    []Escalation    `json:"escalations,omitempty"`
+	Routes         []Route         `json:"routes,omitempty"`
+}
+
+// Escalation notifies Receiver about alerts of a route that have been firing
+// for After without being acknowledged. An escalated alert stays escalated
+// until it is resolved, even if it is acknowledged later.
+type Escalation struct {
+	After    common.Duration `json:"after"`
+	Receiver string          `json:"receiver"`
+}
+
+// DefaultRoute returns a route notifying all receivers about every alert
+// separately and immediately, without repeats.
+func DefaultRoute() Route {
+	return Route{GroupBy: []string{GroupByAll}}
+}
+
+// Validate checks that the routing tree is well-formed.
+// Receiver names are checked by the Dispatcher, which knows the receivers.
+func (r *Route) Validate() error {
+	if len(r.Matchers) > 0 {
+		return fmt.Errorf("%w: the root route matches all alerts and cannot have matchers", ErrorInvalidRoute)
+	}
+	return r.validate()
+}
+
+// validate checks the route and its child routes.
+func (r *Route) validate() error {
+	if r.GroupWait.Duration < 0 || r.GroupInterval.Duration < 0 || r.RepeatInterval.Duration < 0 {
+		return ErrorInvalidDuration
+	}
+	for _, name := range r.GroupBy {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: empty group_by label", ErrorInvalidRoute)
+		}
+		if name == GroupByAll && len(r.GroupBy) > 1 {
+			return fmt.Errorf("%w: %q cannot be combined with other labels", ErrorInvalidRoute, GroupByAll)
+		}
+	}
+	if err := ValidateMatchers(r.Matchers); err != nil {
+		return err
+	}
+	for _, e := range r.Escalations {
+		if e.After.Duration <= 0 {
+			return fmt.Errorf("%w: escalation to %q", ErrorInvalidDuration, e.Receiver)
+		}
+		if strings.TrimSpace(e.Receiver) == "" {
+			return fmt.Errorf("%w: escalation without a receiver", ErrorInvalidRoute)
+		}
+	}
+	for i := range r.Routes {
+		if err := r.Routes[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receivers returns the names of all receivers referenced in the tree.
+func (r *Route) receivers() []string {
+	var result []string
+	if r.Receiver != "" {
+		result = append(result, r.Receiver)
+	}
+	for _, e := range r.Escalations {
+		result = append(result, e.Receiver)
+	}
+	for i := range r.Routes {
+		result = append(result, r.Routes[i].receivers()...)
+	}
+	return result
+}
+
+// groupKey returns the key and labels of the group the alert belongs to.
+func (r *Route) groupKey(a *Alert) (string, map[string]string) {
+	if len(r.GroupBy) == 1 && r.GroupBy[0] == GroupByAll {
+		labels := map[string]string{MatcherAlertName: a.Rule, MatcherMetricType: string(a.MetricType), MatcherMetricName: a.MetricName}
+		for k, v := range a.MetricLabels {
+			labels[k] = v
+		}
+		for k, v := range a.Labels {
+			labels[k] = v
+		}
+		return a.Key(), labels
+	}
+
+	labels := make(map[string]string, len(r.GroupBy))
+	parts := make([]string, len(r.GroupBy))
+	for i, name := range r.GroupBy {
+		v := alertField(a, name)
+		labels[name] = v
+		parts[i] = fmt.Sprintf("%s=%q", name, v)
+	}
+	return "{" + strings.Join(parts, ",") + "}", labels
+}
+
+// escalated reports whether the alert has reached the escalation: it has been
+// firing for After, until now or until it was resolved, and was not
+// acknowledged before that.
+func (e *Escalation) escalated(a *Alert, now time.Time) bool {
+	if a.FiredAt.IsZero() {
+		return false
+	}
+
+	deadline := a.FiredAt.Add(e.After.Duration)
+	end := now
+	if a.State == StateResolved {
+		end = a.ResolvedAt
+	}
+	if end.Before(deadline) {
+		return false
+	}
+
+	return a.AcknowledgedAt.IsZero() || a.AcknowledgedAt.After(deadline)
+}
+
+// routeNode is a route of a validated tree with inherited settings.
+type routeNode struct {
+	id       string
+	route    Route // without child routes
+	children []*routeNode
+}
+
+// newRouteTree builds the tree of the root route.
+func newRouteTree(root Route) *routeNode {
+	return newRouteNode(RootRouteID, root, Route{})
+}
+
+func newRouteNode(id string, r Route, parent Route) *routeNode {
+	if r.Receiver == "" {
+		r.Receiver = parent.Receiver
+	}
+	if r.GroupBy == nil {
+		r.GroupBy = parent.GroupBy
+	}
+	if r.GroupWait.Duration == 0 {
+		r.GroupWait = parent.GroupWait
+	}
+	if r.GroupInterval.Duration == 0 {
+		r.GroupInterval = parent.GroupInterval
+	}
+	if r.RepeatInterval.Duration == 0 {
+		r.RepeatInterval = parent.RepeatInterval
+	}
+
+	children := r.Routes
+	r.Routes = nil
+
+	n := &routeNode{id: id, route: r}
+	for i, c := range children {
+		n.children = append(n.children, newRouteNode(id+"."+strconv.Itoa(i), c, r))
+	}
+	return n
+}
+
+// match returns the routes handling the alert, which must match the route itself.
+func (n *routeNode) match(a *Alert) []*routeNode {
+	var result []*routeNode
+	for _, c := range n.children {
+		if !matchAll(c.route.Matchers, a) {
+			continue
+		}
+		result = append(result, c.match(a)...)
+		if !c.route.Continue {
+			break
+		}
+	}
+	if len(result) == 0 {
+		result = []*routeNode{n}
+	}
+	return result
+}
+
+// RouteTarget is a receiver an alert is routed to.
+type RouteTarget struct {
+	Route         string        // ID of the route, e.g. "root.1.0" for the first child of the second child of the root
+	Receiver      string        // name of the receiver
+	GroupKey      string        // key of the group the alert is notified in
+	EscalateAfter time.Duration // escalation delay, zero if the receiver is notified without escalation
+}
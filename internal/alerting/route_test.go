@@ -0,0 +1,238 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   Route
+		wantErr error
+	}{
+		{"default", DefaultRoute(), nil},
+		{"single group", Route{}, nil},
+		{"by alertname", Route{GroupBy: []string{"alertname", "severity"}}, nil},
+		{"empty label", Route{GroupBy: []string{"alertname", " "}}, ErrorInvalidRoute},
+		{"all combined", Route{GroupBy: []string{GroupByAll, "alertname"}}, ErrorInvalidRoute},
+		{"negative wait", Route{GroupWait: common.Duration{Duration: -time.Second}}, ErrorInvalidDuration},
+		{"root matchers", Route{Matchers: []Matcher{{Name: "severity", Value: "page"}}}, ErrorInvalidRoute},
+		{"child route", Route{Routes: []Route{{Matchers: []Matcher{{Name: "severity", Value: "page"}}, Receiver: "pager"}}}, nil},
+		{"invalid child matcher", Route{Routes: []Route{{Matchers: []Matcher{{Name: "severity", Value: "(", Type: MatchRegexp}}}}}, ErrorInvalidMatcher},
+		{"invalid child interval", Route{Routes: []Route{{RepeatInterval: common.Duration{Duration: -time.Hour}}}}, ErrorInvalidDuration},
+		{"escalation", Route{Escalations: []Escalation{{After: common.Duration{Duration: time.Minute}, Receiver: "lead"}}}, nil},
+		{"escalation without delay", Route{Escalations: []Escalation{{Receiver: "lead"}}}, ErrorInvalidDuration},
+		{"escalation without receiver", Route{Escalations: []Escalation{{After: common.Duration{Duration: time.Minute}}}}, ErrorInvalidRoute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// testRouteTree routes heap alerts to the runtime team and paging alerts to
+// the pager, everything else to ops.
+func testRouteTree() Route {
+	return Route{
+		Receiver: "ops",
+		GroupBy:  []string{MatcherAlertName},
+		Routes: []Route{
+			{
+				Matchers:    []Matcher{{Name: MatcherMetricName, Value: "Heap.*", Type: MatchRegexp}},
+				Receiver:    "runtime",
+				Continue:    true,
+				Escalations: []Escalation{{After: common.Duration{Duration: 15 * time.Minute}, Receiver: "lead"}},
+			},
+			{
+				Matchers: []Matcher{{Name: "severity", Value: "page"}},
+				Receiver: "pager",
+				GroupBy:  []string{GroupByAll},
+			},
+			{
+				Matchers: []Matcher{{Name: MatcherMetricType, Value: "counter"}},
+				Receiver: "counters",
+			},
+		},
+	}
+}
+
+func newRoutingDispatcher(t *testing.T) (*Dispatcher, map[string]*fakeReceiver) {
+	t.Helper()
+	receivers := make(map[string]*fakeReceiver)
+	var list []Receiver
+	for _, name := range []string{"ops", "runtime", "lead", "pager", "counters"} {
+		receivers[name] = &fakeReceiver{name: name}
+		list = append(list, receivers[name])
+	}
+	return newTestDispatcher(t, testRouteTree(), list...), receivers
+}
+
+func TestDispatcher_Routes(t *testing.T) {
+	d, _ := newRoutingDispatcher(t)
+
+	heap := Alert{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc"}
+	heapPage := heap
+	heapPage.Labels = map[string]string{"severity": "page"}
+
+	tests := []struct {
+		name  string
+		alert Alert
+		want  []RouteTarget
+	}{
+		{
+			"no child matches",
+			Alert{Rule: "CPUHigh", MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization1"},
+			[]RouteTarget{{Route: RootRouteID, Receiver: "ops", GroupKey: `{alertname="CPUHigh"}`}},
+		},
+		{
+			"first match with escalation",
+			heap,
+			[]RouteTarget{
+				{Route: "root.0", Receiver: "runtime", GroupKey: `{alertname="HeapAllocHigh"}`},
+				{Route: "root.0", Receiver: "lead", GroupKey: `{alertname="HeapAllocHigh"}`, EscalateAfter: 15 * time.Minute},
+			},
+		},
+		{
+			"continue to the next match",
+			heapPage,
+			[]RouteTarget{
+				{Route: "root.0", Receiver: "runtime", GroupKey: `{alertname="HeapAllocHigh"}`},
+				{Route: "root.0", Receiver: "lead", GroupKey: `{alertname="HeapAllocHigh"}`, EscalateAfter: 15 * time.Minute},
+				{Route: "root.1", Receiver: "pager", GroupKey: heapPage.Key()},
+			},
+		},
+		{
+			"no continue after a match",
+			Alert{Rule: "PollSlow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Labels: map[string]string{"severity": "page"}},
+			[]RouteTarget{{Route: "root.1", Receiver: "pager", GroupKey: "PollSlow|counter|PollCount"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, d.Routes(&tt.alert))
+		})
+	}
+}
+
+func TestDispatcher_SetRouteUnknownReceiver(t *testing.T) {
+	d, err := NewDispatcher(DefaultRoute(), logger.GetLogger())
+	require.NoError(t, err)
+	d.AddReceiver(&fakeReceiver{name: "ops"})
+
+	assert.ErrorIs(t, d.SetRoute(testRouteTree()), ErrorUnknownReceiver)
+	assert.NoError(t, d.SetRoute(Route{Receiver: "ops"}))
+
+	_, err = NewDispatcher(Route{Receiver: "ops"}, logger.GetLogger())
+	assert.ErrorIs(t, err, ErrorUnknownReceiver)
+}
+
+func TestDispatcher_Escalation(t *testing.T) {
+	ctx := context.Background()
+
+	heap := func(ackAt time.Time) []Alert {
+		return []Alert{{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc",
+			State: StateFiring, FiredAt: t0, AcknowledgedAt: ackAt}}
+	}
+
+	t.Run("not acknowledged", func(t *testing.T) {
+		d, rcv := newRoutingDispatcher(t)
+
+		for m := 0; m <= 20; m++ {
+			d.Dispatch(ctx, heap(time.Time{}), t0.Add(time.Duration(m)*time.Minute))
+		}
+		assert.Len(t, rcv["runtime"].notifications, 1)
+		require.Len(t, rcv["lead"].notifications, 1)
+		assert.Empty(t, rcv["ops"].notifications)
+
+		// the resolution reaches the escalation receiver too
+		resolved := heap(time.Time{})
+		resolved[0].State = StateResolved
+		resolved[0].ResolvedAt = t0.Add(21 * time.Minute)
+		d.Dispatch(ctx, resolved, t0.Add(21*time.Minute))
+		require.Len(t, rcv["lead"].notifications, 2)
+		assert.Equal(t, StateResolved, rcv["lead"].notifications[1].Status)
+	})
+
+	t.Run("acknowledged in time", func(t *testing.T) {
+		d, rcv := newRoutingDispatcher(t)
+
+		for m := 0; m <= 20; m++ {
+			d.Dispatch(ctx, heap(t0.Add(10*time.Minute)), t0.Add(time.Duration(m)*time.Minute))
+		}
+		assert.Len(t, rcv["runtime"].notifications, 1)
+		assert.Empty(t, rcv["lead"].notifications)
+	})
+
+	t.Run("acknowledged late", func(t *testing.T) {
+		d, rcv := newRoutingDispatcher(t)
+
+		for m := 0; m <= 20; m++ {
+			ackAt := time.Time{}
+			if m >= 16 {
+				ackAt = t0.Add(16 * time.Minute)
+			}
+			d.Dispatch(ctx, heap(ackAt), t0.Add(time.Duration(m)*time.Minute))
+		}
+		require.Len(t, rcv["lead"].notifications, 1)
+		assert.Equal(t, StateFiring, rcv["lead"].notifications[0].Status)
+	})
+}
+
+func TestEvaluator_Acknowledge(t *testing.T) {
+	ctx := context.Background()
+	e, s := newTestEvaluator(t, heapRule())
+
+	heap := Alert{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc"}
+	key := heap.Key()
+
+	_, err := e.Acknowledge(key, "jane", t0)
+	assert.ErrorIs(t, err, ErrorAlertNotFound)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	_, err = e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+
+	a, err := e.Acknowledge(key, "jane", t0.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "jane", a.AcknowledgedBy)
+	assert.Equal(t, t0.Add(time.Minute), a.AcknowledgedAt)
+
+	// the first acknowledgement is kept
+	a, err = e.Acknowledge(key, "john", t0.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "jane", a.AcknowledgedBy)
+
+	// the acknowledgement is cleared when the alert is resolved
+	setGauge(t, s, "HeapAlloc", 100e6)
+	_, err = e.Evaluate(ctx, t0.Add(3*time.Minute))
+	require.NoError(t, err)
+
+	_, err = e.Acknowledge(key, "jane", t0.Add(4*time.Minute))
+	assert.ErrorIs(t, err, ErrorAlertNotFiring)
+
+	setGauge(t, s, "HeapAlloc", 600e6)
+	_, err = e.Evaluate(ctx, t0.Add(5*time.Minute))
+	require.NoError(t, err)
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateFiring, alerts[0].State)
+	assert.Empty(t, alerts[0].AcknowledgedBy)
+	assert.True(t, alerts[0].AcknowledgedAt.IsZero())
+}
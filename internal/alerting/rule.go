@@ -0,0 +1,379 @@
+package alerting
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/expr"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// Operator is a comparison operator used in a rule condition.
+type Operator string
+
+const (
+	OperatorGreater        Operator = ">"
+	OperatorGreaterOrEqual Operator = ">="
+	OperatorLess           Operator = "<"
+	OperatorLessOrEqual    Operator = "<="
+	OperatorEqual          Operator = "=="
+	OperatorNotEqual       Operator = "!="
+)
+
+// Compare applies the operator to the given value and threshold.
+func (o Operator) Compare(value, threshold float64) bool {
+	switch o {
+	case OperatorGreater:
+		return value > threshold
+	case OperatorGreaterOrEqual:
+		return value >= threshold
+	case OperatorLess:
+		return value < threshold
+	case OperatorLessOrEqual:
+		return value <= threshold
+	case OperatorEqual:
+		return value == threshold
+	case OperatorNotEqual:
+		return value != threshold
+	default:
+		return false
+	}
+}
+
+// IsValid reports whether o is one of the supported operators.
+func (o Operator) IsValid() bool {
+	switch o {
+	case OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual, OperatorEqual, OperatorNotEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// RuleKind determines what condition a rule checks.
+type RuleKind string
+
+const (
+	// RuleKindThreshold compares the metric value with the threshold. It is the default kind.
+	RuleKindThreshold RuleKind = "threshold"
+
+	// RuleKindStale holds when a metric has not been updated for the rule's stale_after duration.
+	RuleKindStale RuleKind = "stale"
+
+	// RuleKindAbsent holds when no metric matches the rule at all.
+	RuleKindAbsent RuleKind = "absent"
+
+	// RuleKindRate compares the per-second growth of a counter over the window with the threshold.
+	RuleKindRate RuleKind = "rate"
+
+	// RuleKindIncrease compares the growth of a counter over the window with the threshold.
+	RuleKindIncrease RuleKind = "increase"
+
+	// RuleKindDelta compares the change of a gauge over the window with the threshold.
+	RuleKindDelta RuleKind = "delta"
+
+	// RuleKindDeriv compares the per-second derivative of a gauge over the window with the threshold.
+	RuleKindDeriv RuleKind = "deriv"
+
+	// RuleKindExpr holds when the boolean expression of the rule is true, see package expr.
+	RuleKindExpr RuleKind = "expr"
+
+	// RuleKindAnomaly holds when a gauge deviates from its learned baseline by at least sigma standard deviations.
+	RuleKindAnomaly RuleKind = "anomaly"
+)
+
+// IsValid reports whether k is one of the supported rule kinds. Empty means threshold.
+func (k RuleKind) IsValid() bool {
+	switch k {
+	case "", RuleKindThreshold, RuleKindStale, RuleKindAbsent, RuleKindRate, RuleKindIncrease, RuleKindDelta, RuleKindDeriv, RuleKindExpr, RuleKindAnomaly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rule is an alert rule evaluated against stored metrics.
+//
+// Example JSON:
+//
+//	{
+//	  "name": "HeapAllocHigh",
+//	  "metric_type": "gauge",
+//	  "metric_name": "HeapAlloc",
+//	  "operator": ">",
+//	  "threshold": 500e6,
+//	  "for": "1m",
+//	  "keep_firing_for": "30s",
+//	  "labels": {"severity": "page"},
+//	  "disabled": false
+//	}
+//
+// Kind selects the condition, threshold by default. A stale rule fires for
+// every matching metric that has not been updated for StaleAfter, e.g. when
+// the agent reporting it died; the alert value is the age of the metric in
+// seconds. An absent rule fires when no metric matches it at all.
+//
+// Rate and increase rules apply to counters, delta and deriv rules to gauges.
+// They compare a value computed over the last Window with the threshold, from
+// the metric values the Evaluator observed at its evaluations. A window should
+// therefore span at least a few evaluation intervals; until two values have
+// been observed, such a rule has no alerts. A decreasing counter is treated
+// as reset and is not reported as a negative rate.
+//
+//	{"name": "AgentDown", "kind": "stale", "metric_type": "counter", "metric_name": "PollCount", "stale_after": "60s"}
+//	{"name": "NoHeap", "kind": "absent", "metric_type": "gauge", "metric_name": "HeapAlloc"}
+//	{"name": "PollSlow", "kind": "rate", "metric_type": "counter", "metric_name": "PollCount", "window": "5m", "operator": "<", "threshold": 0.5}
+//
+// An expr rule evaluates a boolean expression over all stored metrics and
+// has a single alert instance without a metric. Its metric type, name,
+// operator and threshold are not used; if the expression is a comparison,
+// the alert value and threshold are the values of its operands. A reference
+// to a missing metric or a division by zero makes the condition not hold:
+//
+//	{"name": "MemoryLow", "kind": "expr", "expr": "(TotalMemory - FreeMemory) / TotalMemory > 0.9"}
+//
+// An anomaly rule learns the normal range of every matching gauge from the
+// values observed at evaluations, as an exponentially weighted moving average
+// and variance with the smoothing factor Alpha, and holds when the z-score of
+// the current value, i.e. its distance from the average in standard
+// deviations, is at least Sigma in either direction. The alert value is the
+// z-score. A metric has no alert until WarmUp values have been observed, so
+// fresh metrics do not fire while their baseline is being learned. Baselines
+// are persisted like alert states, see Baselines:
+//
+//	{"name": "HeapAllocUnusual", "kind": "anomaly", "metric_type": "gauge", "metric_name": "HeapAlloc", "sigma": 3}
+//
+// LabelMatchers restrict the rule to metrics whose labels match all of
+// them. The name of a matcher is always a metric label name; a missing label
+// matches as an empty string:
+//
+//	{"name": "HeapAllocHighProd", "metric_type": "gauge", "metric_name": "HeapAlloc",
+//	 "label_matchers": [{"name": "env", "value": "prod"}, {"name": "host", "value": "web-.*", "type": "=~"}],
+//	 "operator": ">", "threshold": 500e6}
+//
+// For is how long the condition must hold before a pending alert starts firing.
+// KeepFiringFor is how long a firing alert keeps firing after the condition
+// stopped holding, which protects against flapping of noisy metrics.
+// Labels are attached to every alert produced by the rule.
+// Disabled rules are not evaluated and have no alerts.
+type Rule struct {
+	Name          string            `json:"name"`
+	Kind          RuleKind          `json:"kind,omitempty"`
+	MetricType    metric.MetricType `json:"metric_type"`
+	MetricName    string            `json:"metric_name"`
+	LabelMatchers []Matcher         `json:"label_matchers,omitempty"`
+	Operator      Operator          `json:"operator,omitempty"`
+	Threshold     float64           `json:"threshold"`
+	StaleAfter    common.Duration   `json:"stale_after"`
+	Window        common.Duration   `json:"window"`
+	Expr          string            `json:"expr,omitempty"`
+	Sigma         float64           `json:"sigma,omitempty"`
+	Alpha         float64           `json:"alpha,omitempty"`   // DefaultAnomalyAlpha if zero
+	WarmUp        int               `json:"warm_up,omitempty"` // DefaultAnomalyWarmUp if zero
+	For           common.Duration   `json:"for"`
+	KeepFiringFor common.Duration   `json:"keep_firing_for"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Disabled      bool              `json:"disabled,omitempty"`
+}
+
+// isPattern reports whether the metric name contains glob meta characters.
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// Validate checks that the rule is well-formed.
+func (r *Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return ErrorInvalidRuleName
+	}
+
+	if r.For.Duration < 0 || r.KeepFiringFor.Duration < 0 {
+		return ErrorInvalidDuration
+	}
+
+	if err := ValidateMatchers(r.LabelMatchers); err != nil {
+		return err
+	}
+
+	if r.Kind == RuleKindExpr {
+		_, err := r.parseExpr()
+		return err
+	}
+
+	if r.MetricType != metric.MetricTypeGauge && r.MetricType != metric.MetricTypeCounter {
+		return metric.ErrorInvalidMetricType
+	}
+
+	if isPattern(r.MetricName) {
+		if _, err := path.Match(r.MetricName, ""); err != nil {
+			return ErrorInvalidMetricName
+		}
+	} else if !metric.IsMetricNameValid(r.MetricName) {
+		return ErrorInvalidMetricName
+	}
+
+	switch r.Kind {
+	case "", RuleKindThreshold:
+		if !r.Operator.IsValid() {
+			return ErrorInvalidOperator
+		}
+	case RuleKindStale:
+		if r.StaleAfter.Duration <= 0 {
+			return ErrorInvalidDuration
+		}
+	case RuleKindAbsent:
+	case RuleKindRate, RuleKindIncrease, RuleKindDelta, RuleKindDeriv:
+		if !r.Operator.IsValid() {
+			return ErrorInvalidOperator
+		}
+		if r.Window.Duration <= 0 {
+			return ErrorInvalidDuration
+		}
+		if r.MetricType != r.windowedMetricType() {
+			return fmt.Errorf("%w: %s rules apply to %s metrics", metric.ErrorInvalidMetricType, r.Kind, r.windowedMetricType())
+		}
+	case RuleKindAnomaly:
+		if r.MetricType != metric.MetricTypeGauge {
+			return fmt.Errorf("%w: %s rules apply to %s metrics", metric.ErrorInvalidMetricType, r.Kind, metric.MetricTypeGauge)
+		}
+		if r.Sigma <= 0 {
+			return fmt.Errorf("%w: sigma must be positive", ErrorInvalidRule)
+		}
+		if r.Alpha < 0 || r.Alpha > 1 {
+			return fmt.Errorf("%w: alpha must be between 0 and 1", ErrorInvalidRule)
+		}
+		if r.WarmUp < 0 {
+			return fmt.Errorf("%w: negative warm_up", ErrorInvalidRule)
+		}
+	default:
+		return ErrorInvalidRuleKind
+	}
+
+	return nil
+}
+
+// Matches reports whether the rule applies to the given metric: its type and
+// name match and its labels match the LabelMatchers.
+// Expr rules apply to all metrics at once and match none of them individually.
+func (r *Rule) Matches(m metric.Metric) bool {
+	if r.Kind == RuleKindExpr || m.GetType() != r.MetricType {
+		return false
+	}
+	if !r.matchesName(m.GetName()) {
+		return false
+	}
+
+	labels := m.GetLabels()
+	for i := range r.LabelMatchers {
+		if !r.LabelMatchers[i].matchesValue(labels[r.LabelMatchers[i].Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesName reports whether the metric name matches the name or pattern of the rule.
+func (r *Rule) matchesName(name string) bool {
+	if !isPattern(r.MetricName) {
+		return name == r.MetricName
+	}
+	ok, err := path.Match(r.MetricName, name)
+	return err == nil && ok
+}
+
+// Check reports whether the rule condition holds for the given value.
+func (r *Rule) Check(value float64) bool {
+	return r.Operator.Compare(value, r.Threshold)
+}
+
+// String returns the rule condition in a human-readable form,
+// e.g. "gauge HeapAlloc > 5e+08", "stale(counter PollCount, 1m0s)" or "absent(gauge HeapAlloc)".
+func (r *Rule) String() string {
+	switch r.Kind {
+	case RuleKindStale:
+		return fmt.Sprintf("stale(%s %s, %s)", r.MetricType, r.MetricName, r.StaleAfter)
+	case RuleKindAbsent:
+		return fmt.Sprintf("absent(%s %s)", r.MetricType, r.MetricName)
+	case RuleKindRate, RuleKindIncrease, RuleKindDelta, RuleKindDeriv:
+		return fmt.Sprintf("%s(%s %s[%s]) %s %g", r.Kind, r.MetricType, r.MetricName, r.Window, r.Operator, r.Threshold)
+	case RuleKindExpr:
+		return r.Expr
+	case RuleKindAnomaly:
+		return fmt.Sprintf("anomaly(%s %s) >= %g sigma", r.MetricType, r.MetricName, r.Sigma)
+	default:
+		return fmt.Sprintf("%s %s %s %g", r.MetricType, r.MetricName, r.Operator, r.Threshold)
+	}
+}
+
+// parseExpr parses the expression of an expr rule, which must be boolean.
+func (r *Rule) parseExpr() (*expr.Expr, error) {
+	e, err := expr.Parse(r.Expr)
+	if err != nil {
+		return nil, err
+	}
+	if e.Type() != expr.TypeBool {
+		return nil, fmt.Errorf("%w: expression must be a condition, got %s", ErrorInvalidExpr, e.Type())
+	}
+	return e, nil
+}
+
+// isWindowed reports whether the rule is computed over a window of observed values.
+func (r *Rule) isWindowed() bool {
+	switch r.Kind {
+	case RuleKindRate, RuleKindIncrease, RuleKindDelta, RuleKindDeriv:
+		return true
+	default:
+		return false
+	}
+}
+
+// windowedMetricType returns the metric type a windowed rule applies to.
+func (r *Rule) windowedMetricType() metric.MetricType {
+	if r.Kind == RuleKindRate || r.Kind == RuleKindIncrease {
+		return metric.MetricTypeCounter
+	}
+	return metric.MetricTypeGauge
+}
+
+// windowValue computes the value of a windowed rule over the points.
+func (r *Rule) windowValue(points []point) float64 {
+	switch r.Kind {
+	case RuleKindRate:
+		return rate(points)
+	case RuleKindIncrease:
+		return increase(points)
+	case RuleKindDelta:
+		return delta(points)
+	default:
+		return deriv(points)
+	}
+}
+
+// ValidateRules validates every rule and checks that rule names are unique.
+func ValidateRules(rules []Rule) error {
+	names := make(map[string]struct{}, len(rules))
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", rules[i].Name, err)
+		}
+		if _, exists := names[rules[i].Name]; exists {
+			return fmt.Errorf("rule %q: %w", rules[i].Name, ErrorDuplicateRuleName)
+		}
+		names[rules[i].Name] = struct{}{}
+	}
+	return nil
+}
+
+// metricValue converts the value of a gauge or counter to float64.
+func metricValue(m metric.Metric) (float64, error) {
+	switch v := m.GetValue().(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, ErrorValueNotComparable
+	}
+}
@@ -0,0 +1,147 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/expr"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperator_Compare(t *testing.T) {
+	tests := []struct {
+		op        Operator
+		value     float64
+		threshold float64
+		want      bool
+	}{
+		{OperatorGreater, 2, 1, true},
+		{OperatorGreater, 1, 1, false},
+		{OperatorGreaterOrEqual, 1, 1, true},
+		{OperatorLess, 1, 2, true},
+		{OperatorLessOrEqual, 2, 2, true},
+		{OperatorEqual, 3, 3, true},
+		{OperatorNotEqual, 3, 3, false},
+		{Operator("~"), 3, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.op.Compare(tt.value, tt.threshold))
+		})
+	}
+}
+
+func TestRule_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		err  error
+	}{
+		{name: "ok", rule: Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 1}},
+		{name: "ok pattern", rule: Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization*", Operator: ">"}},
+		{name: "empty name", rule: Rule{MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">"}, err: ErrorInvalidRuleName},
+		{name: "bad type", rule: Rule{Name: "r", MetricType: "unknown", MetricName: "HeapAlloc", Operator: ">"}, err: metric.ErrorInvalidMetricType},
+		{name: "bad metric name", rule: Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "1abc", Operator: ">"}, err: ErrorInvalidMetricName},
+		{name: "bad pattern", rule: Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "abc[", Operator: ">"}, err: ErrorInvalidMetricName},
+		{name: "bad operator", rule: Rule{Name: "r", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "=>"}, err: ErrorInvalidOperator},
+		{name: "bad kind", rule: Rule{Name: "r", Kind: "magic", MetricType: metric.MetricTypeCounter, MetricName: "PollCount"}, err: ErrorInvalidRuleKind},
+		{name: "stale", rule: Rule{Name: "r", Kind: RuleKindStale, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", StaleAfter: common.Duration{Duration: time.Minute}}},
+		{name: "stale without duration", rule: Rule{Name: "r", Kind: RuleKindStale, MetricType: metric.MetricTypeCounter, MetricName: "PollCount"}, err: ErrorInvalidDuration},
+		{name: "rate", rule: Rule{Name: "r", Kind: RuleKindRate, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<", Window: common.Duration{Duration: time.Minute}}},
+		{name: "deriv", rule: Rule{Name: "r", Kind: RuleKindDeriv, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Window: common.Duration{Duration: time.Minute}}},
+		{name: "rate without window", rule: Rule{Name: "r", Kind: RuleKindRate, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<"}, err: ErrorInvalidDuration},
+		{name: "rate without operator", rule: Rule{Name: "r", Kind: RuleKindIncrease, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Window: common.Duration{Duration: time.Minute}}, err: ErrorInvalidOperator},
+		{name: "rate of gauge", rule: Rule{Name: "r", Kind: RuleKindRate, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: "<", Window: common.Duration{Duration: time.Minute}}, err: metric.ErrorInvalidMetricType},
+		{name: "delta of counter", rule: Rule{Name: "r", Kind: RuleKindDelta, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<", Window: common.Duration{Duration: time.Minute}}, err: metric.ErrorInvalidMetricType},
+		{name: "expr", rule: Rule{Name: "r", Kind: RuleKindExpr, Expr: "max(CPUutilization*) > 95"}},
+		{name: "expr syntax", rule: Rule{Name: "r", Kind: RuleKindExpr, Expr: "max(CPUutilization*) >"}, err: expr.ErrorSyntax},
+		{name: "expr not a condition", rule: Rule{Name: "r", Kind: RuleKindExpr, Expr: "max(CPUutilization*)"}, err: ErrorInvalidExpr},
+		{name: "absent", rule: Rule{Name: "r", Kind: RuleKindAbsent, MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization*"}},
+		{name: "anomaly", rule: Rule{Name: "r", Kind: RuleKindAnomaly, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Sigma: 3, Alpha: 0.2, WarmUp: 5}},
+		{name: "anomaly without sigma", rule: Rule{Name: "r", Kind: RuleKindAnomaly, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc"}, err: ErrorInvalidRule},
+		{name: "anomaly bad alpha", rule: Rule{Name: "r", Kind: RuleKindAnomaly, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Sigma: 3, Alpha: 1.5}, err: ErrorInvalidRule},
+		{name: "anomaly of counter", rule: Rule{Name: "r", Kind: RuleKindAnomaly, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Sigma: 3}, err: metric.ErrorInvalidMetricType},
+		{name: "label matchers", rule: Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", LabelMatchers: []Matcher{{Name: "host", Value: "web-.*", Type: MatchRegexp}}}},
+		{name: "bad label matcher", rule: Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", LabelMatchers: []Matcher{{Name: "host", Value: "(", Type: MatchRegexp}}}, err: ErrorInvalidMatcher},
+		{name: "unnamed label matcher", rule: Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", LabelMatchers: []Matcher{{Value: "a"}}}, err: ErrorInvalidMatcher},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	r := Rule{Name: "cpu", MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization*", Operator: ">", Threshold: 95}
+
+	assert.True(t, r.Matches(metric.MustNewGauge("CPUutilization1", 0)))
+	assert.True(t, r.Matches(metric.MustNewGauge("CPUutilization16", 0)))
+	assert.False(t, r.Matches(metric.MustNewGauge("TotalMemory", 0)))
+	assert.False(t, r.Matches(metric.MustNewCounter("CPUutilization1", 0)))
+
+	exact := Rule{Name: "heap", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">"}
+	assert.True(t, exact.Matches(metric.MustNewGauge("HeapAlloc", 0)))
+	assert.False(t, exact.Matches(metric.MustNewGauge("HeapAllocX", 0)))
+
+	labeled := Rule{Name: "heap", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">",
+		LabelMatchers: []Matcher{{Name: "host", Value: "web-.*", Type: MatchRegexp}, {Name: "env", Value: "dev", Type: MatchNotEqual}}}
+	require.NoError(t, labeled.Validate())
+	assert.True(t, labeled.Matches(&metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": "web-1", "env": "prod"}}))
+	assert.True(t, labeled.Matches(&metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": "web-1"}}), "missing label matches as empty")
+	assert.False(t, labeled.Matches(&metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": "web-1", "env": "dev"}}))
+	assert.False(t, labeled.Matches(&metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": "db-1"}}))
+	assert.False(t, labeled.Matches(metric.MustNewGauge("HeapAlloc", 0)))
+
+	// matcher names are labels, not alert fields
+	byName := Rule{Name: "heap", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">",
+		LabelMatchers: []Matcher{{Name: MatcherMetricName, Value: "HeapAlloc"}}}
+	assert.False(t, byName.Matches(metric.MustNewGauge("HeapAlloc", 0)))
+}
+
+func TestRule_String(t *testing.T) {
+	r := Rule{Name: "heap", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6}
+	assert.Equal(t, "gauge HeapAlloc > 5e+08", r.String())
+
+	stale := Rule{Name: "down", Kind: RuleKindStale, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", StaleAfter: common.Duration{Duration: time.Minute}}
+	assert.Equal(t, "stale(counter PollCount, 1m0s)", stale.String())
+
+	absent := Rule{Name: "heap", Kind: RuleKindAbsent, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc"}
+	assert.Equal(t, "absent(gauge HeapAlloc)", absent.String())
+
+	rate := Rule{Name: "poll", Kind: RuleKindRate, MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<", Threshold: 0.5, Window: common.Duration{Duration: 5 * time.Minute}}
+	assert.Equal(t, "rate(counter PollCount[5m0s]) < 0.5", rate.String())
+
+	anomaly := Rule{Name: "heap", Kind: RuleKindAnomaly, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Sigma: 3}
+	assert.Equal(t, "anomaly(gauge HeapAlloc) >= 3 sigma", anomaly.String())
+
+	e := Rule{Name: "mem", Kind: RuleKindExpr, Expr: "FreeMemory < 1e6"}
+	assert.Equal(t, "FreeMemory < 1e6", e.String())
+	assert.False(t, e.Matches(metric.MustNewGauge("FreeMemory", 0)))
+}
+
+func TestValidateRules(t *testing.T) {
+	r := Rule{Name: "heap", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">"}
+
+	require.NoError(t, ValidateRules([]Rule{r}))
+	require.ErrorIs(t, ValidateRules([]Rule{r, r}), ErrorDuplicateRuleName)
+	require.ErrorIs(t, ValidateRules([]Rule{{Name: "x"}}), metric.ErrorInvalidMetricType)
+}
+
+func TestRule_Validate_Durations(t *testing.T) {
+	r := Rule{Name: "r", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">"}
+	r.For.Duration = -1
+	assert.ErrorIs(t, r.Validate(), ErrorInvalidDuration)
+
+	r.For.Duration = 0
+	r.KeepFiringFor.Duration = -1
+	assert.ErrorIs(t, r.Validate(), ErrorInvalidDuration)
+}
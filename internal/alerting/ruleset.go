@@ -0,0 +1,200 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ruleSet keeps track of where the rules of an Evaluator come from.
+//
+// Rules from the config file are the defaults. Rules managed at runtime are
+// kept in a RuleStore; a stored rule with the same name as a config rule
+// overrides it, so config rules can be updated and disabled, but not deleted.
+type ruleSet struct {
+	config []Rule
+	stored []Rule
+	store  RuleStore
+}
+
+// effective merges config and stored rules.
+func (s *ruleSet) effective() []Rule {
+	result := make([]Rule, 0, len(s.config)+len(s.stored))
+	for _, r := range s.config {
+		if i := ruleIndex(s.stored, r.Name); i >= 0 {
+			r = s.stored[i]
+		}
+		result = append(result, r)
+	}
+	for _, r := range s.stored {
+		if ruleIndex(s.config, r.Name) < 0 {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// ruleIndex returns the index of the rule with the given name or -1.
+func ruleIndex(rules []Rule, name string) int {
+	for i := range rules {
+		if rules[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetRuleStore sets the store persisting rules managed at runtime.
+func (e *Evaluator) SetRuleStore(s RuleStore) {
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	e.ruleSet.store = s
+}
+
+// LoadRules loads rules managed at runtime from the rule store
+// and merges them with the rules from the config.
+func (e *Evaluator) LoadRules(ctx context.Context) error {
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	if e.ruleSet.store == nil {
+		return nil
+	}
+
+	stored, err := e.ruleSet.store.LoadRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateRules(stored); err != nil {
+		return err
+	}
+
+	e.applyRules(stored)
+	return nil
+}
+
+// Rules returns a snapshot of the rules in evaluation order.
+func (e *Evaluator) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.rules)
+}
+
+// Rule returns the rule with the given name.
+func (e *Evaluator) Rule(name string) (Rule, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := e.rule(name)
+	if r == nil {
+		return Rule{}, ErrorRuleNotFound
+	}
+	return *r, nil
+}
+
+// AddRule validates and adds a new rule. It is evaluated starting with the next evaluation.
+func (e *Evaluator) AddRule(ctx context.Context, r Rule) error {
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrorInvalidRule, err)
+	}
+
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	if _, err := e.Rule(r.Name); err == nil {
+		return ErrorDuplicateRuleName
+	}
+
+	return e.saveRules(ctx, append(slices.Clone(e.ruleSet.stored), r))
+}
+
+// UpdateRule replaces the rule with the given name. The name of a rule cannot be changed.
+func (e *Evaluator) UpdateRule(ctx context.Context, name string, r Rule) error {
+	if r.Name == "" {
+		r.Name = name
+	}
+	if r.Name != name {
+		return fmt.Errorf("%w: %w", ErrorInvalidRule, ErrorInvalidRuleName)
+	}
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrorInvalidRule, err)
+	}
+
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	if _, err := e.Rule(name); err != nil {
+		return err
+	}
+
+	return e.saveRules(ctx, upsertRule(e.ruleSet.stored, r))
+}
+
+// SetRuleDisabled disables or enables the rule with the given name and returns the updated rule.
+func (e *Evaluator) SetRuleDisabled(ctx context.Context, name string, disabled bool) (Rule, error) {
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	r, err := e.Rule(name)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	r.Disabled = disabled
+	return r, e.saveRules(ctx, upsertRule(e.ruleSet.stored, r))
+}
+
+// DeleteRule deletes the rule with the given name.
+// Rules defined in the config file cannot be deleted, only disabled.
+func (e *Evaluator) DeleteRule(ctx context.Context, name string) error {
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	if ruleIndex(e.ruleSet.config, name) >= 0 {
+		return ErrorRuleInConfig
+	}
+
+	i := ruleIndex(e.ruleSet.stored, name)
+	if i < 0 {
+		return ErrorRuleNotFound
+	}
+
+	return e.saveRules(ctx, slices.Delete(slices.Clone(e.ruleSet.stored), i, i+1))
+}
+
+// upsertRule returns a copy of rules with r replacing the rule of the same name or appended.
+func upsertRule(rules []Rule, r Rule) []Rule {
+	result := slices.Clone(rules)
+	if i := ruleIndex(result, r.Name); i >= 0 {
+		result[i] = r
+		return result
+	}
+	return append(result, r)
+}
+
+// saveRules persists the stored rules and makes them effective.
+// The caller must hold rulesMu.
+func (e *Evaluator) saveRules(ctx context.Context, stored []Rule) error {
+	if e.ruleSet.store != nil {
+		if err := e.ruleSet.store.SaveRules(ctx, stored); err != nil {
+			return err
+		}
+	}
+
+	e.applyRules(stored)
+	return nil
+}
+
+// applyRules makes the given stored rules effective. The caller must hold rulesMu.
+func (e *Evaluator) applyRules(stored []Rule) {
+	e.ruleSet.stored = stored
+	rules := e.ruleSet.effective()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules = rules
+}
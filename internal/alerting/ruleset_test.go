@@ -0,0 +1,157 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRuleStore struct {
+	rules []Rule
+	err   error
+}
+
+func (m *memoryRuleStore) SaveRules(ctx context.Context, rules []Rule) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.rules = rules
+	return nil
+}
+
+func (m *memoryRuleStore) LoadRules(ctx context.Context) ([]Rule, error) {
+	return m.rules, m.err
+}
+
+func pollRule() Rule {
+	return Rule{Name: "PollCountLow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<", Threshold: 10}
+}
+
+func ruleNames(rules []Rule) []string {
+	var names []string
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestEvaluator_LoadRules(t *testing.T) {
+	ctx := context.Background()
+
+	override := heapRule()
+	override.Threshold = 1e9
+
+	e, _ := newTestEvaluator(t, heapRule())
+	require.NoError(t, e.LoadRules(ctx), "no store is not an error")
+
+	e.SetRuleStore(&memoryRuleStore{rules: []Rule{pollRule(), override}})
+	require.NoError(t, e.LoadRules(ctx))
+
+	rules := e.Rules()
+	assert.Equal(t, []string{"HeapAllocHigh", "PollCountLow"}, ruleNames(rules))
+	assert.Equal(t, 1e9, rules[0].Threshold, "stored rule overrides the config rule")
+
+	e.SetRuleStore(&memoryRuleStore{rules: []Rule{pollRule(), pollRule()}})
+	require.ErrorIs(t, e.LoadRules(ctx), ErrorDuplicateRuleName)
+
+	e.SetRuleStore(&memoryRuleStore{err: errors.New("forced")})
+	require.Error(t, e.LoadRules(ctx))
+}
+
+func TestEvaluator_RuleCRUD(t *testing.T) {
+	ctx := context.Background()
+
+	store := &memoryRuleStore{}
+	e, s := newTestEvaluator(t, heapRule())
+	e.SetRuleStore(store)
+
+	// add
+	require.ErrorIs(t, e.AddRule(ctx, Rule{Name: "bad"}), ErrorInvalidRule)
+	require.ErrorIs(t, e.AddRule(ctx, heapRule()), ErrorDuplicateRuleName)
+	require.NoError(t, e.AddRule(ctx, pollRule()))
+	assert.Equal(t, []string{"PollCountLow"}, ruleNames(store.rules))
+
+	// runtime rule is evaluated without a restart
+	require.NoError(t, s.Add(ctx, metric.MustNewCounter("PollCount", 5)))
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, e.Alerts(), 1)
+
+	// update
+	updated := pollRule()
+	updated.Name = ""
+	updated.Threshold = 3
+	require.NoError(t, e.UpdateRule(ctx, "PollCountLow", updated))
+	r, err := e.Rule("PollCountLow")
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, r.Threshold)
+
+	renamed := pollRule()
+	renamed.Name = "Other"
+	require.ErrorIs(t, e.UpdateRule(ctx, "PollCountLow", renamed), ErrorInvalidRule)
+	require.ErrorIs(t, e.UpdateRule(ctx, "Missing", Rule{Name: "Missing", MetricType: metric.MetricTypeGauge, MetricName: "x", Operator: ">"}), ErrorRuleNotFound)
+
+	// config rules can be updated; the stored copy overrides them
+	heap := heapRule()
+	heap.Threshold = 1
+	require.NoError(t, e.UpdateRule(ctx, "HeapAllocHigh", heap))
+	assert.Equal(t, []string{"PollCountLow", "HeapAllocHigh"}, ruleNames(store.rules))
+	assert.Equal(t, []string{"HeapAllocHigh", "PollCountLow"}, ruleNames(e.Rules()), "config order is kept")
+
+	// disable
+	disabled, err := e.SetRuleDisabled(ctx, "HeapAllocHigh", true)
+	require.NoError(t, err)
+	assert.True(t, disabled.Disabled)
+	_, err = e.SetRuleDisabled(ctx, "Missing", true)
+	require.ErrorIs(t, err, ErrorRuleNotFound)
+
+	// delete
+	require.ErrorIs(t, e.DeleteRule(ctx, "HeapAllocHigh"), ErrorRuleInConfig)
+	require.ErrorIs(t, e.DeleteRule(ctx, "Missing"), ErrorRuleNotFound)
+	require.NoError(t, e.DeleteRule(ctx, "PollCountLow"))
+	assert.Equal(t, []string{"HeapAllocHigh"}, ruleNames(e.Rules()))
+
+	// alerts of deleted rules are dropped
+	_, err = e.Evaluate(ctx, t0.Add(1))
+	require.NoError(t, err)
+	assert.Empty(t, e.Alerts())
+}
+
+func TestEvaluator_RuleCRUD_StoreError(t *testing.T) {
+	ctx := context.Background()
+
+	e, _ := newTestEvaluator(t)
+	e.SetRuleStore(&memoryRuleStore{err: errors.New("forced")})
+
+	require.Error(t, e.AddRule(ctx, pollRule()))
+	assert.Empty(t, e.Rules(), "rules are not changed if they cannot be persisted")
+}
+
+func TestEvaluator_DisabledRule(t *testing.T) {
+	ctx := context.Background()
+
+	e, s := newTestEvaluator(t, heapRule())
+	setGauge(t, s, "HeapAlloc", 600e6)
+
+	_, err := e.Evaluate(ctx, t0)
+	require.NoError(t, err)
+	require.Len(t, e.Alerts(), 1)
+
+	_, err = e.SetRuleDisabled(ctx, "HeapAllocHigh", true)
+	require.NoError(t, err)
+
+	_, err = e.Evaluate(ctx, t0.Add(1))
+	require.NoError(t, err)
+	assert.Empty(t, e.Alerts())
+
+	_, err = e.SetRuleDisabled(ctx, "HeapAllocHigh", false)
+	require.NoError(t, err)
+
+	_, err = e.Evaluate(ctx, t0.Add(2))
+	require.NoError(t, err)
+	assert.Len(t, e.Alerts(), 1)
+}
@@ -0,0 +1,143 @@
+package alerting
+
+import (
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+)
+
+// point is a metric value observed at an evaluation.
+type point struct {
+	t time.Time
+	v float64
+}
+
+// seriesBuffer keeps the values of metrics observed at recent evaluations,
+// so that windowed rules (rate, increase, delta, deriv) can be computed.
+type seriesBuffer struct {
+	points map[string][]point
+}
+
+func newSeriesBuffer() *seriesBuffer {
+	return &seriesBuffer{points: make(map[string][]point)}
+}
+
+// seriesKey builds the key of a metric in the buffer.
+func seriesKey(t metric.MetricType, n string, l metric.Labels) string {
+	return string(t) + "|" + n + l.String()
+}
+
+// record appends the current values of the metrics matched by windowed rules
+// and drops values older than the longest window. Series of metrics no longer
+// matched by any windowed rule are dropped.
+func (b *seriesBuffer) record(rules []Rule, metrics []storage.TimestampedMetric, now time.Time) error {
+	var retention time.Duration
+	for i := range rules {
+		if rules[i].isWindowed() && !rules[i].Disabled && rules[i].Window.Duration > retention {
+			retention = rules[i].Window.Duration
+		}
+	}
+
+	points := make(map[string][]point, len(b.points))
+	if retention == 0 {
+		b.points = points
+		return nil
+	}
+
+	for _, tm := range metrics {
+		m := tm.Metric
+		if !matchesWindowedRule(rules, m) {
+			continue
+		}
+
+		v, err := metricValue(m)
+		if err != nil {
+			return err
+		}
+
+		key := seriesKey(m.GetType(), m.GetName(), m.GetLabels())
+		series := append(b.points[key], point{t: now, v: v})
+
+		// keep one point before the window start, so that the whole window is covered
+		from := now.Add(-retention)
+		drop := 0
+		for drop+1 < len(series) && !series[drop+1].t.After(from) {
+			drop++
+		}
+		points[key] = series[drop:]
+	}
+
+	b.points = points
+	return nil
+}
+
+// matchesWindowedRule reports whether any enabled windowed rule applies to the metric.
+func matchesWindowedRule(rules []Rule, m metric.Metric) bool {
+	for i := range rules {
+		if rules[i].isWindowed() && !rules[i].Disabled && rules[i].Matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns the values of the metric observed since from, including the
+// last value observed before from, if any.
+func (b *seriesBuffer) window(t metric.MetricType, n string, l metric.Labels, from time.Time) []point {
+	series := b.points[seriesKey(t, n, l)]
+	for i := len(series) - 1; i >= 0; i-- {
+		if !series[i].t.After(from) {
+			return series[i:]
+		}
+	}
+	return series
+}
+
+// increase returns how much a counter has grown over the points.
+// A value lower than the previous one means the counter was reset, e.g. the
+// server restarted without restoring metrics, and counts as growth from zero.
+func increase(points []point) float64 {
+	var result float64
+	for i := 1; i < len(points); i++ {
+		if d := points[i].v - points[i-1].v; d >= 0 {
+			result += d
+		} else {
+			result += points[i].v
+		}
+	}
+	return result
+}
+
+// rate returns the per-second growth of a counter over the points.
+func rate(points []point) float64 {
+	elapsed := points[len(points)-1].t.Sub(points[0].t).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return increase(points) / elapsed
+}
+
+// delta returns the difference between the last and the first gauge value.
+func delta(points []point) float64 {
+	return points[len(points)-1].v - points[0].v
+}
+
+// deriv returns the per-second derivative of a gauge estimated by simple linear regression.
+func deriv(points []point) float64 {
+	n := float64(len(points))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.t.Sub(points[0].t).Seconds()
+		sumX += x
+		sumY += p.v
+		sumXY += x * p.v
+		sumXX += x * x
+	}
+
+	d := n*sumXX - sumX*sumX
+	if d == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / d
+}
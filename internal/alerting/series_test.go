@@ -0,0 +1,78 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func points(step time.Duration, values ...float64) []point {
+	result := make([]point, len(values))
+	for i, v := range values {
+		result[i] = point{t: t0.Add(time.Duration(i) * step), v: v}
+	}
+	return result
+}
+
+func TestWindowFunctions(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     func([]point) float64
+		points []point
+		want   float64
+	}{
+		{name: "increase", fn: increase, points: points(time.Second, 10, 15, 25), want: 15},
+		{name: "increase with reset", fn: increase, points: points(time.Second, 100, 110, 5, 8), want: 18},
+		{name: "rate", fn: rate, points: points(10*time.Second, 0, 10, 20, 30), want: 1},
+		{name: "rate with reset", fn: rate, points: points(10*time.Second, 1000, 1010, 10), want: 1},
+		{name: "rate over no time", fn: rate, points: []point{{t: t0, v: 1}, {t: t0, v: 2}}, want: 0},
+		{name: "delta", fn: delta, points: points(time.Second, 10, 30, 5), want: -5},
+		{name: "deriv", fn: deriv, points: points(time.Second, 1, 3, 5, 7), want: 2},
+		{name: "deriv of noisy values", fn: deriv, points: points(time.Second, 0, 2, 1, 3), want: 0.8},
+		{name: "deriv over no time", fn: deriv, points: []point{{t: t0, v: 1}, {t: t0, v: 2}}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, tt.fn(tt.points), 1e-9)
+		})
+	}
+}
+
+func TestSeriesBuffer(t *testing.T) {
+	rules := []Rule{
+		{Name: "r", Kind: RuleKindRate, MetricType: metric.MetricTypeCounter, MetricName: "Poll*", Operator: ">",
+			Window: common.Duration{Duration: time.Minute}},
+	}
+
+	b := newSeriesBuffer()
+	for i := 0; i < 5; i++ {
+		metrics := []storage.TimestampedMetric{
+			{Metric: metric.MustNewCounter("PollCount", int64(i))},
+			{Metric: metric.MustNewGauge("PollCount", 1)},
+			{Metric: metric.MustNewCounter("Other", 1)},
+		}
+		require.NoError(t, b.record(rules, metrics, t0.Add(time.Duration(i)*30*time.Second)))
+	}
+
+	// only the matched metric is kept, with one point before the window
+	require.Len(t, b.points, 1)
+	series := b.points[seriesKey(metric.MetricTypeCounter, "PollCount", nil)]
+	require.Len(t, series, 3)
+	assert.Equal(t, t0.Add(time.Minute), series[0].t)
+
+	w := b.window(metric.MetricTypeCounter, "PollCount", nil, t0.Add(90*time.Second))
+	require.Len(t, w, 2)
+	assert.Equal(t, 3.0, w[0].v)
+
+	assert.Len(t, b.window(metric.MetricTypeCounter, "PollCount", nil, t0), 3)
+	assert.Empty(t, b.window(metric.MetricTypeCounter, "Other", nil, t0))
+
+	// series are dropped once no windowed rule needs them
+	require.NoError(t, b.record(nil, nil, t0.Add(3*time.Minute)))
+	assert.Empty(t, b.points)
+}
@@ -0,0 +1,334 @@
+package alerting
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+)
+
+// ExpiredSilenceRetention is how long expired silences are kept before they are garbage-collected.
+var ExpiredSilenceRetention = time.Hour
+
+// SilenceStatus is the status of a silence at a given time.
+type SilenceStatus string
+
+const (
+	SilencePending SilenceStatus = "pending"
+	SilenceActive  SilenceStatus = "active"
+	SilenceExpired SilenceStatus = "expired"
+)
+
+// Silence mutes notifications about alerts matching all of its matchers
+// between StartsAt and EndsAt. Silenced alerts are still evaluated.
+type Silence struct {
+	ID        string    `json:"id"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by"`
+	Comment   string    `json:"comment"`
+}
+
+// Validate checks the silence and compiles its matchers.
+func (s *Silence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("%w: no matchers", ErrorInvalidSilence)
+	}
+	if err := ValidateMatchers(s.Matchers); err != nil {
+		return err
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("%w: end time must be after start time", ErrorInvalidSilence)
+	}
+	if strings.TrimSpace(s.CreatedBy) == "" {
+		return fmt.Errorf("%w: creator is required", ErrorInvalidSilence)
+	}
+	return nil
+}
+
+// Status returns the status of the silence at the given time.
+func (s *Silence) Status(now time.Time) SilenceStatus {
+	switch {
+	case now.Before(s.StartsAt):
+		return SilencePending
+	case now.Before(s.EndsAt):
+		return SilenceActive
+	default:
+		return SilenceExpired
+	}
+}
+
+// Matches reports whether the alert matches all matchers of the silence.
+func (s *Silence) Matches(a *Alert) bool {
+	return matchAll(s.Matchers, a)
+}
+
+// Silences is a concurrency-safe set of silences.
+//
+// Silences are persisted through a SilenceStore, if one is set, or as a
+// section of the metric dump file, see DumpName, DumpState and RestoreState.
+type Silences struct {
+	mu       sync.Mutex
+	silences map[string]*Silence
+	store    SilenceStore
+}
+
+// NewSilences creates an empty set of silences.
+func NewSilences() *Silences {
+	return &Silences{silences: make(map[string]*Silence)}
+}
+
+// newID generates a random ID of a silence or an outbox entry.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SetStore sets the store persisting silences.
+func (s *Silences) SetStore(store SilenceStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store = store
+}
+
+// Load replaces the silences with the ones from the store, if it is set.
+func (s *Silences) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store == nil {
+		return nil
+	}
+
+	list, err := s.store.LoadSilences(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.restore(list)
+}
+
+// save persists the silences with the given one added or replaced
+// and keeps it only if that succeeds. s.mu must be held.
+func (s *Silences) save(ctx context.Context, silence *Silence) error {
+	if s.store != nil {
+		list := make([]Silence, 0, len(s.silences)+1)
+		for id, other := range s.silences {
+			if id != silence.ID {
+				list = append(list, *other)
+			}
+		}
+		list = append(list, *silence)
+
+		if err := s.store.SaveSilences(ctx, list); err != nil {
+			return err
+		}
+	}
+
+	s.silences[silence.ID] = silence
+	return nil
+}
+
+// Add validates and stores a new silence and returns it with its ID set.
+// A zero StartsAt means the silence starts at now.
+func (s *Silences) Add(ctx context.Context, silence Silence, now time.Time) (Silence, error) {
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+
+	if err := silence.Validate(); err != nil {
+		return Silence{}, err
+	}
+	if !silence.EndsAt.After(now) {
+		return Silence{}, fmt.Errorf("%w: end time is in the past", ErrorInvalidSilence)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Silence{}, err
+	}
+	silence.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.save(ctx, &silence); err != nil {
+		return Silence{}, err
+	}
+	return silence, nil
+}
+
+// Expire ends the silence with the given ID at now.
+// Expiring an already expired silence has no effect.
+func (s *Silences) Expire(ctx context.Context, id string, now time.Time) (Silence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silence, ok := s.silences[id]
+	if !ok {
+		return Silence{}, ErrorSilenceNotFound
+	}
+
+	if silence.Status(now) == SilenceExpired {
+		return *silence, nil
+	}
+
+	expired := *silence
+	if now.Before(expired.StartsAt) {
+		expired.StartsAt = now
+	}
+	expired.EndsAt = now
+
+	if err := s.save(ctx, &expired); err != nil {
+		return Silence{}, err
+	}
+	return expired, nil
+}
+
+// Get returns the silence with the given ID.
+func (s *Silences) Get(id string) (Silence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silence, ok := s.silences[id]
+	if !ok {
+		return Silence{}, ErrorSilenceNotFound
+	}
+	return *silence, nil
+}
+
+// List returns all silences sorted by start time.
+func (s *Silences) List() []Silence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Silence, 0, len(s.silences))
+	for _, silence := range s.silences {
+		result = append(result, *silence)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartsAt.Equal(result[j].StartsAt) {
+			return result[i].StartsAt.Before(result[j].StartsAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// Silencing returns sorted IDs of the silences active at now that match the alert.
+func (s *Silences) Silencing(a *Alert, now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for id, silence := range s.silences {
+		if silence.Status(now) == SilenceActive && silence.Matches(a) {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// GC removes silences expired for longer than ExpiredSilenceRetention
+// and returns the number of removed silences. They are removed from the
+// store with the next change, until then Load removes them again.
+func (s *Silences) GC(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, silence := range s.silences {
+		if now.Sub(silence.EndsAt) >= ExpiredSilenceRetention {
+			delete(s.silences, id)
+			n++
+		}
+	}
+	return n
+}
+
+// DumpName returns the name of the dump file section holding silences.
+func (s *Silences) DumpName() string {
+	return "silences"
+}
+
+// DumpState serializes silences for the dump file.
+func (s *Silences) DumpState(ctx context.Context) ([]byte, error) {
+	return json.Marshal(s.List())
+}
+
+// RestoreState restores silences from the dump file.
+func (s *Silences) RestoreState(ctx context.Context, data []byte) error {
+	var list []Silence
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.restore(list)
+}
+
+// restore replaces the silences with the given ones. s.mu must be held.
+func (s *Silences) restore(list []Silence) error {
+	silences := make(map[string]*Silence, len(list))
+	for i := range list {
+		silence := list[i]
+		if err := ValidateMatchers(silence.Matchers); err != nil {
+			return err
+		}
+		silences[silence.ID] = &silence
+	}
+
+	s.silences = silences
+	return nil
+}
+
+// SilenceToDTO converts a silence into its transfer representation.
+func SilenceToDTO(s *Silence, now time.Time) dto.Silence {
+	o := dto.Silence{
+		ID:        s.ID,
+		Matchers:  make([]dto.Matcher, len(s.Matchers)),
+		StartsAt:  s.StartsAt,
+		EndsAt:    s.EndsAt,
+		CreatedBy: s.CreatedBy,
+		Comment:   s.Comment,
+		Status:    string(s.Status(now)),
+	}
+	for i, m := range s.Matchers {
+		o.Matchers[i] = dto.Matcher{Name: m.Name, Value: m.Value, Type: string(m.Type)}
+	}
+	return o
+}
+
+// SilenceFromDTO converts a transfer representation into a silence.
+// The ID and status of the DTO are ignored.
+func SilenceFromDTO(d *dto.Silence) Silence {
+	s := Silence{
+		Matchers:  make([]Matcher, len(d.Matchers)),
+		StartsAt:  d.StartsAt,
+		EndsAt:    d.EndsAt,
+		CreatedBy: d.CreatedBy,
+		Comment:   d.Comment,
+	}
+	for i, m := range d.Matchers {
+		s.Matchers[i] = Matcher{Name: m.Name, Value: m.Value, Type: MatchType(m.Type)}
+	}
+	return s
+}
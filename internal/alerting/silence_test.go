@@ -0,0 +1,232 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func heapSilence(startsAt, endsAt time.Time) Silence {
+	return Silence{
+		Matchers:  []Matcher{{Name: MatcherMetricName, Value: "HeapAlloc"}},
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: "jane",
+		Comment:   "deploy",
+	}
+}
+
+func TestSilences_Add(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		modify  func(s *Silence)
+		wantErr error
+	}{
+		{name: "valid", modify: func(s *Silence) {}},
+		{name: "starts now", modify: func(s *Silence) { s.StartsAt = time.Time{} }},
+		{name: "no matchers", modify: func(s *Silence) { s.Matchers = nil }, wantErr: ErrorInvalidSilence},
+		{name: "bad matcher", modify: func(s *Silence) { s.Matchers[0].Type = "?" }, wantErr: ErrorInvalidMatcher},
+		{name: "ends before start", modify: func(s *Silence) { s.EndsAt = s.StartsAt }, wantErr: ErrorInvalidSilence},
+		{name: "already ended", modify: func(s *Silence) { s.StartsAt = t0.Add(-2 * time.Hour); s.EndsAt = t0.Add(-time.Hour) }, wantErr: ErrorInvalidSilence},
+		{name: "no creator", modify: func(s *Silence) { s.CreatedBy = "" }, wantErr: ErrorInvalidSilence},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := heapSilence(t0, t0.Add(time.Hour))
+			tt.modify(&s)
+
+			silences := NewSilences()
+			added, err := silences.Add(ctx, s, t0)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, silences.List())
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, added.ID)
+			assert.Equal(t, t0, added.StartsAt)
+
+			got, err := silences.Get(added.ID)
+			require.NoError(t, err)
+			assert.Equal(t, added.ID, got.ID)
+		})
+	}
+}
+
+func TestSilences_Silencing(t *testing.T) {
+	ctx := context.Background()
+
+	silences := NewSilences()
+
+	active, err := silences.Add(ctx, heapSilence(t0, t0.Add(time.Hour)), t0)
+	require.NoError(t, err)
+	_, err = silences.Add(ctx, heapSilence(t0.Add(time.Hour), t0.Add(2*time.Hour)), t0)
+	require.NoError(t, err)
+
+	heap := &Alert{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc"}
+	other := &Alert{Rule: "CPUHigh", MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization1"}
+
+	assert.Equal(t, []string{active.ID}, silences.Silencing(heap, t0.Add(time.Minute)))
+	assert.Empty(t, silences.Silencing(other, t0.Add(time.Minute)))
+	assert.Len(t, silences.Silencing(heap, t0.Add(time.Hour)), 1, "first silence ended, second started")
+	assert.Empty(t, silences.Silencing(heap, t0.Add(3*time.Hour)))
+}
+
+func TestSilences_Expire(t *testing.T) {
+	ctx := context.Background()
+
+	silences := NewSilences()
+
+	s, err := silences.Add(ctx, heapSilence(t0, t0.Add(time.Hour)), t0)
+	require.NoError(t, err)
+
+	now := t0.Add(10 * time.Minute)
+	expired, err := silences.Expire(ctx, s.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, expired.EndsAt)
+	assert.Equal(t, SilenceExpired, expired.Status(now))
+
+	// expiring again keeps the original end time
+	expired, err = silences.Expire(ctx, s.ID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, now, expired.EndsAt)
+
+	// pending silence gets a valid time range
+	p, err := silences.Add(ctx, heapSilence(t0.Add(time.Hour), t0.Add(2*time.Hour)), t0)
+	require.NoError(t, err)
+	expired, err = silences.Expire(ctx, p.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, expired.StartsAt)
+	assert.Equal(t, now, expired.EndsAt)
+
+	_, err = silences.Expire(ctx, "unknown", now)
+	require.ErrorIs(t, err, ErrorSilenceNotFound)
+}
+
+func TestSilences_GC(t *testing.T) {
+	ctx := context.Background()
+
+	silences := NewSilences()
+
+	s1, err := silences.Add(ctx, heapSilence(t0, t0.Add(time.Minute)), t0)
+	require.NoError(t, err)
+	s2, err := silences.Add(ctx, heapSilence(t0, t0.Add(time.Hour)), t0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, silences.GC(t0.Add(2*time.Minute)), "expired silences are retained for a while")
+	assert.Equal(t, 1, silences.GC(t0.Add(time.Minute+ExpiredSilenceRetention)))
+
+	_, err = silences.Get(s1.ID)
+	require.ErrorIs(t, err, ErrorSilenceNotFound)
+	_, err = silences.Get(s2.ID)
+	require.NoError(t, err)
+}
+
+func TestSilences_DumpSection(t *testing.T) {
+	ctx := context.Background()
+
+	silences := NewSilences()
+	s, err := silences.Add(ctx, Silence{
+		Matchers:  []Matcher{{Name: MatcherMetricName, Value: "CPU.*", Type: MatchRegexp}},
+		EndsAt:    t0.Add(time.Hour),
+		CreatedBy: "jane",
+	}, t0)
+	require.NoError(t, err)
+
+	data, err := silences.DumpState(ctx)
+	require.NoError(t, err)
+
+	restored := NewSilences()
+	require.NoError(t, restored.RestoreState(ctx, data))
+	assert.Equal(t, "silences", restored.DumpName())
+
+	a := &Alert{MetricName: "CPUutilization1"}
+	assert.Equal(t, []string{s.ID}, restored.Silencing(a, t0.Add(time.Minute)))
+
+	require.Error(t, restored.RestoreState(ctx, []byte("not json")))
+}
+
+// memorySilenceStore is a SilenceStore keeping silences in a slice.
+type memorySilenceStore struct {
+	silences []Silence
+	err      error
+}
+
+func (m *memorySilenceStore) SaveSilences(ctx context.Context, silences []Silence) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.silences = silences
+	return nil
+}
+
+func (m *memorySilenceStore) LoadSilences(ctx context.Context) ([]Silence, error) {
+	return m.silences, m.err
+}
+
+func TestSilences_Store(t *testing.T) {
+	ctx := context.Background()
+
+	store := &memorySilenceStore{}
+	silences := NewSilences()
+	silences.SetStore(store)
+
+	s1, err := silences.Add(ctx, heapSilence(t0, t0.Add(time.Hour)), t0)
+	require.NoError(t, err)
+	s2, err := silences.Add(ctx, heapSilence(t0, t0.Add(time.Hour)), t0)
+	require.NoError(t, err)
+	_, err = silences.Expire(ctx, s1.ID, t0.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, store.silences, 2)
+
+	// restored after a restart
+	restored := NewSilences()
+	restored.SetStore(store)
+	require.NoError(t, restored.Load(ctx))
+	assert.Equal(t, silences.List(), restored.List())
+	assert.Equal(t, []string{s2.ID}, restored.Silencing(&Alert{MetricName: "HeapAlloc"}, t0.Add(2*time.Minute)))
+
+	// kept in memory only if stored
+	store.err = errors.New("connection refused")
+	_, err = silences.Add(ctx, heapSilence(t0, t0.Add(time.Hour)), t0)
+	require.Error(t, err)
+	_, err = silences.Expire(ctx, s2.ID, t0.Add(time.Minute))
+	require.Error(t, err)
+	assert.Len(t, silences.List(), 2)
+	got, err := silences.Get(s2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, t0.Add(time.Hour), got.EndsAt)
+
+	require.Error(t, restored.Load(ctx))
+}
+
+func TestSilenceDTO(t *testing.T) {
+	d := &dto.Silence{
+		ID:        "ignored",
+		Matchers:  []dto.Matcher{{Name: "team", Value: "infra"}, {Name: "metric_name", Value: "CPU.*", Type: "=~"}},
+		StartsAt:  t0,
+		EndsAt:    t0.Add(time.Hour),
+		CreatedBy: "jane",
+		Comment:   "deploy",
+	}
+
+	s := SilenceFromDTO(d)
+	assert.Empty(t, s.ID)
+	assert.Equal(t, MatchRegexp, s.Matchers[1].Type)
+	require.NoError(t, s.Validate())
+
+	s.ID = "abc"
+	o := SilenceToDTO(&s, t0.Add(time.Minute))
+	assert.Equal(t, "abc", o.ID)
+	assert.Equal(t, "active", o.Status)
+	assert.Equal(t, d.Matchers, o.Matchers)
+	assert.Equal(t, "pending", SilenceToDTO(&s, t0.Add(-time.Minute)).Status)
+}
@@ -0,0 +1,70 @@
+package alerting
+
+import "sync"
+
+// WatchBuffer is how many transitions a watcher may fall behind before it
+// is disconnected, see Evaluator.Watch.
+var WatchBuffer = 256
+
+// watchers are the channels transitions are published to.
+type watchers struct {
+	mu    sync.Mutex
+	next  int
+	chans map[int]chan Transition
+}
+
+// add registers a new watcher and returns its channel and ID.
+func (w *watchers) add() (chan Transition, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.chans == nil {
+		w.chans = make(map[int]chan Transition)
+	}
+
+	id := w.next
+	w.next++
+	ch := make(chan Transition, WatchBuffer)
+	w.chans[id] = ch
+	return ch, id
+}
+
+// remove closes the channel of the watcher unless it is already closed.
+func (w *watchers) remove(id int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if ch, ok := w.chans[id]; ok {
+		close(ch)
+		delete(w.chans, id)
+	}
+}
+
+// publish sends the transitions to all watchers without blocking.
+// Watchers whose buffer is full are removed.
+func (w *watchers) publish(transitions []Transition) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id, ch := range w.chans {
+		for _, t := range transitions {
+			select {
+			case ch <- t:
+				continue
+			default:
+			}
+			close(ch)
+			delete(w.chans, id)
+			break
+		}
+	}
+}
+
+// Watch returns a channel receiving every transition as it happens and a
+// function stopping the watch. The channel is closed when the watch is
+// stopped or when the watcher falls behind by more than WatchBuffer
+// transitions; the watcher should then watch again and re-read Alerts.
+func (e *Evaluator) Watch() (<-chan Transition, func()) {
+	ch, id := e.watchers.add()
+	return ch, func() { e.watchers.remove(id) }
+}
@@ -0,0 +1,231 @@
+package alerttest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func dur(d time.Duration) common.Duration {
+	return common.Duration{Duration: d}
+}
+
+const testRules = `{
+  "alert_rules": [
+    {"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "operator": ">", "threshold": 500e6, "for": "20s"},
+    {"name": "PollSlow", "kind": "increase", "metric_type": "counter", "metric_name": "PollCount", "window": "30s", "operator": "<", "threshold": 1},
+    {"name": "AgentDown", "kind": "stale", "metric_type": "counter", "metric_name": "PollCount", "stale_after": "45s"}
+  ]
+}`
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+
+	rules, err := LoadRules(writeFile(t, dir, "config.json", testRules))
+	require.NoError(t, err)
+	assert.Len(t, rules.AlertRules, 3)
+
+	rules, err = LoadRules(writeFile(t, dir, "rules.json", `[{"name": "r", "metric_type": "gauge", "metric_name": "HeapAlloc", "operator": ">"}]`))
+	require.NoError(t, err)
+	require.Len(t, rules.AlertRules, 1)
+	assert.Equal(t, "r", rules.AlertRules[0].Name)
+
+	_, err = LoadRules(writeFile(t, dir, "bad.json", `{`))
+	assert.Error(t, err)
+}
+
+func TestLoadInputs(t *testing.T) {
+	dir := t.TempDir()
+
+	dump1 := writeFile(t, dir, "1.dump", "HeapAlloc:gauge:100\n@alerts []\n")
+	timed := writeFile(t, dir, "samples.txt", "# offset metric\n30s PollCount:counter:5\n\n0s HeapAlloc:gauge:1\n45s HeapAlloc:gauge:2:{host=\"web1\"}\n")
+	dump2 := writeFile(t, dir, "2.dump", "HeapAlloc:gauge:200\n")
+
+	inputs, err := LoadInputs([]string{dump1, timed, dump2}, 10*time.Second)
+	require.NoError(t, err)
+	require.Len(t, inputs, 3)
+	assert.Equal(t, Input{Dump: dump1}, inputs[0])
+	assert.Equal(t, []Sample{
+		{At: 0, Type: metric.MetricTypeGauge, Name: "HeapAlloc", Value: "1"},
+		{At: 30 * time.Second, Type: metric.MetricTypeCounter, Name: "PollCount", Value: "5"},
+		{At: 45 * time.Second, Type: metric.MetricTypeGauge, Name: "HeapAlloc", Labels: metric.Labels{"host": "web1"}, Value: "2"},
+	}, inputs[1].Samples)
+	assert.Equal(t, Input{At: 10 * time.Second, Dump: dump2}, inputs[2])
+
+	bad := writeFile(t, dir, "bad.txt", "0s HeapAlloc:gauge:1\nxx HeapAlloc:gauge:2\n")
+	_, err = LoadInputs([]string{bad}, 10*time.Second)
+	assert.ErrorIs(t, err, ErrorInvalidSample)
+}
+
+func TestLoadExpectations(t *testing.T) {
+	dir := t.TempDir()
+
+	exp, err := LoadExpectations(writeFile(t, dir, "exp.json",
+		`[{"at": "1m", "alerts": [{"rule": "r", "state": "firing"}]}, {"at": "0s", "alerts": []}]`))
+	require.NoError(t, err)
+	require.Len(t, exp, 2)
+	assert.Equal(t, time.Duration(0), exp[0].At.Duration)
+
+	_, err = LoadExpectations(writeFile(t, dir, "resolved.json", `[{"at": "1m", "alerts": [{"rule": "r", "state": "resolved"}]}]`))
+	assert.ErrorIs(t, err, ErrorInvalidExpectation)
+}
+
+func TestTest_Run(t *testing.T) {
+	dir := t.TempDir()
+
+	rules, err := LoadRules(writeFile(t, dir, "config.json", testRules))
+	require.NoError(t, err)
+
+	// the agent reports every 10s, heap grows at 20s, polling stops after 30s
+	timed := writeFile(t, dir, "samples.txt", `
+0s  HeapAlloc:gauge:100e6
+0s  PollCount:counter:0
+10s PollCount:counter:10
+20s HeapAlloc:gauge:600e6
+20s PollCount:counter:20
+30s PollCount:counter:30
+`)
+	inputs, err := LoadInputs([]string{timed}, 10*time.Second)
+	require.NoError(t, err)
+
+	heap := func(state alerting.State) ExpectedAlert {
+		return ExpectedAlert{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", State: state}
+	}
+	poll := ExpectedAlert{Rule: "PollSlow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", State: alerting.StateFiring}
+	down := ExpectedAlert{Rule: "AgentDown", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", State: alerting.StateFiring}
+
+	expectations := []Expectation{
+		{At: dur(0)},
+		{At: dur(25 * time.Second), Alerts: []ExpectedAlert{heap(alerting.StatePending)}},
+		{At: dur(40 * time.Second), Alerts: []ExpectedAlert{heap(alerting.StateFiring)}},
+		{At: dur(75 * time.Second), Alerts: []ExpectedAlert{heap(alerting.StateFiring), poll}},
+		{At: dur(80 * time.Second), Alerts: []ExpectedAlert{heap(alerting.StateFiring), poll, down}},
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		var out bytes.Buffer
+		test := &Test{Rules: rules, Inputs: inputs, Expectations: expectations, Interval: 10 * time.Second}
+
+		ok, err := test.Run(context.Background(), &out, zap.NewNop().Sugar())
+		require.NoError(t, err)
+		assert.True(t, ok, out.String())
+		assert.Contains(t, out.String(), "ok   1m20s: 3 alerts\n")
+		assert.Contains(t, out.String(), "PASS: 5 expectations met\n")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		var out bytes.Buffer
+		wrong := []Expectation{{At: dur(40 * time.Second), Alerts: []ExpectedAlert{heap(alerting.StatePending)}}}
+		test := &Test{Rules: rules, Inputs: inputs, Expectations: wrong, Interval: 10 * time.Second}
+
+		ok, err := test.Run(context.Background(), &out, zap.NewNop().Sugar())
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, "FAIL 40s:\n"+
+			"+ HeapAllocHigh gauge HeapAlloc firing\n"+
+			"- HeapAllocHigh gauge HeapAlloc pending\n"+
+			"FAIL: 1 of 1 expectations not met\n", out.String())
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		test := &Test{Rules: &Rules{AlertRules: []alerting.Rule{{Name: "bad"}}}, Interval: 10 * time.Second}
+		_, err := test.Run(context.Background(), &bytes.Buffer{}, zap.NewNop().Sugar())
+		assert.ErrorIs(t, err, metric.ErrorInvalidMetricType)
+	})
+}
+
+func TestTest_RunDumps(t *testing.T) {
+	dir := t.TempDir()
+
+	rules := &Rules{AlertRules: []alerting.Rule{
+		{Name: "NoHeap", Kind: alerting.RuleKindAbsent, MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc"},
+		{Name: "PollCountLow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<", Threshold: 10},
+	}}
+
+	// the second snapshot replaces the first one, so HeapAlloc is absent
+	inputs, err := LoadInputs([]string{
+		writeFile(t, dir, "1.dump", "HeapAlloc:gauge:100\nPollCount:counter:5\n"),
+		writeFile(t, dir, "2.dump", "PollCount:counter:50\n"),
+	}, time.Minute)
+	require.NoError(t, err)
+
+	expectations := []Expectation{
+		{At: dur(0), Alerts: []ExpectedAlert{{Rule: "PollCountLow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", State: alerting.StateFiring}}},
+		{At: dur(time.Minute), Alerts: []ExpectedAlert{{Rule: "NoHeap", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", State: alerting.StateFiring}}},
+	}
+
+	var out bytes.Buffer
+	test := &Test{Rules: rules, Inputs: inputs, Expectations: expectations, Interval: time.Minute}
+
+	ok, err := test.Run(context.Background(), &out, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	assert.True(t, ok, out.String())
+}
+
+func TestTest_RunLabels(t *testing.T) {
+	dir := t.TempDir()
+
+	rules := &Rules{AlertRules: []alerting.Rule{
+		{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6, For: dur(20 * time.Second)},
+	}}
+
+	// web1 fires, web2 only becomes pending
+	inputs, err := LoadInputs([]string{writeFile(t, dir, "samples.txt", `
+0s  HeapAlloc:gauge:600e6:{host="web1"}
+0s  HeapAlloc:gauge:100e6:{host="web2"}
+20s HeapAlloc:gauge:600e6:{host="web2"}
+`)}, 10*time.Second)
+	require.NoError(t, err)
+
+	heap := func(host string, state alerting.State) ExpectedAlert {
+		return ExpectedAlert{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc",
+			MetricLabels: metric.Labels{"host": host}, State: state}
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		var out bytes.Buffer
+		expectations := []Expectation{{At: dur(20 * time.Second), Alerts: []ExpectedAlert{
+			heap("web1", alerting.StateFiring), heap("web2", alerting.StatePending),
+		}}}
+		test := &Test{Rules: rules, Inputs: inputs, Expectations: expectations, Interval: 10 * time.Second}
+
+		ok, err := test.Run(context.Background(), &out, zap.NewNop().Sugar())
+		require.NoError(t, err)
+		assert.True(t, ok, out.String())
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		var out bytes.Buffer
+		expectations := []Expectation{{At: dur(20 * time.Second), Alerts: []ExpectedAlert{
+			heap("web1", alerting.StatePending), heap("web2", alerting.StateFiring),
+		}}}
+		test := &Test{Rules: rules, Inputs: inputs, Expectations: expectations, Interval: 10 * time.Second}
+
+		ok, err := test.Run(context.Background(), &out, zap.NewNop().Sugar())
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, "FAIL 20s:\n"+
+			"+ HeapAllocHigh gauge HeapAlloc{host=\"web1\"} firing\n"+
+			"- HeapAllocHigh gauge HeapAlloc{host=\"web1\"} pending\n"+
+			"- HeapAllocHigh gauge HeapAlloc{host=\"web2\"} firing\n"+
+			"+ HeapAllocHigh gauge HeapAlloc{host=\"web2\"} pending\n"+
+			"FAIL: 1 of 1 expectations not met\n", out.String())
+	})
+}
@@ -0,0 +1,41 @@
+// Package alerttest replays metric snapshots through the alert rule evaluator
+// with a simulated clock and checks the alerts against expectations, so that
+// rule changes can be validated offline, e.g. in CI. It is used by the
+// alerttest command.
+//
+// Rules are read from a JSON file holding either an array of rules, as
+// managed at runtime, or the server JSON config, whose "alert_rules" and
+// "inhibit_rules" are used.
+//
+// Inputs are files in one of two formats. A dump file, as written by
+// file.FileSaver, is a complete snapshot of the stored metrics:
+//
+//	HeapAlloc:gauge:612345678
+//	PollCount:counter:42
+//
+// The n-th dump file among the inputs is loaded at the n-th evaluation and
+// replaces all metrics; sections of the dump (alert states etc.) are ignored.
+// A timed file updates single metrics at offsets from the start of the test:
+//
+//	# offset name:type:value
+//	0s  HeapAlloc:gauge:100e6
+//	30s HeapAlloc:gauge:600e6
+//	1m  PollCount:counter:10
+//	1m  HeapAlloc:gauge:700e6:{host="web1"}
+//
+// Counter values are absolute in both formats, as in the dump file. Labeled
+// metrics have their labels in a fourth field, also as in the dump file.
+//
+// Expectations are read from a JSON file and list the pending and firing
+// alerts at offsets from the start; resolved alerts are not compared. Alerts
+// of labeled metrics name the labels in "metric_labels":
+//
+//	[
+//	  {"at": "0s", "alerts": []},
+//	  {"at": "1m", "alerts": [{"rule": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "state": "firing"}]},
+//	  {"at": "2m", "alerts": [{"rule": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "metric_labels": {"host": "web1"}, "state": "firing"}]}
+//	]
+//
+// The rules are evaluated every interval starting at offset 0; the alerts at
+// an offset are those after the last evaluation at or before it.
+package alerttest
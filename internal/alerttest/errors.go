@@ -0,0 +1,9 @@
+package alerttest
+
+import "errors"
+
+var (
+	ErrorInvalidSample      = errors.New("invalid sample")
+	ErrorInvalidExpectation = errors.New("invalid expectation")
+	ErrorInvalidInterval    = errors.New("invalid evaluation interval")
+)
@@ -0,0 +1,200 @@
+package alerttest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// Rules are the rules under test.
+type Rules struct {
+	AlertRules   []alerting.Rule        `json:"alert_rules"`
+	InhibitRules []alerting.InhibitRule `json:"inhibit_rules"`
+}
+
+// LoadRules reads rules from a JSON file holding an array of rules or the
+// server JSON config.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules Rules
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &rules.AlertRules)
+	} else {
+		err = json.Unmarshal(data, &rules)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &rules, nil
+}
+
+// Sample is a metric value set at an offset from the start of the test.
+type Sample struct {
+	At     time.Duration
+	Type   metric.MetricType
+	Name   string
+	Labels metric.Labels
+	Value  string
+}
+
+// Input is a dump file loaded at an offset, or samples from a timed file.
+type Input struct {
+	At      time.Duration // offset of a dump file
+	Dump    string        // path of a dump file, empty for a timed file
+	Samples []Sample
+}
+
+// LoadInputs reads the input files in the given order. Dump files are placed
+// at consecutive evaluations, see the package documentation.
+func LoadInputs(paths []string, interval time.Duration) ([]Input, error) {
+	var result []Input
+	dumps := 0
+
+	for _, path := range paths {
+		samples, timed, err := readTimed(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !timed {
+			result = append(result, Input{At: time.Duration(dumps) * interval, Dump: path})
+			dumps++
+			continue
+		}
+
+		result = append(result, Input{Samples: samples})
+	}
+
+	return result, nil
+}
+
+// readTimed reads a timed file. A file whose first line is not a timed sample
+// is reported as not timed and is expected to be a dump file.
+func readTimed(path string) ([]Sample, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	var result []Sample
+	scanner := bufio.NewScanner(f)
+	n := 0
+
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		s, err := parseSample(line)
+		if err != nil {
+			if len(result) == 0 {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		result = append(result, s)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].At < result[j].At })
+	return result, len(result) > 0, nil
+}
+
+// parseSample parses a line of a timed file: an offset and a metric in the dump format.
+func parseSample(line string) (Sample, error) {
+	at, rest, ok := strings.Cut(line, " ")
+	if !ok {
+		return Sample{}, fmt.Errorf("%w: %s", ErrorInvalidSample, line)
+	}
+
+	d, err := time.ParseDuration(at)
+	if err != nil || d < 0 {
+		return Sample{}, fmt.Errorf("%w: invalid offset %s", ErrorInvalidSample, at)
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(rest), ":", 4)
+	if len(parts) < 3 {
+		return Sample{}, fmt.Errorf("%w: %s", ErrorInvalidSample, line)
+	}
+
+	var labels metric.Labels
+	if len(parts) == 4 {
+		labels, err = metric.ParseLabels(parts[3])
+		if err != nil {
+			return Sample{}, fmt.Errorf("%w: %s", ErrorInvalidSample, line)
+		}
+	}
+
+	return Sample{At: d, Name: parts[0], Type: metric.MetricType(parts[1]), Labels: labels, Value: parts[2]}, nil
+}
+
+// ExpectedAlert is an alert expected at an offset.
+type ExpectedAlert struct {
+	Rule         string            `json:"rule"`
+	MetricType   metric.MetricType `json:"metric_type,omitempty"`
+	MetricName   string            `json:"metric_name,omitempty"`
+	MetricLabels metric.Labels     `json:"metric_labels,omitempty"`
+	State        alerting.State    `json:"state"`
+}
+
+// String returns the alert as a line of the report, e.g. "HeapAllocHigh gauge HeapAlloc firing"
+// or `HeapAllocHigh gauge HeapAlloc{host="web1"} firing` for a labeled metric.
+func (a ExpectedAlert) String() string {
+	if a.MetricName == "" {
+		return fmt.Sprintf("%s %s", a.Rule, a.State)
+	}
+	return fmt.Sprintf("%s %s %s%s %s", a.Rule, a.MetricType, a.MetricName, a.MetricLabels.String(), a.State)
+}
+
+// Expectation lists all pending and firing alerts expected at an offset.
+type Expectation struct {
+	At     common.Duration `json:"at"`
+	Alerts []ExpectedAlert `json:"alerts"`
+}
+
+// LoadExpectations reads expectations from a JSON file, ordered by offset.
+func LoadExpectations(path string) ([]Expectation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Expectation
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, e := range result {
+		if e.At.Duration < 0 {
+			return nil, fmt.Errorf("%s: %w: negative offset %s", path, ErrorInvalidExpectation, e.At)
+		}
+		for _, a := range e.Alerts {
+			if a.State != alerting.StatePending && a.State != alerting.StateFiring {
+				return nil, fmt.Errorf("%s: %w: state of %s must be pending or firing", path, ErrorInvalidExpectation, a.Rule)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].At.Duration < result[j].At.Duration })
+	return result, nil
+}
@@ -0,0 +1,219 @@
+package alerttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/file"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+)
+
+// Start is the simulated time of offset 0.
+var Start = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Test replays inputs through the rules and checks the expectations.
+type Test struct {
+	Rules        *Rules
+	Inputs       []Input
+	Expectations []Expectation
+	Interval     time.Duration // evaluation interval
+}
+
+// replayStorage is the storage the rules are evaluated against.
+// A dump file replaces the embedded storage, samples update it.
+type replayStorage struct {
+	*memory.MemStorage
+}
+
+// load replaces the stored metrics with the ones in the dump file.
+func (s *replayStorage) load(ctx context.Context, path string, clock func() time.Time) error {
+	ms := memory.NewMemStorage()
+	ms.Clock = clock
+	if err := file.NewFileSaver(path, ms).RestoreDump(ctx); err != nil {
+		return err
+	}
+	s.MemStorage = ms
+	return nil
+}
+
+// set sets the metric to the value of the sample. Counters are set to the
+// absolute value, a lower value is stored as a counter reset.
+func (s *replayStorage) set(ctx context.Context, sample Sample) error {
+	m, err := s.Retrieve(ctx, sample.Type, sample.Name, sample.Labels)
+	if errors.Is(err, common.ErrorMetricDoesNotExist) {
+		m, err = metric.NewLabeledMetric(sample.Type, sample.Name, sample.Labels)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrorInvalidSample, sample.Name, err)
+		}
+		err = s.Add(ctx, m)
+	}
+	if err != nil {
+		return err
+	}
+
+	var value any = sample.Value
+	if c, ok := m.(*metric.Counter); ok {
+		v, err := strconv.ParseInt(sample.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrorInvalidSample, sample.Name, sample.Value)
+		}
+		value = v - c.Value
+	}
+
+	if err := s.Update(ctx, m, value); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrorInvalidSample, sample.Name, err)
+	}
+	return nil
+}
+
+// end returns the offset of the last input or expectation.
+func (t *Test) end() time.Duration {
+	var result time.Duration
+	for _, in := range t.Inputs {
+		result = max(result, in.At)
+		for _, s := range in.Samples {
+			result = max(result, s.At)
+		}
+	}
+	for _, e := range t.Expectations {
+		result = max(result, e.At.Duration)
+	}
+	return result
+}
+
+// Run evaluates the rules every interval from offset 0 until the last input
+// or expectation and writes a report of the checked expectations to w.
+// Returns whether all expectations were met, and an error if the rules or
+// inputs are invalid.
+func (t *Test) Run(ctx context.Context, w io.Writer, l logger.Logger) (bool, error) {
+	if t.Interval <= 0 {
+		return false, ErrorInvalidInterval
+	}
+
+	now := Start
+	clock := func() time.Time { return now }
+
+	st := &replayStorage{MemStorage: memory.NewMemStorage()}
+	st.Clock = clock
+
+	e, err := alerting.NewEvaluator(st, t.Rules.AlertRules, t.Interval, l)
+	if err != nil {
+		return false, err
+	}
+	if err := e.SetInhibitRules(t.Rules.InhibitRules); err != nil {
+		return false, err
+	}
+
+	var dumps []Input
+	var samples []Sample
+	for _, in := range t.Inputs {
+		if in.Dump != "" {
+			dumps = append(dumps, in)
+		}
+		samples = append(samples, in.Samples...)
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].At < samples[j].At })
+
+	expectations := t.Expectations
+	failed := 0
+	end := t.end()
+
+	for offset := time.Duration(0); offset <= end; offset += t.Interval {
+		now = Start.Add(offset)
+
+		for len(dumps) > 0 && dumps[0].At <= offset {
+			if err := st.load(ctx, dumps[0].Dump, clock); err != nil {
+				return false, err
+			}
+			dumps = dumps[1:]
+		}
+
+		for len(samples) > 0 && samples[0].At <= offset {
+			if err := st.set(ctx, samples[0]); err != nil {
+				return false, err
+			}
+			samples = samples[1:]
+		}
+
+		if _, err := e.Evaluate(ctx, now); err != nil {
+			return false, fmt.Errorf("evaluation at %s: %w", offset, err)
+		}
+
+		for len(expectations) > 0 && expectations[0].At.Duration < offset+t.Interval {
+			if !report(w, expectations[0], e.Alerts()) {
+				failed++
+			}
+			expectations = expectations[1:]
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(w, "FAIL: %d of %d expectations not met\n", failed, len(t.Expectations))
+		return false, nil
+	}
+
+	fmt.Fprintf(w, "PASS: %d expectations met\n", len(t.Expectations))
+	return true, nil
+}
+
+// report compares the pending and firing alerts with the expected ones and
+// writes the result. Lines of a mismatch are prefixed with "-" if expected
+// only and with "+" if got only.
+func report(w io.Writer, exp Expectation, alerts []alerting.Alert) bool {
+	want := make(map[string]bool, len(exp.Alerts))
+	for _, a := range exp.Alerts {
+		want[a.String()] = true
+	}
+
+	got := make(map[string]bool, len(alerts))
+	for _, a := range alerts {
+		if a.State != alerting.StatePending && a.State != alerting.StateFiring {
+			continue
+		}
+		ea := ExpectedAlert{Rule: a.Rule, MetricType: a.MetricType, MetricName: a.MetricName, MetricLabels: a.MetricLabels, State: a.State}
+		got[ea.String()] = true
+	}
+
+	lines := make([]string, 0, len(want)+len(got))
+	ok := true
+	for line := range want {
+		lines = append(lines, line)
+		if !got[line] {
+			ok = false
+		}
+	}
+	for line := range got {
+		if !want[line] {
+			lines = append(lines, line)
+			ok = false
+		}
+	}
+	sort.Strings(lines)
+
+	if ok {
+		fmt.Fprintf(w, "ok   %s: %d alerts\n", exp.At, len(want))
+		return true
+	}
+
+	fmt.Fprintf(w, "FAIL %s:\n", exp.At)
+	for _, line := range lines {
+		prefix := " "
+		switch {
+		case !got[line]:
+			prefix = "-"
+		case !want[line]:
+			prefix = "+"
+		}
+		fmt.Fprintf(w, "%s %s\n", prefix, line)
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+package assets
+
+import (
+	"embed"
+)
+
+// NotifyTemplates holds the default templates of notification messages.
+//
+//go:embed notify/*.tmpl
+var NotifyTemplates embed.FS
@@ -6,7 +6,7 @@ import (
 	"time"
 )
 
-// Duration wraps time.Duration to provide custom JSON unmarshalling.
+// Duration wraps time.Duration to provide custom JSON marshalling and unmarshalling.
 //
 // It supports both string and numeric values in JSON:
 //
@@ -57,3 +57,16 @@ func (duration *Duration) UnmarshalJSON(b []byte) error {
 
 	return nil
 }
+
+// MarshalJSON implements the json.Marshaler interface for Duration.
+//
+// The duration is encoded as a string accepted by time.ParseDuration,
+// so marshalled values can be unmarshalled back.
+//
+// Example:
+//
+//	b, _ := json.Marshal(Duration{Duration: 90 * time.Second})
+//	fmt.Println(string(b)) // "1m30s"
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(duration.String())
+}
@@ -117,3 +117,25 @@ func TestDuration_RoundTrip_StringNumber(t *testing.T) {
 		t.Fatalf("round-trip mismatch: a=%v b=%v", a.Duration, b.Duration)
 	}
 }
+
+func TestDuration_MarshalJSON(t *testing.T) {
+	type cfg struct {
+		Interval Duration `json:"interval"`
+	}
+
+	b, err := json.Marshal(cfg{Interval: Duration{Duration: 90 * time.Second}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(b) != `{"interval":"1m30s"}` {
+		t.Fatalf("got %s", b)
+	}
+
+	var c cfg
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if c.Interval.Duration != 90*time.Second {
+		t.Fatalf("round-trip mismatch: %v", c.Interval.Duration)
+	}
+}
@@ -11,6 +11,10 @@ var (
 	ErrorMetricDoesNotExist  = errors.New("metric does not exist")
 	ErrorMetricAlreadyExists = errors.New("metric already exists")
 	ErrorTypeNotImplemented  = errors.New("not implemented")
+	ErrorOutOfOrderSample    = errors.New("sample is older than the stored one")
+
+	ErrorMetadataDoesNotExist  = errors.New("metric metadata does not exist")
+	ErrorMetadataAlreadyExists = errors.New("metric metadata already exists")
 )
 
 type WrappedError struct {
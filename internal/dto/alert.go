@@ -0,0 +1,159 @@
+package dto
+
+import "time"
+
+// Alert represents an alert instance in notifications and API responses.
+type Alert struct {
+	// Name is the name of the rule that produced the alert.
+	Name string `json:"name"`
+
+	// MetricName is the name of the offending metric.
+	MetricName string `json:"metric_name"`
+
+	// MetricType is the type of the offending metric: "gauge" or "counter".
+	MetricType string `json:"metric_type"`
+
+	// MetricLabels are the labels of the offending metric.
+	MetricLabels map[string]string `json:"metric_labels,omitempty"`
+
+	// State is the alert state: "pending", "firing" or "resolved".
+	State string `json:"state"`
+
+	// Value is the metric value at the last evaluation.
+	Value float64 `json:"value"`
+
+	// Threshold is the threshold of the rule.
+	Threshold float64 `json:"threshold"`
+
+	// Labels are the labels attached to the alert.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ActiveAt is the time the alert became active. Can be nil.
+	ActiveAt *time.Time `json:"active_at,omitempty"`
+
+	// FiredAt is the time the alert started firing. Can be nil.
+	FiredAt *time.Time `json:"fired_at,omitempty"`
+
+	// ResolvedAt is the time the alert was resolved. Can be nil.
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	// SilencedBy are the IDs of the silences muting the alert.
+	SilencedBy []string `json:"silenced_by,omitempty"`
+
+	// InhibitedBy are the keys ("rule|metric_type|metric_name" followed by the
+	// metric labels, if any) of the firing alerts inhibiting the alert.
+	InhibitedBy []string `json:"inhibited_by,omitempty"`
+
+	// AcknowledgedBy is who acknowledged the firing alert.
+	AcknowledgedBy string `json:"acknowledged_by,omitempty"`
+
+	// AcknowledgedAt is the time the firing alert was acknowledged. Can be nil.
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// AlertAcknowledgement is a request to acknowledge a firing alert.
+type AlertAcknowledgement struct {
+	// Name is the name of the rule that produced the alert.
+	Name string `json:"name"`
+
+	// MetricName is the name of the metric of the alert.
+	MetricName string `json:"metric_name"`
+
+	// MetricType is the type of the metric of the alert.
+	MetricType string `json:"metric_type"`
+
+	// MetricLabels are the labels of the metric of the alert.
+	MetricLabels map[string]string `json:"metric_labels,omitempty"`
+
+	// AcknowledgedBy is who acknowledges the alert.
+	AcknowledgedBy string `json:"acknowledged_by"`
+}
+
+// RouteTarget is a receiver an alert would be routed to.
+type RouteTarget struct {
+	// Route is the ID of the matching route, e.g. "root.1" for the second child of the root route.
+	Route string `json:"route"`
+
+	// Receiver is the name of the receiver.
+	Receiver string `json:"receiver"`
+
+	// GroupKey identifies the group the alert would be notified in.
+	GroupKey string `json:"group_key"`
+
+	// EscalateAfter is how long the alert must be firing without an
+	// acknowledgement before the receiver is notified, e.g. "15m0s".
+	// Empty if the receiver is notified without escalation.
+	EscalateAfter string `json:"escalate_after,omitempty"`
+}
+
+// AlertNotification is the payload sent to notification receivers such as webhooks.
+type AlertNotification struct {
+	// Receiver is the name of the receiver the notification is sent to.
+	Receiver string `json:"receiver"`
+
+	// Status is "firing" if any of the alerts is firing, "resolved" otherwise.
+	Status string `json:"status"`
+
+	// GroupKey identifies the group of alerts the notification is about.
+	GroupKey string `json:"group_key,omitempty"`
+
+	// GroupLabels are the values of the labels the alerts are grouped by.
+	GroupLabels map[string]string `json:"group_labels,omitempty"`
+
+	// Alerts are the alerts included in the notification.
+	Alerts []Alert `json:"alerts"`
+}
+
+// AlertTransition represents a change of an alert state in the alert history.
+type AlertTransition struct {
+	// Alert is a snapshot of the alert after the transition.
+	Alert Alert `json:"alert"`
+
+	// From is the previous state.
+	From string `json:"from"`
+
+	// To is the new state.
+	To string `json:"to"`
+
+	// At is the time of the transition.
+	At time.Time `json:"at"`
+}
+
+// AlertHistory is a page of the alert history, newest transitions first.
+type AlertHistory struct {
+	// Total is the number of transitions matching the filters.
+	Total int `json:"total"`
+
+	// Offset is the number of skipped transitions.
+	Offset int `json:"offset"`
+
+	// Limit is the maximum number of transitions in the page.
+	Limit int `json:"limit"`
+
+	// Transitions are the transitions of the page.
+	Transitions []AlertTransition `json:"transitions"`
+}
+
+// OutboxEntry is a notification queued for delivery or given up on.
+type OutboxEntry struct {
+	// ID identifies the entry.
+	ID string `json:"id"`
+
+	// Notification is the queued notification.
+	Notification AlertNotification `json:"notification"`
+
+	// CreatedAt is the time the notification was queued.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Attempts is the number of failed delivery attempts.
+	Attempts int `json:"attempts"`
+
+	// NextAttemptAt is the earliest time of the next attempt; omitted for dead letters.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+
+	// LastError is the error of the last failed attempt.
+	LastError string `json:"last_error,omitempty"`
+
+	// DeadAt is the time delivery was given up; omitted for pending entries.
+	DeadAt *time.Time `json:"dead_at,omitempty"`
+}
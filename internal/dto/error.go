@@ -0,0 +1,7 @@
+package dto
+
+// Error is the body of an error response of the JSON API.
+type Error struct {
+	// Error is the error message.
+	Error string `json:"error"`
+}
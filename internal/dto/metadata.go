@@ -0,0 +1,16 @@
+package dto
+
+// Metadata describes the metrics with a given name.
+type Metadata struct {
+	// Name is the metric name, set in lists of metadata.
+	Name string `json:"name,omitempty"`
+
+	// Help describes what the metric measures.
+	Help string `json:"help,omitempty"`
+
+	// Unit is the unit of the values, e.g. "bytes", "seconds" or "percent".
+	Unit string `json:"unit,omitempty"`
+
+	// Owner is the team responsible for the metric.
+	Owner string `json:"owner,omitempty"`
+}
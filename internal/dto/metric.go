@@ -1,18 +1,96 @@
 // Package dto defines data transfer objects used for communication between the agent and the server.
-// It includes representations of metrics in JSON format for both gauge and counter types.
+// It includes representations of metrics in JSON format for gauge, counter, histogram and summary types
+// and of their metadata, and of alerts and silences exchanged with notification receivers and API clients.
 package dto
 
+import "time"
+
 // Metrics represents a metric data transfer object.
 type Metrics struct {
 	// ID is the name of the metric.
 	ID string `json:"id"`
 
-	// MType indicates the metric type: "gauge" or "counter".
+	// MType indicates the metric type: "gauge", "counter", "histogram" or "summary".
 	MType string `json:"type"`
 
 	// Delta is the value for a "counter" metric. Can be nil.
 	Delta *int64 `json:"delta,omitempty"`
 
-	// Value is the value for a "gauge" metric. Can be nil.
+	// Value is the value for a "gauge" metric, or a single observed value
+	// for a "histogram" or "summary" metric. Can be nil.
 	Value *float64 `json:"value,omitempty"`
+
+	// Histogram is the value for a "histogram" metric. Can be nil.
+	Histogram *Histogram `json:"histogram,omitempty"`
+
+	// Summary is the value for a "summary" metric. Can be nil.
+	Summary *Summary `json:"summary,omitempty"`
+
+	// Quantiles are the estimated quantiles of a "summary" metric by their
+	// rank, e.g. "0.99", returned by the server and ignored in updates. Can be nil.
+	Quantiles map[string]float64 `json:"quantiles,omitempty"`
+
+	// Labels distinguish metrics of the same type and name. Can be nil.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Timestamp is the client-side time the value was sampled at. It is
+	// optional in updates; a gauge sample older than the stored one is
+	// ignored or rejected, depending on the server configuration. Can be nil.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+
+	// CreatedAt is the time the metric was first stored, returned by the
+	// server and ignored in updates. Can be nil.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// UpdatedAt is the time the metric was last updated, returned by the
+	// server and ignored in updates. Can be nil.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// Metadata describes the metrics with the name of this one. In updates it
+	// is stored unless the name already has metadata, so that agents can send
+	// it along with the first value. Can be nil.
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Histogram is the value of a histogram metric.
+type Histogram struct {
+	// Bounds are the sorted upper bounds of the buckets, without +Inf.
+	Bounds []float64 `json:"bounds"`
+
+	// Counts are the numbers of observed values in each bucket, not cumulative.
+	// The last count is of the values greater than all bounds.
+	Counts []int64 `json:"counts"`
+
+	// Count is the number of all observed values.
+	Count int64 `json:"count"`
+
+	// Sum is the sum of all observed values.
+	Sum float64 `json:"sum"`
+}
+
+// Summary is the value of a summary metric: a DDSketch of the observed values.
+type Summary struct {
+	// RelativeAccuracy is the relative accuracy of the estimated quantiles.
+	RelativeAccuracy float64 `json:"relative_accuracy"`
+
+	// Positive are the counts of positive values by the index of their bucket.
+	Positive map[int32]int64 `json:"positive,omitempty"`
+
+	// Negative are the counts of negative values by the index of the bucket of their absolute value.
+	Negative map[int32]int64 `json:"negative,omitempty"`
+
+	// Zero is the number of observed zeros.
+	Zero int64 `json:"zero,omitempty"`
+
+	// Count is the number of all observed values.
+	Count int64 `json:"count"`
+
+	// Sum is the sum of all observed values.
+	Sum float64 `json:"sum"`
+
+	// Min is the smallest observed value.
+	Min float64 `json:"min"`
+
+	// Max is the largest observed value.
+	Max float64 `json:"max"`
 }
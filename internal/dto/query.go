@@ -0,0 +1,13 @@
+package dto
+
+// QueryResult is the result of evaluating an expression over stored metrics.
+type QueryResult struct {
+	// Expr is the normalized expression.
+	Expr string `json:"expr"`
+
+	// Type is "number" or "bool".
+	Type string `json:"type"`
+
+	// Value is a float64 or a bool depending on Type.
+	Value any `json:"value"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// Matcher matches an alert field or label in a silence.
+type Matcher struct {
+	// Name is "alertname", "metric_name", "metric_type" or a label name.
+	Name string `json:"name"`
+
+	// Value is the value or regular expression to match.
+	Value string `json:"value"`
+
+	// Type is one of "=", "!=", "=~" and "!~". Empty means "=".
+	Type string `json:"type,omitempty"`
+}
+
+// Silence represents a silence in API requests and responses.
+type Silence struct {
+	// ID is assigned by the server.
+	ID string `json:"id,omitempty"`
+
+	// Matchers must all match an alert for it to be silenced.
+	Matchers []Matcher `json:"matchers"`
+
+	// StartsAt is the start of the silence. Zero means now.
+	StartsAt time.Time `json:"starts_at"`
+
+	// EndsAt is the end of the silence.
+	EndsAt time.Time `json:"ends_at"`
+
+	// CreatedBy is the author of the silence.
+	CreatedBy string `json:"created_by"`
+
+	// Comment describes the reason of the silence.
+	Comment string `json:"comment"`
+
+	// Status is "pending", "active" or "expired". Set by the server.
+	Status string `json:"status,omitempty"`
+}
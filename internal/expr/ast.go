@@ -0,0 +1,282 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Type is the type of an expression value.
+type Type int
+
+const (
+	TypeNumber Type = iota
+	TypeBool
+)
+
+// String returns the name of the type.
+func (t Type) String() string {
+	if t == TypeBool {
+		return "bool"
+	}
+	return "number"
+}
+
+// Value is the result of an evaluation.
+type Value struct {
+	Type Type
+	Num  float64 // value of a number
+	Bool bool    // value of a bool
+}
+
+// String formats the value.
+func (v Value) String() string {
+	if v.Type == TypeBool {
+		return strconv.FormatBool(v.Bool)
+	}
+	return strconv.FormatFloat(v.Num, 'g', -1, 64)
+}
+
+func number(v float64) Value { return Value{Type: TypeNumber, Num: v} }
+func boolean(v bool) Value   { return Value{Type: TypeBool, Bool: v} }
+
+// node is a node of the expression tree.
+type node interface {
+	eval(env Env) (Value, error)
+	typ() Type
+	refs(acc []Ref) []Ref
+	format(parentPrec int) string
+}
+
+// precedence returns the binding strength of a binary operator.
+func precedence(op string) int {
+	switch op {
+	case "||":
+		return 1
+	case "&&":
+		return 2
+	case "==", "!=", "<", "<=", ">", ">=":
+		return 3
+	case "+", "-":
+		return 4
+	case "*", "/", "%":
+		return 5
+	default:
+		return 0
+	}
+}
+
+// unaryPrec is the binding strength of unary operators.
+const unaryPrec = 6
+
+// numberNode is a numeric literal.
+type numberNode struct {
+	v float64
+}
+
+func (n *numberNode) eval(Env) (Value, error) { return number(n.v), nil }
+func (n *numberNode) typ() Type               { return TypeNumber }
+func (n *numberNode) refs(acc []Ref) []Ref    { return acc }
+func (n *numberNode) format(int) string       { return strconv.FormatFloat(n.v, 'g', -1, 64) }
+
+// refNode is a reference to a single metric.
+type refNode struct {
+	ref Ref
+}
+
+func (n *refNode) eval(env Env) (Value, error) {
+	values, err := env.Lookup(n.ref)
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch len(values) {
+	case 0:
+		return Value{}, fmt.Errorf("%w: %s", ErrorNoData, n.ref)
+	case 1:
+		return number(values[0]), nil
+	default:
+		return Value{}, fmt.Errorf("%w: %s, qualify it with the metric type", ErrorAmbiguousRef, n.ref)
+	}
+}
+
+func (n *refNode) typ() Type            { return TypeNumber }
+func (n *refNode) refs(acc []Ref) []Ref { return append(acc, n.ref) }
+func (n *refNode) format(int) string    { return n.ref.String() }
+
+// aggregates maps names of aggregate functions to their implementations.
+// An implementation returns false if the aggregate of the values is undefined.
+var aggregates = map[string]func(values []float64) (float64, bool){
+	"sum": func(values []float64) (float64, bool) {
+		var s float64
+		for _, v := range values {
+			s += v
+		}
+		return s, true
+	},
+	"avg": func(values []float64) (float64, bool) {
+		if len(values) == 0 {
+			return 0, false
+		}
+		var s float64
+		for _, v := range values {
+			s += v
+		}
+		return s / float64(len(values)), true
+	},
+	"min": func(values []float64) (float64, bool) {
+		if len(values) == 0 {
+			return 0, false
+		}
+		m := values[0]
+		for _, v := range values[1:] {
+			m = math.Min(m, v)
+		}
+		return m, true
+	},
+	"max": func(values []float64) (float64, bool) {
+		if len(values) == 0 {
+			return 0, false
+		}
+		m := values[0]
+		for _, v := range values[1:] {
+			m = math.Max(m, v)
+		}
+		return m, true
+	},
+	"count": func(values []float64) (float64, bool) {
+		return float64(len(values)), true
+	},
+}
+
+// aggregateNode applies an aggregate function to all metrics matching a reference.
+type aggregateNode struct {
+	fn  string
+	ref Ref
+}
+
+func (n *aggregateNode) eval(env Env) (Value, error) {
+	values, err := env.Lookup(n.ref)
+	if err != nil {
+		return Value{}, err
+	}
+
+	v, ok := aggregates[n.fn](values)
+	if !ok {
+		return Value{}, fmt.Errorf("%w: %s(%s)", ErrorNoData, n.fn, n.ref)
+	}
+	return number(v), nil
+}
+
+func (n *aggregateNode) typ() Type            { return TypeNumber }
+func (n *aggregateNode) refs(acc []Ref) []Ref { return append(acc, n.ref) }
+func (n *aggregateNode) format(int) string    { return n.fn + "(" + n.ref.String() + ")" }
+
+// unaryNode is a negation or a logical not.
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n *unaryNode) eval(env Env) (Value, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return Value{}, err
+	}
+	if n.op == "!" {
+		return boolean(!v.Bool), nil
+	}
+	return number(-v.Num), nil
+}
+
+func (n *unaryNode) typ() Type            { return n.x.typ() }
+func (n *unaryNode) refs(acc []Ref) []Ref { return n.x.refs(acc) }
+func (n *unaryNode) format(int) string    { return n.op + n.x.format(unaryPrec) }
+
+// binaryNode is an arithmetic, comparison or boolean operation.
+type binaryNode struct {
+	op   string
+	l, r node
+}
+
+func (n *binaryNode) typ() Type {
+	if precedence(n.op) <= precedence("==") {
+		return TypeBool
+	}
+	return TypeNumber
+}
+
+func (n *binaryNode) refs(acc []Ref) []Ref { return n.r.refs(n.l.refs(acc)) }
+
+func (n *binaryNode) format(parentPrec int) string {
+	prec := precedence(n.op)
+	leftPrec := prec
+	if prec == precedence("==") {
+		// comparisons cannot be chained
+		leftPrec++
+	}
+	// operators are left-associative, so a right operand of the same precedence needs parentheses
+	s := n.l.format(leftPrec) + " " + n.op + " " + n.r.format(prec+1)
+	if prec < parentPrec {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func (n *binaryNode) eval(env Env) (Value, error) {
+	l, err := n.l.eval(env)
+	if err != nil {
+		return Value{}, err
+	}
+
+	// short-circuit evaluation of boolean operators
+	if (n.op == "&&" && !l.Bool) || (n.op == "||" && l.Bool) {
+		return l, nil
+	}
+
+	r, err := n.r.eval(env)
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return r, nil
+	case "==":
+		if l.Type == TypeBool {
+			return boolean(l.Bool == r.Bool), nil
+		}
+		return boolean(l.Num == r.Num), nil
+	case "!=":
+		if l.Type == TypeBool {
+			return boolean(l.Bool != r.Bool), nil
+		}
+		return boolean(l.Num != r.Num), nil
+	case "<":
+		return boolean(l.Num < r.Num), nil
+	case "<=":
+		return boolean(l.Num <= r.Num), nil
+	case ">":
+		return boolean(l.Num > r.Num), nil
+	case ">=":
+		return boolean(l.Num >= r.Num), nil
+	case "+":
+		return number(l.Num + r.Num), nil
+	case "-":
+		return number(l.Num - r.Num), nil
+	case "*":
+		return number(l.Num * r.Num), nil
+	case "/":
+		if r.Num == 0 {
+			return Value{}, ErrorDivisionByZero
+		}
+		return number(l.Num / r.Num), nil
+	case "%":
+		if r.Num == 0 {
+			return Value{}, ErrorDivisionByZero
+		}
+		return number(math.Mod(l.Num, r.Num)), nil
+	default:
+		return Value{}, fmt.Errorf("unknown operator %q", n.op)
+	}
+}
@@ -0,0 +1,42 @@
+// Package expr implements a small expression language over stored metrics,
+// used by alert rule conditions and ad hoc queries.
+//
+// Expressions combine metric references and numbers with arithmetic,
+// comparison and boolean operators, for example
+//
+//	(TotalMemory - FreeMemory) / TotalMemory > 0.9
+//	max(CPUutilization*) > 95 && gauge HeapAlloc > 5e8
+//	!(counter PollCount >= 10)
+//
+// A metric reference is a metric name, optionally qualified by its type
+// ("gauge" or "counter"). Names follow the rules of metric.IsMetricNameValid.
+// An unqualified name refers to the metric of either type; it is an error if
+// both a gauge and a counter with that name exist. Histograms and summaries
+// have no single value and are never referenced.
+//
+// The aggregate functions sum, avg, min, max and count accept a metric name
+// or a glob pattern (see path.Match), optionally qualified by type, and
+// aggregate all matching metrics. count of no metrics is 0, sum of no
+// metrics is 0, the others have no value.
+//
+// Operators, from the lowest precedence:
+//
+//	||
+//	&&
+//	== != < <= > >=
+//	+ -
+//	* / %
+//	! - (unary)
+//
+// Expressions are type checked when parsed: arithmetic and comparisons
+// apply to numbers, boolean operators to booleans. Syntax errors are
+// reported as *SyntaxError with the 1-based column of the offending token.
+//
+// Typical usage:
+//
+//	e, err := expr.Parse("max(CPUutilization*) > 95")
+//	if err != nil {
+//	    return err
+//	}
+//	v, err := e.Eval(expr.MetricsEnv(metrics))
+package expr
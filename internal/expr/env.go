@@ -0,0 +1,73 @@
+package expr
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// Ref is a reference to metrics in an expression.
+type Ref struct {
+	Type metric.MetricType // metric type, empty for any type
+	Name string            // metric name, or glob pattern in aggregate functions
+}
+
+// IsPattern reports whether the reference name is a glob pattern.
+func (r Ref) IsPattern() bool {
+	return strings.ContainsAny(r.Name, "*?[")
+}
+
+// Matches reports whether the metric is referenced.
+// Histograms and summaries have no single value and are never referenced.
+func (r Ref) Matches(m metric.Metric) bool {
+	if m.GetType() == metric.MetricTypeHistogram || m.GetType() == metric.MetricTypeSummary {
+		return false
+	}
+	if r.Type != "" && m.GetType() != r.Type {
+		return false
+	}
+	if !r.IsPattern() {
+		return m.GetName() == r.Name
+	}
+	ok, err := path.Match(r.Name, m.GetName())
+	return err == nil && ok
+}
+
+// String returns the reference as written in expressions, e.g. "gauge HeapAlloc".
+func (r Ref) String() string {
+	if r.Type == "" {
+		return r.Name
+	}
+	return string(r.Type) + " " + r.Name
+}
+
+// Env resolves metric references during evaluation.
+type Env interface {
+	// Lookup returns the values of all metrics matching the reference.
+	Lookup(ref Ref) ([]float64, error)
+}
+
+// MetricsEnv is an Env over a list of metrics, e.g. the result of storage.Storage.RetrieveAll.
+type MetricsEnv []metric.Metric
+
+// Lookup returns the values of all metrics matching the reference.
+func (env MetricsEnv) Lookup(ref Ref) ([]float64, error) {
+	var result []float64
+	for _, m := range env {
+		if !ref.Matches(m) {
+			continue
+		}
+
+		switch v := m.GetValue().(type) {
+		case float64:
+			result = append(result, v)
+		case int64:
+			result = append(result, float64(v))
+		default:
+			return nil, fmt.Errorf("%s: %w", m.GetName(), metric.ErrorInvalidMetricValue)
+		}
+	}
+	return result, nil
+}
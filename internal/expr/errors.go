@@ -0,0 +1,27 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrorSyntax         = errors.New("syntax error")
+	ErrorNoData         = errors.New("no data")
+	ErrorAmbiguousRef   = errors.New("ambiguous metric reference")
+	ErrorDivisionByZero = errors.New("division by zero")
+)
+
+// SyntaxError describes an invalid expression. It wraps ErrorSyntax.
+type SyntaxError struct {
+	Column int // 1-based column of the offending token
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d: %s", ErrorSyntax, e.Column, e.Msg)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrorSyntax
+}
@@ -0,0 +1,121 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingEnv struct{}
+
+func (failingEnv) Lookup(Ref) ([]float64, error) {
+	return nil, errors.New("forced")
+}
+
+func testEnv() MetricsEnv {
+	return MetricsEnv{
+		metric.MustNewGauge("TotalMemory", 1000),
+		metric.MustNewGauge("FreeMemory", 50),
+		metric.MustNewGauge("CPUutilization1", 99),
+		metric.MustNewGauge("CPUutilization2", 10),
+		metric.MustNewGauge("CPUutilization3", 40),
+		metric.MustNewCounter("PollCount", 7),
+		metric.MustNewGauge("Dup", 1),
+		metric.MustNewCounter("Dup", 2),
+	}
+}
+
+func TestExpr_Eval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want Value
+	}{
+		{src: "(TotalMemory - FreeMemory) / TotalMemory", want: number(0.95)},
+		{src: "(TotalMemory - FreeMemory) / TotalMemory > 0.9", want: boolean(true)},
+		{src: "max(CPUutilization*) > 95", want: boolean(true)},
+		{src: "min(CPUutilization*)", want: number(10)},
+		{src: "avg(gauge CPUutilization*)", want: number(49.666666666666664)},
+		{src: "sum(CPUutilization[12])", want: number(109)},
+		{src: "count(CPUutilization*)", want: number(3)},
+		{src: "count(Missing*)", want: number(0)},
+		{src: "sum(Missing*)", want: number(0)},
+		{src: "count(Dup)", want: number(2)},
+		{src: "gauge Dup + counter Dup", want: number(3)},
+		{src: "PollCount % 4", want: number(3)},
+		{src: "-PollCount * 2", want: number(-14)},
+		{src: "PollCount >= 7 && !(FreeMemory != 50)", want: boolean(true)},
+		{src: "PollCount < 7 || FreeMemory <= 49", want: boolean(false)},
+		{src: "(PollCount == 7) == (FreeMemory == 50)", want: boolean(true)},
+		{src: "(PollCount == 7) != (FreeMemory == 50)", want: boolean(false)},
+		// short-circuit: the missing metric is not evaluated
+		{src: "PollCount > 100 && Missing > 1", want: boolean(false)},
+		{src: "PollCount > 1 || Missing > 1", want: boolean(true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := MustParse(tt.src).Eval(testEnv())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpr_Eval_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		err error
+	}{
+		{src: "Missing > 1", err: ErrorNoData},
+		{src: "max(Missing*)", err: ErrorNoData},
+		{src: "avg(gauge PollCount)", err: ErrorNoData},
+		{src: "Dup", err: ErrorAmbiguousRef},
+		{src: "PollCount / (FreeMemory - 50)", err: ErrorDivisionByZero},
+		{src: "PollCount % 0", err: ErrorDivisionByZero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := MustParse(tt.src).Eval(testEnv())
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err := MustParse("max(CPU*)").Eval(failingEnv{})
+	require.Error(t, err)
+}
+
+func TestMetricsEnv_Lookup(t *testing.T) {
+	env := testEnv()
+
+	values, err := env.Lookup(Ref{Type: metric.MetricTypeCounter, Name: "Dup"})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{2}, values)
+
+	values, err = env.Lookup(Ref{Name: "CPUutilization?"})
+	require.NoError(t, err)
+	assert.Len(t, values, 3)
+
+	values, err = MetricsEnv{metric.MustNewGauge("Dup", 1), metric.NewHistogram("Dup"), metric.NewSummary("Dup")}.Lookup(Ref{Name: "Dup"})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1}, values, "histograms and summaries are not referenced")
+
+	_, err = MetricsEnv{&metric.Gauge{Name: "x"}, badMetric{}}.Lookup(Ref{Name: "bad"})
+	require.ErrorIs(t, err, metric.ErrorInvalidMetricValue)
+}
+
+type badMetric struct{}
+
+func (badMetric) GetType() metric.MetricType { return metric.MetricTypeGauge }
+func (badMetric) GetName() string            { return "bad" }
+func (badMetric) GetLabels() metric.Labels   { return nil }
+func (badMetric) GetTimestamp() time.Time    { return time.Time{} }
+func (badMetric) GetValue() interface{}      { return "text" }
+func (badMetric) Update(interface{}) error   { return nil }
+
+func TestValue_String(t *testing.T) {
+	assert.Equal(t, "0.95", number(0.95).String())
+	assert.Equal(t, "true", boolean(true).String())
+}
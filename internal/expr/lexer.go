@@ -0,0 +1,144 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind is a kind of lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+// token is a lexical token with its 1-based column in the source.
+type token struct {
+	kind tokenKind
+	text string
+	col  int
+}
+
+// operators lists the supported operators, longer ones first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!"}
+
+// lexer splits an expression into tokens.
+type lexer struct {
+	src string
+	pos int
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isPatternChar reports whether c may appear in a glob pattern of metric names.
+func isPatternChar(c byte) bool {
+	return isIdentChar(c) || strings.IndexByte("*?[]^-\\", c) >= 0
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) && isSpace(l.src[l.pos]) {
+		l.pos++
+	}
+}
+
+func (l *lexer) errorf(col int, format string, args ...any) error {
+	return &SyntaxError{Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the next token.
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+
+	start := l.pos
+	col := start + 1
+
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, col: col}, nil
+	}
+
+	c := l.src[l.pos]
+
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokenLParen, text: "(", col: col}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokenRParen, text: ")", col: col}, nil
+	case isDigit(c) || c == '.':
+		return l.number()
+	case isIdentStart(c):
+		for l.pos < len(l.src) && isIdentChar(l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenIdent, text: l.src[start:l.pos], col: col}, nil
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokenOperator, text: op, col: col}, nil
+		}
+	}
+
+	return token{}, l.errorf(col, "unexpected character %q", c)
+}
+
+// number scans a decimal floating-point literal such as 42, 0.9 or 5e8.
+func (l *lexer) number() (token, error) {
+	start := l.pos
+
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+
+	// a number must not run into an identifier, e.g. 5abc
+	if l.pos < len(l.src) && isIdentChar(l.src[l.pos]) {
+		return token{}, l.errorf(start+1, "invalid number %q", l.src[start:l.pos+1])
+	}
+
+	return token{kind: tokenNumber, text: l.src[start:l.pos], col: start + 1}, nil
+}
+
+// pattern scans a metric name or glob pattern, e.g. CPUutilization*.
+// Glob patterns are only allowed as arguments of aggregate functions,
+// where they cannot be confused with multiplication.
+func (l *lexer) pattern() token {
+	l.skipSpace()
+
+	start := l.pos
+	for l.pos < len(l.src) && isPatternChar(l.src[l.pos]) {
+		l.pos++
+	}
+
+	return token{kind: tokenIdent, text: l.src[start:l.pos], col: start + 1}
+}
@@ -0,0 +1,365 @@
+package expr
+
+import (
+	"path"
+	"strconv"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// Expr is a parsed and type-checked expression.
+type Expr struct {
+	root node
+}
+
+// Parse parses an expression. Syntax and type errors are returned as *SyntaxError.
+func Parse(s string) (*Expr, error) {
+	p := &parser{lex: lexer{src: s}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+
+	return &Expr{root: root}, nil
+}
+
+// MustParse is like Parse but panics if the expression is invalid.
+// It is intended for expressions known at compile time.
+func MustParse(s string) *Expr {
+	e, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Type returns the type of the expression value.
+func (e *Expr) Type() Type {
+	return e.root.typ()
+}
+
+// Eval evaluates the expression, resolving metric references through env.
+// A reference to a missing metric results in an error wrapping ErrorNoData.
+func (e *Expr) Eval(env Env) (Value, error) {
+	return e.root.eval(env)
+}
+
+// Refs returns the metric references of the expression in order of appearance.
+func (e *Expr) Refs() []Ref {
+	return e.root.refs(nil)
+}
+
+// Comparison splits an expression whose top-level operator is a comparison
+// into its operands, e.g. "max(CPU*) > 95" into "max(CPU*)", ">" and "95".
+func (e *Expr) Comparison() (left *Expr, op string, right *Expr, ok bool) {
+	b, isBinary := e.root.(*binaryNode)
+	if !isBinary || precedence(b.op) != precedence("==") || b.l.typ() != TypeNumber {
+		return nil, "", nil, false
+	}
+	return &Expr{root: b.l}, b.op, &Expr{root: b.r}, true
+}
+
+// String returns the expression in a normalized form.
+func (e *Expr) String() string {
+	return e.root.format(0)
+}
+
+// parser is a recursive descent parser of expressions.
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// unexpected returns an error about the current token.
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return p.lex.errorf(p.tok.col, "unexpected end of expression")
+	}
+	return p.lex.errorf(p.tok.col, "unexpected %q", p.tok.text)
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	if p.tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if p.tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// binary parses a left-associative chain of binary operators.
+func (p *parser) binary(operand func() (node, error), ops ...string) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator(ops...) {
+		op := p.tok
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		if l, err = p.newBinary(op, l, r); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// newBinary type-checks the operands of a binary operator.
+func (p *parser) newBinary(op token, l, r node) (node, error) {
+	want := TypeNumber
+	if op.text == "&&" || op.text == "||" {
+		want = TypeBool
+	}
+
+	if op.text == "==" || op.text == "!=" {
+		if l.typ() != r.typ() {
+			return nil, p.lex.errorf(op.col, "operator %s expects operands of the same type, got %s and %s", op.text, l.typ(), r.typ())
+		}
+	} else if l.typ() != want || r.typ() != want {
+		return nil, p.lex.errorf(op.col, "operator %s expects %s operands, got %s and %s", op.text, want, l.typ(), r.typ())
+	}
+
+	return &binaryNode{op: op.text, l: l, r: r}, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.binary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.binary(p.parseComparison, "&&")
+}
+
+// parseComparison parses a comparison. Comparisons cannot be chained.
+func (p *parser) parseComparison() (node, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := []string{"==", "!=", "<", "<=", ">", ">="}
+	if !p.isOperator(cmp...) {
+		return l, nil
+	}
+
+	op := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	r, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isOperator(cmp...) {
+		return nil, p.lex.errorf(p.tok.col, "comparisons cannot be chained, use &&")
+	}
+
+	return p.newBinary(op, l, r)
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.binary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.binary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if !p.isOperator("-", "!") {
+		return p.parsePrimary()
+	}
+
+	op := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	want := TypeNumber
+	if op.text == "!" {
+		want = TypeBool
+	}
+	if x.typ() != want {
+		return nil, p.lex.errorf(op.col, "operator %s expects a %s operand, got %s", op.text, want, x.typ())
+	}
+
+	return &unaryNode{op: op.text, x: x}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.lex.errorf(tok.col, "invalid number %q", tok.text)
+		}
+		return &numberNode{v: v}, p.advance()
+
+	case tokenLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenRParen {
+			return nil, p.expected("')'")
+		}
+		return x, p.advance()
+
+	case tokenIdent:
+		// a function call
+		if _, isAggregate := aggregates[tok.text]; isAggregate && p.peekByte() == '(' {
+			return p.parseAggregate()
+		}
+		if p.peekByte() == '(' {
+			return nil, p.lex.errorf(tok.col, "unknown function %q", tok.text)
+		}
+
+		ref, err := p.parseRef()
+		if err != nil {
+			return nil, err
+		}
+		return &refNode{ref: ref}, nil
+
+	default:
+		return nil, p.unexpected()
+	}
+}
+
+// expected returns an error about a missing token.
+func (p *parser) expected(what string) error {
+	if p.tok.kind == tokenEOF {
+		return p.lex.errorf(p.tok.col, "expected %s, got end of expression", what)
+	}
+	return p.lex.errorf(p.tok.col, "expected %s, got %q", what, p.tok.text)
+}
+
+// peekByte returns the next non-space character after the current token, or 0.
+func (p *parser) peekByte() byte {
+	for i := p.lex.pos; i < len(p.lex.src); i++ {
+		if !isSpace(p.lex.src[i]) {
+			return p.lex.src[i]
+		}
+	}
+	return 0
+}
+
+// metricType returns the metric type named by an identifier, or an empty string.
+func metricType(s string) metric.MetricType {
+	switch metric.MetricType(s) {
+	case metric.MetricTypeGauge, metric.MetricTypeCounter:
+		return metric.MetricType(s)
+	default:
+		return ""
+	}
+}
+
+// parseRef parses a metric reference: a name optionally preceded by a metric type.
+// The current token is the first identifier.
+func (p *parser) parseRef() (Ref, error) {
+	tok := p.tok
+	if err := p.advance(); err != nil {
+		return Ref{}, err
+	}
+
+	ref := Ref{Name: tok.text}
+
+	// "gauge HeapAlloc"; a metric can still be named "gauge"
+	if t := metricType(tok.text); t != "" && p.tok.kind == tokenIdent {
+		tok = p.tok
+		ref = Ref{Type: t, Name: tok.text}
+		if err := p.advance(); err != nil {
+			return Ref{}, err
+		}
+	}
+
+	if !metric.IsMetricNameValid(ref.Name) {
+		return Ref{}, p.lex.errorf(tok.col, "invalid metric name %q", ref.Name)
+	}
+
+	return ref, nil
+}
+
+// parseAggregate parses an aggregate function call, e.g. max(gauge CPUutilization*).
+// The current token is the function name.
+func (p *parser) parseAggregate() (node, error) {
+	fn := p.tok.text
+
+	// skip the function name and the opening parenthesis
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	arg := p.lex.pattern()
+	ref := Ref{Name: arg.text}
+
+	if t := metricType(arg.text); t != "" && p.peekByte() != ')' {
+		ref.Type = t
+		arg = p.lex.pattern()
+		ref.Name = arg.text
+	}
+
+	if arg.text == "" {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return nil, p.expected("metric name or pattern")
+	}
+
+	if ref.IsPattern() {
+		if _, err := path.Match(ref.Name, ""); err != nil {
+			return nil, p.lex.errorf(arg.col, "invalid pattern %q", ref.Name)
+		}
+	} else if !metric.IsMetricNameValid(ref.Name) {
+		return nil, p.lex.errorf(arg.col, "invalid metric name %q", ref.Name)
+	}
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenRParen {
+		return nil, p.expected("')'")
+	}
+
+	return &aggregateNode{fn: fn, ref: ref}, p.advance()
+}
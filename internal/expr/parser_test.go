@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+		typ  Type
+	}{
+		{src: "42", want: "42", typ: TypeNumber},
+		{src: "5e8", want: "5e+08", typ: TypeNumber},
+		{src: "HeapAlloc", want: "HeapAlloc", typ: TypeNumber},
+		{src: "gauge  HeapAlloc", want: "gauge HeapAlloc", typ: TypeNumber},
+		{src: "gauge", want: "gauge", typ: TypeNumber},
+		{src: "gauge + 1", want: "gauge + 1", typ: TypeNumber},
+		{src: "node:cpu_total", want: "node:cpu_total", typ: TypeNumber},
+		{src: "(TotalMemory - FreeMemory) / TotalMemory > 0.9", want: "(TotalMemory - FreeMemory) / TotalMemory > 0.9", typ: TypeBool},
+		{src: "1 - (2 - 3)", want: "1 - (2 - 3)", typ: TypeNumber},
+		{src: "(1 - 2) - 3", want: "1 - 2 - 3", typ: TypeNumber},
+		{src: "1+2*3", want: "1 + 2 * 3", typ: TypeNumber},
+		{src: "max(CPUutilization*) > 95", want: "max(CPUutilization*) > 95", typ: TypeBool},
+		{src: "max( gauge CPUutilization[0-9] )", want: "max(gauge CPUutilization[0-9])", typ: TypeNumber},
+		{src: "count(gauge)", want: "count(gauge)", typ: TypeNumber},
+		{src: "max(CPU*)*2", want: "max(CPU*) * 2", typ: TypeNumber},
+		{src: "a > 1 && b < 2 || !(c == 3)", want: "a > 1 && b < 2 || !(c == 3)", typ: TypeBool},
+		{src: "a > 1 && (b < 2 || c == 3)", want: "a > 1 && (b < 2 || c == 3)", typ: TypeBool},
+		{src: "-(a + b) % 2", want: "-(a + b) % 2", typ: TypeNumber},
+		{src: "(a > 1) == (b > 1)", want: "(a > 1) == (b > 1)", typ: TypeBool},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.String())
+			assert.Equal(t, tt.typ, e.Type())
+
+			// the normalized form parses to the same expression
+			again, err := Parse(e.String())
+			require.NoError(t, err)
+			assert.Equal(t, e, again)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src    string
+		column int
+		msg    string
+	}{
+		{src: "", column: 1, msg: "unexpected end of expression"},
+		{src: "1 +", column: 4, msg: "unexpected end of expression"},
+		{src: "HeapAlloc >> 1", column: 12, msg: `unexpected ">"`},
+		{src: "a $ b", column: 3, msg: `unexpected character '$'`},
+		{src: "(a + b", column: 7, msg: "expected ')', got end of expression"},
+		{src: "a + b)", column: 6, msg: `unexpected ")"`},
+		{src: "5abc", column: 1, msg: `invalid number "5a"`},
+		{src: "1..2", column: 1, msg: `invalid number "1..2"`},
+		{src: "CPU* > 1", column: 6, msg: `unexpected ">"`},
+		{src: "median(CPU*)", column: 1, msg: `unknown function "median"`},
+		{src: "max()", column: 5, msg: "expected metric name or pattern"},
+		{src: "max(CPU[)", column: 5, msg: `invalid pattern "CPU["`},
+		{src: "max(CPU x)", column: 9, msg: `expected ')', got "x"`},
+		{src: "a < b < c", column: 7, msg: "comparisons cannot be chained"},
+		{src: "a && b", column: 3, msg: "operator && expects bool operands, got number and number"},
+		{src: "a > 1 + (b > 2)", column: 7, msg: "operator + expects number operands, got number and bool"},
+		{src: "!a", column: 1, msg: "operator ! expects a bool operand, got number"},
+		{src: "-(a > 1)", column: 1, msg: "operator - expects a number operand, got bool"},
+		{src: "(a > 1) == 1", column: 9, msg: "operator == expects operands of the same type, got bool and number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrorSyntax)
+
+			var se *SyntaxError
+			require.True(t, errors.As(err, &se))
+			assert.Equal(t, tt.column, se.Column, se.Error())
+			assert.Contains(t, se.Msg, tt.msg)
+		})
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	assert.NotNil(t, MustParse("1 + 1"))
+	assert.Panics(t, func() { MustParse("1 +") })
+}
+
+func TestExpr_Refs(t *testing.T) {
+	e := MustParse("(gauge TotalMemory - FreeMemory) / max(counter Poll*) > 0")
+	assert.Equal(t, []Ref{
+		{Type: metric.MetricTypeGauge, Name: "TotalMemory"},
+		{Name: "FreeMemory"},
+		{Type: metric.MetricTypeCounter, Name: "Poll*"},
+	}, e.Refs())
+}
+
+func TestExpr_Comparison(t *testing.T) {
+	l, op, r, ok := MustParse("max(CPU*) >= 95 - 1").Comparison()
+	require.True(t, ok)
+	assert.Equal(t, "max(CPU*)", l.String())
+	assert.Equal(t, ">=", op)
+	assert.Equal(t, "95 - 1", r.String())
+
+	_, _, _, ok = MustParse("a > 1 && b > 1").Comparison()
+	assert.False(t, ok)
+
+	_, _, _, ok = MustParse("(a > 1) == (b > 1)").Comparison()
+	assert.False(t, ok)
+
+	_, _, _, ok = MustParse("a + 1").Comparison()
+	assert.False(t, ok)
+}
@@ -2,13 +2,16 @@ package metric
 
 import (
 	"strconv"
+	"time"
 )
 
 // Counter represents a 64-bit integer metric that can only increase.
 // It is commonly used to track things like the number of requests, events, or errors.
 type Counter struct {
-	Name  string // Name is the unique name of the counter metric.
-	Value int64  // Value holds the current counter value.
+	Name      string    // Name is the unique name of the counter metric.
+	Value     int64     // Value holds the current counter value.
+	Labels    Labels    // Labels distinguish counters with the same name, nil if there are none.
+	Timestamp time.Time // Timestamp is the client-side time of the last increment, zero if unknown.
 }
 
 // GetType returns the metric type ("counter").
@@ -21,6 +24,16 @@ func (c *Counter) GetName() string {
 	return c.Name
 }
 
+// GetLabels returns the labels of the counter metric.
+func (c *Counter) GetLabels() Labels {
+	return c.Labels
+}
+
+// GetTimestamp returns the client-side time of the last increment of the counter.
+func (c *Counter) GetTimestamp() time.Time {
+	return c.Timestamp
+}
+
 // GetValue returns the current value of the counter as interface{}.
 func (c *Counter) GetValue() interface{} {
 	return c.Value
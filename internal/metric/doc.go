@@ -1,11 +1,32 @@
 // Package metric defines core types and interfaces used to represent and manage application metrics.
 //
-// It includes a Metric interface that abstracts different types of metrics (e.g., gauge and counter),
+// It includes a Metric interface that abstracts different types of metrics (e.g., gauge, counter, histogram and summary),
 // and a MetricType enumeration to distinguish between metric kinds.
 //
 // The supported metric types are:
 //   - gauge   — a float64 representing a value that can go up or down (e.g., memory usage)
 //   - counter — an int64 representing a monotonically increasing value (e.g., number of requests)
+//   - histogram — a HistogramValue counting observed values in buckets with configurable
+//     bounds, along with their count and sum (e.g., request latencies)
+//   - summary — a SummaryValue holding a DDSketch of observed values, from which
+//     quantiles such as p99 are estimated within a relative accuracy
+//
+// Histograms and summaries are updated by single observations or by whole
+// values of the same kind: HistogramValues with the same bounds are merged by
+// adding their counts and sums, SummaryValues with the same accuracy by adding
+// the counts of their sketch buckets. Merged sketches give the quantiles of
+// all values observed by many agents, which averaging their quantiles would not.
+//
+// Metrics of the same type and name are distinguished by their Labels,
+// e.g. the host reporting them; metrics without labels keep working as before.
+//
+// A metric may carry the client-side Timestamp of its sample, the time the
+// agent collected the value rather than the time the server received it.
+// Storages use it to drop gauge samples that arrive out of order.
+//
+// Metadata, i.e. a help text, a Unit and the owning team, is attached to
+// metric names rather than to single metrics. The unit is used to format
+// values for humans, see Unit.Format.
 //
 // Example usage:
 //
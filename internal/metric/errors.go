@@ -3,7 +3,9 @@ package metric
 import "errors"
 
 var (
-	ErrorInvalidMetricType  = errors.New("invalid metric type")
-	ErrorInvalidMetricName  = errors.New("invalid metric name")
-	ErrorInvalidMetricValue = errors.New("invalid metric value")
+	ErrorInvalidMetricType     = errors.New("invalid metric type")
+	ErrorInvalidMetricName     = errors.New("invalid metric name")
+	ErrorInvalidMetricValue    = errors.New("invalid metric value")
+	ErrorInvalidMetricLabels   = errors.New("invalid metric labels")
+	ErrorInvalidMetricMetadata = errors.New("invalid metric metadata")
 )
@@ -2,12 +2,15 @@ package metric
 
 import (
 	"strconv"
+	"time"
 )
 
 // Gauge represents a floating-point metric that can go up or down.
 type Gauge struct {
-	Name  string  // Name is the unique name of the metric.
-	Value float64 // Value holds the current float64 value of the metric.
+	Name      string    // Name is the unique name of the metric.
+	Value     float64   // Value holds the current float64 value of the metric.
+	Labels    Labels    // Labels distinguish gauges with the same name, nil if there are none.
+	Timestamp time.Time // Timestamp is the client-side time the value was sampled at, zero if unknown.
 }
 
 // GetType returns the type of the metric ("gauge").
@@ -20,6 +23,16 @@ func (c *Gauge) GetName() string {
 	return c.Name
 }
 
+// GetLabels returns the labels of the gauge metric.
+func (c *Gauge) GetLabels() Labels {
+	return c.Labels
+}
+
+// GetTimestamp returns the client-side time the value of the gauge was sampled at.
+func (c *Gauge) GetTimestamp() time.Time {
+	return c.Timestamp
+}
+
 // GetValue returns the current value of the gauge as interface{}.
 func (c *Gauge) GetValue() interface{} {
 	return c.Value
@@ -0,0 +1,303 @@
+package metric
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHistogramBounds are the bucket bounds of a histogram whose first
+// update is a single observation rather than a whole HistogramValue, unless
+// the histogram has other DefaultBounds. They are the default buckets of the
+// Prometheus client libraries.
+var DefaultHistogramBounds = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramValue is the value of a histogram: the number of observed values
+// in each bucket, and the count and sum of all observed values.
+//
+// Bucket i counts the values greater than Bounds[i-1] and less than or equal
+// to Bounds[i]; the last bucket counts the values greater than all bounds,
+// so there is one more count than there are bounds.
+type HistogramValue struct {
+	Bounds []float64 `json:"bounds"` // Sorted upper bounds of the buckets, without +Inf
+	Counts []int64   `json:"counts"` // Number of observed values in each bucket
+	Count  int64     `json:"count"`  // Number of all observed values
+	Sum    float64   `json:"sum"`    // Sum of all observed values
+}
+
+// HistogramBucket is a bucket of a histogram with a cumulative count, as in Prometheus.
+type HistogramBucket struct {
+	UpperBound float64 // Upper bound of the bucket, +Inf for the last bucket
+	Count      int64   // Number of observed values less than or equal to the bound
+}
+
+// NewHistogramValue creates an empty histogram value with the given bucket bounds.
+// Returns an error if the bounds are not finite and strictly increasing.
+func NewHistogramValue(bounds []float64) (HistogramValue, error) {
+	if err := validateBounds(bounds); err != nil {
+		return HistogramValue{}, err
+	}
+	return HistogramValue{Bounds: append([]float64(nil), bounds...), Counts: make([]int64, len(bounds)+1)}, nil
+}
+
+func validateBounds(bounds []float64) error {
+	for i, b := range bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) || (i > 0 && b <= bounds[i-1]) {
+			return fmt.Errorf("%w: histogram bounds must be finite and increasing", ErrorInvalidMetricValue)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the bounds are finite and increasing and that the
+// counts of the buckets are consistent with the total count.
+func (v HistogramValue) Validate() error {
+	if err := validateBounds(v.Bounds); err != nil {
+		return err
+	}
+	if len(v.Counts) != len(v.Bounds)+1 {
+		return fmt.Errorf("%w: histogram must have one more count than bounds", ErrorInvalidMetricValue)
+	}
+
+	var total int64
+	for _, c := range v.Counts {
+		if c < 0 {
+			return fmt.Errorf("%w: negative histogram count", ErrorInvalidMetricValue)
+		}
+		total += c
+	}
+	if total != v.Count {
+		return fmt.Errorf("%w: histogram count differs from the counts of the buckets", ErrorInvalidMetricValue)
+	}
+	if math.IsNaN(v.Sum) || math.IsInf(v.Sum, 0) {
+		return fmt.Errorf("%w: histogram sum must be finite", ErrorInvalidMetricValue)
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the value.
+func (v HistogramValue) Clone() HistogramValue {
+	v.Bounds = append([]float64(nil), v.Bounds...)
+	v.Counts = append([]int64(nil), v.Counts...)
+	return v
+}
+
+// Buckets returns the buckets with cumulative counts, the last one with the bound +Inf.
+func (v HistogramValue) Buckets() []HistogramBucket {
+	result := make([]HistogramBucket, len(v.Counts))
+	var cumulative int64
+	for i, c := range v.Counts {
+		cumulative += c
+		bound := math.Inf(1)
+		if i < len(v.Bounds) {
+			bound = v.Bounds[i]
+		}
+		result[i] = HistogramBucket{UpperBound: bound, Count: cumulative}
+	}
+	return result
+}
+
+// observe adds a single value to the histogram.
+func (v *HistogramValue) observe(x float64) error {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return ErrorInvalidMetricValue
+	}
+	v.Counts[sort.SearchFloat64s(v.Bounds, x)]++
+	v.Count++
+	v.Sum += x
+	return nil
+}
+
+// merge adds the observations of o, which must have the same bounds.
+func (v *HistogramValue) merge(o HistogramValue) error {
+	if len(v.Bounds) != len(o.Bounds) {
+		return fmt.Errorf("%w: histogram bounds differ", ErrorInvalidMetricValue)
+	}
+	for i := range v.Bounds {
+		if v.Bounds[i] != o.Bounds[i] {
+			return fmt.Errorf("%w: histogram bounds differ", ErrorInvalidMetricValue)
+		}
+	}
+
+	for i := range v.Counts {
+		v.Counts[i] += o.Counts[i]
+	}
+	v.Count += o.Count
+	v.Sum += o.Sum
+	return nil
+}
+
+// String returns the value in the text form used in the dump file and by the
+// value endpoints: the count, the sum and the non-cumulative count of each
+// bucket by its upper bound, e.g. "count=3,sum=1.7,0.1=1,1=2,+Inf=0".
+func (v HistogramValue) String() string {
+	var b strings.Builder
+	b.WriteString("count=")
+	b.WriteString(strconv.FormatInt(v.Count, 10))
+	b.WriteString(",sum=")
+	b.WriteString(strconv.FormatFloat(v.Sum, 'g', -1, 64))
+	for i, c := range v.Counts {
+		b.WriteByte(',')
+		if i < len(v.Bounds) {
+			b.WriteString(strconv.FormatFloat(v.Bounds[i], 'g', -1, 64))
+		} else {
+			b.WriteString("+Inf")
+		}
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatInt(c, 10))
+	}
+	return b.String()
+}
+
+// ParseHistogramValue parses a histogram value in the text form returned by
+// HistogramValue.String and validates it.
+func ParseHistogramValue(s string) (HistogramValue, error) {
+	var v HistogramValue
+
+	parts := strings.Split(s, ",")
+	if len(parts) < 3 {
+		return v, ErrorInvalidMetricValue
+	}
+
+	for i, part := range parts {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return v, ErrorInvalidMetricValue
+		}
+
+		var err error
+		switch {
+		case i == 0 && key == "count":
+			v.Count, err = strconv.ParseInt(value, 10, 64)
+		case i == 1 && key == "sum":
+			v.Sum, err = strconv.ParseFloat(value, 64)
+		case i == len(parts)-1 && key == "+Inf":
+			err = v.appendCount(value)
+		case i >= 2 && i < len(parts)-1:
+			var bound float64
+			bound, err = strconv.ParseFloat(key, 64)
+			if err == nil {
+				v.Bounds = append(v.Bounds, bound)
+				err = v.appendCount(value)
+			}
+		default:
+			err = ErrorInvalidMetricValue
+		}
+		if err != nil {
+			return HistogramValue{}, ErrorInvalidMetricValue
+		}
+	}
+
+	if err := v.Validate(); err != nil {
+		return HistogramValue{}, err
+	}
+	return v, nil
+}
+
+// appendCount appends the count of the next bucket, parsed from s.
+func (v *HistogramValue) appendCount(s string) error {
+	c, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	v.Counts = append(v.Counts, c)
+	return nil
+}
+
+// Histogram represents the distribution of observed values, e.g. request
+// latencies, as counts of values in buckets with configurable bounds.
+type Histogram struct {
+	Name      string         // Name is the unique name of the histogram metric.
+	Value     HistogramValue // Value holds the buckets, count and sum of the histogram.
+	Labels    Labels         // Labels distinguish histograms with the same name, nil if there are none.
+	Timestamp time.Time      // Timestamp is the client-side time of the last merged value, zero if unknown.
+
+	// DefaultBounds are the bucket bounds taken by the histogram if its first
+	// update is a single observation, DefaultHistogramBounds if nil.
+	DefaultBounds []float64
+}
+
+// GetType returns the type of the metric ("histogram").
+func (h *Histogram) GetType() MetricType {
+	return MetricTypeHistogram
+}
+
+// GetName returns the name of the histogram metric.
+func (h *Histogram) GetName() string {
+	return h.Name
+}
+
+// GetLabels returns the labels of the histogram metric.
+func (h *Histogram) GetLabels() Labels {
+	return h.Labels
+}
+
+// GetTimestamp returns the client-side time of the last value merged into the histogram.
+func (h *Histogram) GetTimestamp() time.Time {
+	return h.Timestamp
+}
+
+// GetValue returns a copy of the current value of the histogram as interface{}.
+func (h *Histogram) GetValue() interface{} {
+	return h.Value.Clone()
+}
+
+// Update merges the given value into the histogram. It accepts a HistogramValue,
+// whose bounds must be the same as of the histogram, or a single observed value
+// as float64 or a string. A string may also hold a HistogramValue in its text form.
+// A histogram without bounds takes the bounds of the first merged value, or
+// DefaultBounds on the first observation.
+func (h *Histogram) Update(value interface{}) error {
+
+	if s, ok := value.(string); ok {
+		if strings.Contains(s, "=") {
+			v, err := ParseHistogramValue(s)
+			if err != nil {
+				return err
+			}
+			value = v
+		} else {
+			x, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return ErrorInvalidMetricValue
+			}
+			value = x
+		}
+	}
+
+	switch v := value.(type) {
+	case HistogramValue:
+		if err := v.Validate(); err != nil {
+			return err
+		}
+		if h.Value.Counts == nil {
+			h.Value = v.Clone()
+			return nil
+		}
+		return h.Value.merge(v)
+	case float64:
+		if h.Value.Counts == nil {
+			bounds := h.DefaultBounds
+			if bounds == nil {
+				bounds = DefaultHistogramBounds
+			}
+			empty, err := NewHistogramValue(bounds)
+			if err != nil {
+				return err
+			}
+			h.Value = empty
+		}
+		return h.Value.observe(v)
+	default:
+		return ErrorInvalidMetricValue
+	}
+}
+
+// NewHistogram creates a new Histogram with the specified name and no bounds,
+// which are set by its first update.
+func NewHistogram(name string) *Histogram {
+	return &Histogram{Name: name}
+}
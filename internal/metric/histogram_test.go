@@ -0,0 +1,124 @@
+package metric
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistogramValue(t *testing.T) {
+	v, err := NewHistogramValue([]float64{0.1, 1})
+	require.NoError(t, err)
+	assert.Equal(t, HistogramValue{Bounds: []float64{0.1, 1}, Counts: []int64{0, 0, 0}}, v)
+
+	_, err = NewHistogramValue([]float64{1, 0.1})
+	assert.ErrorIs(t, err, ErrorInvalidMetricValue)
+
+	_, err = NewHistogramValue([]float64{1, math.Inf(1)})
+	assert.ErrorIs(t, err, ErrorInvalidMetricValue)
+}
+
+func TestHistogramValue_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       HistogramValue
+		wantErr bool
+	}{
+		{"valid", HistogramValue{Bounds: []float64{1}, Counts: []int64{2, 1}, Count: 3, Sum: 4.5}, false},
+		{"no bounds", HistogramValue{Counts: []int64{1}, Count: 1, Sum: 2}, false},
+		{"missing counts", HistogramValue{Bounds: []float64{1}, Counts: []int64{1}, Count: 1}, true},
+		{"unsorted bounds", HistogramValue{Bounds: []float64{2, 1}, Counts: []int64{0, 0, 0}}, true},
+		{"negative count", HistogramValue{Bounds: []float64{1}, Counts: []int64{-1, 1}}, true},
+		{"wrong total", HistogramValue{Bounds: []float64{1}, Counts: []int64{1, 1}, Count: 3}, true},
+		{"infinite sum", HistogramValue{Counts: []int64{1}, Count: 1, Sum: math.Inf(1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.v.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrorInvalidMetricValue)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHistogram_Update_Observations(t *testing.T) {
+	h := NewHistogram("latency")
+	for _, x := range []float64{0.003, 0.3, 0.5, 20} {
+		require.NoError(t, h.Update(x))
+	}
+	require.NoError(t, h.Update("1"))
+
+	v := h.GetValue().(HistogramValue)
+	assert.Equal(t, DefaultHistogramBounds, v.Bounds)
+	assert.Equal(t, int64(5), v.Count)
+	assert.InDelta(t, 21.803, v.Sum, 1e-9)
+	assert.Equal(t, []int64{1, 0, 0, 0, 0, 0, 2, 1, 0, 0, 0, 1}, v.Counts)
+
+	assert.ErrorIs(t, h.Update(math.NaN()), ErrorInvalidMetricValue)
+	assert.ErrorIs(t, h.Update("abc"), ErrorInvalidMetricValue)
+	assert.ErrorIs(t, h.Update(int64(1)), ErrorInvalidMetricValue)
+
+	h = &Histogram{Name: "latency", DefaultBounds: []float64{0.5, 1}}
+	require.NoError(t, h.Update(0.3))
+	assert.Equal(t, HistogramValue{Bounds: []float64{0.5, 1}, Counts: []int64{1, 0, 0}, Count: 1, Sum: 0.3}, h.GetValue())
+
+	h = &Histogram{Name: "latency", DefaultBounds: []float64{1, 0.5}}
+	assert.ErrorIs(t, h.Update(0.3), ErrorInvalidMetricValue)
+}
+
+func TestHistogram_Update_Merge(t *testing.T) {
+	h := NewHistogram("latency")
+	first := HistogramValue{Bounds: []float64{0.1, 1}, Counts: []int64{1, 2, 0}, Count: 3, Sum: 1.7}
+	require.NoError(t, h.Update(first))
+
+	first.Counts[0] = 100
+	assert.Equal(t, int64(1), h.Value.Counts[0], "the histogram keeps its own copy")
+
+	require.NoError(t, h.Update("count=2,sum=5.05,0.1=1,1=0,+Inf=1"))
+	assert.Equal(t, HistogramValue{Bounds: []float64{0.1, 1}, Counts: []int64{2, 2, 1}, Count: 5, Sum: 6.75}, h.Value)
+
+	err := h.Update(HistogramValue{Bounds: []float64{0.5}, Counts: []int64{1, 0}, Count: 1, Sum: 0.2})
+	assert.ErrorIs(t, err, ErrorInvalidMetricValue)
+	assert.Equal(t, int64(5), h.Value.Count, "a rejected value is not merged")
+
+	assert.ErrorIs(t, h.Update(HistogramValue{Bounds: []float64{0.1, 1}, Counts: []int64{1}}), ErrorInvalidMetricValue)
+}
+
+func TestHistogramValue_StringAndParse(t *testing.T) {
+	v := HistogramValue{Bounds: []float64{0.1, 1}, Counts: []int64{1, 2, 0}, Count: 3, Sum: 1.7}
+	assert.Equal(t, "count=3,sum=1.7,0.1=1,1=2,+Inf=0", v.String())
+
+	got, err := ParseHistogramValue(v.String())
+	require.NoError(t, err)
+	assert.Equal(t, v, got)
+
+	for _, s := range []string{
+		"",
+		"count=1,sum=1",
+		"sum=1,count=1,+Inf=1",
+		"count=1,sum=1,1=1",
+		"count=1,sum=1,x=1,+Inf=0",
+		"count=2,sum=1,1=1,+Inf=0",
+		"count=1,sum=1,+Inf=1,1=0",
+	} {
+		_, err := ParseHistogramValue(s)
+		assert.ErrorIs(t, err, ErrorInvalidMetricValue, s)
+	}
+}
+
+func TestHistogramValue_Buckets(t *testing.T) {
+	v := HistogramValue{Bounds: []float64{0.1, 1}, Counts: []int64{1, 2, 3}, Count: 6}
+	assert.Equal(t, []HistogramBucket{{0.1, 1}, {1, 3}, {math.Inf(1), 6}}, v.Buckets())
+}
+
+func TestNewMetric_Histogram(t *testing.T) {
+	m, err := NewLabeledMetric(MetricTypeHistogram, "latency", Labels{"host": "web1"})
+	require.NoError(t, err)
+	assert.Equal(t, &Histogram{Name: "latency", Labels: Labels{"host": "web1"}}, m)
+	assert.Equal(t, MetricTypeHistogram, m.GetType())
+}
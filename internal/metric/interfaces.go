@@ -1,6 +1,8 @@
 package metric
 
-// MetricType defines the type of a metric, such as "gauge" or "counter".
+import "time"
+
+// MetricType defines the type of a metric, such as "gauge", "counter", "histogram" or "summary".
 type MetricType string
 
 const (
@@ -9,13 +11,23 @@ const (
 
 	// MetricTypeCounter represents an int64 metric that only increases.
 	MetricTypeCounter MetricType = "counter"
+
+	// MetricTypeHistogram represents the distribution of observed values in buckets.
+	MetricTypeHistogram MetricType = "histogram"
+
+	// MetricTypeSummary represents the distribution of observed values as a sketch of quantiles.
+	MetricTypeSummary MetricType = "summary"
 )
 
 // Metric represents a single named metric of a specific type.
-// It supports getting its type, name, current value, and updating the value.
+// It supports getting its type, name, labels, current value, and updating the value.
+// GetTimestamp returns the time the client sampled the current value at,
+// or the zero time if the client did not send one.
 type Metric interface {
 	GetType() MetricType
 	GetName() string
+	GetLabels() Labels
+	GetTimestamp() time.Time
 	GetValue() interface{}
 	Update(interface{}) error
 }
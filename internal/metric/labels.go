@@ -0,0 +1,157 @@
+package metric
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Labels are key/value pairs distinguishing metrics of the same type and name,
+// e.g. the host an agent runs on. A metric is identified by its type, name
+// and label set; nil and empty labels are the same label set.
+type Labels map[string]string
+
+// Label names follow the same rules as in Prometheus: they may contain ASCII
+// letters, digits and underscores and must match the regex [a-zA-Z_][a-zA-Z0-9_]*.
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// IsLabelNameValid reports whether n is a valid label name.
+func IsLabelNameValid(n string) bool {
+	return labelNameRe.MatchString(n)
+}
+
+// Validate checks that all label names are valid and no value is empty.
+func (l Labels) Validate() error {
+	for k, v := range l {
+		if !IsLabelNameValid(k) || v == "" {
+			return ErrorInvalidMetricLabels
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted label names.
+func (l Labels) Names() []string {
+	names := make([]string, 0, len(l))
+	for k := range l {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Equal reports whether both label sets hold the same pairs.
+func (l Labels) Equal(o Labels) bool {
+	if len(l) != len(o) {
+		return false
+	}
+	for k, v := range l {
+		if ov, ok := o[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the canonical form of the labels, e.g. {dc="eu",host="web1"},
+// with names sorted and values quoted, or an empty string if there are no labels.
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range l.Names() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l[k]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// ParseLabels parses labels written as a comma-separated list of name=value
+// pairs, e.g. "host=web1,dc=eu", or in the canonical form returned by
+// Labels.String, e.g. {dc="eu",host="web1"}. Values containing commas must
+// be quoted. An empty string means no labels.
+func ParseLabels(s string) (Labels, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if s == "" {
+		return nil, nil
+	}
+
+	l := make(Labels)
+	for s != "" {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return nil, ErrorInvalidMetricLabels
+		}
+		name = strings.TrimSpace(name)
+		rest = strings.TrimSpace(rest)
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, ErrorInvalidMetricLabels
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = strings.TrimSpace(rest[len(quoted):])
+			if rest != "" && !strings.HasPrefix(rest, ",") {
+				return nil, ErrorInvalidMetricLabels
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+			rest = "," + rest
+		}
+
+		if _, ok := l[name]; ok {
+			return nil, ErrorInvalidMetricLabels
+		}
+		l[name] = value
+		s = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	}
+
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewLabeledMetric creates a metric with the given type, name and labels.
+// Returns an error if the name, the type or any of the labels is invalid.
+func NewLabeledMetric(metricType MetricType, metricName string, labels Labels) (Metric, error) {
+	if err := labels.Validate(); err != nil {
+		return nil, err
+	}
+
+	m, err := NewMetric(metricType, metricName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(labels) == 0 {
+		return m, nil
+	}
+
+	switch v := m.(type) {
+	case *Gauge:
+		v.Labels = labels
+	case *Counter:
+		v.Labels = labels
+	case *Histogram:
+		v.Labels = labels
+	case *Summary:
+		v.Labels = labels
+	}
+	return m, nil
+}
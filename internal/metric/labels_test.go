@@ -0,0 +1,114 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels Labels
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"empty", Labels{}, ""},
+		{"sorted", Labels{"host": "web1", "dc": "eu"}, `{dc="eu",host="web1"}`},
+		{"quoted", Labels{"path": `/a "b"`}, `{path="/a \"b\""}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.labels.String())
+		})
+	}
+}
+
+func TestLabels_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  Labels
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", Labels{"host": "web1", "_dc": "eu"}, false},
+		{"invalid name", Labels{"1host": "web1"}, true},
+		{"dash", Labels{"data-center": "eu"}, true},
+		{"empty value", Labels{"host": ""}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.labels.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrorInvalidMetricLabels)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLabels_Equal(t *testing.T) {
+	assert.True(t, Labels(nil).Equal(Labels{}))
+	assert.True(t, Labels{"a": "1", "b": "2"}.Equal(Labels{"b": "2", "a": "1"}))
+	assert.False(t, Labels{"a": "1"}.Equal(Labels{"a": "2"}))
+	assert.False(t, Labels{"a": "1"}.Equal(Labels{"b": "1"}))
+	assert.False(t, Labels{"a": "1"}.Equal(nil))
+}
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Labels
+		wantErr bool
+	}{
+		{"empty", " ", nil, false},
+		{"pairs", "host=web1, dc=eu", Labels{"host": "web1", "dc": "eu"}, false},
+		{"canonical", `{dc="eu",host="web1"}`, Labels{"host": "web1", "dc": "eu"}, false},
+		{"quoted comma", `path="/a,b", host=web1`, Labels{"path": "/a,b", "host": "web1"}, false},
+		{"escaped quote", `{path="/a \"b\""}`, Labels{"path": `/a "b"`}, false},
+		{"empty braces", "{}", nil, false},
+		{"missing value", "host", nil, true},
+		{"unterminated quote", `host="web1`, nil, true},
+		{"garbage after quote", `host="web1"x`, nil, true},
+		{"duplicate", "host=a,host=b", nil, true},
+		{"empty value", "host=", nil, true},
+		{"invalid name", "data-center=eu", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLabels(tt.s)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrorInvalidMetricLabels)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseLabels_RoundTrip(t *testing.T) {
+	l := Labels{"host": "web1", "path": `/a, "b"`, "dc": "eu=1"}
+	got, err := ParseLabels(l.String())
+	require.NoError(t, err)
+	assert.Equal(t, l, got)
+}
+
+func TestNewLabeledMetric(t *testing.T) {
+	m, err := NewLabeledMetric(MetricTypeGauge, "Alloc", Labels{"host": "web1"})
+	require.NoError(t, err)
+	assert.Equal(t, &Gauge{Name: "Alloc", Labels: Labels{"host": "web1"}}, m)
+
+	m, err = NewLabeledMetric(MetricTypeCounter, "PollCount", Labels{})
+	require.NoError(t, err)
+	assert.Nil(t, m.GetLabels(), "empty labels are normalized to nil")
+
+	_, err = NewLabeledMetric(MetricTypeCounter, "PollCount", Labels{"1": "x"})
+	assert.ErrorIs(t, err, ErrorInvalidMetricLabels)
+
+	_, err = NewLabeledMetric("bogus", "PollCount", nil)
+	assert.ErrorIs(t, err, ErrorInvalidMetricType)
+}
@@ -0,0 +1,82 @@
+package metric
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Unit is the unit of the values of a metric, e.g. "bytes". Following the
+// Prometheus conventions, units are lowercase base units in plural, so that
+// they may be used as a suffix of metric names, e.g. heap_alloc_bytes.
+type Unit string
+
+const (
+	UnitBytes   Unit = "bytes"   // values are numbers of bytes
+	UnitSeconds Unit = "seconds" // values are durations in seconds
+	UnitPercent Unit = "percent" // values are percentages from 0 to 100
+)
+
+// Units may contain lowercase ASCII letters, digits and underscores and must start with a letter.
+var unitRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Validate checks that the unit is empty or a valid unit name.
+func (u Unit) Validate() error {
+	if u != "" && !unitRe.MatchString(string(u)) {
+		return ErrorInvalidMetricMetadata
+	}
+	return nil
+}
+
+// Format formats the value v in the unit for humans:
+//   - bytes with binary prefixes, e.g. 1536 as "1.5KiB"
+//   - seconds as a duration, e.g. 90 as "1m30s", rounded to milliseconds
+//     below a second and to seconds otherwise
+//   - percent rounded to one decimal, e.g. 95.34 as "95.3%"
+//
+// Values in other units are followed by the unit, e.g. "3 requests",
+// and values without a unit are formatted as they are.
+func (u Unit) Format(v float64) string {
+	switch u {
+	case "":
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case UnitBytes:
+		units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+		i := 0
+		for math.Abs(v) >= 1024 && i < len(units)-1 {
+			v /= 1024
+			i++
+		}
+		return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64) + units[i]
+	case UnitSeconds:
+		d := time.Duration(v * float64(time.Second))
+		if d > -time.Second && d < time.Second {
+			return d.Round(time.Millisecond).String()
+		}
+		return d.Round(time.Second).String()
+	case UnitPercent:
+		return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64) + "%"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64) + " " + string(u)
+	}
+}
+
+// Metadata describes the metrics with a given name, whatever their type and labels.
+// It holds what the HELP and UNIT lines of the Prometheus exposition format
+// say about a metric, and the team owning it.
+type Metadata struct {
+	Help  string `json:"help,omitempty"`  // Help describes what the metric measures
+	Unit  Unit   `json:"unit,omitempty"`  // Unit of the values, empty if unknown or dimensionless
+	Owner string `json:"owner,omitempty"` // Owner is the team responsible for the metric
+}
+
+// IsEmpty reports whether no field of the metadata is set.
+func (md Metadata) IsEmpty() bool {
+	return md == Metadata{}
+}
+
+// Validate checks that the unit of the metadata is valid.
+func (md Metadata) Validate() error {
+	return md.Unit.Validate()
+}
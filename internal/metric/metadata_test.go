@@ -0,0 +1,59 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Format(t *testing.T) {
+	tests := []struct {
+		name string
+		unit Unit
+		v    float64
+		want string
+	}{
+		{"no unit", "", 0.42, "0.42"},
+		{"bytes", UnitBytes, 512, "512B"},
+		{"kibibytes", UnitBytes, 1536, "1.5KiB"},
+		{"mebibytes", UnitBytes, 600e6, "572.2MiB"},
+		{"seconds", UnitSeconds, 90, "1m30s"},
+		{"fractional seconds", UnitSeconds, 0.25, "250ms"},
+		{"percent", UnitPercent, 95.34, "95.3%"},
+		{"other unit", "requests", 3, "3 requests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.unit.Format(tt.v))
+		})
+	}
+}
+
+func TestMetadata_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		md      Metadata
+		wantErr bool
+	}{
+		{"empty", Metadata{}, false},
+		{"known unit", Metadata{Help: "Heap bytes in use.", Unit: UnitBytes, Owner: "runtime"}, false},
+		{"custom unit", Metadata{Unit: "requests_2xx"}, false},
+		{"uppercase unit", Metadata{Unit: "Bytes"}, true},
+		{"unit with space", Metadata{Unit: "kilo bytes"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.md.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrorInvalidMetricMetadata)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMetadata_IsEmpty(t *testing.T) {
+	assert.True(t, Metadata{}.IsEmpty())
+	assert.False(t, Metadata{Owner: "runtime"}.IsEmpty())
+}
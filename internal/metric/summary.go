@@ -0,0 +1,449 @@
+package metric
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSummaryAccuracy is the relative accuracy of the quantiles of a
+// summary whose first update is a single observation rather than a whole
+// SummaryValue: a quantile is within 1% of the exact value.
+const DefaultSummaryAccuracy = 0.01
+
+// DefaultSummaryQuantiles are the quantiles returned by the value endpoints
+// and shown in the UI.
+var DefaultSummaryQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// SummaryValue is the value of a summary: a DDSketch of the observed values,
+// along with their count, sum, minimum and maximum.
+//
+// A DDSketch counts the observed values in buckets whose bounds grow
+// exponentially, so that the quantiles estimated from the buckets are within
+// the relative accuracy of the exact ones. Sketches with the same accuracy are
+// merged by adding the counts of their buckets, so quantiles of values
+// observed by many agents can be computed from their sketches.
+type SummaryValue struct {
+	RelativeAccuracy float64         `json:"relative_accuracy"`  // Relative accuracy of quantiles, between 0 and 1
+	Positive         map[int32]int64 `json:"positive,omitempty"` // Counts of positive values by bucket index
+	Negative         map[int32]int64 `json:"negative,omitempty"` // Counts of negative values by bucket index of their absolute value
+	Zero             int64           `json:"zero,omitempty"`     // Count of observed zeros
+	Count            int64           `json:"count"`              // Number of all observed values
+	Sum              float64         `json:"sum"`                // Sum of all observed values
+	Min              float64         `json:"min"`                // Smallest observed value, 0 if there are none
+	Max              float64         `json:"max"`                // Largest observed value, 0 if there are none
+}
+
+// Quantile is an estimated quantile of a summary, e.g. the median for Q 0.5.
+type Quantile struct {
+	Q     float64 // Quantile between 0 and 1
+	Value float64 // Estimated value of the quantile
+}
+
+// NewSummaryValue creates an empty summary value with the given relative accuracy.
+// Returns an error if the accuracy is not between 0 and 1.
+func NewSummaryValue(relativeAccuracy float64) (SummaryValue, error) {
+	if !(relativeAccuracy > 0 && relativeAccuracy < 1) {
+		return SummaryValue{}, fmt.Errorf("%w: summary accuracy must be between 0 and 1", ErrorInvalidMetricValue)
+	}
+	return SummaryValue{RelativeAccuracy: relativeAccuracy}, nil
+}
+
+// gamma returns the ratio of the upper bounds of consecutive buckets.
+func (v SummaryValue) gamma() float64 {
+	return (1 + v.RelativeAccuracy) / (1 - v.RelativeAccuracy)
+}
+
+// index returns the index of the bucket of the positive value x, the bucket
+// holding the values greater than gamma^(i-1) and less than or equal to gamma^i.
+func (v SummaryValue) index(x float64) int32 {
+	return int32(math.Ceil(math.Log(x) / math.Log(v.gamma())))
+}
+
+// bucketValue returns the estimate of the values in the bucket with index i,
+// which is within the relative accuracy of all of them.
+func (v SummaryValue) bucketValue(i int32) float64 {
+	return math.Pow(v.gamma(), float64(i)) * (1 - v.RelativeAccuracy)
+}
+
+// Validate checks that the accuracy is between 0 and 1 and that the counts
+// of the buckets are consistent with the total count.
+func (v SummaryValue) Validate() error {
+	if _, err := NewSummaryValue(v.RelativeAccuracy); err != nil {
+		return err
+	}
+
+	total := v.Zero
+	if v.Zero < 0 {
+		return fmt.Errorf("%w: negative summary count", ErrorInvalidMetricValue)
+	}
+	for _, store := range []map[int32]int64{v.Positive, v.Negative} {
+		for _, c := range store {
+			if c < 0 {
+				return fmt.Errorf("%w: negative summary count", ErrorInvalidMetricValue)
+			}
+			total += c
+		}
+	}
+	if total != v.Count {
+		return fmt.Errorf("%w: summary count differs from the counts of the buckets", ErrorInvalidMetricValue)
+	}
+
+	for _, f := range []float64{v.Sum, v.Min, v.Max} {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("%w: summary sum, min and max must be finite", ErrorInvalidMetricValue)
+		}
+	}
+	if v.Min > v.Max {
+		return fmt.Errorf("%w: summary min is greater than max", ErrorInvalidMetricValue)
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the value.
+func (v SummaryValue) Clone() SummaryValue {
+	v.Positive = cloneStore(v.Positive)
+	v.Negative = cloneStore(v.Negative)
+	return v
+}
+
+func cloneStore(store map[int32]int64) map[int32]int64 {
+	if store == nil {
+		return nil
+	}
+	result := make(map[int32]int64, len(store))
+	for i, c := range store {
+		result[i] = c
+	}
+	return result
+}
+
+// observe adds a single value to the summary.
+func (v *SummaryValue) observe(x float64) error {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return ErrorInvalidMetricValue
+	}
+
+	switch {
+	case x > 0:
+		if v.Positive == nil {
+			v.Positive = make(map[int32]int64)
+		}
+		v.Positive[v.index(x)]++
+	case x < 0:
+		if v.Negative == nil {
+			v.Negative = make(map[int32]int64)
+		}
+		v.Negative[v.index(-x)]++
+	default:
+		v.Zero++
+	}
+
+	if v.Count == 0 || x < v.Min {
+		v.Min = x
+	}
+	if v.Count == 0 || x > v.Max {
+		v.Max = x
+	}
+	v.Count++
+	v.Sum += x
+	return nil
+}
+
+// merge adds the observations of o, which must have the same accuracy.
+func (v *SummaryValue) merge(o SummaryValue) error {
+	if v.RelativeAccuracy != o.RelativeAccuracy {
+		return fmt.Errorf("%w: summary accuracies differ", ErrorInvalidMetricValue)
+	}
+	if o.Count == 0 {
+		return nil
+	}
+
+	if len(o.Positive) > 0 && v.Positive == nil {
+		v.Positive = make(map[int32]int64, len(o.Positive))
+	}
+	for i, c := range o.Positive {
+		v.Positive[i] += c
+	}
+	if len(o.Negative) > 0 && v.Negative == nil {
+		v.Negative = make(map[int32]int64, len(o.Negative))
+	}
+	for i, c := range o.Negative {
+		v.Negative[i] += c
+	}
+	v.Zero += o.Zero
+
+	if v.Count == 0 || o.Min < v.Min {
+		v.Min = o.Min
+	}
+	if v.Count == 0 || o.Max > v.Max {
+		v.Max = o.Max
+	}
+	v.Count += o.Count
+	v.Sum += o.Sum
+	return nil
+}
+
+// Quantile returns the estimated q-quantile of the observed values, e.g. the
+// median for q 0.5, or 0 if there are none.
+func (v SummaryValue) Quantile(q float64) float64 {
+	if v.Count == 0 {
+		return 0
+	}
+
+	rank := q * float64(v.Count-1)
+	var cumulative int64
+
+	// in the order of values: negative ones from the largest absolute value,
+	// zeros and positive ones
+	negative := sortedIndexes(v.Negative)
+	for i := len(negative) - 1; i >= 0; i-- {
+		cumulative += v.Negative[negative[i]]
+		if float64(cumulative) > rank {
+			return v.clamp(-v.bucketValue(negative[i]))
+		}
+	}
+
+	cumulative += v.Zero
+	if float64(cumulative) > rank {
+		return v.clamp(0)
+	}
+
+	for _, i := range sortedIndexes(v.Positive) {
+		cumulative += v.Positive[i]
+		if float64(cumulative) > rank {
+			return v.clamp(v.bucketValue(i))
+		}
+	}
+	return v.Max
+}
+
+// clamp limits an estimated quantile to the range of the observed values.
+func (v SummaryValue) clamp(x float64) float64 {
+	return math.Max(v.Min, math.Min(v.Max, x))
+}
+
+func sortedIndexes(store map[int32]int64) []int32 {
+	result := make([]int32, 0, len(store))
+	for i := range store {
+		result = append(result, i)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// Quantiles returns the estimated DefaultSummaryQuantiles, or nil if there are no observed values.
+func (v SummaryValue) Quantiles() []Quantile {
+	if v.Count == 0 {
+		return nil
+	}
+	result := make([]Quantile, len(DefaultSummaryQuantiles))
+	for i, q := range DefaultSummaryQuantiles {
+		result[i] = Quantile{Q: q, Value: v.Quantile(q)}
+	}
+	return result
+}
+
+// QuantilesString returns the count, the sum and the DefaultSummaryQuantiles
+// in the text form returned by the value endpoints,
+// e.g. "count=3,sum=2.2,0.5=0.99,0.9=0.99,0.95=0.99,0.99=0.99".
+func (v SummaryValue) QuantilesString() string {
+	var b strings.Builder
+	b.WriteString("count=")
+	b.WriteString(strconv.FormatInt(v.Count, 10))
+	b.WriteString(",sum=")
+	b.WriteString(strconv.FormatFloat(v.Sum, 'g', -1, 64))
+	for _, q := range v.Quantiles() {
+		b.WriteByte(',')
+		b.WriteString(strconv.FormatFloat(q.Q, 'g', -1, 64))
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatFloat(q.Value, 'g', -1, 64))
+	}
+	return b.String()
+}
+
+// String returns the whole sketch in the text form used in the dump file:
+// the accuracy, count, sum, minimum and maximum followed by the count of
+// zeros and the counts of the buckets of positive (p) and negative (n) values
+// by their index, e.g. "accuracy=0.01,count=3,sum=2.2,min=0.2,max=1,zero=0,p-80=1,p0=2".
+func (v SummaryValue) String() string {
+	var b strings.Builder
+	b.WriteString("accuracy=")
+	b.WriteString(strconv.FormatFloat(v.RelativeAccuracy, 'g', -1, 64))
+	b.WriteString(",count=")
+	b.WriteString(strconv.FormatInt(v.Count, 10))
+	for _, f := range []struct {
+		key   string
+		value float64
+	}{{"sum", v.Sum}, {"min", v.Min}, {"max", v.Max}} {
+		b.WriteString("," + f.key + "=")
+		b.WriteString(strconv.FormatFloat(f.value, 'g', -1, 64))
+	}
+	b.WriteString(",zero=")
+	b.WriteString(strconv.FormatInt(v.Zero, 10))
+	for _, store := range []struct {
+		prefix string
+		counts map[int32]int64
+	}{{"p", v.Positive}, {"n", v.Negative}} {
+		for _, i := range sortedIndexes(store.counts) {
+			b.WriteString("," + store.prefix)
+			b.WriteString(strconv.FormatInt(int64(i), 10))
+			b.WriteByte('=')
+			b.WriteString(strconv.FormatInt(store.counts[i], 10))
+		}
+	}
+	return b.String()
+}
+
+// ParseSummaryValue parses a summary value in the text form returned by
+// SummaryValue.String and validates it.
+func ParseSummaryValue(s string) (SummaryValue, error) {
+	var v SummaryValue
+
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return SummaryValue{}, ErrorInvalidMetricValue
+		}
+
+		var err error
+		switch key {
+		case "accuracy":
+			v.RelativeAccuracy, err = strconv.ParseFloat(value, 64)
+		case "count":
+			v.Count, err = strconv.ParseInt(value, 10, 64)
+		case "sum":
+			v.Sum, err = strconv.ParseFloat(value, 64)
+		case "min":
+			v.Min, err = strconv.ParseFloat(value, 64)
+		case "max":
+			v.Max, err = strconv.ParseFloat(value, 64)
+		case "zero":
+			v.Zero, err = strconv.ParseInt(value, 10, 64)
+		default:
+			switch {
+			case strings.HasPrefix(key, "p"):
+				v.Positive, err = parseBucket(v.Positive, key[1:], value)
+			case strings.HasPrefix(key, "n"):
+				v.Negative, err = parseBucket(v.Negative, key[1:], value)
+			default:
+				err = ErrorInvalidMetricValue
+			}
+		}
+		if err != nil {
+			return SummaryValue{}, ErrorInvalidMetricValue
+		}
+	}
+
+	if err := v.Validate(); err != nil {
+		return SummaryValue{}, err
+	}
+	return v, nil
+}
+
+// parseBucket adds the bucket with the index and count parsed from the given
+// strings to the store, which is created if nil.
+func parseBucket(store map[int32]int64, index, count string) (map[int32]int64, error) {
+	i, err := strconv.ParseInt(index, 10, 32)
+	if err != nil {
+		return store, err
+	}
+	c, err := strconv.ParseInt(count, 10, 64)
+	if err != nil {
+		return store, err
+	}
+	if store == nil {
+		store = make(map[int32]int64)
+	}
+	if _, ok := store[int32(i)]; ok {
+		return store, ErrorInvalidMetricValue
+	}
+	store[int32(i)] = c
+	return store, nil
+}
+
+// Summary represents the distribution of observed values, e.g. request
+// latencies, as a mergeable sketch from which quantiles are estimated.
+type Summary struct {
+	Name      string       // Name is the unique name of the summary metric.
+	Value     SummaryValue // Value holds the sketch, count and sum of the summary.
+	Labels    Labels       // Labels distinguish summaries with the same name, nil if there are none.
+	Timestamp time.Time    // Timestamp is the client-side time of the last merged value, zero if unknown.
+}
+
+// GetType returns the type of the metric ("summary").
+func (s *Summary) GetType() MetricType {
+	return MetricTypeSummary
+}
+
+// GetName returns the name of the summary metric.
+func (s *Summary) GetName() string {
+	return s.Name
+}
+
+// GetLabels returns the labels of the summary metric.
+func (s *Summary) GetLabels() Labels {
+	return s.Labels
+}
+
+// GetTimestamp returns the client-side time of the last value merged into the summary.
+func (s *Summary) GetTimestamp() time.Time {
+	return s.Timestamp
+}
+
+// GetValue returns a copy of the current value of the summary as interface{}.
+func (s *Summary) GetValue() interface{} {
+	return s.Value.Clone()
+}
+
+// Update merges the given value into the summary. It accepts a SummaryValue,
+// whose accuracy must be the same as of the summary, or a single observed
+// value as float64 or a string. A string may also hold a SummaryValue in its
+// text form. A summary without accuracy takes the accuracy of the first merged
+// value, or DefaultSummaryAccuracy on the first observation.
+func (s *Summary) Update(value interface{}) error {
+
+	if str, ok := value.(string); ok {
+		if strings.Contains(str, "=") {
+			v, err := ParseSummaryValue(str)
+			if err != nil {
+				return err
+			}
+			value = v
+		} else {
+			x, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				return ErrorInvalidMetricValue
+			}
+			value = x
+		}
+	}
+
+	switch v := value.(type) {
+	case SummaryValue:
+		if err := v.Validate(); err != nil {
+			return err
+		}
+		if s.Value.RelativeAccuracy == 0 {
+			s.Value = v.Clone()
+			return nil
+		}
+		return s.Value.merge(v)
+	case float64:
+		if s.Value.RelativeAccuracy == 0 {
+			s.Value.RelativeAccuracy = DefaultSummaryAccuracy
+		}
+		return s.Value.observe(v)
+	default:
+		return ErrorInvalidMetricValue
+	}
+}
+
+// NewSummary creates a new Summary with the specified name and no accuracy,
+// which is set by its first update.
+func NewSummary(name string) *Summary {
+	return &Summary{Name: name}
+}
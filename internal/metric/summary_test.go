@@ -0,0 +1,150 @@
+package metric
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary_Update_Observations(t *testing.T) {
+	s := NewSummary("latency")
+	for i := 1; i <= 1000; i++ {
+		require.NoError(t, s.Update(float64(i)))
+	}
+
+	v := s.GetValue().(SummaryValue)
+	assert.Equal(t, DefaultSummaryAccuracy, v.RelativeAccuracy)
+	assert.Equal(t, int64(1000), v.Count)
+	assert.Equal(t, float64(500500), v.Sum)
+	assert.Equal(t, float64(1), v.Min)
+	assert.Equal(t, float64(1000), v.Max)
+
+	for _, q := range []float64{0, 0.5, 0.9, 0.99, 1} {
+		exact := 1 + q*999
+		assert.InEpsilon(t, exact, v.Quantile(q), DefaultSummaryAccuracy*1.01, "quantile %v", q)
+	}
+
+	assert.ErrorIs(t, s.Update(math.Inf(1)), ErrorInvalidMetricValue)
+	assert.ErrorIs(t, s.Update("abc"), ErrorInvalidMetricValue)
+	assert.ErrorIs(t, s.Update(int64(1)), ErrorInvalidMetricValue)
+}
+
+func TestSummary_Update_NegativeAndZero(t *testing.T) {
+	s := NewSummary("delta")
+	for _, x := range []float64{-10, -1, 0, 0, 1, 10, 100} {
+		require.NoError(t, s.Update(x))
+	}
+
+	assert.Equal(t, float64(-10), s.Value.Quantile(0))
+	assert.InEpsilon(t, -1, s.Value.Quantile(1.0/6), 0.011)
+	assert.Equal(t, float64(0), s.Value.Quantile(0.5))
+	assert.InEpsilon(t, 10, s.Value.Quantile(5.0/6), 0.011)
+	assert.Equal(t, float64(100), s.Value.Quantile(1))
+}
+
+func TestSummary_Update_Merge(t *testing.T) {
+	var a, b SummaryValue
+	all := NewSummary("latency")
+	for i := 1; i <= 200; i++ {
+		x := float64(i)
+		target := &a
+		if i%3 == 0 {
+			target = &b
+		}
+		if target.RelativeAccuracy == 0 {
+			target.RelativeAccuracy = DefaultSummaryAccuracy
+		}
+		require.NoError(t, target.observe(x))
+		require.NoError(t, all.Update(x))
+	}
+
+	s := NewSummary("latency")
+	require.NoError(t, s.Update(a))
+	a.Positive[0] = 1000
+	assert.NotEqual(t, int64(1000), s.Value.Positive[0], "the summary keeps its own copy")
+	a.Positive[0] = 1
+
+	require.NoError(t, s.Update(b.String()))
+	assert.Equal(t, all.Value, s.Value, "merged sketches equal the sketch of all values")
+
+	other, err := NewSummaryValue(0.05)
+	require.NoError(t, err)
+	require.NoError(t, other.observe(1))
+	assert.ErrorIs(t, s.Update(other), ErrorInvalidMetricValue)
+	assert.Equal(t, int64(200), s.Value.Count, "a rejected value is not merged")
+}
+
+func TestSummaryValue_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       SummaryValue
+		wantErr bool
+	}{
+		{"valid", SummaryValue{RelativeAccuracy: 0.01, Positive: map[int32]int64{0: 2}, Zero: 1, Count: 3, Sum: 2, Max: 1}, false},
+		{"empty", SummaryValue{RelativeAccuracy: 0.01}, false},
+		{"no accuracy", SummaryValue{}, true},
+		{"accuracy too large", SummaryValue{RelativeAccuracy: 1}, true},
+		{"negative count", SummaryValue{RelativeAccuracy: 0.01, Negative: map[int32]int64{1: -1}, Count: -1}, true},
+		{"wrong total", SummaryValue{RelativeAccuracy: 0.01, Positive: map[int32]int64{0: 2}, Count: 3}, true},
+		{"min above max", SummaryValue{RelativeAccuracy: 0.01, Zero: 1, Count: 1, Min: 1}, true},
+		{"infinite sum", SummaryValue{RelativeAccuracy: 0.01, Zero: 1, Count: 1, Sum: math.Inf(1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.v.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrorInvalidMetricValue)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSummaryValue_StringAndParse(t *testing.T) {
+	s := NewSummary("latency")
+	for _, x := range []float64{0.2, 1, 1} {
+		require.NoError(t, s.Update(x))
+	}
+	assert.Equal(t, "accuracy=0.01,count=3,sum=2.2,min=0.2,max=1,zero=0,p-80=1,p0=2", s.Value.String())
+	assert.Equal(t, "count=3,sum=2.2,0.5=0.99,0.9=0.99,0.95=0.99,0.99=0.99", s.Value.QuantilesString())
+
+	got, err := ParseSummaryValue("accuracy=0.01,count=4,sum=-2.8,min=-5,max=1,zero=1,p-80=1,p0=1,n81=1")
+	require.NoError(t, err)
+	assert.Equal(t, SummaryValue{RelativeAccuracy: 0.01, Positive: map[int32]int64{-80: 1, 0: 1}, Negative: map[int32]int64{81: 1},
+		Zero: 1, Count: 4, Sum: -2.8, Min: -5, Max: 1}, got)
+
+	roundTrip, err := ParseSummaryValue(got.String())
+	require.NoError(t, err)
+	assert.Equal(t, got, roundTrip)
+
+	for _, str := range []string{
+		"",
+		"accuracy=0.01,count=1,sum=1,min=1,max=1,zero=0",
+		"accuracy=0.01,count=1,sum=1,min=1,max=1,zero=0,p0=1,p0=1",
+		"accuracy=0.01,count=1,sum=1,min=1,max=1,zero=0,x0=1",
+		"accuracy=0.01,count=1,sum=1,min=1,max=1,zero=0,pa=1",
+	} {
+		_, err := ParseSummaryValue(str)
+		assert.ErrorIs(t, err, ErrorInvalidMetricValue, str)
+	}
+}
+
+func TestSummaryValue_Quantiles(t *testing.T) {
+	v, err := NewSummaryValue(DefaultSummaryAccuracy)
+	require.NoError(t, err)
+	assert.Nil(t, v.Quantiles())
+	assert.Equal(t, float64(0), v.Quantile(0.5))
+
+	require.NoError(t, v.observe(3))
+	assert.Equal(t, []Quantile{{0.5, 3}, {0.9, 3}, {0.95, 3}, {0.99, 3}}, v.Quantiles())
+}
+
+func TestNewMetric_Summary(t *testing.T) {
+	m, err := NewLabeledMetric(MetricTypeSummary, "latency", Labels{"host": "web1"})
+	require.NoError(t, err)
+	assert.Equal(t, &Summary{Name: "latency", Labels: Labels{"host": "web1"}}, m)
+	assert.Equal(t, MetricTypeSummary, m.GetType())
+}
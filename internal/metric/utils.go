@@ -2,6 +2,7 @@ package metric
 
 import (
 	"regexp"
+	"time"
 )
 
 // Metric names and labels (from Prometheus docs)
@@ -34,8 +35,48 @@ func NewMetric(metricType MetricType, metricName string) (Metric, error) {
 		return NewGauge(metricName), nil
 	case MetricTypeCounter:
 		return NewCounter(metricName), nil
+	case MetricTypeHistogram:
+		return NewHistogram(metricName), nil
+	case MetricTypeSummary:
+		return NewSummary(metricName), nil
 	default:
 		return nil, ErrorInvalidMetricType
 	}
 
 }
+
+// SetTimestamp sets the client-side time the value of the metric was sampled at.
+func SetTimestamp(m Metric, ts time.Time) {
+	switch v := m.(type) {
+	case *Gauge:
+		v.Timestamp = ts
+	case *Counter:
+		v.Timestamp = ts
+	case *Histogram:
+		v.Timestamp = ts
+	case *Summary:
+		v.Timestamp = ts
+	}
+}
+
+// Clone returns a copy of the metric whose value may be read while the
+// original is updated. Labels are shared, as they never change.
+func Clone(m Metric) Metric {
+	switch v := m.(type) {
+	case *Gauge:
+		c := *v
+		return &c
+	case *Counter:
+		c := *v
+		return &c
+	case *Histogram:
+		c := *v
+		c.Value = v.Value.Clone()
+		return &c
+	case *Summary:
+		c := *v
+		c.Value = v.Value.Clone()
+		return &c
+	}
+	return m
+}
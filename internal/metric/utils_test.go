@@ -1,6 +1,7 @@
 package metric
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -61,3 +62,26 @@ func TestNewMetric(t *testing.T) {
 		})
 	}
 }
+
+func TestClone(t *testing.T) {
+	h := &Histogram{Name: "latency", Labels: Labels{"host": "web1"}}
+	assert.NoError(t, h.Update(0.2))
+
+	c := Clone(h).(*Histogram)
+	assert.Equal(t, h, c)
+	assert.NoError(t, h.Update(0.3))
+	assert.Equal(t, int64(1), c.Value.Count)
+	assert.Equal(t, int64(1), c.Value.Counts[sort.SearchFloat64s(c.Value.Bounds, 0.2)])
+
+	sm := NewSummary("size")
+	assert.NoError(t, sm.Update(2.0))
+	cs := Clone(sm).(*Summary)
+	assert.NoError(t, sm.Update(3.0))
+	assert.Equal(t, int64(1), cs.Value.Count)
+	assert.Len(t, cs.Value.Positive, 1)
+
+	g := MustNewGauge("temperature", 1)
+	cg := Clone(g)
+	assert.NoError(t, g.Update(2.0))
+	assert.Equal(t, 1.0, cg.GetValue())
+}
@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -26,6 +27,11 @@ type UpdateMetricValueRequest struct {
 	MetricType    string                 `protobuf:"bytes,1,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`
 	MetricName    string                 `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
 	MetricValue   string                 `protobuf:"bytes,3,opt,name=metric_value,json=metricValue,proto3" json:"metric_value,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // labels of the metric, see metric.Labels
+	Histogram     *Histogram             `protobuf:"bytes,5,opt,name=histogram,proto3" json:"histogram,omitempty"`                                                                     // value of a histogram metric, merged instead of metric_value if set
+	Summary       *Summary               `protobuf:"bytes,6,opt,name=summary,proto3" json:"summary,omitempty"`                                                                         // value of a summary metric, merged instead of metric_value if set
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                                                     // client-side time the value was sampled at, optional
+	Metadata      *Metadata              `protobuf:"bytes,8,opt,name=metadata,proto3" json:"metadata,omitempty"`                                                                       // metadata of the metric name, stored unless the name has one, optional
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,6 +87,272 @@ func (x *UpdateMetricValueRequest) GetMetricValue() string {
 	return ""
 }
 
+func (x *UpdateMetricValueRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *UpdateMetricValueRequest) GetHistogram() *Histogram {
+	if x != nil {
+		return x.Histogram
+	}
+	return nil
+}
+
+func (x *UpdateMetricValueRequest) GetSummary() *Summary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *UpdateMetricValueRequest) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *UpdateMetricValueRequest) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// Metadata describes the metrics with a given name, see metric.Metadata.
+type Metadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Help          string                 `protobuf:"bytes,1,opt,name=help,proto3" json:"help,omitempty"`
+	Unit          string                 `protobuf:"bytes,2,opt,name=unit,proto3" json:"unit,omitempty"`   // e.g. bytes, seconds or percent
+	Owner         string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"` // team responsible for the metric
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Metadata) GetHelp() string {
+	if x != nil {
+		return x.Help
+	}
+	return ""
+}
+
+func (x *Metadata) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Metadata) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+// Histogram is the value of a histogram metric, see metric.HistogramValue.
+type Histogram struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bounds        []float64              `protobuf:"fixed64,1,rep,packed,name=bounds,proto3" json:"bounds,omitempty"` // sorted upper bounds of the buckets, without +Inf
+	Counts        []int64                `protobuf:"varint,2,rep,packed,name=counts,proto3" json:"counts,omitempty"`  // non-cumulative counts of the buckets, one more than bounds
+	Count         int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Sum           float64                `protobuf:"fixed64,4,opt,name=sum,proto3" json:"sum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Histogram) Reset() {
+	*x = Histogram{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Histogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Histogram) ProtoMessage() {}
+
+func (x *Histogram) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Histogram.ProtoReflect.Descriptor instead.
+func (*Histogram) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Histogram) GetBounds() []float64 {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+func (x *Histogram) GetCounts() []int64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+func (x *Histogram) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Histogram) GetSum() float64 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+// Summary is the value of a summary metric, a DDSketch, see metric.SummaryValue.
+type Summary struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RelativeAccuracy float64                `protobuf:"fixed64,1,opt,name=relative_accuracy,json=relativeAccuracy,proto3" json:"relative_accuracy,omitempty"`
+	Positive         map[int32]int64        `protobuf:"bytes,2,rep,name=positive,proto3" json:"positive,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // counts of positive values by bucket index
+	Negative         map[int32]int64        `protobuf:"bytes,3,rep,name=negative,proto3" json:"negative,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // counts of negative values by bucket index of their absolute value
+	Zero             int64                  `protobuf:"varint,4,opt,name=zero,proto3" json:"zero,omitempty"`
+	Count            int64                  `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	Sum              float64                `protobuf:"fixed64,6,opt,name=sum,proto3" json:"sum,omitempty"`
+	Min              float64                `protobuf:"fixed64,7,opt,name=min,proto3" json:"min,omitempty"`
+	Max              float64                `protobuf:"fixed64,8,opt,name=max,proto3" json:"max,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Summary) Reset() {
+	*x = Summary{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Summary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Summary) ProtoMessage() {}
+
+func (x *Summary) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Summary.ProtoReflect.Descriptor instead.
+func (*Summary) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Summary) GetRelativeAccuracy() float64 {
+	if x != nil {
+		return x.RelativeAccuracy
+	}
+	return 0
+}
+
+func (x *Summary) GetPositive() map[int32]int64 {
+	if x != nil {
+		return x.Positive
+	}
+	return nil
+}
+
+func (x *Summary) GetNegative() map[int32]int64 {
+	if x != nil {
+		return x.Negative
+	}
+	return nil
+}
+
+func (x *Summary) GetZero() int64 {
+	if x != nil {
+		return x.Zero
+	}
+	return 0
+}
+
+func (x *Summary) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Summary) GetSum() float64 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+func (x *Summary) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *Summary) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
 type UpdateMetricValueResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
@@ -90,7 +362,7 @@ type UpdateMetricValueResponse struct {
 
 func (x *UpdateMetricValueResponse) Reset() {
 	*x = UpdateMetricValueResponse{}
-	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	mi := &file_internal_proto_metrics_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -102,7 +374,7 @@ func (x *UpdateMetricValueResponse) String() string {
 func (*UpdateMetricValueResponse) ProtoMessage() {}
 
 func (x *UpdateMetricValueResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	mi := &file_internal_proto_metrics_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -115,7 +387,7 @@ func (x *UpdateMetricValueResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateMetricValueResponse.ProtoReflect.Descriptor instead.
 func (*UpdateMetricValueResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{1}
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *UpdateMetricValueResponse) GetValue() string {
@@ -134,7 +406,7 @@ type EncryptedMessage struct {
 
 func (x *EncryptedMessage) Reset() {
 	*x = EncryptedMessage{}
-	mi := &file_internal_proto_metrics_proto_msgTypes[2]
+	mi := &file_internal_proto_metrics_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -146,7 +418,7 @@ func (x *EncryptedMessage) String() string {
 func (*EncryptedMessage) ProtoMessage() {}
 
 func (x *EncryptedMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_metrics_proto_msgTypes[2]
+	mi := &file_internal_proto_metrics_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -159,7 +431,7 @@ func (x *EncryptedMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EncryptedMessage.ProtoReflect.Descriptor instead.
 func (*EncryptedMessage) Descriptor() ([]byte, []int) {
-	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{2}
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *EncryptedMessage) GetData() []byte {
@@ -169,24 +441,667 @@ func (x *EncryptedMessage) GetData() []byte {
 	return nil
 }
 
-var File_internal_proto_metrics_proto protoreflect.FileDescriptor
+// Alert is an alert instance produced by an alert rule.
+type Alert struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // name of the rule
+	MetricType     string                 `protobuf:"bytes,2,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`
+	MetricName     string                 `protobuf:"bytes,3,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	State          string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"` // "pending", "firing" or "resolved"
+	Value          float64                `protobuf:"fixed64,5,opt,name=value,proto3" json:"value,omitempty"`
+	Threshold      float64                `protobuf:"fixed64,6,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Labels         map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ActiveAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=active_at,json=activeAt,proto3" json:"active_at,omitempty"`
+	FiredAt        *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=fired_at,json=firedAt,proto3" json:"fired_at,omitempty"`
+	ResolvedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=resolved_at,json=resolvedAt,proto3" json:"resolved_at,omitempty"`
+	SilencedBy     []string               `protobuf:"bytes,11,rep,name=silenced_by,json=silencedBy,proto3" json:"silenced_by,omitempty"`    // IDs of silences muting the alert
+	InhibitedBy    []string               `protobuf:"bytes,12,rep,name=inhibited_by,json=inhibitedBy,proto3" json:"inhibited_by,omitempty"` // keys of firing alerts inhibiting the alert
+	AcknowledgedBy string                 `protobuf:"bytes,13,opt,name=acknowledged_by,json=acknowledgedBy,proto3" json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=acknowledged_at,json=acknowledgedAt,proto3" json:"acknowledged_at,omitempty"`
+	MetricLabels   map[string]string      `protobuf:"bytes,15,rep,name=metric_labels,json=metricLabels,proto3" json:"metric_labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // labels of the offending metric
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
 
-const file_internal_proto_metrics_proto_rawDesc = "" +
-	"\n" +
-	"\x1cinternal/proto/metrics.proto\x12\x17metric.alerting.service\"\x7f\n" +
-	"\x18UpdateMetricValueRequest\x12\x1f\n" +
-	"\vmetric_type\x18\x01 \x01(\tR\n" +
-	"metricType\x12\x1f\n" +
-	"\vmetric_name\x18\x02 \x01(\tR\n" +
-	"metricName\x12!\n" +
-	"\fmetric_value\x18\x03 \x01(\tR\vmetricValue\"1\n" +
-	"\x19UpdateMetricValueResponse\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\tR\x05value\"&\n" +
-	"\x10EncryptedMessage\x12\x12\n" +
-	"\x04data\x18\x01 \x01(\fR\x04data2\x88\x02\n" +
-	"\rMetricService\x12z\n" +
-	"\x11UpdateMetricValue\x121.metric.alerting.service.UpdateMetricValueRequest\x1a2.metric.alerting.service.UpdateMetricValueResponse\x12{\n" +
-	"\x1aUpdateMetricValueEncrypted\x12).metric.alerting.service.EncryptedMessage\x1a2.metric.alerting.service.UpdateMetricValueResponseBEZCgithub.com/dmitrijs2005/metric-alerting-service/internal/grpc/protob\x06proto3"
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Alert) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Alert) GetMetricType() string {
+	if x != nil {
+		return x.MetricType
+	}
+	return ""
+}
+
+func (x *Alert) GetMetricName() string {
+	if x != nil {
+		return x.MetricName
+	}
+	return ""
+}
+
+func (x *Alert) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Alert) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Alert) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *Alert) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Alert) GetActiveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ActiveAt
+	}
+	return nil
+}
+
+func (x *Alert) GetFiredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FiredAt
+	}
+	return nil
+}
+
+func (x *Alert) GetResolvedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResolvedAt
+	}
+	return nil
+}
+
+func (x *Alert) GetSilencedBy() []string {
+	if x != nil {
+		return x.SilencedBy
+	}
+	return nil
+}
+
+func (x *Alert) GetInhibitedBy() []string {
+	if x != nil {
+		return x.InhibitedBy
+	}
+	return nil
+}
+
+func (x *Alert) GetAcknowledgedBy() string {
+	if x != nil {
+		return x.AcknowledgedBy
+	}
+	return ""
+}
+
+func (x *Alert) GetAcknowledgedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AcknowledgedAt
+	}
+	return nil
+}
+
+func (x *Alert) GetMetricLabels() map[string]string {
+	if x != nil {
+		return x.MetricLabels
+	}
+	return nil
+}
+
+type ListAlertsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IncludeResolved bool                   `protobuf:"varint,1,opt,name=include_resolved,json=includeResolved,proto3" json:"include_resolved,omitempty"` // also return recently resolved alerts
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListAlertsRequest) Reset() {
+	*x = ListAlertsRequest{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertsRequest) ProtoMessage() {}
+
+func (x *ListAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertsRequest.ProtoReflect.Descriptor instead.
+func (*ListAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListAlertsRequest) GetIncludeResolved() bool {
+	if x != nil {
+		return x.IncludeResolved
+	}
+	return false
+}
+
+type ListAlertsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alerts        []*Alert               `protobuf:"bytes,1,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAlertsResponse) Reset() {
+	*x = ListAlertsResponse{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAlertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertsResponse) ProtoMessage() {}
+
+func (x *ListAlertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertsResponse.ProtoReflect.Descriptor instead.
+func (*ListAlertsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListAlertsResponse) GetAlerts() []*Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+// Rule is an alert rule with the outcome of its last evaluation.
+type Rule struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Kind            string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`           // "threshold" if empty
+	Condition       string                 `protobuf:"bytes,3,opt,name=condition,proto3" json:"condition,omitempty"` // human-readable condition, e.g. "gauge HeapAlloc > 5e+08"
+	MetricType      string                 `protobuf:"bytes,4,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`
+	MetricName      string                 `protobuf:"bytes,5,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	Labels          map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Disabled        bool                   `protobuf:"varint,7,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	Health          string                 `protobuf:"bytes,8,opt,name=health,proto3" json:"health,omitempty"` // "unknown", "ok" or "err"
+	LastError       string                 `protobuf:"bytes,9,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastEvaluatedAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_evaluated_at,json=lastEvaluatedAt,proto3" json:"last_evaluated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Rule) Reset() {
+	*x = Rule{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rule) ProtoMessage() {}
+
+func (x *Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rule.ProtoReflect.Descriptor instead.
+func (*Rule) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Rule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Rule) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Rule) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
+func (x *Rule) GetMetricType() string {
+	if x != nil {
+		return x.MetricType
+	}
+	return ""
+}
+
+func (x *Rule) GetMetricName() string {
+	if x != nil {
+		return x.MetricName
+	}
+	return ""
+}
+
+func (x *Rule) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Rule) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *Rule) GetHealth() string {
+	if x != nil {
+		return x.Health
+	}
+	return ""
+}
+
+func (x *Rule) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Rule) GetLastEvaluatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastEvaluatedAt
+	}
+	return nil
+}
+
+type ListRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRulesRequest) Reset() {
+	*x = ListRulesRequest{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesRequest) ProtoMessage() {}
+
+func (x *ListRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListRulesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{10}
+}
+
+type ListRulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*Rule                `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRulesResponse) Reset() {
+	*x = ListRulesResponse{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesResponse) ProtoMessage() {}
+
+func (x *ListRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListRulesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListRulesResponse) GetRules() []*Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type WatchAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAlertsRequest) Reset() {
+	*x = WatchAlertsRequest{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAlertsRequest) ProtoMessage() {}
+
+func (x *WatchAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAlertsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{12}
+}
+
+// AlertEvent is a change of an alert state.
+type AlertEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alert         *Alert                 `protobuf:"bytes,1,opt,name=alert,proto3" json:"alert,omitempty"` // the alert after the change
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertEvent) Reset() {
+	*x = AlertEvent{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertEvent) ProtoMessage() {}
+
+func (x *AlertEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertEvent.ProtoReflect.Descriptor instead.
+func (*AlertEvent) Descriptor() ([]byte, []int) {
+	return file_internal_proto_metrics_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AlertEvent) GetAlert() *Alert {
+	if x != nil {
+		return x.Alert
+	}
+	return nil
+}
+
+func (x *AlertEvent) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *AlertEvent) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+var File_internal_proto_metrics_proto protoreflect.FileDescriptor
+
+const file_internal_proto_metrics_proto_rawDesc = "" +
+	"\n" +
+	"\x1cinternal/proto/metrics.proto\x12\x17metric.alerting.service\x1a\x1fgoogle/protobuf/timestamp.proto\"\x88\x04\n" +
+	"\x18UpdateMetricValueRequest\x12\x1f\n" +
+	"\vmetric_type\x18\x01 \x01(\tR\n" +
+	"metricType\x12\x1f\n" +
+	"\vmetric_name\x18\x02 \x01(\tR\n" +
+	"metricName\x12!\n" +
+	"\fmetric_value\x18\x03 \x01(\tR\vmetricValue\x12U\n" +
+	"\x06labels\x18\x04 \x03(\v2=.metric.alerting.service.UpdateMetricValueRequest.LabelsEntryR\x06labels\x12@\n" +
+	"\thistogram\x18\x05 \x01(\v2\".metric.alerting.service.HistogramR\thistogram\x12:\n" +
+	"\asummary\x18\x06 \x01(\v2 .metric.alerting.service.SummaryR\asummary\x128\n" +
+	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12=\n" +
+	"\bmetadata\x18\b \x01(\v2!.metric.alerting.service.MetadataR\bmetadata\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"H\n" +
+	"\bMetadata\x12\x12\n" +
+	"\x04help\x18\x01 \x01(\tR\x04help\x12\x12\n" +
+	"\x04unit\x18\x02 \x01(\tR\x04unit\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\"c\n" +
+	"\tHistogram\x12\x16\n" +
+	"\x06bounds\x18\x01 \x03(\x01R\x06bounds\x12\x16\n" +
+	"\x06counts\x18\x02 \x03(\x03R\x06counts\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12\x10\n" +
+	"\x03sum\x18\x04 \x01(\x01R\x03sum\"\xa8\x03\n" +
+	"\aSummary\x12+\n" +
+	"\x11relative_accuracy\x18\x01 \x01(\x01R\x10relativeAccuracy\x12J\n" +
+	"\bpositive\x18\x02 \x03(\v2..metric.alerting.service.Summary.PositiveEntryR\bpositive\x12J\n" +
+	"\bnegative\x18\x03 \x03(\v2..metric.alerting.service.Summary.NegativeEntryR\bnegative\x12\x12\n" +
+	"\x04zero\x18\x04 \x01(\x03R\x04zero\x12\x14\n" +
+	"\x05count\x18\x05 \x01(\x03R\x05count\x12\x10\n" +
+	"\x03sum\x18\x06 \x01(\x01R\x03sum\x12\x10\n" +
+	"\x03min\x18\a \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\b \x01(\x01R\x03max\x1a;\n" +
+	"\rPositiveEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a;\n" +
+	"\rNegativeEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"1\n" +
+	"\x19UpdateMetricValueResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\"&\n" +
+	"\x10EncryptedMessage\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\x9d\x06\n" +
+	"\x05Alert\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
+	"\vmetric_type\x18\x02 \x01(\tR\n" +
+	"metricType\x12\x1f\n" +
+	"\vmetric_name\x18\x03 \x01(\tR\n" +
+	"metricName\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\x01R\x05value\x12\x1c\n" +
+	"\tthreshold\x18\x06 \x01(\x01R\tthreshold\x12B\n" +
+	"\x06labels\x18\a \x03(\v2*.metric.alerting.service.Alert.LabelsEntryR\x06labels\x127\n" +
+	"\tactive_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bactiveAt\x125\n" +
+	"\bfired_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\afiredAt\x12;\n" +
+	"\vresolved_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"resolvedAt\x12\x1f\n" +
+	"\vsilenced_by\x18\v \x03(\tR\n" +
+	"silencedBy\x12!\n" +
+	"\finhibited_by\x18\f \x03(\tR\vinhibitedBy\x12'\n" +
+	"\x0facknowledged_by\x18\r \x01(\tR\x0eacknowledgedBy\x12C\n" +
+	"\x0facknowledged_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\x0eacknowledgedAt\x12U\n" +
+	"\rmetric_labels\x18\x0f \x03(\v20.metric.alerting.service.Alert.MetricLabelsEntryR\fmetricLabels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11MetricLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\">\n" +
+	"\x11ListAlertsRequest\x12)\n" +
+	"\x10include_resolved\x18\x01 \x01(\bR\x0fincludeResolved\"L\n" +
+	"\x12ListAlertsResponse\x126\n" +
+	"\x06alerts\x18\x01 \x03(\v2\x1e.metric.alerting.service.AlertR\x06alerts\"\xa7\x03\n" +
+	"\x04Rule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x1c\n" +
+	"\tcondition\x18\x03 \x01(\tR\tcondition\x12\x1f\n" +
+	"\vmetric_type\x18\x04 \x01(\tR\n" +
+	"metricType\x12\x1f\n" +
+	"\vmetric_name\x18\x05 \x01(\tR\n" +
+	"metricName\x12A\n" +
+	"\x06labels\x18\x06 \x03(\v2).metric.alerting.service.Rule.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bdisabled\x18\a \x01(\bR\bdisabled\x12\x16\n" +
+	"\x06health\x18\b \x01(\tR\x06health\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\t \x01(\tR\tlastError\x12F\n" +
+	"\x11last_evaluated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0flastEvaluatedAt\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x12\n" +
+	"\x10ListRulesRequest\"H\n" +
+	"\x11ListRulesResponse\x123\n" +
+	"\x05rules\x18\x01 \x03(\v2\x1d.metric.alerting.service.RuleR\x05rules\"\x14\n" +
+	"\x12WatchAlertsRequest\"\x92\x01\n" +
+	"\n" +
+	"AlertEvent\x124\n" +
+	"\x05alert\x18\x01 \x01(\v2\x1e.metric.alerting.service.AlertR\x05alert\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12*\n" +
+	"\x02at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02at2\x88\x02\n" +
+	"\rMetricService\x12z\n" +
+	"\x11UpdateMetricValue\x121.metric.alerting.service.UpdateMetricValueRequest\x1a2.metric.alerting.service.UpdateMetricValueResponse\x12{\n" +
+	"\x1aUpdateMetricValueEncrypted\x12).metric.alerting.service.EncryptedMessage\x1a2.metric.alerting.service.UpdateMetricValueResponse2\xbc\x02\n" +
+	"\fAlertService\x12e\n" +
+	"\n" +
+	"ListAlerts\x12*.metric.alerting.service.ListAlertsRequest\x1a+.metric.alerting.service.ListAlertsResponse\x12b\n" +
+	"\tListRules\x12).metric.alerting.service.ListRulesRequest\x1a*.metric.alerting.service.ListRulesResponse\x12a\n" +
+	"\vWatchAlerts\x12+.metric.alerting.service.WatchAlertsRequest\x1a#.metric.alerting.service.AlertEvent0\x01BEZCgithub.com/dmitrijs2005/metric-alerting-service/internal/grpc/protob\x06proto3"
 
 var (
 	file_internal_proto_metrics_proto_rawDescOnce sync.Once
@@ -200,22 +1115,65 @@ func file_internal_proto_metrics_proto_rawDescGZIP() []byte {
 	return file_internal_proto_metrics_proto_rawDescData
 }
 
-var file_internal_proto_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_internal_proto_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_internal_proto_metrics_proto_goTypes = []any{
 	(*UpdateMetricValueRequest)(nil),  // 0: metric.alerting.service.UpdateMetricValueRequest
-	(*UpdateMetricValueResponse)(nil), // 1: metric.alerting.service.UpdateMetricValueResponse
-	(*EncryptedMessage)(nil),          // 2: metric.alerting.service.EncryptedMessage
+	(*Metadata)(nil),                  // 1: metric.alerting.service.Metadata
+	(*Histogram)(nil),                 // 2: metric.alerting.service.Histogram
+	(*Summary)(nil),                   // 3: metric.alerting.service.Summary
+	(*UpdateMetricValueResponse)(nil), // 4: metric.alerting.service.UpdateMetricValueResponse
+	(*EncryptedMessage)(nil),          // 5: metric.alerting.service.EncryptedMessage
+	(*Alert)(nil),                     // 6: metric.alerting.service.Alert
+	(*ListAlertsRequest)(nil),         // 7: metric.alerting.service.ListAlertsRequest
+	(*ListAlertsResponse)(nil),        // 8: metric.alerting.service.ListAlertsResponse
+	(*Rule)(nil),                      // 9: metric.alerting.service.Rule
+	(*ListRulesRequest)(nil),          // 10: metric.alerting.service.ListRulesRequest
+	(*ListRulesResponse)(nil),         // 11: metric.alerting.service.ListRulesResponse
+	(*WatchAlertsRequest)(nil),        // 12: metric.alerting.service.WatchAlertsRequest
+	(*AlertEvent)(nil),                // 13: metric.alerting.service.AlertEvent
+	nil,                               // 14: metric.alerting.service.UpdateMetricValueRequest.LabelsEntry
+	nil,                               // 15: metric.alerting.service.Summary.PositiveEntry
+	nil,                               // 16: metric.alerting.service.Summary.NegativeEntry
+	nil,                               // 17: metric.alerting.service.Alert.LabelsEntry
+	nil,                               // 18: metric.alerting.service.Alert.MetricLabelsEntry
+	nil,                               // 19: metric.alerting.service.Rule.LabelsEntry
+	(*timestamppb.Timestamp)(nil),     // 20: google.protobuf.Timestamp
 }
 var file_internal_proto_metrics_proto_depIdxs = []int32{
-	0, // 0: metric.alerting.service.MetricService.UpdateMetricValue:input_type -> metric.alerting.service.UpdateMetricValueRequest
-	2, // 1: metric.alerting.service.MetricService.UpdateMetricValueEncrypted:input_type -> metric.alerting.service.EncryptedMessage
-	1, // 2: metric.alerting.service.MetricService.UpdateMetricValue:output_type -> metric.alerting.service.UpdateMetricValueResponse
-	1, // 3: metric.alerting.service.MetricService.UpdateMetricValueEncrypted:output_type -> metric.alerting.service.UpdateMetricValueResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	14, // 0: metric.alerting.service.UpdateMetricValueRequest.labels:type_name -> metric.alerting.service.UpdateMetricValueRequest.LabelsEntry
+	2,  // 1: metric.alerting.service.UpdateMetricValueRequest.histogram:type_name -> metric.alerting.service.Histogram
+	3,  // 2: metric.alerting.service.UpdateMetricValueRequest.summary:type_name -> metric.alerting.service.Summary
+	20, // 3: metric.alerting.service.UpdateMetricValueRequest.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 4: metric.alerting.service.UpdateMetricValueRequest.metadata:type_name -> metric.alerting.service.Metadata
+	15, // 5: metric.alerting.service.Summary.positive:type_name -> metric.alerting.service.Summary.PositiveEntry
+	16, // 6: metric.alerting.service.Summary.negative:type_name -> metric.alerting.service.Summary.NegativeEntry
+	17, // 7: metric.alerting.service.Alert.labels:type_name -> metric.alerting.service.Alert.LabelsEntry
+	20, // 8: metric.alerting.service.Alert.active_at:type_name -> google.protobuf.Timestamp
+	20, // 9: metric.alerting.service.Alert.fired_at:type_name -> google.protobuf.Timestamp
+	20, // 10: metric.alerting.service.Alert.resolved_at:type_name -> google.protobuf.Timestamp
+	20, // 11: metric.alerting.service.Alert.acknowledged_at:type_name -> google.protobuf.Timestamp
+	18, // 12: metric.alerting.service.Alert.metric_labels:type_name -> metric.alerting.service.Alert.MetricLabelsEntry
+	6,  // 13: metric.alerting.service.ListAlertsResponse.alerts:type_name -> metric.alerting.service.Alert
+	19, // 14: metric.alerting.service.Rule.labels:type_name -> metric.alerting.service.Rule.LabelsEntry
+	20, // 15: metric.alerting.service.Rule.last_evaluated_at:type_name -> google.protobuf.Timestamp
+	9,  // 16: metric.alerting.service.ListRulesResponse.rules:type_name -> metric.alerting.service.Rule
+	6,  // 17: metric.alerting.service.AlertEvent.alert:type_name -> metric.alerting.service.Alert
+	20, // 18: metric.alerting.service.AlertEvent.at:type_name -> google.protobuf.Timestamp
+	0,  // 19: metric.alerting.service.MetricService.UpdateMetricValue:input_type -> metric.alerting.service.UpdateMetricValueRequest
+	5,  // 20: metric.alerting.service.MetricService.UpdateMetricValueEncrypted:input_type -> metric.alerting.service.EncryptedMessage
+	7,  // 21: metric.alerting.service.AlertService.ListAlerts:input_type -> metric.alerting.service.ListAlertsRequest
+	10, // 22: metric.alerting.service.AlertService.ListRules:input_type -> metric.alerting.service.ListRulesRequest
+	12, // 23: metric.alerting.service.AlertService.WatchAlerts:input_type -> metric.alerting.service.WatchAlertsRequest
+	4,  // 24: metric.alerting.service.MetricService.UpdateMetricValue:output_type -> metric.alerting.service.UpdateMetricValueResponse
+	4,  // 25: metric.alerting.service.MetricService.UpdateMetricValueEncrypted:output_type -> metric.alerting.service.UpdateMetricValueResponse
+	8,  // 26: metric.alerting.service.AlertService.ListAlerts:output_type -> metric.alerting.service.ListAlertsResponse
+	11, // 27: metric.alerting.service.AlertService.ListRules:output_type -> metric.alerting.service.ListRulesResponse
+	13, // 28: metric.alerting.service.AlertService.WatchAlerts:output_type -> metric.alerting.service.AlertEvent
+	24, // [24:29] is the sub-list for method output_type
+	19, // [19:24] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_metrics_proto_init() }
@@ -229,9 +1187,9 @@ func file_internal_proto_metrics_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_metrics_proto_rawDesc), len(file_internal_proto_metrics_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   20,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_internal_proto_metrics_proto_goTypes,
 		DependencyIndexes: file_internal_proto_metrics_proto_depIdxs,
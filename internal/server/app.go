@@ -1,6 +1,6 @@
 // Package server initializes and runs the main application server.
 // It configures storage backends, handles graceful shutdown, restores and saves metric dumps,
-// and starts the HTTP server for metric collection.
+// starts the HTTP server for metric collection and the alert rule evaluator.
 package server
 
 import (
@@ -12,7 +12,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting/notify"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/server/config"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/server/http"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
@@ -23,8 +26,13 @@ import (
 	gs "github.com/dmitrijs2005/metric-alerting-service/internal/server/grpc"
 )
 
-var newPostgresClient = func(dsn string) (storage.DBStorage, error) {
-	return db.NewPostgresClient(dsn)
+var newPostgresClient = func(dsn string, outOfOrder storage.OutOfOrderPolicy) (storage.DBStorage, error) {
+	c, err := db.NewPostgresClient(dsn)
+	if err != nil {
+		return nil, err
+	}
+	c.OutOfOrder = outOfOrder
+	return c, nil
 }
 
 type App struct {
@@ -49,22 +57,39 @@ func (app *App) initSignalHandler(cancelFunc context.CancelFunc) {
 	}()
 }
 
-func (app *App) initDumpSyncAgent(s storage.Storage) (*file.FileSaver, error) {
-	return file.NewFileSaver(app.config.FileStoragePath, s), nil
+func (app *App) initDumpSyncAgent(s storage.Storage, sections ...file.DumpSection) (*file.FileSaver, error) {
+	return file.NewFileSaver(app.config.FileStoragePath, s, sections...), nil
+}
+
+// checkHistogramBounds validates the configured bucket bounds of histograms
+// created by a single observation, which are passed to the servers.
+func (app *App) checkHistogramBounds() error {
+	if len(app.config.HistogramBuckets) == 0 {
+		return nil
+	}
+
+	_, err := metric.NewHistogramValue(app.config.HistogramBuckets)
+	return err
 }
 
 func (app *App) initStorage(ctx context.Context) (storage.Storage, error) {
 
 	var s storage.Storage
 
+	if err := app.config.OutOfOrder.Validate(); err != nil {
+		return nil, err
+	}
+
 	if app.config.DatabaseDSN == "" {
 
-		s = memory.NewMemStorage()
+		m := memory.NewMemStorage()
+		m.OutOfOrder = app.config.OutOfOrder
+		s = m
 	} else {
 
 		var err error
 
-		pgClient, err := newPostgresClient(app.config.DatabaseDSN)
+		pgClient, err := newPostgresClient(app.config.DatabaseDSN, app.config.OutOfOrder)
 		if err != nil {
 			return nil, err
 		}
@@ -113,7 +138,7 @@ func (app *App) restoreDumpIfNeeded(ctx context.Context, a file.DumpSaver, s sto
 
 }
 
-func (app *App) startHTTPServer(ctx context.Context, cancelFunc context.CancelFunc, wg *sync.WaitGroup, s storage.Storage) {
+func (app *App) startHTTPServer(ctx context.Context, cancelFunc context.CancelFunc, wg *sync.WaitGroup, s storage.Storage, evaluator *alerting.Evaluator) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -122,6 +147,8 @@ func (app *App) startHTTPServer(ctx context.Context, cancelFunc context.CancelFu
 			app.logger.Error(err)
 			cancelFunc()
 		} else {
+			s.Alerting = evaluator
+			s.HistogramBounds = app.config.HistogramBuckets
 			e := s.ConfigureRoutes()
 
 			if err := s.Run(ctx, e); err != nil {
@@ -132,7 +159,7 @@ func (app *App) startHTTPServer(ctx context.Context, cancelFunc context.CancelFu
 	}()
 }
 
-func (app *App) startGRPCServer(ctx context.Context, cancelFunc context.CancelFunc, wg *sync.WaitGroup, s storage.Storage) {
+func (app *App) startGRPCServer(ctx context.Context, cancelFunc context.CancelFunc, wg *sync.WaitGroup, s storage.Storage, evaluator *alerting.Evaluator) {
 
 	wg.Add(1)
 	go func() {
@@ -142,6 +169,8 @@ func (app *App) startGRPCServer(ctx context.Context, cancelFunc context.CancelFu
 			app.logger.Error(err)
 			cancelFunc()
 		} else {
+			s.Alerting = evaluator
+			s.HistogramBounds = app.config.HistogramBuckets
 
 			if err := s.Run(ctx); err != nil {
 				app.logger.Error(err)
@@ -152,6 +181,146 @@ func (app *App) startGRPCServer(ctx context.Context, cancelFunc context.CancelFu
 
 }
 
+func (app *App) initAlertEvaluator(s storage.Storage) (*alerting.Evaluator, error) {
+
+	e, err := alerting.NewEvaluator(s, app.config.AlertRules, app.config.AlertEvaluationInterval, app.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	receivers, err := notify.NewReceivers(app.config.Receivers, app.config.NotificationURL())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range receivers {
+		e.AddReceiver(r)
+	}
+
+	// transitions are kept in the database if the storage supports it,
+	// otherwise in memory and in the dump file
+	if h, ok := s.(alerting.HistoryStore); ok {
+		e.SetHistoryStore(h)
+	} else {
+		e.SetHistoryStore(alerting.NewMemoryHistory(app.config.AlertHistoryMaxCount))
+	}
+	e.SetHistoryRetention(alerting.HistoryRetention{MaxAge: app.config.AlertHistoryMaxAge, MaxCount: app.config.AlertHistoryMaxCount})
+
+	if err := e.SetInhibitRules(app.config.InhibitRules); err != nil {
+		return nil, err
+	}
+
+	if app.config.Route != nil {
+		if err := e.SetRoute(*app.config.Route); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// initAlertStateStoreIfNeeded makes the evaluator persist alert states,
+// baselines of anomaly rules and silences in the database and restores
+// previously saved ones, if the storage supports it.
+func (app *App) initAlertStateStoreIfNeeded(ctx context.Context, s storage.Storage, e *alerting.Evaluator) (bool, error) {
+
+	store, ok := s.(alerting.StateStore)
+	if !ok {
+		return false, nil
+	}
+
+	e.SetStateStore(store)
+
+	if bs, ok := s.(alerting.BaselineStore); ok {
+		e.SetBaselineStore(bs)
+	}
+
+	if err := e.LoadState(ctx); err != nil {
+		return false, err
+	}
+
+	if ss, ok := s.(alerting.SilenceStore); ok {
+		e.Silences().SetStore(ss)
+		if err := e.Silences().Load(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// initRuleStore makes the evaluator persist rules managed at runtime and loads them.
+// Rules are kept in the database if the storage supports it,
+// otherwise in a file next to the dump file.
+func (app *App) initRuleStore(ctx context.Context, s storage.Storage, e *alerting.Evaluator) error {
+
+	store, ok := s.(alerting.RuleStore)
+	if !ok {
+		if app.config.FileStoragePath == "" {
+			return nil
+		}
+		store = file.NewRuleFile(app.config.FileStoragePath)
+	}
+
+	e.SetRuleStore(store)
+
+	return e.LoadRules(ctx)
+}
+
+// initOutbox makes the evaluator queue notifications in an outbox retrying
+// failed deliveries and loads the entries left by the previous run.
+// The outbox is kept in the database if the storage supports it,
+// otherwise in a file next to the dump file, or only in memory.
+func (app *App) initOutbox(ctx context.Context, s storage.Storage, e *alerting.Evaluator) error {
+
+	store, ok := s.(alerting.OutboxStore)
+	if !ok && app.config.FileStoragePath != "" {
+		store = file.NewOutboxFile(app.config.FileStoragePath)
+	}
+
+	o, err := alerting.NewOutbox(store, app.config.NotificationRetry, app.logger)
+	if err != nil {
+		return err
+	}
+
+	if err := o.Load(ctx); err != nil {
+		return err
+	}
+
+	e.SetOutbox(o)
+	return nil
+}
+
+func (app *App) startOutbox(ctx context.Context, wg *sync.WaitGroup, e *alerting.Evaluator) {
+
+	o := e.Outbox()
+	if o == nil {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		app.logger.Infow("Starting notification outbox", "pending", len(o.Pending()), "dead_letters", len(o.DeadLetters()))
+		o.Run(ctx)
+	}()
+}
+
+func (app *App) startAlertEvaluator(ctx context.Context, wg *sync.WaitGroup, e *alerting.Evaluator) {
+
+	// zero interval disables alert evaluation
+	if app.config.AlertEvaluationInterval == 0 {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		app.logger.Infow("Starting alert evaluator", "rules", len(e.Rules()), "interval", app.config.AlertEvaluationInterval)
+		e.Run(ctx)
+	}()
+}
+
 func (app *App) saveDump(ctx context.Context, a file.DumpSaver) {
 
 	err := a.SaveDump(ctx)
@@ -219,6 +388,12 @@ func (app *App) Run() {
 
 	app.initSignalHandler(cancelFunc)
 
+	if err := app.checkHistogramBounds(); err != nil {
+		app.logger.Errorw("Histogram buckets error", "err", err)
+		cancelFunc()
+		return
+	}
+
 	s, err := app.initStorage(ctx)
 	if err != nil {
 		app.logger.Errorw("Storage initialization error", "err", err)
@@ -226,7 +401,36 @@ func (app *App) Run() {
 		return
 	}
 
-	a, err := app.initDumpSyncAgent(s)
+	defer func() {
+		closed, err := app.closeDBIfNeeded(s)
+		if err != nil {
+			app.logger.Errorw("Error closing database connection:", "err", err)
+		} else {
+			if closed {
+				app.logger.Infow("Database closed")
+			}
+		}
+	}()
+
+	evaluator, err := app.initAlertEvaluator(s)
+	if err != nil {
+		app.logger.Errorw("Alert evaluator initialization error", "err", err)
+		cancelFunc()
+		return
+	}
+
+	sections := []file.DumpSection{evaluator, evaluator.Silences(), evaluator.Baselines()}
+	if h, ok := evaluator.History().(file.DumpSection); ok {
+		sections = append(sections, h)
+	}
+	if md, ok := s.(file.DumpSection); ok {
+		sections = append(sections, md)
+	}
+	if ms, ok := s.(*memory.MemStorage); ok {
+		sections = append(sections, ms.Times())
+	}
+
+	a, err := app.initDumpSyncAgent(s, sections...)
 	if err != nil {
 		app.logger.Errorw("Dump sync agent initialization error", "err", err)
 		cancelFunc()
@@ -240,25 +444,39 @@ func (app *App) Run() {
 		return
 	}
 
-	defer func() {
-		closed, err := app.closeDBIfNeeded(s)
-		if err != nil {
-			app.logger.Errorw("Error closing database connection:", "err", err)
-		} else {
-			if closed {
-				app.logger.Infow("Database closed")
-			}
-		}
-	}()
+	_, err = app.initAlertStateStoreIfNeeded(ctx, s, evaluator)
+	if err != nil {
+		app.logger.Errorw("Alert state restore error", "err", err)
+		cancelFunc()
+		return
+	}
+
+	err = app.initRuleStore(ctx, s, evaluator)
+	if err != nil {
+		app.logger.Errorw("Alert rules restore error", "err", err)
+		cancelFunc()
+		return
+	}
+
+	err = app.initOutbox(ctx, s, evaluator)
+	if err != nil {
+		app.logger.Errorw("Notification outbox restore error", "err", err)
+		cancelFunc()
+		return
+	}
 
 	var wg sync.WaitGroup
 
-	app.startHTTPServer(ctx, cancelFunc, &wg, s)
+	app.startHTTPServer(ctx, cancelFunc, &wg, s, evaluator)
 
-	app.startGRPCServer(ctx, cancelFunc, &wg, s)
+	app.startGRPCServer(ctx, cancelFunc, &wg, s, evaluator)
 
 	app.initPeriodicDumpSaveIfNeeded(ctx, s, a, &wg)
 
+	app.startAlertEvaluator(ctx, &wg, evaluator)
+
+	app.startOutbox(ctx, &wg, evaluator)
+
 	wg.Wait()
 
 	app.saveDumpIfNeeded(ctx, s, a)
@@ -3,16 +3,21 @@ package server
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting/notify"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/server/config"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/file"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +27,26 @@ func TestApp_initStorage_Memory(t *testing.T) {
 	st, err := app.initStorage(context.Background())
 	require.NoError(t, err)
 	require.IsType(t, &memory.MemStorage{}, st)
+
+	app.config.OutOfOrder = storage.OutOfOrderReject
+	st, err = app.initStorage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, storage.OutOfOrderReject, st.(*memory.MemStorage).OutOfOrder)
+
+	app.config.OutOfOrder = "drop"
+	_, err = app.initStorage(context.Background())
+	assert.Error(t, err)
+}
+
+func TestApp_checkHistogramBounds(t *testing.T) {
+	app := &App{config: &config.Config{}}
+	require.NoError(t, app.checkHistogramBounds())
+
+	app = &App{config: &config.Config{HistogramBuckets: []float64{1, 0.5}}}
+	require.ErrorIs(t, app.checkHistogramBounds(), metric.ErrorInvalidMetricValue)
+
+	app = &App{config: &config.Config{HistogramBuckets: []float64{0.5, 1}}}
+	require.NoError(t, app.checkHistogramBounds())
 }
 
 type fakeDBStorage struct {
@@ -87,7 +112,10 @@ func TestApp_startHTTPServer(t *testing.T) {
 	defer cancel()
 	var wg sync.WaitGroup
 
-	app.startHTTPServer(ctx, cancel, &wg, st)
+	e, err := app.initAlertEvaluator(st)
+	require.NoError(t, err)
+
+	app.startHTTPServer(ctx, cancel, &wg, st, e)
 	cancel()
 	wg.Wait()
 }
@@ -124,7 +152,7 @@ func (c *MockDBClient) Update(ctx context.Context, m metric.Metric, v interface{
 	return nil
 }
 
-func (c *MockDBClient) Retrieve(ctx context.Context, m metric.MetricType, n string) (metric.Metric, error) {
+func (c *MockDBClient) Retrieve(ctx context.Context, m metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
 	return nil, nil
 }
 
@@ -184,7 +212,7 @@ func TestApp_initStorage(t *testing.T) {
 		}
 
 		oldNew := newPostgresClient
-		newPostgresClient = func(_ string) (storage.DBStorage, error) {
+		newPostgresClient = func(_ string, _ storage.OutOfOrderPolicy) (storage.DBStorage, error) {
 			return mockClient, nil
 		}
 		defer func() { newPostgresClient = oldNew }()
@@ -201,7 +229,7 @@ func TestApp_initStorage(t *testing.T) {
 		}
 
 		oldNew := newPostgresClient
-		newPostgresClient = func(_ string) (storage.DBStorage, error) {
+		newPostgresClient = func(_ string, _ storage.OutOfOrderPolicy) (storage.DBStorage, error) {
 			return mockClient, nil
 		}
 		defer func() { newPostgresClient = oldNew }()
@@ -214,7 +242,7 @@ func TestApp_initStorage(t *testing.T) {
 
 	t.Run("postgres client constructor error", func(t *testing.T) {
 		oldNew := newPostgresClient
-		newPostgresClient = func(_ string) (storage.DBStorage, error) {
+		newPostgresClient = func(_ string, _ storage.OutOfOrderPolicy) (storage.DBStorage, error) {
 			return nil, errors.New("cannot connect")
 		}
 		defer func() { newPostgresClient = oldNew }()
@@ -225,3 +253,284 @@ func TestApp_initStorage(t *testing.T) {
 		require.Nil(t, st)
 	})
 }
+
+func TestApp_initAlertEvaluator(t *testing.T) {
+	t.Run("valid rules", func(t *testing.T) {
+		app := &App{config: &config.Config{
+			AlertRules:              []alerting.Rule{{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6}},
+			AlertEvaluationInterval: time.Second,
+		}, logger: logger.GetLogger()}
+
+		e, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.NoError(t, err)
+		require.NotNil(t, e)
+	})
+
+	t.Run("memory history", func(t *testing.T) {
+		app := &App{config: &config.Config{AlertHistoryMaxCount: 5}, logger: logger.GetLogger()}
+
+		e, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.NoError(t, err)
+		require.IsType(t, &alerting.MemoryHistory{}, e.History())
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		app := &App{config: &config.Config{
+			AlertRules: []alerting.Rule{{Name: "bad", MetricType: "unknown"}},
+		}, logger: logger.GetLogger()}
+
+		_, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid receiver", func(t *testing.T) {
+		app := &App{config: &config.Config{
+			Receivers: []notify.ReceiverConfig{{Name: "ops", Webhooks: []notify.WebhookConfig{{URL: "not a url"}}}},
+		}, logger: logger.GetLogger()}
+
+		_, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.ErrorIs(t, err, notify.ErrorInvalidWebhookURL)
+	})
+
+	t.Run("invalid route", func(t *testing.T) {
+		app := &App{config: &config.Config{
+			Route: &alerting.Route{GroupBy: []string{alerting.GroupByAll, "alertname"}},
+		}, logger: logger.GetLogger()}
+
+		_, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.ErrorIs(t, err, alerting.ErrorInvalidRoute)
+	})
+
+	t.Run("unknown route receiver", func(t *testing.T) {
+		app := &App{config: &config.Config{
+			Route: &alerting.Route{Routes: []alerting.Route{{Receiver: "pager"}}},
+		}, logger: logger.GetLogger()}
+
+		_, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.ErrorIs(t, err, alerting.ErrorUnknownReceiver)
+	})
+
+	t.Run("invalid inhibit rule", func(t *testing.T) {
+		app := &App{config: &config.Config{
+			InhibitRules: []alerting.InhibitRule{{SourceMatchers: []alerting.Matcher{{Name: "alertname", Value: "AgentDown"}}}},
+		}, logger: logger.GetLogger()}
+
+		_, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.ErrorIs(t, err, alerting.ErrorInvalidInhibitRule)
+	})
+}
+
+func TestApp_startAlertEvaluator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	st := memory.NewMemStorage()
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("HeapAlloc", 600e6)))
+
+	app := &App{config: &config.Config{
+		AlertRules:              []alerting.Rule{{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6}},
+		AlertEvaluationInterval: 10 * time.Millisecond,
+	}, logger: logger.GetLogger()}
+
+	e, err := app.initAlertEvaluator(st)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	app.startAlertEvaluator(ctx, &wg, e)
+
+	require.Eventually(t, func() bool { return len(e.Alerts()) == 1 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+
+	t.Run("zero interval disables evaluation", func(t *testing.T) {
+		app.config.AlertEvaluationInterval = 0
+		e, err := app.initAlertEvaluator(st)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		app.startAlertEvaluator(context.Background(), &wg, e)
+		wg.Wait()
+		assert.Empty(t, e.Alerts())
+	})
+}
+
+type fakeAlertStateDB struct {
+	fakeDBStorage
+	alerts    []alerting.Alert
+	baselines []alerting.Baseline
+	silences  []alerting.Silence
+}
+
+func (f *fakeAlertStateDB) SaveAlerts(ctx context.Context, alerts []alerting.Alert) error {
+	f.alerts = alerts
+	return nil
+}
+
+func (f *fakeAlertStateDB) LoadAlerts(ctx context.Context) ([]alerting.Alert, error) {
+	return f.alerts, nil
+}
+
+func (f *fakeAlertStateDB) SaveBaselines(ctx context.Context, baselines []alerting.Baseline) error {
+	f.baselines = baselines
+	return nil
+}
+
+func (f *fakeAlertStateDB) LoadBaselines(ctx context.Context) ([]alerting.Baseline, error) {
+	return f.baselines, nil
+}
+
+func (f *fakeAlertStateDB) SaveSilences(ctx context.Context, silences []alerting.Silence) error {
+	f.silences = silences
+	return nil
+}
+
+func (f *fakeAlertStateDB) LoadSilences(ctx context.Context) ([]alerting.Silence, error) {
+	return f.silences, nil
+}
+
+func TestApp_initAlertStateStoreIfNeeded(t *testing.T) {
+	app := &App{config: &config.Config{}, logger: logger.GetLogger()}
+
+	t.Run("memory storage keeps state in the dump", func(t *testing.T) {
+		st := memory.NewMemStorage()
+		e, err := app.initAlertEvaluator(st)
+		require.NoError(t, err)
+
+		ok, err := app.initAlertStateStoreIfNeeded(context.Background(), st, e)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("database storage restores state", func(t *testing.T) {
+		st := &fakeAlertStateDB{
+			alerts:    []alerting.Alert{{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "m", State: alerting.StateFiring}},
+			baselines: []alerting.Baseline{{Rule: "r2", MetricType: metric.MetricTypeGauge, MetricName: "m", Mean: 1, Count: 3}},
+			silences: []alerting.Silence{{ID: "s1", Matchers: []alerting.Matcher{{Name: "team", Value: "infra"}},
+				StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour), CreatedBy: "jane"}},
+		}
+		e, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.NoError(t, err)
+
+		ok, err := app.initAlertStateStoreIfNeeded(context.Background(), st, e)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, e.Alerts(), 1)
+		require.Equal(t, st.baselines, e.Baselines().List())
+		require.Len(t, e.Silences().List(), 1)
+
+		// silences added later are stored too
+		_, err = e.Silences().Add(context.Background(), alerting.Silence{Matchers: []alerting.Matcher{{Name: "team", Value: "db"}},
+			EndsAt: time.Now().Add(time.Hour), CreatedBy: "jane"}, time.Now())
+		require.NoError(t, err)
+		require.Len(t, st.silences, 2)
+	})
+}
+
+type fakeRuleDB struct {
+	fakeDBStorage
+	rules []alerting.Rule
+}
+
+func (f *fakeRuleDB) SaveRules(ctx context.Context, rules []alerting.Rule) error {
+	f.rules = rules
+	return nil
+}
+
+func (f *fakeRuleDB) LoadRules(ctx context.Context) ([]alerting.Rule, error) {
+	return f.rules, nil
+}
+
+func TestApp_initRuleStore(t *testing.T) {
+	ctx := context.Background()
+	rule := alerting.Rule{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6}
+
+	t.Run("memory storage keeps rules next to the dump file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metrics.sav")
+		app := &App{config: &config.Config{FileStoragePath: path}, logger: logger.GetLogger()}
+
+		st := memory.NewMemStorage()
+		e, err := app.initAlertEvaluator(st)
+		require.NoError(t, err)
+
+		require.NoError(t, app.initRuleStore(ctx, st, e))
+		require.NoError(t, e.AddRule(ctx, rule))
+		require.FileExists(t, path+file.RuleFileSuffix)
+
+		// rules are loaded after a restart
+		e, err = app.initAlertEvaluator(st)
+		require.NoError(t, err)
+		require.NoError(t, app.initRuleStore(ctx, st, e))
+		require.Len(t, e.Rules(), 1)
+	})
+
+	t.Run("no dump file", func(t *testing.T) {
+		app := &App{config: &config.Config{}, logger: logger.GetLogger()}
+
+		st := memory.NewMemStorage()
+		e, err := app.initAlertEvaluator(st)
+		require.NoError(t, err)
+
+		require.NoError(t, app.initRuleStore(ctx, st, e))
+		require.NoError(t, e.AddRule(ctx, rule))
+	})
+
+	t.Run("database storage", func(t *testing.T) {
+		app := &App{config: &config.Config{FileStoragePath: filepath.Join(t.TempDir(), "metrics.sav")}, logger: logger.GetLogger()}
+
+		st := &fakeRuleDB{rules: []alerting.Rule{rule}}
+		e, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.NoError(t, err)
+
+		require.NoError(t, app.initRuleStore(ctx, st, e))
+		require.Len(t, e.Rules(), 1)
+
+		_, err = e.SetRuleDisabled(ctx, rule.Name, true)
+		require.NoError(t, err)
+		require.True(t, st.rules[0].Disabled)
+		require.NoFileExists(t, app.config.FileStoragePath+file.RuleFileSuffix)
+	})
+
+	t.Run("invalid stored rules", func(t *testing.T) {
+		app := &App{config: &config.Config{}, logger: logger.GetLogger()}
+
+		st := &fakeRuleDB{rules: []alerting.Rule{{Name: "bad"}}}
+		e, err := app.initAlertEvaluator(memory.NewMemStorage())
+		require.NoError(t, err)
+
+		require.Error(t, app.initRuleStore(ctx, st, e))
+	})
+}
+
+func TestApp_initOutbox(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("memory storage keeps the outbox next to the dump file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metrics.sav")
+		app := &App{config: &config.Config{FileStoragePath: path}, logger: logger.GetLogger()}
+
+		st := memory.NewMemStorage()
+		e, err := app.initAlertEvaluator(st)
+		require.NoError(t, err)
+
+		require.NoError(t, app.initOutbox(ctx, st, e))
+		require.NotNil(t, e.Outbox())
+		_, err = e.Outbox().Enqueue(ctx, alerting.NewNotification("ops", nil), time.Now())
+		require.NoError(t, err)
+		require.FileExists(t, path+file.OutboxFileSuffix)
+
+		// undelivered notifications are loaded after a restart
+		e, err = app.initAlertEvaluator(st)
+		require.NoError(t, err)
+		require.NoError(t, app.initOutbox(ctx, st, e))
+		require.Len(t, e.Outbox().Pending(), 1)
+	})
+
+	t.Run("invalid retry policy", func(t *testing.T) {
+		app := &App{config: &config.Config{NotificationRetry: alerting.RetryPolicy{MaxAttempts: -1}}, logger: logger.GetLogger()}
+
+		st := memory.NewMemStorage()
+		e, err := app.initAlertEvaluator(st)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, app.initOutbox(ctx, st, e), alerting.ErrorInvalidRetryPolicy)
+	})
+}
@@ -2,7 +2,14 @@
 // including parsing environment variables and command-line flags.
 package config
 
-import "time"
+import (
+	"net"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting/notify"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+)
 
 func (c *Config) LoadDefaults() {
 	c.DatabaseDSN = ""
@@ -14,6 +21,7 @@ func (c *Config) LoadDefaults() {
 	c.Restore = true
 	c.CryptoKey = ""
 	c.TrustedSubnet = ""
+	c.AlertEvaluationInterval = time.Duration(10) * time.Second
 }
 
 type Config struct {
@@ -26,6 +34,19 @@ type Config struct {
 	Restore          bool
 	CryptoKey        string
 	TrustedSubnet    string
+
+	AlertRules              []alerting.Rule
+	AlertEvaluationInterval time.Duration // zero disables alert evaluation
+	Receivers               []notify.ReceiverConfig
+	Route                   *alerting.Route // nil means alerting.DefaultRoute
+	InhibitRules            []alerting.InhibitRule
+	ExternalURL             string               // base URL of the server in notification links
+	AlertHistoryMaxAge      time.Duration        // zero means no limit
+	AlertHistoryMaxCount    int                  // zero means no limit, alerting.DefaultHistoryCapacity in memory
+	NotificationRetry       alerting.RetryPolicy // zero fields mean alerting.DefaultRetryPolicy
+
+	HistogramBuckets []float64                // bucket bounds of histograms created by an observation, nil means metric.DefaultHistogramBounds
+	OutOfOrder       storage.OutOfOrderPolicy // what to do with out-of-order gauge samples, "" means storage.OutOfOrderIgnore
 }
 
 func LoadConfig() *Config {
@@ -38,3 +59,21 @@ func LoadConfig() *Config {
 
 	return config
 }
+
+// NotificationURL returns the base URL of the server used in links in
+// notifications: ExternalURL if set, otherwise an http URL of EndpointAddr
+// with localhost as the default host.
+func (c *Config) NotificationURL() string {
+	if c.ExternalURL != "" {
+		return c.ExternalURL
+	}
+
+	host, port, err := net.SplitHostPort(c.EndpointAddr)
+	if err != nil {
+		return "http://" + c.EndpointAddr
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
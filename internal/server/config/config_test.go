@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_NotificationURL(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"external url", Config{EndpointAddr: ":8080", ExternalURL: "https://metrics.example.com"}, "https://metrics.example.com"},
+		{"port only", Config{EndpointAddr: ":8080"}, "http://localhost:8080"},
+		{"host and port", Config{EndpointAddr: "10.0.0.1:8080"}, "http://10.0.0.1:8080"},
+		{"ipv6", Config{EndpointAddr: "[::1]:8080"}, "http://[::1]:8080"},
+		{"no port", Config{EndpointAddr: "metrics"}, "http://metrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.NotificationURL())
+		})
+	}
+}
@@ -22,13 +22,13 @@ func TestParseFlags(t *testing.T) {
 			"-k", "secretkey1", "-crypto-key", "some_file.pem", "-t", "192.168.1.0/24", "-g", ":3200", "-r", "true"},
 			expected: &Config{EndpointAddr: "127.0.0.1:9090", StoreInterval: 30 * time.Second,
 				FileStoragePath: "/tmp/tmp.sav", Restore: true, DatabaseDSN: "db", Key: "secretkey1", CryptoKey: "some_file.pem",
-				TrustedSubnet: "192.168.1.0/24", GRPCEndpointAddr: ":3200"}}, // Edge case: empty value
+				TrustedSubnet: "192.168.1.0/24", GRPCEndpointAddr: ":3200", AlertEvaluationInterval: 10 * time.Second}}, // Edge case: empty value
 		{name: "Test2 :port", args: []string{"cmd"},
 			expected: &Config{EndpointAddr: ":8080", StoreInterval: 30 * time.Second,
-				FileStoragePath: "/tmp/tmp.sav", Restore: true, DatabaseDSN: "", Key: "", GRPCEndpointAddr: ":50051"}}, // Default value
+				FileStoragePath: "/tmp/tmp.sav", Restore: true, DatabaseDSN: "", Key: "", GRPCEndpointAddr: ":50051", AlertEvaluationInterval: 10 * time.Second}}, // Default value
 		{name: "Test3 empty string", args: []string{"cmd", "-a", ""},
 			expected: &Config{EndpointAddr: "", StoreInterval: 30 * time.Second,
-				FileStoragePath: "/tmp/tmp.sav", Restore: true, DatabaseDSN: "", Key: "", GRPCEndpointAddr: ":50051"}}, // Edge case: empty value
+				FileStoragePath: "/tmp/tmp.sav", Restore: true, DatabaseDSN: "", Key: "", GRPCEndpointAddr: ":50051", AlertEvaluationInterval: 10 * time.Second}}, // Edge case: empty value
 	}
 
 	for _, tt := range tests {
@@ -5,7 +5,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting/notify"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 )
 
 // JsonConfig defines a configuration structure tailored for JSON unmarshalling.
@@ -24,6 +27,19 @@ type JsonConfig struct {
 	Key           string          `json:"key"`
 	CryptoKey     string          `json:"crypto_key"`
 	TrustedSubnet string          `json:"trusted_subnet"`
+
+	AlertRules              []alerting.Rule         `json:"alert_rules"`
+	AlertEvaluationInterval *common.Duration        `json:"alert_evaluation_interval"` // nil if not set, as "0s" disables evaluation
+	Receivers               []notify.ReceiverConfig `json:"receivers"`
+	Route                   *alerting.Route         `json:"route"`
+	InhibitRules            []alerting.InhibitRule  `json:"inhibit_rules"`
+	ExternalURL             string                  `json:"external_url"`
+	AlertHistoryMaxAge      common.Duration         `json:"alert_history_max_age"`
+	AlertHistoryMaxCount    int                     `json:"alert_history_max_count"`
+	NotificationRetry       alerting.RetryPolicy    `json:"notification_retry"`
+
+	HistogramBuckets []float64                `json:"histogram_buckets"`
+	OutOfOrder       storage.OutOfOrderPolicy `json:"out_of_order"`
 }
 
 // parseJson loads configuration values from a JSON file into the provided
@@ -46,6 +62,18 @@ type JsonConfig struct {
 //   - StoreInterval
 //   - Restore
 //   - CryptoKey
+//   - TrustedSubnet
+//   - AlertRules
+//   - AlertEvaluationInterval (only if set, "0s" disables alert evaluation)
+//   - Receivers
+//   - Route (only if set)
+//   - InhibitRules
+//   - ExternalURL
+//   - AlertHistoryMaxAge
+//   - AlertHistoryMaxCount
+//   - NotificationRetry
+//   - HistogramBuckets
+//   - OutOfOrder
 //
 // The caller is expected to merge these values with defaults, environment
 // variables, and command-line flags as part of the full configuration process.
@@ -82,4 +110,21 @@ func parseJson(config *Config) {
 	config.Restore = c.Restore
 	config.CryptoKey = c.CryptoKey
 	config.TrustedSubnet = c.TrustedSubnet
+	config.AlertRules = c.AlertRules
+	config.Receivers = c.Receivers
+	config.InhibitRules = c.InhibitRules
+	config.ExternalURL = c.ExternalURL
+	config.AlertHistoryMaxAge = c.AlertHistoryMaxAge.Duration
+	config.AlertHistoryMaxCount = c.AlertHistoryMaxCount
+	config.NotificationRetry = c.NotificationRetry
+	config.HistogramBuckets = c.HistogramBuckets
+	config.OutOfOrder = c.OutOfOrder
+
+	if c.Route != nil {
+		config.Route = c.Route
+	}
+
+	if c.AlertEvaluationInterval != nil {
+		config.AlertEvaluationInterval = c.AlertEvaluationInterval.Duration
+	}
 }
@@ -7,6 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -169,3 +173,152 @@ func Test_parseJson_DurationForms(t *testing.T) {
 		assert.Equal(t, 750*time.Millisecond, cfg.StoreInterval)
 	})
 }
+
+func Test_parseJson_AlertRules(t *testing.T) {
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+
+	path := writeTempJSON(t, "", "", map[string]any{
+		"alert_evaluation_interval": "15s",
+		"alert_rules": []map[string]any{
+			{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "operator": ">", "threshold": 500e6, "for": "1m", "keep_firing_for": "30s"},
+			{"name": "PollCountLow", "metric_type": "counter", "metric_name": "PollCount", "operator": "<", "threshold": 10},
+		},
+	})
+
+	t.Setenv("CONFIG", path)
+	os.Args = []string{"testbin"}
+
+	cfg := &Config{}
+	cfg.LoadDefaults()
+	parseJson(cfg)
+
+	assert.Equal(t, 15*time.Second, cfg.AlertEvaluationInterval)
+	require.Len(t, cfg.AlertRules, 2)
+	assert.Equal(t, "HeapAllocHigh", cfg.AlertRules[0].Name)
+	assert.Equal(t, metric.MetricTypeGauge, cfg.AlertRules[0].MetricType)
+	assert.Equal(t, "HeapAlloc", cfg.AlertRules[0].MetricName)
+	assert.Equal(t, alerting.OperatorGreater, cfg.AlertRules[0].Operator)
+	assert.Equal(t, 500e6, cfg.AlertRules[0].Threshold)
+	assert.Equal(t, time.Minute, cfg.AlertRules[0].For.Duration)
+	assert.Equal(t, 30*time.Second, cfg.AlertRules[0].KeepFiringFor.Duration)
+	assert.Equal(t, "counter PollCount < 10", cfg.AlertRules[1].String())
+
+	t.Run("interval keeps default when not set", func(t *testing.T) {
+		path := writeTempJSON(t, "", "", map[string]any{"alert_rules": []map[string]any{}})
+		t.Setenv("CONFIG", path)
+
+		cfg := &Config{}
+		cfg.LoadDefaults()
+		parseJson(cfg)
+		assert.Equal(t, 10*time.Second, cfg.AlertEvaluationInterval)
+	})
+
+	t.Run("zero interval disables evaluation", func(t *testing.T) {
+		path := writeTempJSON(t, "", "", map[string]any{"alert_evaluation_interval": "0s"})
+		t.Setenv("CONFIG", path)
+
+		cfg := &Config{}
+		cfg.LoadDefaults()
+		parseJson(cfg)
+		assert.Zero(t, cfg.AlertEvaluationInterval)
+	})
+}
+
+func Test_parseJson_Receivers(t *testing.T) {
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+
+	path := writeTempJSON(t, "", "", map[string]any{
+		"receivers": []map[string]any{
+			{"name": "ops", "webhooks": []map[string]any{{"url": "https://hooks.example.com/alerts", "key": "secret", "timeout": "5s"}}},
+		},
+	})
+
+	t.Setenv("CONFIG", path)
+	os.Args = []string{"testbin"}
+
+	cfg := &Config{}
+	cfg.LoadDefaults()
+	parseJson(cfg)
+
+	require.Len(t, cfg.Receivers, 1)
+	assert.Equal(t, "ops", cfg.Receivers[0].Name)
+	require.Len(t, cfg.Receivers[0].Webhooks, 1)
+	assert.Equal(t, "https://hooks.example.com/alerts", cfg.Receivers[0].Webhooks[0].URL)
+	assert.Equal(t, "secret", cfg.Receivers[0].Webhooks[0].Key)
+	assert.Equal(t, 5*time.Second, cfg.Receivers[0].Webhooks[0].Timeout.Duration)
+}
+
+func Test_parseJson_RouteAndHistory(t *testing.T) {
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+	os.Args = []string{"testbin"}
+
+	path := writeTempJSON(t, "", "", map[string]any{
+		"route": map[string]any{
+			"group_by":        []string{"alertname"},
+			"group_wait":      "30s",
+			"group_interval":  "5m",
+			"repeat_interval": "4h",
+		},
+		"alert_history_max_age":   "720h",
+		"alert_history_max_count": 50000,
+		"notification_retry":      map[string]any{"max_backoff": "10m", "max_attempts": 20},
+		"histogram_buckets":       []float64{0.1, 1, 10},
+		"out_of_order":            "reject",
+	})
+	t.Setenv("CONFIG", path)
+
+	cfg := &Config{}
+	cfg.LoadDefaults()
+	parseJson(cfg)
+
+	assert.Equal(t, 720*time.Hour, cfg.AlertHistoryMaxAge)
+	assert.Equal(t, 50000, cfg.AlertHistoryMaxCount)
+	assert.Equal(t, alerting.RetryPolicy{MaxBackoff: common.Duration{Duration: 10 * time.Minute}, MaxAttempts: 20}, cfg.NotificationRetry)
+	assert.Equal(t, []float64{0.1, 1, 10}, cfg.HistogramBuckets)
+	assert.Equal(t, storage.OutOfOrderReject, cfg.OutOfOrder)
+
+	require.NotNil(t, cfg.Route)
+	assert.Equal(t, []string{"alertname"}, cfg.Route.GroupBy)
+	assert.Equal(t, 30*time.Second, cfg.Route.GroupWait.Duration)
+	assert.Equal(t, 5*time.Minute, cfg.Route.GroupInterval.Duration)
+	assert.Equal(t, 4*time.Hour, cfg.Route.RepeatInterval.Duration)
+
+	t.Run("default route when not set", func(t *testing.T) {
+		path := writeTempJSON(t, "", "", map[string]any{})
+		t.Setenv("CONFIG", path)
+
+		cfg := &Config{}
+		cfg.LoadDefaults()
+		parseJson(cfg)
+		assert.Nil(t, cfg.Route)
+	})
+}
+
+func Test_parseJson_InhibitRules(t *testing.T) {
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+	os.Args = []string{"testbin"}
+
+	path := writeTempJSON(t, "", "", map[string]any{
+		"inhibit_rules": []map[string]any{{
+			"source_matchers": []map[string]any{{"name": "alertname", "value": "AgentDown"}},
+			"target_matchers": []map[string]any{{"name": "alertname", "value": "AgentDown", "type": "!="}},
+			"equal":           []string{"host"},
+		}},
+	})
+	t.Setenv("CONFIG", path)
+
+	cfg := &Config{}
+	cfg.LoadDefaults()
+	parseJson(cfg)
+
+	require.Len(t, cfg.InhibitRules, 1)
+	r := cfg.InhibitRules[0]
+	require.NoError(t, r.Validate())
+	assert.Equal(t, "AgentDown", r.SourceMatchers[0].Value)
+	assert.Equal(t, alerting.MatchNotEqual, r.TargetMatchers[0].Type)
+	assert.Equal(t, []string{"host"}, r.Equal)
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	pb "github.com/dmitrijs2005/metric-alerting-service/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AlertServer implements the gRPC AlertServiceServer on top of an alert
+// evaluator. It is registered by MetricsServer.Run if MetricsServer.Alerting is set.
+type AlertServer struct {
+	pb.UnimplementedAlertServiceServer
+	evaluator *alerting.Evaluator
+	done      chan struct{}
+}
+
+// NewAlertServer creates an AlertServer for the evaluator.
+func NewAlertServer(e *alerting.Evaluator) *AlertServer {
+	return &AlertServer{evaluator: e, done: make(chan struct{})}
+}
+
+// shutdown ends open WatchAlerts streams, which would otherwise block
+// a graceful stop of the gRPC server.
+func (s *AlertServer) shutdown() {
+	close(s.done)
+}
+
+// ListAlerts returns the pending and firing alerts, and the recently resolved
+// ones if requested, sorted by rule and metric name.
+func (s *AlertServer) ListAlerts(ctx context.Context, req *pb.ListAlertsRequest) (*pb.ListAlertsResponse, error) {
+	var response pb.ListAlertsResponse
+
+	for _, a := range s.evaluator.Alerts() {
+		if a.State == alerting.StatePending || a.State == alerting.StateFiring ||
+			(a.State == alerting.StateResolved && req.IncludeResolved) {
+			response.Alerts = append(response.Alerts, alertToPB(&a))
+		}
+	}
+
+	return &response, nil
+}
+
+// ListRules returns the alert rules in evaluation order with the outcome
+// of their last evaluation.
+func (s *AlertServer) ListRules(ctx context.Context, req *pb.ListRulesRequest) (*pb.ListRulesResponse, error) {
+	var response pb.ListRulesResponse
+
+	for _, r := range s.evaluator.RuleStatuses() {
+		response.Rules = append(response.Rules, &pb.Rule{
+			Name:            r.Name,
+			Kind:            string(r.Kind),
+			Condition:       r.Rule.String(),
+			MetricType:      string(r.MetricType),
+			MetricName:      r.MetricName,
+			Labels:          r.Labels,
+			Disabled:        r.Disabled,
+			Health:          string(r.Health),
+			LastError:       r.LastError,
+			LastEvaluatedAt: timestampToPB(r.LastEvaluatedAt),
+		})
+	}
+
+	return &response, nil
+}
+
+// WatchAlerts streams alert transitions until the client cancels the stream
+// or the server is stopped. The response headers are sent once the watch is
+// active.
+func (s *AlertServer) WatchAlerts(req *pb.WatchAlertsRequest, stream grpc.ServerStreamingServer[pb.AlertEvent]) error {
+	ch, cancel := s.evaluator.Watch()
+	defer cancel()
+
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is stopping")
+		case t, ok := <-ch:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher fell behind, watch again")
+			}
+			event := &pb.AlertEvent{Alert: alertToPB(&t.Alert), From: string(t.From), To: string(t.To), At: timestampToPB(t.At)}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// alertToPB converts an alert to its protobuf representation.
+func alertToPB(a *alerting.Alert) *pb.Alert {
+	return &pb.Alert{
+		Name:           a.Rule,
+		MetricType:     string(a.MetricType),
+		MetricName:     a.MetricName,
+		MetricLabels:   a.MetricLabels,
+		State:          string(a.State),
+		Value:          a.Value,
+		Threshold:      a.Threshold,
+		Labels:         a.Labels,
+		ActiveAt:       timestampToPB(a.ActiveAt),
+		FiredAt:        timestampToPB(a.FiredAt),
+		ResolvedAt:     timestampToPB(a.ResolvedAt),
+		SilencedBy:     a.SilencedBy,
+		InhibitedBy:    a.InhibitedBy,
+		AcknowledgedBy: a.AcknowledgedBy,
+		AcknowledgedAt: timestampToPB(a.AcknowledgedAt),
+	}
+}
+
+// timestampToPB converts a time to a timestamp, a zero time to nil.
+func timestampToPB(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
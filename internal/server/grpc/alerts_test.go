@@ -0,0 +1,139 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	pb "github.com/dmitrijs2005/metric-alerting-service/internal/proto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var alertsT0 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// startAlertServer serves an AlertServer for an evaluator with a HeapAlloc rule
+// and returns a client connected to it.
+func startAlertServer(t *testing.T) (pb.AlertServiceClient, *AlertServer, *alerting.Evaluator, *memory.MemStorage) {
+	t.Helper()
+
+	st := memory.NewMemStorage()
+	rule := alerting.Rule{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6,
+		Labels: map[string]string{"severity": "page"}}
+	e, err := alerting.NewEvaluator(st, []alerting.Rule{rule}, time.Second, logger.GetLogger())
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := NewAlertServer(e)
+	si := grpc.NewServer()
+	pb.RegisterAlertServiceServer(si, srv)
+	go si.Serve(lis)
+	t.Cleanup(si.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewAlertServiceClient(conn), srv, e, st
+}
+
+func TestAlertServer_ListAlertsAndRules(t *testing.T) {
+	ctx := context.Background()
+	client, _, e, st := startAlertServer(t)
+
+	rules, err := client.ListRules(ctx, &pb.ListRulesRequest{})
+	require.NoError(t, err)
+	require.Len(t, rules.Rules, 1)
+	assert.Equal(t, "HeapAllocHigh", rules.Rules[0].Name)
+	assert.Equal(t, "unknown", rules.Rules[0].Health)
+	assert.Nil(t, rules.Rules[0].LastEvaluatedAt)
+
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("HeapAlloc", 600e6)))
+	_, err = e.Evaluate(ctx, alertsT0)
+	require.NoError(t, err)
+
+	alerts, err := client.ListAlerts(ctx, &pb.ListAlertsRequest{})
+	require.NoError(t, err)
+	require.Len(t, alerts.Alerts, 1)
+	a := alerts.Alerts[0]
+	assert.Equal(t, "HeapAllocHigh", a.Name)
+	assert.Equal(t, "firing", a.State)
+	assert.Equal(t, 600e6, a.Value)
+	assert.Equal(t, map[string]string{"severity": "page"}, a.Labels)
+	assert.Equal(t, alertsT0, a.FiredAt.AsTime())
+	assert.Nil(t, a.ResolvedAt)
+
+	rules, err = client.ListRules(ctx, &pb.ListRulesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", rules.Rules[0].Health)
+	assert.Equal(t, "gauge HeapAlloc > 5e+08", rules.Rules[0].Condition)
+	assert.Equal(t, alertsT0, rules.Rules[0].LastEvaluatedAt.AsTime())
+
+	// resolved alerts are returned on request
+	require.NoError(t, st.Update(ctx, metric.NewGauge("HeapAlloc"), 100.0))
+	_, err = e.Evaluate(ctx, alertsT0.Add(time.Second))
+	require.NoError(t, err)
+
+	alerts, err = client.ListAlerts(ctx, &pb.ListAlertsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, alerts.Alerts)
+
+	alerts, err = client.ListAlerts(ctx, &pb.ListAlertsRequest{IncludeResolved: true})
+	require.NoError(t, err)
+	require.Len(t, alerts.Alerts, 1)
+	assert.Equal(t, "resolved", alerts.Alerts[0].State)
+}
+
+func TestAlertServer_WatchAlerts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, srv, e, st := startAlertServer(t)
+
+	stream, err := client.WatchAlerts(ctx, &pb.WatchAlertsRequest{})
+	require.NoError(t, err)
+
+	// the watch is active once the headers are received
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("HeapAlloc", 600e6)))
+	_, err = e.Evaluate(ctx, alertsT0)
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", event.From)
+	assert.Equal(t, "firing", event.To)
+	assert.Equal(t, alertsT0, event.At.AsTime())
+	assert.Equal(t, "HeapAlloc", event.Alert.MetricName)
+
+	require.NoError(t, st.Update(ctx, metric.NewGauge("HeapAlloc"), 100.0))
+	_, err = e.Evaluate(ctx, alertsT0.Add(time.Second))
+	require.NoError(t, err)
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", event.To)
+
+	// open streams end when the server stops
+	srv.shutdown()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
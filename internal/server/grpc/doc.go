@@ -1,4 +1,6 @@
 // Package grpc implements the gRPC server layer for the metric alerting service.
 // It provides a MetricsServer that exposes gRPC endpoints for updating metrics,
 // optionally with encryption, and supports access control by trusted subnet.
+// An AlertServer exposes alerts and alert rules and streams alert state
+// changes to watching clients.
 package grpc
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 	pb "github.com/dmitrijs2005/metric-alerting-service/internal/proto"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/secure"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/server/usecase"
@@ -17,22 +19,56 @@ import (
 func (s *MetricsServer) UpdateMetricValue(ctx context.Context, req *pb.UpdateMetricValueRequest) (*pb.UpdateMetricValueResponse, error) {
 	var response pb.UpdateMetricValueResponse
 
-	m, err := usecase.RetrieveMetric(ctx, s.storage, req.MetricType, req.MetricName)
+	var metricValue interface{} = req.MetricValue
+	if h := req.GetHistogram(); h != nil {
+		metricValue = metric.HistogramValue{Bounds: h.Bounds, Counts: h.Counts, Count: h.Count, Sum: h.Sum}
+	}
+	if sm := req.GetSummary(); sm != nil {
+		metricValue = metric.SummaryValue{RelativeAccuracy: sm.RelativeAccuracy, Positive: sm.Positive, Negative: sm.Negative,
+			Zero: sm.Zero, Count: sm.Count, Sum: sm.Sum, Min: sm.Min, Max: sm.Max}
+	}
+
+	var ts time.Time
+	if req.GetTimestamp() != nil {
+		ts = req.GetTimestamp().AsTime()
+	}
+
+	var md metric.Metadata
+	if pm := req.GetMetadata(); pm != nil {
+		md = metric.Metadata{Help: pm.Help, Unit: metric.Unit(pm.Unit), Owner: pm.Owner}
+	}
+	if err := md.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	m, err := usecase.RetrieveMetric(ctx, s.storage, req.MetricType, req.MetricName, req.Labels)
 
 	if err != nil {
 		if !errors.Is(err, common.ErrorMetricDoesNotExist) {
 			return nil, status.Error(codes.Internal, err.Error())
 		} else {
-			m, err = usecase.AddNewMetric(ctx, s.storage, req.MetricType, req.MetricName, req.MetricValue)
+			m, err = usecase.AddNewMetric(ctx, s.storage, req.MetricType, req.MetricName, req.Labels, metricValue, ts, s.HistogramBounds)
 			if err != nil {
 				return nil, err
 			}
 		}
 	} else {
-		err = usecase.UpdateMetric(ctx, s.storage, m, req.MetricValue)
+		err = usecase.UpdateMetric(ctx, s.storage, m, metricValue, ts)
+		if errors.Is(err, common.ErrorOutOfOrderSample) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		if err != nil {
 			return nil, err
 		}
+		// m is a copy taken before the update
+		m, err = usecase.RetrieveMetric(ctx, s.storage, req.MetricType, req.MetricName, req.Labels)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if err := usecase.AddMetadataIfMissing(ctx, s.storage, m.GetName(), md); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	response.Value = fmt.Sprintf("%v", m.GetValue())
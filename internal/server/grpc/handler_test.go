@@ -5,16 +5,20 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 	pb "github.com/dmitrijs2005/metric-alerting-service/internal/proto"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/secure"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // fakeMetricsServer наследуем от твоего MetricsServer, чтобы подменить UpdateMetricValue
@@ -111,7 +115,7 @@ func TestUpdateMetricValueEncrypted_HappyPath(t *testing.T) {
 	require.Equal(t, "42", resp.Value)
 
 	// 6. проверяем, что в сторедже появилась метрика
-	m, err := st.Retrieve(ctx, "gauge", "cpu")
+	m, err := st.Retrieve(ctx, "gauge", "cpu", nil)
 	require.NoError(t, err)
 	require.Equal(t, float64(42), m.GetValue())
 }
@@ -132,11 +136,118 @@ func TestMetricsServer_UpdateMetricValue_AddsNew(t *testing.T) {
 	require.Equal(t, "42", resp.Value)
 
 	// verify in storage
-	m, err := st.Retrieve(ctx, metric.MetricTypeGauge, "cpu")
+	m, err := st.Retrieve(ctx, metric.MetricTypeGauge, "cpu", nil)
 	require.NoError(t, err)
 	require.Equal(t, float64(42), m.GetValue())
 }
 
+func TestMetricsServer_UpdateMetricValue_Labels(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewMemStorage()
+	srv := &MetricsServer{storage: st}
+
+	for i, host := range []string{"web1", "web2", "web1"} {
+		req := &pb.UpdateMetricValueRequest{
+			MetricType:  "counter",
+			MetricName:  "PollCount",
+			MetricValue: fmt.Sprint(i + 1),
+			Labels:      map[string]string{"host": host},
+		}
+		_, err := srv.UpdateMetricValue(ctx, req)
+		require.NoError(t, err)
+	}
+
+	m, err := st.Retrieve(ctx, metric.MetricTypeCounter, "PollCount", metric.Labels{"host": "web1"})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), m.GetValue())
+
+	m, err = st.Retrieve(ctx, metric.MetricTypeCounter, "PollCount", metric.Labels{"host": "web2"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), m.GetValue())
+
+	_, err = srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "counter", MetricName: "PollCount", MetricValue: "1",
+		Labels: map[string]string{"host": ""}})
+	require.ErrorIs(t, err, metric.ErrorInvalidMetricLabels)
+}
+
+func TestMetricsServer_UpdateMetricValue_Histogram(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewMemStorage()
+	srv := &MetricsServer{storage: st}
+
+	h := &pb.Histogram{Bounds: []float64{0.1, 1}, Counts: []int64{1, 2, 0}, Count: 3, Sum: 1.7}
+	_, err := srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "histogram", MetricName: "latency", Histogram: h})
+	require.NoError(t, err)
+
+	resp, err := srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "histogram", MetricName: "latency", MetricValue: "0.05"})
+	require.NoError(t, err)
+	require.Equal(t, "count=4,sum=1.75,0.1=2,1=2,+Inf=0", resp.Value)
+
+	h.Bounds = []float64{0.5, 1}
+	_, err = srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "histogram", MetricName: "latency", Histogram: h})
+	require.ErrorIs(t, err, metric.ErrorInvalidMetricValue)
+}
+
+func TestMetricsServer_UpdateMetricValue_Summary(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewMemStorage()
+	srv := &MetricsServer{storage: st}
+
+	sm := &pb.Summary{RelativeAccuracy: 0.01, Positive: map[int32]int64{-80: 1}, Count: 1, Sum: 0.2, Min: 0.2, Max: 0.2}
+	_, err := srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "summary", MetricName: "latency", Summary: sm})
+	require.NoError(t, err)
+
+	resp, err := srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "summary", MetricName: "latency", MetricValue: "1"})
+	require.NoError(t, err)
+	require.Equal(t, "accuracy=0.01,count=2,sum=1.2,min=0.2,max=1,zero=0,p-80=1,p0=1", resp.Value)
+
+	sm.RelativeAccuracy = 0.05
+	_, err = srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "summary", MetricName: "latency", Summary: sm})
+	require.ErrorIs(t, err, metric.ErrorInvalidMetricValue)
+}
+
+func TestMetricsServer_UpdateMetricValue_Timestamp(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewMemStorage()
+	st.OutOfOrder = storage.OutOfOrderReject
+	srv := &MetricsServer{storage: st}
+
+	sampled := time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC)
+	_, err := srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "gauge", MetricName: "cpu", MetricValue: "10",
+		Timestamp: timestamppb.New(sampled)})
+	require.NoError(t, err)
+
+	_, err = srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "gauge", MetricName: "cpu", MetricValue: "5",
+		Timestamp: timestamppb.New(sampled.Add(-time.Second))})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	m, err := st.Retrieve(ctx, metric.MetricTypeGauge, "cpu", nil)
+	require.NoError(t, err)
+	require.Equal(t, &metric.Gauge{Name: "cpu", Value: 10, Timestamp: sampled}, m)
+}
+
+func TestMetricsServer_UpdateMetricValue_Metadata(t *testing.T) {
+	ctx := context.Background()
+	st := memory.NewMemStorage()
+	srv := &MetricsServer{storage: st}
+
+	_, err := srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "gauge", MetricName: "cpu", MetricValue: "10",
+		Metadata: &pb.Metadata{Help: "CPU utilization.", Unit: "percent"}})
+	require.NoError(t, err)
+
+	_, err = srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "gauge", MetricName: "cpu", MetricValue: "5",
+		Metadata: &pb.Metadata{Help: "other"}})
+	require.NoError(t, err)
+
+	_, err = srv.UpdateMetricValue(ctx, &pb.UpdateMetricValueRequest{MetricType: "gauge", MetricName: "cpu", MetricValue: "5",
+		Metadata: &pb.Metadata{Unit: "Percent"}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	md, err := st.RetrieveMetadata(ctx, "cpu")
+	require.NoError(t, err)
+	require.Equal(t, metric.Metadata{Help: "CPU utilization.", Unit: metric.UnitPercent}, md)
+}
+
 func TestMetricsServer_UpdateMetricValue_UpdatesExisting(t *testing.T) {
 	ctx := context.Background()
 	st := memory.NewMemStorage()
@@ -159,7 +270,7 @@ func TestMetricsServer_UpdateMetricValue_UpdatesExisting(t *testing.T) {
 	require.Equal(t, "99", resp.Value)
 
 	// проверяем в сторедже
-	m, err := st.Retrieve(ctx, metric.MetricTypeGauge, "cpu")
+	m, err := st.Retrieve(ctx, metric.MetricTypeGauge, "cpu", nil)
 	require.NoError(t, err)
 	require.Equal(t, float64(99), m.GetValue())
 }
@@ -194,7 +305,7 @@ func (b *brokenStorage) Add(ctx context.Context, m metric.Metric) error {
 func (b *brokenStorage) Update(ctx context.Context, m metric.Metric, v interface{}) error {
 	return errors.New("db error")
 }
-func (b *brokenStorage) Retrieve(ctx context.Context, mt metric.MetricType, n string) (metric.Metric, error) {
+func (b *brokenStorage) Retrieve(ctx context.Context, mt metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
 	return nil, errors.New("db error")
 }
 func (b *brokenStorage) RetrieveAll(ctx context.Context) ([]metric.Metric, error) {
@@ -26,22 +26,52 @@ func NewTrustedSubnetInterceptor(trustedSubnet *net.IPNet) grpc.UnaryServerInter
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
 
-		var realIP string
-		if md, ok := metadata.FromIncomingContext(ctx); ok {
-			values := md.Get("x-real-ip")
-			if len(values) > 0 {
-				realIP = values[0]
-			}
+		if err := checkRealIP(ctx, trustedSubnet); err != nil {
+			return nil, err
 		}
 
-		if realIP == "" {
-			return nil, status.Error(codes.PermissionDenied, "cannot find real ip header")
+		return handler(ctx, req)
+	}
+}
+
+// NewTrustedSubnetStreamInterceptor returns a gRPC StreamServerInterceptor
+// performing the same check as NewTrustedSubnetInterceptor when a stream
+// is opened.
+func NewTrustedSubnetStreamInterceptor(trustedSubnet *net.IPNet) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+
+		if err := checkRealIP(ss.Context(), trustedSubnet); err != nil {
+			return err
 		}
 
-		if !trustedSubnet.Contains(net.ParseIP(realIP)) {
-			return nil, status.Error(codes.PermissionDenied, "ip address is not in trusted subnet")
+		return handler(srv, ss)
+	}
+}
+
+// checkRealIP checks that the "x-real-ip" header of the incoming metadata
+// belongs to the trusted subnet.
+func checkRealIP(ctx context.Context, trustedSubnet *net.IPNet) error {
+
+	var realIP string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		values := md.Get("x-real-ip")
+		if len(values) > 0 {
+			realIP = values[0]
 		}
+	}
 
-		return handler(ctx, req)
+	if realIP == "" {
+		return status.Error(codes.PermissionDenied, "cannot find real ip header")
 	}
+
+	if !trustedSubnet.Contains(net.ParseIP(realIP)) {
+		return status.Error(codes.PermissionDenied, "ip address is not in trusted subnet")
+	}
+
+	return nil
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
@@ -45,3 +46,46 @@ func TestTrustedSubnetInterceptor(t *testing.T) {
 		require.Equal(t, "PermissionDenied", st.Code().String())
 	})
 }
+
+// fakeServerStream is a server stream with the given context.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestTrustedSubnetStreamInterceptor(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("192.168.0.0/24")
+	interceptor := NewTrustedSubnetStreamInterceptor(subnet)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Method", IsServerStream: true}
+
+	called := false
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		called = true
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		wantCode codes.Code
+	}{
+		{"allowed ip", metadata.New(map[string]string{"x-real-ip": "192.168.0.42"}), codes.OK},
+		{"denied ip", metadata.New(map[string]string{"x-real-ip": "10.0.0.5"}), codes.PermissionDenied},
+		{"missing header", metadata.MD{}, codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			err := interceptor(nil, &fakeServerStream{ctx: ctx}, info, handler)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			require.Equal(t, tt.wantCode == codes.OK, called)
+		})
+	}
+}
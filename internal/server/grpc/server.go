@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"net"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
 	pb "github.com/dmitrijs2005/metric-alerting-service/internal/proto"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/secure"
@@ -24,6 +25,14 @@ type MetricsServer struct {
 	logger        logger.Logger
 	trustedSubnet *net.IPNet
 	privateKey    *rsa.PrivateKey
+
+	// Alerting is the alert evaluator served through the AlertService.
+	// The service is not registered if it is nil.
+	Alerting *alerting.Evaluator
+
+	// HistogramBounds are the bucket bounds of histograms created by a single
+	// observation, metric.DefaultHistogramBounds if nil.
+	HistogramBounds []float64
 }
 
 // NewgRPCMetricsServer creates a new instance of MetricsServer.
@@ -77,18 +86,29 @@ func (s *MetricsServer) Run(ctx context.Context) error {
 	var opts []grpc.ServerOption
 
 	if s.trustedSubnet != nil {
-		opts = append(opts, grpc.UnaryInterceptor(NewTrustedSubnetInterceptor(s.trustedSubnet)))
+		opts = append(opts,
+			grpc.UnaryInterceptor(NewTrustedSubnetInterceptor(s.trustedSubnet)),
+			grpc.StreamInterceptor(NewTrustedSubnetStreamInterceptor(s.trustedSubnet)))
 	}
 
 	// creates gRPC-server
 	srv := grpc.NewServer(opts...)
 
-	// registers service
+	// registers services
 	pb.RegisterMetricServiceServer(srv, s)
 
+	var alerts *AlertServer
+	if s.Alerting != nil {
+		alerts = NewAlertServer(s.Alerting)
+		pb.RegisterAlertServiceServer(srv, alerts)
+	}
+
 	go func() {
 		<-ctx.Done()
 		s.logger.Info("Stopping gPRC server...")
+		if alerts != nil {
+			alerts.shutdown()
+		}
 		srv.GracefulStop()
 	}()
 
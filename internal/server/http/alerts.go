@@ -0,0 +1,217 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// ListAlertsHandler handles an HTTP GET request that returns pending and firing alerts
+// as a JSON array of dto.Alert, sorted by rule and metric name. Alerts muted by
+// inhibition rules list the keys of the inhibiting alerts in "inhibited_by".
+//
+// Example response:
+//
+//	[
+//	  {
+//	    "name": "HeapAllocHigh",
+//	    "metric_name": "HeapAlloc",
+//	    "metric_type": "gauge",
+//	    "state": "firing",
+//	    "value": 612345678,
+//	    "threshold": 500000000,
+//	    "active_at": "2025-01-01T12:00:00Z",
+//	    "fired_at": "2025-01-01T12:01:00Z",
+//	    "inhibited_by": ["AgentDown|counter|PollCount"]
+//	  }
+//	]
+//
+// Responses:
+//   - 200 OK: with the list of alerts
+func (s *HTTPServer) ListAlertsHandler(c echo.Context) error {
+
+	result := []dto.Alert{}
+	for _, a := range s.Alerting.Alerts() {
+		if a.State == alerting.StatePending || a.State == alerting.StateFiring {
+			result = append(result, alerting.AlertToDTO(&a))
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// AcknowledgeAlertHandler handles an HTTP POST request that acknowledges
+// a firing alert, so that escalations of its route not reached yet are not
+// notified. The acknowledgement is cleared when the alert is resolved.
+//
+// Example request:
+//
+//	POST /api/alerts/ack
+//	{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "acknowledged_by": "jane"}
+//
+// Responses:
+//   - 200 OK: with the acknowledged alert as dto.Alert
+//   - 400 Bad Request: if the body is malformed
+//   - 404 Not Found: if there is no such alert
+//   - 409 Conflict: if the alert is not firing
+func (s *HTTPServer) AcknowledgeAlertHandler(c echo.Context) error {
+
+	req := new(dto.AlertAcknowledgement)
+	if err := c.Bind(req); err != nil || req.Name == "" {
+		return jsonError(c, http.StatusBadRequest, errors.New("bad request"))
+	}
+
+	a := alerting.Alert{Rule: req.Name, MetricType: metric.MetricType(req.MetricType), MetricName: req.MetricName, MetricLabels: req.MetricLabels}
+
+	acked, err := s.Alerting.Acknowledge(a.Key(), req.AcknowledgedBy, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, alerting.ErrorAlertNotFound):
+			return jsonError(c, http.StatusNotFound, err)
+		case errors.Is(err, alerting.ErrorAlertNotFiring):
+			return jsonError(c, http.StatusConflict, err)
+		default:
+			return jsonError(c, http.StatusInternalServerError, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, alerting.AlertToDTO(&acked))
+}
+
+// TestRouteHandler handles an HTTP POST request that returns the receivers
+// a hypothetical alert would be routed to, as a JSON array of dto.RouteTarget,
+// without notifying them. Escalation receivers are included with their delay.
+//
+// Example request:
+//
+//	POST /api/routes/test
+//	{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "labels": {"severity": "page"}}
+//
+// Example response:
+//
+//	[
+//	  {"route": "root.0", "receiver": "runtime-team", "group_key": "{alertname=\"HeapAllocHigh\"}"},
+//	  {"route": "root.0", "receiver": "runtime-lead", "group_key": "{alertname=\"HeapAllocHigh\"}", "escalate_after": "15m0s"}
+//	]
+//
+// Responses:
+//   - 200 OK: with the list of receivers, empty if no receiver would be notified
+//   - 400 Bad Request: if the body is malformed
+func (s *HTTPServer) TestRouteHandler(c echo.Context) error {
+
+	req := new(dto.Alert)
+	if err := c.Bind(req); err != nil {
+		return jsonError(c, http.StatusBadRequest, errors.New("bad request"))
+	}
+
+	a := alerting.Alert{Rule: req.Name, MetricType: metric.MetricType(req.MetricType), MetricName: req.MetricName,
+		MetricLabels: req.MetricLabels, Labels: req.Labels}
+
+	targets := s.Alerting.Routes(a)
+	result := make([]dto.RouteTarget, len(targets))
+	for i := range targets {
+		result[i] = alerting.RouteTargetToDTO(&targets[i])
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// AlertHistoryHandler handles an HTTP GET request that returns a page of
+// alert state transitions, newest first, as dto.AlertHistory.
+//
+// Supported query parameters, all optional:
+//   - rule        — rule name
+//   - metric_type — metric type
+//   - metric_name — metric name
+//   - from, to    — time range in RFC 3339 format, from inclusive, to exclusive
+//   - offset      — number of transitions to skip
+//   - limit       — page size, 100 by default, at most 1000
+//
+// Example request:
+//
+//	GET /api/alerts/history?rule=HeapAllocHigh&from=2025-01-01T00:00:00Z&limit=10
+//
+// Responses:
+//   - 200 OK: with the page of transitions
+//   - 400 Bad Request: if a query parameter is invalid
+//   - 500 Internal Server Error: if the history could not be read
+func (s *HTTPServer) AlertHistoryHandler(c echo.Context) error {
+
+	ctx := c.Request().Context()
+
+	q, err := parseHistoryQuery(c)
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err)
+	}
+
+	transitions, total, err := s.Alerting.History().QueryHistory(ctx, q)
+	if err != nil {
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	result := dto.AlertHistory{Total: total, Offset: q.Offset, Limit: q.Limit, Transitions: make([]dto.AlertTransition, len(transitions))}
+	for i := range transitions {
+		result.Transitions[i] = alerting.TransitionToDTO(&transitions[i])
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// parseHistoryQuery builds a history query from the request query parameters.
+func parseHistoryQuery(c echo.Context) (alerting.HistoryQuery, error) {
+	q := alerting.HistoryQuery{
+		Rule:       c.QueryParam("rule"),
+		MetricType: metric.MetricType(c.QueryParam("metric_type")),
+		MetricName: c.QueryParam("metric_name"),
+		Limit:      defaultHistoryLimit,
+	}
+
+	var err error
+
+	if q.From, err = parseTimeParam(c, "from"); err != nil {
+		return q, err
+	}
+	if q.To, err = parseTimeParam(c, "to"); err != nil {
+		return q, err
+	}
+
+	if v := c.QueryParam("offset"); v != "" {
+		if q.Offset, err = strconv.Atoi(v); err != nil || q.Offset < 0 {
+			return q, errors.New("invalid offset")
+		}
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit <= 0 || q.Limit > maxHistoryLimit {
+			return q, fmt.Errorf("invalid limit, must be between 1 and %d", maxHistoryLimit)
+		}
+	}
+
+	return q, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 query parameter.
+func parseTimeParam(c echo.Context, name string) (time.Time, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,280 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var alertsT0 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// prepareAlertsTestServer returns a server with a firing HeapAlloc alert
+// and pending CPUutilization1 and CPUutilization2 alerts.
+func prepareAlertsTestServer(t *testing.T) *HTTPServer {
+	t.Helper()
+	ctx := context.Background()
+
+	s := prepareAlertingTestServer(t,
+		alerting.Rule{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6},
+		alerting.Rule{Name: "CPUHigh", MetricType: metric.MetricTypeGauge, MetricName: "CPUutilization*", Operator: ">", Threshold: 95, For: common.Duration{Duration: time.Hour}},
+	)
+
+	require.NoError(t, s.Storage.Add(ctx, metric.MustNewGauge("CPUutilization2", 10)))
+	require.NoError(t, s.Storage.Add(ctx, metric.MustNewGauge("HeapAlloc", 600e6)))
+	_, err := s.Alerting.Evaluate(ctx, alertsT0)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Storage.Add(ctx, metric.MustNewGauge("CPUutilization1", 99)))
+	require.NoError(t, s.Storage.Update(ctx, metric.NewGauge("CPUutilization2"), 99.0))
+	_, err = s.Alerting.Evaluate(ctx, alertsT0.Add(time.Minute))
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestHTTPServer_ListAlertsHandler(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodGet, "/api/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var alerts []dto.Alert
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &alerts))
+	require.Len(t, alerts, 3)
+
+	assert.Equal(t, "CPUutilization1", alerts[0].MetricName)
+	assert.Equal(t, "pending", alerts[0].State)
+	assert.Nil(t, alerts[0].FiredAt)
+
+	assert.Equal(t, "HeapAllocHigh", alerts[2].Name)
+	assert.Equal(t, "firing", alerts[2].State)
+	assert.Equal(t, 600e6, alerts[2].Value)
+	require.NotNil(t, alerts[2].FiredAt)
+	assert.Equal(t, alertsT0, *alerts[2].FiredAt)
+}
+
+func TestHTTPServer_ListAlertsHandler_SkipsResolved(t *testing.T) {
+	ctx := context.Background()
+	s := prepareAlertsTestServer(t)
+
+	require.NoError(t, s.Storage.Update(ctx, metric.NewGauge("HeapAlloc"), 100.0))
+	_, err := s.Alerting.Evaluate(ctx, alertsT0.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/api/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var alerts []dto.Alert
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &alerts))
+	require.Len(t, alerts, 2)
+	for _, a := range alerts {
+		assert.Equal(t, "pending", a.State)
+	}
+}
+
+func TestHTTPServer_ListAlertsHandler_Empty(t *testing.T) {
+	s := prepareAlertingTestServer(t)
+	rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/api/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+}
+
+func TestHTTPServer_AlertHistoryHandler(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+	e := s.ConfigureRoutes()
+
+	tests := []struct {
+		name        string
+		query       string
+		wantTotal   int
+		wantMetrics []string
+	}{
+		{name: "all", query: "", wantTotal: 3, wantMetrics: []string{"CPUutilization1", "CPUutilization2", "HeapAlloc"}},
+		{name: "by rule", query: "?rule=CPUHigh", wantTotal: 2, wantMetrics: []string{"CPUutilization1", "CPUutilization2"}},
+		{name: "by metric", query: "?metric_type=gauge&metric_name=HeapAlloc", wantTotal: 1, wantMetrics: []string{"HeapAlloc"}},
+		{name: "time range", query: "?from=2025-01-01T00:00:30Z&to=2025-01-01T00:02:00Z", wantTotal: 2, wantMetrics: []string{"CPUutilization1", "CPUutilization2"}},
+		{name: "page", query: "?offset=2&limit=1", wantTotal: 3, wantMetrics: []string{"HeapAlloc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodGet, "/api/alerts/history"+tt.query, "")
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var h dto.AlertHistory
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &h))
+			assert.Equal(t, tt.wantTotal, h.Total)
+
+			var names []string
+			for _, tr := range h.Transitions {
+				names = append(names, tr.Alert.MetricName)
+			}
+			assert.ElementsMatch(t, tt.wantMetrics, names)
+		})
+	}
+}
+
+func TestHTTPServer_AlertHistoryHandler_BadRequest(t *testing.T) {
+	s := prepareAlertingTestServer(t)
+	e := s.ConfigureRoutes()
+
+	for _, query := range []string{"?from=yesterday", "?to=1", "?offset=-1", "?limit=0", "?limit=5000", "?limit=x"} {
+		t.Run(query, func(t *testing.T) {
+			rec := doRequest(e, http.MethodGet, "/api/alerts/history"+query, "")
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var resp dto.Error
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.NotEmpty(t, resp.Error)
+		})
+	}
+}
+
+func TestHTTPServer_AlertHistoryHandler_Defaults(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+	rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/api/alerts/history", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var h dto.AlertHistory
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &h))
+	assert.Equal(t, defaultHistoryLimit, h.Limit)
+	assert.Equal(t, 0, h.Offset)
+}
+
+func TestHTTPServer_ListAlertsHandler_Gzip(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+
+	rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/api/alerts", "", "Accept-Encoding", "gzip")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+	r, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+
+	var alerts []dto.Alert
+	require.NoError(t, json.NewDecoder(r).Decode(&alerts))
+	assert.Len(t, alerts, 3)
+}
+
+func TestHTTPServer_AcknowledgeAlertHandler(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+	e := s.ConfigureRoutes()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"firing", `{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "acknowledged_by": "jane"}`, http.StatusOK},
+		{"pending", `{"name": "CPUHigh", "metric_type": "gauge", "metric_name": "CPUutilization1"}`, http.StatusConflict},
+		{"unknown", `{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "StackInuse"}`, http.StatusNotFound},
+		{"no name", `{"metric_type": "gauge", "metric_name": "HeapAlloc"}`, http.StatusBadRequest},
+		{"malformed", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodPost, "/api/alerts/ack", tt.body)
+			require.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+		})
+	}
+
+	rec := doRequest(e, http.MethodGet, "/api/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var alerts []dto.Alert
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &alerts))
+	require.Len(t, alerts, 3)
+	assert.Equal(t, "jane", alerts[2].AcknowledgedBy)
+	assert.NotNil(t, alerts[2].AcknowledgedAt)
+	assert.Nil(t, alerts[0].AcknowledgedAt)
+}
+
+func TestHTTPServer_AcknowledgeLabeledAlert(t *testing.T) {
+	ctx := context.Background()
+	s := prepareAlertingTestServer(t,
+		alerting.Rule{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6})
+	e := s.ConfigureRoutes()
+
+	for _, host := range []string{"web1", "web2"} {
+		require.NoError(t, s.Storage.Add(ctx, &metric.Gauge{Name: "HeapAlloc", Labels: metric.Labels{"host": host}, Value: 600e6}))
+	}
+	_, err := s.Alerting.Evaluate(ctx, alertsT0)
+	require.NoError(t, err)
+
+	rec := doRequest(e, http.MethodPost, "/api/alerts/ack", `{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "acknowledged_by": "jane"}`)
+	require.Equal(t, http.StatusNotFound, rec.Code, "the metric labels are part of the alert identity")
+
+	rec = doRequest(e, http.MethodPost, "/api/alerts/ack", `{"name": "HeapAllocHigh", "metric_type": "gauge", "metric_name": "HeapAlloc", "metric_labels": {"host": "web2"}, "acknowledged_by": "jane"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<input type="hidden" name="metric_labels" value="{host=&#34;web1&#34;}">`)
+
+	resp := postForm(e, "/alerts/ack", url.Values{"name": {"HeapAllocHigh"}, "metric_type": {"gauge"}, "metric_name": {"HeapAlloc"},
+		"metric_labels": {`{host="web1"}`}, "acknowledged_by": {"joe"}})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	rec = doRequest(e, http.MethodGet, "/api/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var alerts []dto.Alert
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &alerts))
+	require.Len(t, alerts, 2)
+	assert.Equal(t, map[string]string{"host": "web1"}, alerts[0].MetricLabels)
+	assert.Equal(t, "joe", alerts[0].AcknowledgedBy)
+	assert.Equal(t, map[string]string{"host": "web2"}, alerts[1].MetricLabels)
+	assert.Equal(t, "jane", alerts[1].AcknowledgedBy)
+}
+
+// namedReceiver is a receiver that discards notifications.
+type namedReceiver string
+
+func (r namedReceiver) Name() string {
+	return string(r)
+}
+
+func (r namedReceiver) Notify(ctx context.Context, n *alerting.Notification) error {
+	return nil
+}
+
+func TestHTTPServer_TestRouteHandler(t *testing.T) {
+	s := prepareAlertingTestServer(t)
+	s.Alerting.AddReceiver(namedReceiver("ops"))
+	s.Alerting.AddReceiver(namedReceiver("pager"))
+	require.NoError(t, s.Alerting.SetRoute(alerting.Route{
+		Receiver: "ops",
+		GroupBy:  []string{alerting.MatcherAlertName},
+		Routes: []alerting.Route{{
+			Matchers:    []alerting.Matcher{{Name: "severity", Value: "page"}},
+			Escalations: []alerting.Escalation{{After: common.Duration{Duration: 15 * time.Minute}, Receiver: "pager"}},
+		}},
+	}))
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodPost, "/api/routes/test", `{"name": "HeapAllocHigh", "labels": {"severity": "page"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var targets []dto.RouteTarget
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &targets))
+	assert.Equal(t, []dto.RouteTarget{
+		{Route: "root.0", Receiver: "ops", GroupKey: `{alertname="HeapAllocHigh"}`},
+		{Route: "root.0", Receiver: "pager", GroupKey: `{alertname="HeapAllocHigh"}`, EscalateAfter: "15m0s"},
+	}, targets)
+
+	rec = doRequest(e, http.MethodPost, "/api/routes/test", `{`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -3,6 +3,9 @@
 // This package implements endpoints for updating, retrieving, and listing application metrics.
 // It supports both individual and batch operations via JSON or path parameters,
 // and includes support for health checks and HTML rendering of metrics.
+// Labels of a metric are passed in the labels object of dto.Metrics, or, with
+// path parameters, as query parameters prefixed with "label.",
+// e.g. /update/gauge/Alloc/1?label.host=web1.
 //
 // The main handlers include:
 //
@@ -13,6 +16,24 @@
 //   - ValueJSONHandler: retrieves a metric value via JSON payload
 //   - ListHandler: renders all metrics as HTML
 //   - PingHandler: health check endpoint to verify DB connectivity
+//   - QueryHandler: evaluates an expression over stored metrics under /api/query
+//   - ListMetadataHandler, GetMetadataHandler, SetMetadataHandler, DeleteMetadataHandler:
+//     manage the help text, unit and owner of metric names under /api/metadata
+//   - ListAlertsHandler, AlertHistoryHandler, AcknowledgeAlertHandler: active
+//     alerts, alert history and acknowledgements under /api/alerts
+//   - TestRouteHandler: receivers an alert would be routed to under /api/routes/test
+//   - ListRulesHandler, GetRuleHandler, CreateRuleHandler, UpdateRuleHandler,
+//     DisableRuleHandler, EnableRuleHandler, DeleteRuleHandler: manage alert
+//     rules at runtime under /api/rules
+//   - ListSilencesHandler, CreateSilenceHandler, ExpireSilenceHandler: manage
+//     alert silences under /api/silences
+//   - ListOutboxHandler, ListDeadLettersHandler, RetryOutboxEntryHandler,
+//     DeleteOutboxEntryHandler: pending notifications and dead letters of the
+//     notification outbox under /api/outbox
+//   - RulesPageHandler, AlertsPageHandler, SilencesPageHandler, HistoryPageHandler:
+//     render rules, alerts, silences and alert history as HTML under /rules,
+//     /alerts, /silences and /history; the alerts and silences pages have forms
+//     acknowledging alerts and creating and expiring silences
 //
 // All handlers are implemented as methods on the HTTPServer struct,
 // and rely on a shared metric storage layer and logging interface.
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
@@ -171,6 +172,7 @@ func ExampleHTTPServer_ValueJSONHandler() {
 	address := "localhost:8080"
 	key := "key"
 	storage := memory.NewMemStorage()
+	storage.Clock = func() time.Time { return time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC) }
 	log := logger.GetLogger()
 	cryptoKey := ""
 
@@ -202,5 +204,5 @@ func ExampleHTTPServer_ValueJSONHandler() {
 	fmt.Printf("%d %s", resp.StatusCode, strings.TrimSpace(string(body)))
 
 	// Output:
-	// 200 {"id":"temperature","type":"gauge","value":36.6}
+	// 200 {"id":"temperature","type":"gauge","value":36.6,"created_at":"2025-09-25T10:00:00Z","updated_at":"2025-09-25T10:00:00Z"}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
@@ -16,7 +17,7 @@ import (
 
 func (s *HTTPServer) MetricFromDto(mDTO dto.Metrics) (metric.Metric, error) {
 
-	m, err := metric.NewMetric(metric.MetricType(mDTO.MType), mDTO.ID)
+	m, err := metric.NewLabeledMetric(metric.MetricType(mDTO.MType), mDTO.ID, mDTO.Labels)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +32,28 @@ func (s *HTTPServer) MetricFromDto(mDTO dto.Metrics) (metric.Metric, error) {
 		if err != nil {
 			return nil, err
 		}
+	} else if histogram, ok := m.(*metric.Histogram); ok {
+		value, err := histogramValueFromDto(mDTO)
+		if err != nil {
+			return nil, err
+		}
+		err = histogram.Update(value)
+		if err != nil {
+			return nil, err
+		}
+	} else if summary, ok := m.(*metric.Summary); ok {
+		value, err := summaryValueFromDto(mDTO)
+		if err != nil {
+			return nil, err
+		}
+		err = summary.Update(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if mDTO.Timestamp != nil {
+		metric.SetTimestamp(m, *mDTO.Timestamp)
 	}
 
 	return m, nil
@@ -39,12 +62,22 @@ func (s *HTTPServer) MetricFromDto(mDTO dto.Metrics) (metric.Metric, error) {
 
 func (s *HTTPServer) DTOFromMetric(m metric.Metric) (*dto.Metrics, error) {
 
-	o := &dto.Metrics{ID: m.GetName(), MType: string(m.GetType())}
+	o := &dto.Metrics{ID: m.GetName(), MType: string(m.GetType()), Labels: m.GetLabels()}
 
 	if gauge, ok := m.(*metric.Gauge); ok {
 		o.Value = float64Ptr(gauge.Value)
 	} else if counter, ok := m.(*metric.Counter); ok {
 		o.Delta = int64Ptr(counter.Value)
+	} else if histogram, ok := m.(*metric.Histogram); ok {
+		o.Histogram = usecase.HistogramToDto(histogram.Value.Clone())
+	} else if summary, ok := m.(*metric.Summary); ok {
+		v := summary.Value.Clone()
+		o.Summary = usecase.SummaryToDto(v)
+		o.Quantiles = usecase.QuantilesToDto(v)
+	}
+
+	if ts := m.GetTimestamp(); !ts.IsZero() {
+		o.Timestamp = &ts
 	}
 
 	return o, nil
@@ -53,12 +86,13 @@ func (s *HTTPServer) DTOFromMetric(m metric.Metric) (*dto.Metrics, error) {
 
 // UpdateJSONHandler handles an HTTP POST request with a single metric in JSON format.
 //
-// It expects a JSON body representing a metric (either `gauge` or `counter`) as defined by dto.Metrics.
+// It expects a JSON body representing a metric (`gauge`, `counter`, `histogram` or `summary`) as defined by dto.Metrics.
 //
 // If the metric is valid, it updates the internal metric storage and returns the updated metric as JSON.
 //
 // Returns:
-//   - 400 Bad Request: if the input is invalid or contains an unsupported metric type
+//   - 400 Bad Request: if the input is invalid, contains an unsupported metric type or invalid metadata
+//   - 409 Conflict: if the gauge sample is older than the stored one and such samples are rejected
 //   - 500 Internal Server Error: if updating or retrieving the metric fails
 //   - 200 OK: with the updated metric in JSON format
 //
@@ -67,12 +101,34 @@ func (s *HTTPServer) DTOFromMetric(m metric.Metric) (*dto.Metrics, error) {
 //	{
 //	  "id": "Alloc",
 //	  "type": "gauge",
-//	  "value": 123.4
+//	  "value": 123.4,
+//	  "labels": {"host": "web1"}
 //	}
 //
+// The labels are optional; metrics with the same type and name but different
+// labels are stored separately. So is the client-side `timestamp` of the
+// sample in RFC 3339 format, e.g. "2025-09-25T10:00:00Z"; a gauge sample with
+// a timestamp older than the one of the stored value is out of order and is
+// ignored or rejected, depending on the server configuration. The optional
+// `metadata` describing the metric name, e.g.
+// {"help": "Bytes of allocated heap objects.", "unit": "bytes"}, is stored
+// unless the name already has metadata.
+//
 // Supported metric types:
 //   - gauge (float64)
 //   - counter (int64)
+//   - histogram (a single observed value in `value`, or a whole histogram in
+//     `histogram`, which must have the same bounds as the stored one)
+//   - summary (a single observed value in `value`, or a whole sketch in
+//     `summary`, which must have the same accuracy as the stored one)
+//
+// Example JSON body of a histogram:
+//
+//	{
+//	  "id": "RequestDuration",
+//	  "type": "histogram",
+//	  "histogram": {"bounds": [0.1, 1], "counts": [3, 1, 0], "count": 4, "sum": 0.9}
+//	}
 func (s *HTTPServer) UpdateJSONHandler(c echo.Context) error {
 
 	ctx := c.Request().Context()
@@ -97,12 +153,38 @@ func (s *HTTPServer) UpdateJSONHandler(c echo.Context) error {
 			return c.String(http.StatusBadRequest, msg)
 		}
 		metricValue = *mDTO.Value
+	case metric.MetricTypeHistogram:
+		value, err := histogramValueFromDto(*mDTO)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		metricValue = value
+	case metric.MetricTypeSummary:
+		value, err := summaryValueFromDto(*mDTO)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		metricValue = value
 	}
 
-	m, err := usecase.UpdateMetricByValue(ctx, s.Storage, mDTO.MType, mDTO.ID, metricValue)
+	var ts time.Time
+	if mDTO.Timestamp != nil {
+		ts = *mDTO.Timestamp
+	}
+
+	md := usecase.MetadataFromDto(mDTO.Metadata)
+	if err := md.Validate(); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	m, err := usecase.UpdateMetricByValue(ctx, s.Storage, mDTO.MType, mDTO.ID, mDTO.Labels, metricValue, ts, s.HistogramBounds)
 	if err != nil {
 
-		isBadRequest := errors.Is(err, metric.ErrorInvalidMetricName) || errors.Is(err, metric.ErrorInvalidMetricType) || errors.Is(err, metric.ErrorInvalidMetricValue)
+		if errors.Is(err, common.ErrorOutOfOrderSample) {
+			return c.String(http.StatusConflict, err.Error())
+		}
+
+		isBadRequest := errors.Is(err, metric.ErrorInvalidMetricName) || errors.Is(err, metric.ErrorInvalidMetricType) || errors.Is(err, metric.ErrorInvalidMetricValue) || errors.Is(err, metric.ErrorInvalidMetricLabels)
 
 		if isBadRequest {
 			return c.String(http.StatusBadRequest, err.Error())
@@ -111,7 +193,11 @@ func (s *HTTPServer) UpdateJSONHandler(c echo.Context) error {
 		}
 	}
 
-	updated, err := s.Storage.Retrieve(ctx, m.GetType(), m.GetName())
+	if err := usecase.AddMetadataIfMissing(ctx, s.Storage, m.GetName(), md); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	updated, err := s.Storage.Retrieve(ctx, m.GetType(), m.GetName(), m.GetLabels())
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
@@ -128,14 +214,18 @@ func (s *HTTPServer) UpdateJSONHandler(c echo.Context) error {
 // UpdateHandler handles an HTTP POST request that updates a metric using URL path parameters.
 //
 // Expected URL path parameters:
-//   - :type  — metric type ("gauge" or "counter")
+//   - :type  — metric type ("gauge", "counter", "histogram" or "summary")
 //   - :name  — metric name
-//   - :value — metric value (float64 for gauge, int64 for counter)
+//   - :value — metric value (float64 for gauge, int64 for counter, a float64
+//     observation for histogram and summary)
+//
+// Labels of the metric, if any, are passed as query parameters prefixed with
+// "label."; other query parameters are ignored.
 //
 // Example request:
 //
 //	POST /update/counter/requests/42
-//	POST /update/gauge/temperature/36.6
+//	POST /update/gauge/temperature/36.6?label.host=web1
 //
 // Responses:
 //   - 200 OK: if the metric was successfully updated
@@ -149,11 +239,11 @@ func (s *HTTPServer) UpdateHandler(c echo.Context) error {
 	metricName := c.Param("name")
 	metricValue := c.Param("value")
 
-	_, err := usecase.UpdateMetricByValue(ctx, s.Storage, metricType, metricName, metricValue)
+	_, err := usecase.UpdateMetricByValue(ctx, s.Storage, metricType, metricName, labelsFromQuery(c), metricValue, time.Time{}, s.HistogramBounds)
 
 	if err != nil {
 
-		isBadRequest := errors.Is(err, metric.ErrorInvalidMetricName) || errors.Is(err, metric.ErrorInvalidMetricType) || errors.Is(err, metric.ErrorInvalidMetricValue)
+		isBadRequest := errors.Is(err, metric.ErrorInvalidMetricName) || errors.Is(err, metric.ErrorInvalidMetricType) || errors.Is(err, metric.ErrorInvalidMetricValue) || errors.Is(err, metric.ErrorInvalidMetricLabels)
 
 		if isBadRequest {
 			return c.String(http.StatusBadRequest, err.Error())
@@ -168,9 +258,15 @@ func (s *HTTPServer) UpdateHandler(c echo.Context) error {
 
 // ValueJSONHandler handles an HTTP POST request that retrieves the current value of a metric specified in JSON format.
 //
-// It expects a JSON body with the metric's `id` and `type` (either "gauge" or "counter").
+// It expects a JSON body with the metric's `id` and `type` ("gauge", "counter",
+// "histogram" or "summary"), and the `labels` of the metric if it has any.
 //
-// If the metric exists, the response includes the same metric object with the `value` or `delta` field populated.
+// If the metric exists, the response includes the same metric object with the
+// `value`, `delta` or `histogram` field populated, or for a summary the
+// `summary` field and the estimated `quantiles` (see metric.DefaultSummaryQuantiles).
+// It also includes the `created_at` and `updated_at` times of the metric if
+// the storage tracks them, the client-side `timestamp` of its value if sent,
+// and the `metadata` of the metric name if it has any.
 //
 // Example request:
 //
@@ -185,7 +281,10 @@ func (s *HTTPServer) UpdateHandler(c echo.Context) error {
 //	{
 //	  "id": "Alloc",
 //	  "type": "gauge",
-//	  "value": 123.45
+//	  "value": 123.45,
+//	  "created_at": "2025-09-25T09:00:00Z",
+//	  "updated_at": "2025-09-25T10:00:01Z",
+//	  "timestamp": "2025-09-25T10:00:00Z"
 //	}
 //
 // Responses:
@@ -205,9 +304,9 @@ func (s *HTTPServer) ValueJSONHandler(c echo.Context) error {
 	metricType := mDTO.MType
 	metricName := mDTO.ID
 
-	m, err := s.Storage.Retrieve(ctx, metric.MetricType(metricType), metricName)
+	tm, err := usecase.RetrieveTimestampedMetric(ctx, s.Storage, metricType, metricName, mDTO.Labels)
 
-	if m == nil {
+	if tm.Metric == nil {
 		if errors.Is(err, common.ErrorMetricDoesNotExist) {
 			return c.String(http.StatusNotFound, err.Error())
 		} else {
@@ -215,12 +314,20 @@ func (s *HTTPServer) ValueJSONHandler(c echo.Context) error {
 		}
 	}
 
-	err = usecase.FillValue(m, mDTO)
+	err = usecase.FillValue(tm.Metric, mDTO)
 
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
+	usecase.FillTimestamps(tm, mDTO)
+
+	md, err := usecase.RetrieveMetadata(ctx, s.Storage, metricName)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	usecase.FillMetadata(md, mDTO)
+
 	return c.JSON(http.StatusOK, mDTO)
 }
 
@@ -228,17 +335,23 @@ func (s *HTTPServer) ValueJSONHandler(c echo.Context) error {
 // specified via URL path parameters.
 //
 // Expected URL path parameters:
-//   - :type — metric type ("gauge" or "counter")
+//   - :type — metric type ("gauge", "counter", "histogram" or "summary")
 //   - :name — metric name
 //
+// Labels of the metric, if any, are passed as query parameters prefixed with
+// "label."; other query parameters are ignored. A histogram is returned in its
+// text form, e.g. "count=3,sum=1.7,0.1=1,1=2,+Inf=0", and a summary as its
+// count, sum and estimated quantiles, e.g. "count=3,sum=2.2,0.5=0.99,...".
+//
 // Example request:
 //
 //	GET /value/counter/requests
-//	GET /value/gauge/temperature
+//	GET /value/gauge/temperature?label.host=web1
 //
 // Responses:
 //   - 200 OK: returns the current value of the requested metric as plain text
 //   - 404 Not Found: if the metric does not exist
+//   - 500 Internal Server Error: if the metric could not be retrieved
 func (s *HTTPServer) ValueHandler(c echo.Context) error {
 
 	ctx := c.Request().Context()
@@ -246,19 +359,30 @@ func (s *HTTPServer) ValueHandler(c echo.Context) error {
 	metricType := c.Param("type")
 	metricName := c.Param("name")
 
-	m, err := s.Storage.Retrieve(ctx, metric.MetricType(metricType), metricName)
+	m, err := s.Storage.Retrieve(ctx, metric.MetricType(metricType), metricName, labelsFromQuery(c))
 
-	if m == nil && errors.Is(err, common.ErrorMetricDoesNotExist) {
+	if errors.Is(err, common.ErrorMetricDoesNotExist) {
 		return c.String(http.StatusNotFound, err.Error())
 	}
 
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	if summary, ok := m.GetValue().(metric.SummaryValue); ok {
+		return c.String(http.StatusOK, summary.QuantilesString())
+	}
+
 	return c.String(http.StatusOK, fmt.Sprintf("%v", m.GetValue()))
 }
 
 // ListHandler handles an HTTP GET request that renders a list of all stored metrics.
 //
-// It retrieves all available metrics from the storage, sorts them alphabetically by name,
-// and renders them using the "list.html" template.
+// It retrieves all available metrics from the storage, with the times they were
+// added and last updated if the storage tracks them, sorts them alphabetically
+// by name and then by labels, and renders them using the "list.html" template.
+// Values of gauges and counters are formatted in the unit from the metadata of
+// their name, e.g. bytes as "1.5MiB", and the help text is shown on hover.
 //
 // Responses:
 //   - 200 OK: renders the list of metrics
@@ -266,17 +390,52 @@ func (s *HTTPServer) ValueHandler(c echo.Context) error {
 func (s *HTTPServer) ListHandler(c echo.Context) error {
 
 	ctx := c.Request().Context()
-	metrics, err := s.Storage.RetrieveAll(ctx)
+	metrics, err := usecase.RetrieveAllTimestampedMetrics(ctx, s.Storage)
+
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
 
+	metadata, err := usecase.RetrieveAllMetadata(ctx, s.Storage)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
 	sort.Slice(metrics, func(i, j int) bool {
-		return metrics[i].GetName() < metrics[j].GetName()
+		if metrics[i].Metric.GetName() != metrics[j].Metric.GetName() {
+			return metrics[i].Metric.GetName() < metrics[j].Metric.GetName()
+		}
+		return metrics[i].Metric.GetLabels().String() < metrics[j].Metric.GetLabels().String()
 	})
 
-	return c.Render(http.StatusOK, "list.html", metrics)
+	rows := make([]metricRow, len(metrics))
+	for i, m := range metrics {
+		rows[i] = metricRow{TimestampedMetric: m, Metadata: metadata[m.Metric.GetName()]}
+	}
+
+	return c.Render(http.StatusOK, "list.html", rows)
+}
+
+// metricRow is a metric shown on the list page, with the metadata of its name.
+type metricRow struct {
+	storage.TimestampedMetric
+	Metadata metric.Metadata
+}
+
+// FormattedValue returns the value of a gauge or counter formatted in the unit
+// from the metadata, or an empty string for other metrics and metrics without a unit.
+func (r metricRow) FormattedValue() string {
+	if r.Metadata.Unit == "" {
+		return ""
+	}
+	switch v := r.Metric.GetValue().(type) {
+	case float64:
+		return r.Metadata.Unit.Format(v)
+	case int64:
+		return r.Metadata.Unit.Format(float64(v))
+	default:
+		return ""
+	}
 }
 
 // PingHandler handles a health check request to verify database connectivity.
@@ -309,7 +468,7 @@ func (s *HTTPServer) PingHandler(c echo.Context) error {
 
 // UpdatesJSONHandler handles an HTTP POST request that updates multiple metrics in batch via JSON.
 //
-// It expects a JSON array of metric objects (either `gauge` or `counter`) as input.
+// It expects a JSON array of metric objects (`gauge`, `counter`, `histogram` or `summary`) as input.
 // Each object is converted to an internal metric and passed to a batch update operation.
 //
 // After updating, it retrieves and returns the updated metrics with their current values.
@@ -329,9 +488,13 @@ func (s *HTTPServer) PingHandler(c echo.Context) error {
 //	  {"id": "temperature", "type": "gauge", "value": 36.6}
 //	]
 //
+// Out-of-order gauge samples and metadata are handled as in UpdateJSONHandler;
+// a rejected sample fails the whole batch if the storage updates it atomically.
+//
 // Responses:
 //   - 200 OK: if all metrics were successfully updated
 //   - 400 Bad Request: if input is malformed or update fails
+//   - 409 Conflict: if a gauge sample is older than the stored one and such samples are rejected
 //   - 500 Internal Server Error: if retrieval or transformation fails
 func (s *HTTPServer) UpdatesJSONHandler(c echo.Context) error {
 
@@ -349,18 +512,30 @@ func (s *HTTPServer) UpdatesJSONHandler(c echo.Context) error {
 		if err != nil {
 			return c.String(http.StatusBadRequest, "bad request")
 		}
+		if err := usecase.MetadataFromDto(o.Metadata).Validate(); err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
 		metrics[i] = m
 	}
 
 	err := s.Storage.UpdateBatch(ctx, &metrics)
+	if errors.Is(err, common.ErrorOutOfOrderSample) {
+		return c.String(http.StatusConflict, err.Error())
+	}
 	if err != nil {
 		return c.String(http.StatusBadRequest, err.Error())
 	}
 
+	for _, o := range *mDTO {
+		if err := usecase.AddMetadataIfMissing(ctx, s.Storage, o.ID, usecase.MetadataFromDto(o.Metadata)); err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+	}
+
 	results := make([]dto.Metrics, len(*mDTO))
 
 	for i, o := range *mDTO {
-		updated, err := s.Storage.Retrieve(ctx, metric.MetricType(o.MType), o.ID)
+		updated, err := s.Storage.Retrieve(ctx, metric.MetricType(o.MType), o.ID, o.Labels)
 		if err != nil {
 			return c.String(http.StatusInternalServerError, err.Error())
 		}
@@ -12,10 +12,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/assets"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/server/usecase"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
@@ -39,7 +41,7 @@ func (f faultyStorage) Add(ctx context.Context, m metric.Metric) error {
 func (f faultyStorage) Update(ctx context.Context, m metric.Metric, v interface{}) error {
 	return errors.New("forced error in Update")
 }
-func (f faultyStorage) Retrieve(ctx context.Context, t metric.MetricType, n string) (metric.Metric, error) {
+func (f faultyStorage) Retrieve(ctx context.Context, t metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
 	return nil, errors.New("forced error in Retrieve")
 }
 func (f faultyStorage) RetrieveAll(ctx context.Context) ([]metric.Metric, error) {
@@ -177,15 +179,17 @@ func TestHTTPServer_ValueHandler(t *testing.T) {
 		code        int
 	}
 	tests := []struct {
-		name   string
-		method string
-		url    string
-		want   want
+		name    string
+		method  string
+		url     string
+		storage storage.Storage
+		want    want
 	}{
 
 		{name: "Counter OK", method: http.MethodGet, url: "/value/counter/counter1", want: want{code: 200, response: fmt.Sprintf("%v", m1.GetValue()), contentType: "text/plain; charset=UTF-8"}},
 		{name: "Gauge OK", method: http.MethodGet, url: "/value/gauge/gauge1", want: want{code: 200, response: fmt.Sprintf("%v", m2.GetValue()), contentType: "text/plain; charset=UTF-8"}},
 		{name: "Unnown metric", method: http.MethodGet, url: "/value/gauge/unknwn", want: want{code: 404, response: common.ErrorMetricDoesNotExist.Error(), contentType: "text/plain; charset=UTF-8"}},
+		{name: "Bad storage", method: http.MethodGet, url: "/value/gauge/gauge1", storage: faultyStorage{}, want: want{code: 500, response: "forced error in Retrieve", contentType: "text/plain; charset=UTF-8"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -193,6 +197,9 @@ func TestHTTPServer_ValueHandler(t *testing.T) {
 				Address: addr,
 				Storage: stor,
 			}
+			if tt.storage != nil {
+				s.Storage = tt.storage
+			}
 
 			e := echo.New()
 
@@ -347,7 +354,7 @@ func (c *MockDBClient) Update(ctx context.Context, m metric.Metric, v interface{
 	return nil
 }
 
-func (c *MockDBClient) Retrieve(ctx context.Context, m metric.MetricType, n string) (metric.Metric, error) {
+func (c *MockDBClient) Retrieve(ctx context.Context, m metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
 	return nil, nil
 }
 
@@ -569,7 +576,7 @@ func TestHTTPServer_UpdatesJSONHandler(t *testing.T) {
 
 			if tt.wantCode == 200 {
 				for _, mwant := range tt.want {
-					m, err := usecase.RetrieveMetric(ctx, s.Storage, string(mwant.GetType()), mwant.GetName())
+					m, err := usecase.RetrieveMetric(ctx, s.Storage, string(mwant.GetType()), mwant.GetName(), mwant.GetLabels())
 					if (err != nil) != tt.wantErr {
 						t.Errorf("HTTPServer.TestHTTPServer_UpdatesJSONHandler() error = %v, wantErr %v", err, tt.wantErr)
 						return
@@ -667,3 +674,198 @@ func BenchmarkHTTPServer_ValueJSONHandler(b *testing.B) {
 		_ = s.ValueJSONHandler(c)
 	}
 }
+
+func TestHTTPServer_Labels(t *testing.T) {
+	st := memory.NewMemStorage()
+	st.Clock = func() time.Time { return time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC) }
+	s, err := NewHTTPServer(":8080", "", st, logger.GetLogger(), "", "")
+	require.NoError(t, err)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodPost, "/update/gauge/Alloc/1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/update/gauge/Alloc/2?label.host=web1&_=123", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/update/gauge/Alloc/3?label.host=", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "Alloc", "type": "gauge", "value": 4, "labels": {"host": "web2"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "Alloc", "type": "gauge", "value": 4, "labels": {"host": "web2"}}`, rec.Body.String())
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "Alloc", "type": "gauge", "value": 4, "labels": {"1host": "web2"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(e, http.MethodPost, "/updates/", `[{"id": "Alloc", "type": "gauge", "value": 5, "labels": {"host": "web1"}}]`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"id": "Alloc", "type": "gauge", "value": 5, "labels": {"host": "web1"}}]`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/value/gauge/Alloc", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/value/gauge/Alloc?_=123", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/value/gauge/Alloc?label.host=web1&utm_source=mail", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "5", rec.Body.String())
+	rec = doRequest(e, http.MethodGet, "/value/gauge/Alloc?label.host=web3", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(e, http.MethodPost, "/value/", `{"id": "Alloc", "type": "gauge", "labels": {"host": "web2"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "Alloc", "type": "gauge", "value": 4, "labels": {"host": "web2"},
+		"created_at": "2025-09-25T10:00:00Z", "updated_at": "2025-09-25T10:00:00Z"}`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	unlabeled := strings.Index(body, "<td>Alloc</td>")
+	web1 := strings.Index(body, "<td>Alloc{host=&#34;web1&#34;}</td>")
+	web2 := strings.Index(body, "<td>Alloc{host=&#34;web2&#34;}</td>")
+	assert.True(t, unlabeled >= 0 && unlabeled < web1 && web1 < web2, body)
+}
+
+func TestHTTPServer_Histogram(t *testing.T) {
+	st := memory.NewMemStorage()
+	st.Clock = func() time.Time { return time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC) }
+	s, err := NewHTTPServer(":8080", "", st, logger.GetLogger(), "", "")
+	require.NoError(t, err)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodPost, "/update/", `{"id": "latency", "type": "histogram",
+		"histogram": {"bounds": [0.1, 1], "counts": [1, 2, 0], "count": 3, "sum": 1.7}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "latency", "type": "histogram",
+		"histogram": {"bounds": [0.1, 1], "counts": [1, 2, 0], "count": 3, "sum": 1.7}}`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "latency", "type": "histogram", "value": 0.05}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/update/histogram/latency/5", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/updates/", `[{"id": "latency", "type": "histogram",
+		"histogram": {"bounds": [0.1, 1], "counts": [0, 1, 0], "count": 1, "sum": 0.5}}]`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"id": "latency", "type": "histogram",
+		"histogram": {"bounds": [0.1, 1], "counts": [2, 3, 1], "count": 6, "sum": 7.25}}]`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/value/histogram/latency", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "count=6,sum=7.25,0.1=2,1=3,+Inf=1", rec.Body.String())
+
+	s.HistogramBounds = []float64{0.5, 1}
+	rec = doRequest(e, http.MethodPost, "/update/histogram/size/0.3", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodGet, "/value/histogram/size", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "count=1,sum=0.3,0.5=1,1=0,+Inf=0", rec.Body.String())
+
+	rec = doRequest(e, http.MethodPost, "/value/", `{"id": "latency", "type": "histogram"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "latency", "type": "histogram",
+		"histogram": {"bounds": [0.1, 1], "counts": [2, 3, 1], "count": 6, "sum": 7.25},
+		"created_at": "2025-09-25T10:00:00Z", "updated_at": "2025-09-25T10:00:00Z"}`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<td>latency</td><td>count 6, sum 7.25, le 0.1: 2, le 1: 5, le &#43;Inf: 6</td>")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no value", `{"id": "latency", "type": "histogram"}`},
+		{"other bounds", `{"id": "latency", "type": "histogram", "histogram": {"bounds": [0.5], "counts": [1, 0], "count": 1, "sum": 0.2}}`},
+		{"inconsistent count", `{"id": "latency", "type": "histogram", "histogram": {"bounds": [0.1, 1], "counts": [1, 0, 0], "count": 2, "sum": 0.2}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodPost, "/update/", tt.body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestHTTPServer_Timestamps(t *testing.T) {
+	st := memory.NewMemStorage()
+	st.Clock = func() time.Time { return time.Date(2025, 9, 25, 10, 0, 5, 0, time.UTC) }
+	s, err := NewHTTPServer(":8080", "", st, logger.GetLogger(), "", "")
+	require.NoError(t, err)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodPost, "/update/", `{"id": "temperature", "type": "gauge", "value": 20, "timestamp": "2025-09-25T10:00:00Z"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "temperature", "type": "gauge", "value": 20, "timestamp": "2025-09-25T10:00:00Z"}`, rec.Body.String())
+
+	// an older sample is ignored by default
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "temperature", "type": "gauge", "value": 19, "timestamp": "2025-09-25T09:59:00Z"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "temperature", "type": "gauge", "value": 20, "timestamp": "2025-09-25T10:00:00Z"}`, rec.Body.String())
+
+	st.OutOfOrder = storage.OutOfOrderReject
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "temperature", "type": "gauge", "value": 19, "timestamp": "2025-09-25T09:59:00Z"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/updates/", `[{"id": "temperature", "type": "gauge", "value": 19, "timestamp": "2025-09-25T09:59:00Z"}]`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doRequest(e, http.MethodPost, "/updates/", `[{"id": "temperature", "type": "gauge", "value": 21, "timestamp": "2025-09-25T10:00:01Z"}]`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(e, http.MethodPost, "/value/", `{"id": "temperature", "type": "gauge"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "temperature", "type": "gauge", "value": 21, "timestamp": "2025-09-25T10:00:01Z",
+		"created_at": "2025-09-25T10:00:05Z", "updated_at": "2025-09-25T10:00:05Z"}`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<td>temperature</td><td>21</td><td>2025-09-25 10:00:01 UTC</td><td>2025-09-25 10:00:05 UTC</td><td>2025-09-25 10:00:05 UTC</td>")
+}
+
+func TestHTTPServer_Summary(t *testing.T) {
+	st := memory.NewMemStorage()
+	st.Clock = func() time.Time { return time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC) }
+	s, err := NewHTTPServer(":8080", "", st, logger.GetLogger(), "", "")
+	require.NoError(t, err)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodPost, "/update/", `{"id": "latency", "type": "summary",
+		"summary": {"relative_accuracy": 0.01, "positive": {"-80": 1}, "count": 1, "sum": 0.2, "min": 0.2, "max": 0.2}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "latency", "type": "summary",
+		"summary": {"relative_accuracy": 0.01, "positive": {"-80": 1}, "count": 1, "sum": 0.2, "min": 0.2, "max": 0.2},
+		"quantiles": {"0.5": 0.2, "0.9": 0.2, "0.95": 0.2, "0.99": 0.2}}`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodPost, "/update/summary/latency/1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/updates/", `[{"id": "latency", "type": "summary", "value": 1}]`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(e, http.MethodGet, "/value/summary/latency", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "count=3,sum=2.2,0.5=0.99,0.9=0.99,0.95=0.99,0.99=0.99", rec.Body.String())
+
+	rec = doRequest(e, http.MethodPost, "/value/", `{"id": "latency", "type": "summary"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id": "latency", "type": "summary",
+		"summary": {"relative_accuracy": 0.01, "positive": {"-80": 1, "0": 2}, "count": 3, "sum": 2.2, "min": 0.2, "max": 1},
+		"quantiles": {"0.5": 0.99, "0.9": 0.99, "0.95": 0.99, "0.99": 0.99},
+		"created_at": "2025-09-25T10:00:00Z", "updated_at": "2025-09-25T10:00:00Z"}`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<td>latency</td><td>count 3, sum 2.2, quantile 0.5: 0.99, quantile 0.9: 0.99, quantile 0.95: 0.99, quantile 0.99: 0.99</td>")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no value", `{"id": "latency", "type": "summary"}`},
+		{"other accuracy", `{"id": "latency", "type": "summary", "summary": {"relative_accuracy": 0.05, "zero": 1, "count": 1}}`},
+		{"inconsistent count", `{"id": "latency", "type": "summary", "summary": {"relative_accuracy": 0.01, "zero": 1, "count": 2}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodPost, "/update/", tt.body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/server/usecase"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// metadataStorage returns the storage as storage.MetadataStorage.
+// The metadata routes are only registered if the storage implements it.
+func (s *HTTPServer) metadataStorage() storage.MetadataStorage {
+	ms, _ := s.Storage.(storage.MetadataStorage)
+	return ms
+}
+
+// ListMetadataHandler handles an HTTP GET request that returns the metadata of
+// all metric names that have one, sorted by name, as a JSON array of dto.Metadata.
+//
+// Responses:
+//   - 200 OK: with the list of metadata
+//   - 500 Internal Server Error: if the metadata could not be retrieved
+func (s *HTTPServer) ListMetadataHandler(c echo.Context) error {
+
+	all, err := s.metadataStorage().RetrieveAllMetadata(c.Request().Context())
+	if err != nil {
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	result := make([]*dto.Metadata, 0, len(all))
+	for name, md := range all {
+		result = append(result, usecase.MetadataToDto(name, md))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetMetadataHandler handles an HTTP GET request that returns the metadata of the given metric name.
+//
+// Responses:
+//   - 200 OK: with the metadata
+//   - 404 Not Found: if the name has no metadata
+//   - 500 Internal Server Error: if the metadata could not be retrieved
+func (s *HTTPServer) GetMetadataHandler(c echo.Context) error {
+
+	name := c.Param("name")
+
+	md, err := s.metadataStorage().RetrieveMetadata(c.Request().Context(), name)
+	if err != nil {
+		if errors.Is(err, common.ErrorMetadataDoesNotExist) {
+			return jsonError(c, http.StatusNotFound, err)
+		}
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, usecase.MetadataToDto(name, md))
+}
+
+// SetMetadataHandler handles an HTTP PUT request that sets the metadata of the
+// given metric name, replacing the existing one. The name in the body can be
+// omitted but must not differ from the one in the path. Metadata may be set
+// before any metric with the name is reported.
+//
+// Example request:
+//
+//	PUT /api/metadata/HeapAlloc
+//	{
+//	  "help": "Bytes of allocated heap objects.",
+//	  "unit": "bytes",
+//	  "owner": "runtime"
+//	}
+//
+// Responses:
+//   - 200 OK: with the stored metadata
+//   - 400 Bad Request: if the body is malformed, or the name or the unit is invalid
+//   - 500 Internal Server Error: if the metadata could not be stored
+func (s *HTTPServer) SetMetadataHandler(c echo.Context) error {
+
+	name := c.Param("name")
+	if !metric.IsMetricNameValid(name) {
+		return jsonError(c, http.StatusBadRequest, metric.ErrorInvalidMetricName)
+	}
+
+	d := dto.Metadata{}
+	if err := c.Bind(&d); err != nil {
+		return jsonError(c, http.StatusBadRequest, errors.New("bad request"))
+	}
+	if d.Name != "" && d.Name != name {
+		return jsonError(c, http.StatusBadRequest, errors.New("name differs from the path"))
+	}
+
+	md := usecase.MetadataFromDto(&d)
+	if err := md.Validate(); err != nil {
+		return jsonError(c, http.StatusBadRequest, err)
+	}
+
+	if err := s.metadataStorage().SetMetadata(c.Request().Context(), name, md); err != nil {
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, usecase.MetadataToDto(name, md))
+}
+
+// DeleteMetadataHandler handles an HTTP DELETE request that removes the metadata of the given metric name.
+//
+// Responses:
+//   - 204 No Content: if the metadata was removed
+//   - 404 Not Found: if the name has no metadata
+//   - 500 Internal Server Error: if the metadata could not be removed
+func (s *HTTPServer) DeleteMetadataHandler(c echo.Context) error {
+
+	if err := s.metadataStorage().DeleteMetadata(c.Request().Context(), c.Param("name")); err != nil {
+		if errors.Is(err, common.ErrorMetadataDoesNotExist) {
+			return jsonError(c, http.StatusNotFound, err)
+		}
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_Metadata(t *testing.T) {
+	s, err := NewHTTPServer(":8080", "", faultyStorage{}, logger.GetLogger(), "", "")
+	require.NoError(t, err)
+	rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/api/metadata", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "no routes without a metadata storage")
+
+	s, err = NewHTTPServer(":8080", "", memory.NewMemStorage(), logger.GetLogger(), "", "")
+	require.NoError(t, err)
+	e := s.ConfigureRoutes()
+
+	rec = doRequest(e, http.MethodPut, "/api/metadata/HeapAlloc", `{"help": "Bytes of allocated heap objects.", "unit": "bytes", "owner": "runtime"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"name": "HeapAlloc", "help": "Bytes of allocated heap objects.", "unit": "bytes", "owner": "runtime"}`, rec.Body.String())
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		want   int
+	}{
+		{"invalid name", http.MethodPut, "/api/metadata/1x", `{"help": "x"}`, http.StatusBadRequest},
+		{"invalid unit", http.MethodPut, "/api/metadata/HeapAlloc", `{"unit": "Bytes"}`, http.StatusBadRequest},
+		{"name differs", http.MethodPut, "/api/metadata/HeapAlloc", `{"name": "Sys"}`, http.StatusBadRequest},
+		{"malformed", http.MethodPut, "/api/metadata/HeapAlloc", `{`, http.StatusBadRequest},
+		{"unknown", http.MethodGet, "/api/metadata/Sys", "", http.StatusNotFound},
+		{"delete unknown", http.MethodDelete, "/api/metadata/Sys", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, tt.method, tt.url, tt.body)
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+
+	// metadata sent with values does not replace the existing one
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "HeapAlloc", "type": "gauge", "value": 1572864, "metadata": {"help": "other"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "PollCount", "type": "counter", "delta": 2}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/updates/", `[{"id": "PollCount", "type": "counter", "delta": 3, "metadata": {"help": "Number of polls."}}]`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(e, http.MethodPost, "/update/", `{"id": "Sys", "type": "gauge", "value": 1, "metadata": {"unit": "Bytes"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(e, http.MethodGet, "/api/metadata", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"name": "HeapAlloc", "help": "Bytes of allocated heap objects.", "unit": "bytes", "owner": "runtime"},
+		{"name": "PollCount", "help": "Number of polls."}]`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodPost, "/value/", `{"id": "HeapAlloc", "type": "gauge"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"metadata":{"help":"Bytes of allocated heap objects.","unit":"bytes","owner":"runtime"}`)
+
+	// the UI formats values in their unit
+	rec = doRequest(e, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<td title="Bytes of allocated heap objects.">HeapAlloc</td><td>1.5MiB</td>`)
+	assert.Contains(t, rec.Body.String(), `<td title="Number of polls.">PollCount</td><td>5</td>`)
+	assert.Contains(t, rec.Body.String(), `<td>runtime</td>`)
+
+	rec = doRequest(e, http.MethodDelete, "/api/metadata/HeapAlloc", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doRequest(e, http.MethodGet, "/api/metadata/HeapAlloc", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
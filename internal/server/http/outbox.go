@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/labstack/echo/v4"
+)
+
+// ListOutboxHandler handles an HTTP GET request that returns the notifications
+// waiting for delivery, oldest first, as a JSON array of dto.OutboxEntry.
+//
+// Responses:
+//   - 200 OK: with the list of pending entries
+func (s *HTTPServer) ListOutboxHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, outboxEntriesToDTO(s.Alerting.Outbox().Pending()))
+}
+
+// ListDeadLettersHandler handles an HTTP GET request that returns the
+// notifications whose delivery has been given up, oldest first, as a JSON
+// array of dto.OutboxEntry.
+//
+// Responses:
+//   - 200 OK: with the list of dead letters
+func (s *HTTPServer) ListDeadLettersHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, outboxEntriesToDTO(s.Alerting.Outbox().DeadLetters()))
+}
+
+// RetryOutboxEntryHandler handles an HTTP POST request that resets the
+// attempts of the outbox entry with the given ID and delivers it immediately.
+// A dead letter becomes pending again.
+//
+// Example request:
+//
+//	POST /api/outbox/4f2a9c1e7b3d5a60/retry
+//
+// Responses:
+//   - 200 OK: with the pending entry
+//   - 404 Not Found: if the entry does not exist
+//   - 500 Internal Server Error: if the entry could not be stored
+func (s *HTTPServer) RetryOutboxEntryHandler(c echo.Context) error {
+
+	e, err := s.Alerting.Outbox().Retry(c.Request().Context(), c.Param("id"), time.Now())
+	if err != nil {
+		if errors.Is(err, alerting.ErrorOutboxEntryNotFound) {
+			return jsonError(c, http.StatusNotFound, err)
+		}
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, alerting.OutboxEntryToDTO(&e))
+}
+
+// DeleteOutboxEntryHandler handles an HTTP DELETE request that removes the
+// outbox entry with the given ID without delivering it, e.g. a dead letter
+// that has been dealt with.
+//
+// Example request:
+//
+//	DELETE /api/outbox/4f2a9c1e7b3d5a60
+//
+// Responses:
+//   - 204 No Content: if the entry was removed
+//   - 404 Not Found: if the entry does not exist
+//   - 500 Internal Server Error: if the entry could not be removed from the store
+func (s *HTTPServer) DeleteOutboxEntryHandler(c echo.Context) error {
+
+	if err := s.Alerting.Outbox().Delete(c.Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, alerting.ErrorOutboxEntryNotFound) {
+			return jsonError(c, http.StatusNotFound, err)
+		}
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// outboxEntriesToDTO converts outbox entries into their transfer representation.
+func outboxEntriesToDTO(entries []alerting.OutboxEntry) []dto.OutboxEntry {
+	result := make([]dto.OutboxEntry, len(entries))
+	for i := range entries {
+		result[i] = alerting.OutboxEntryToDTO(&entries[i])
+	}
+	return result
+}
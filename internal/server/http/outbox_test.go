@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_Outbox(t *testing.T) {
+	ctx := context.Background()
+
+	s := prepareAlertingTestServer(t)
+	rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/api/outbox", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "no routes without an outbox")
+
+	o, err := alerting.NewOutbox(nil, alerting.RetryPolicy{}, logger.GetLogger())
+	require.NoError(t, err)
+	s.Alerting.SetOutbox(o)
+	e := s.ConfigureRoutes()
+
+	alerts := []alerting.Alert{{Rule: "HeapAllocHigh", MetricType: "gauge", MetricName: "HeapAlloc", State: alerting.StateFiring}}
+	entry, err := o.Enqueue(ctx, alerting.NewNotification("gone", alerts), alertsT0)
+	require.NoError(t, err)
+
+	rec = doRequest(e, http.MethodGet, "/api/outbox", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var pending []dto.OutboxEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pending))
+	require.Len(t, pending, 1)
+	assert.Equal(t, entry.ID, pending[0].ID)
+	assert.Equal(t, "gone", pending[0].Notification.Receiver)
+	assert.NotNil(t, pending[0].NextAttemptAt)
+
+	// the receiver does not exist
+	o.Deliver(ctx, alertsT0)
+
+	rec = doRequest(e, http.MethodGet, "/api/outbox/dead", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var dead []dto.OutboxEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dead))
+	require.Len(t, dead, 1)
+	assert.Equal(t, 1, dead[0].Attempts)
+	assert.Contains(t, dead[0].LastError, "unknown receiver")
+	assert.NotNil(t, dead[0].DeadAt)
+	assert.Nil(t, dead[0].NextAttemptAt)
+
+	rec = doRequest(e, http.MethodPost, "/api/outbox/"+entry.ID+"/retry", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var retried dto.OutboxEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &retried))
+	assert.Zero(t, retried.Attempts)
+	assert.Nil(t, retried.DeadAt)
+	assert.Len(t, o.Pending(), 1)
+
+	rec = doRequest(e, http.MethodDelete, "/api/outbox/"+entry.ID, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, o.Pending())
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+	}{
+		{"retry", http.MethodPost, "/api/outbox/" + entry.ID + "/retry"},
+		{"delete", http.MethodDelete, "/api/outbox/" + entry.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" not found", func(t *testing.T) {
+			rec := doRequest(e, tt.method, tt.url, "")
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultSilenceDuration is the duration proposed by the silence form.
+const defaultSilenceDuration = "2h"
+
+// RulesPageHandler handles an HTTP GET request that renders the alert rules
+// with the health and the error of their last evaluation using the
+// "rules.html" template.
+//
+// Responses:
+//   - 200 OK: renders the list of rules
+func (s *HTTPServer) RulesPageHandler(c echo.Context) error {
+	return c.Render(http.StatusOK, "rules.html", s.Alerting.RuleStatuses())
+}
+
+// AlertsPageHandler handles an HTTP GET request that renders the tracked
+// alerts, including recently resolved ones, using the "alerts.html" template.
+// Firing alerts can be acknowledged from the page.
+//
+// Responses:
+//   - 200 OK: renders the list of alerts
+func (s *HTTPServer) AlertsPageHandler(c echo.Context) error {
+	return c.Render(http.StatusOK, "alerts.html", s.Alerting.Alerts())
+}
+
+// AcknowledgeAlertFormHandler handles the acknowledgement form of the alerts
+// page and redirects back to it, see AcknowledgeAlertHandler.
+//
+// Responses:
+//   - 303 See Other: to the alerts page
+//   - 400 Bad Request: if the form is incomplete
+//   - 404 Not Found: if there is no such alert
+//   - 409 Conflict: if the alert is not firing
+func (s *HTTPServer) AcknowledgeAlertFormHandler(c echo.Context) error {
+
+	labels, err := metric.ParseLabels(c.FormValue("metric_labels"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	a := alerting.Alert{Rule: c.FormValue("name"), MetricType: metric.MetricType(c.FormValue("metric_type")), MetricName: c.FormValue("metric_name"), MetricLabels: labels}
+	by := strings.TrimSpace(c.FormValue("acknowledged_by"))
+	if a.Rule == "" || by == "" {
+		return c.String(http.StatusBadRequest, "bad request")
+	}
+
+	if _, err := s.Alerting.Acknowledge(a.Key(), by, time.Now()); err != nil {
+		switch {
+		case errors.Is(err, alerting.ErrorAlertNotFound):
+			return c.String(http.StatusNotFound, err.Error())
+		case errors.Is(err, alerting.ErrorAlertNotFiring):
+			return c.String(http.StatusConflict, err.Error())
+		default:
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/alerts")
+}
+
+// silenceForm holds the fields of the silence form.
+type silenceForm struct {
+	Matchers  string
+	Duration  string
+	CreatedBy string
+	Comment   string
+}
+
+// silenceRow is a silence with its current status.
+type silenceRow struct {
+	alerting.Silence
+	Status alerting.SilenceStatus
+}
+
+// silencesPage is the data of the "silences.html" template.
+type silencesPage struct {
+	Silences []silenceRow
+	Form     silenceForm
+	Error    string
+}
+
+// renderSilences renders the silences page with the given form and error.
+func (s *HTTPServer) renderSilences(c echo.Context, code int, form silenceForm, err error) error {
+	now := time.Now()
+
+	page := silencesPage{Form: form}
+	for _, silence := range s.Alerting.Silences().List() {
+		page.Silences = append(page.Silences, silenceRow{Silence: silence, Status: silence.Status(now)})
+	}
+	if err != nil {
+		page.Error = err.Error()
+	}
+
+	return c.Render(code, "silences.html", page)
+}
+
+// SilencesPageHandler handles an HTTP GET request that renders the silences
+// with a form creating a new one using the "silences.html" template.
+//
+// Responses:
+//   - 200 OK: renders the list of silences
+func (s *HTTPServer) SilencesPageHandler(c echo.Context) error {
+	return s.renderSilences(c, http.StatusOK, silenceForm{Duration: defaultSilenceDuration}, nil)
+}
+
+// CreateSilenceFormHandler handles the form of the silences page creating a
+// silence that starts immediately and redirects back to the page. Matchers are
+// entered as a comma-separated list, see alerting.ParseMatchers, the duration
+// as a Go duration, e.g. "2h30m".
+//
+// Responses:
+//   - 303 See Other: to the silences page
+//   - 400 Bad Request: renders the page with the error and the entered values
+//   - 500 Internal Server Error: if the silence could not be stored
+func (s *HTTPServer) CreateSilenceFormHandler(c echo.Context) error {
+
+	form := silenceForm{
+		Matchers:  c.FormValue("matchers"),
+		Duration:  c.FormValue("duration"),
+		CreatedBy: c.FormValue("created_by"),
+		Comment:   c.FormValue("comment"),
+	}
+
+	matchers, err := alerting.ParseMatchers(form.Matchers)
+	if err != nil {
+		return s.renderSilences(c, http.StatusBadRequest, form, err)
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(form.Duration))
+	if err != nil || d <= 0 {
+		return s.renderSilences(c, http.StatusBadRequest, form, errors.New("invalid duration"))
+	}
+
+	now := time.Now()
+	silence := alerting.Silence{Matchers: matchers, StartsAt: now, EndsAt: now.Add(d), CreatedBy: form.CreatedBy, Comment: form.Comment}
+
+	if _, err := s.Alerting.Silences().Add(c.Request().Context(), silence, now); err != nil {
+		if errors.Is(err, alerting.ErrorInvalidSilence) || errors.Is(err, alerting.ErrorInvalidMatcher) {
+			return s.renderSilences(c, http.StatusBadRequest, form, err)
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/silences")
+}
+
+// ExpireSilenceFormHandler handles the expire button of the silences page
+// and redirects back to it.
+//
+// Responses:
+//   - 303 See Other: to the silences page
+//   - 404 Not Found: if the silence does not exist
+func (s *HTTPServer) ExpireSilenceFormHandler(c echo.Context) error {
+
+	if _, err := s.Alerting.Silences().Expire(c.Request().Context(), c.Param("id"), time.Now()); err != nil {
+		if errors.Is(err, alerting.ErrorSilenceNotFound) {
+			return c.String(http.StatusNotFound, err.Error())
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/silences")
+}
+
+// historyPage is the data of the "history.html" template.
+type historyPage struct {
+	Query       alerting.HistoryQuery
+	Transitions []alerting.Transition
+	Total       int
+	PrevURL     string // newer transitions, empty on the first page
+	NextURL     string // older transitions, empty on the last page
+}
+
+// HistoryPageHandler handles an HTTP GET request that renders a page of the
+// alert history, newest transitions first, using the "history.html" template.
+// It accepts the query parameters of AlertHistoryHandler.
+//
+// Responses:
+//   - 200 OK: renders the page of transitions
+//   - 400 Bad Request: if a query parameter is invalid
+//   - 500 Internal Server Error: if the history could not be read
+func (s *HTTPServer) HistoryPageHandler(c echo.Context) error {
+
+	ctx := c.Request().Context()
+
+	q, err := parseHistoryQuery(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	transitions, total, err := s.Alerting.History().QueryHistory(ctx, q)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	page := historyPage{Query: q, Transitions: transitions, Total: total}
+	if q.Offset > 0 {
+		page.PrevURL = historyPageURL(c, max(q.Offset-q.Limit, 0))
+	}
+	if q.Offset+q.Limit < total {
+		page.NextURL = historyPageURL(c, q.Offset+q.Limit)
+	}
+
+	return c.Render(http.StatusOK, "history.html", page)
+}
+
+// historyPageURL returns the URL of the history page at the given offset
+// with the other query parameters of the request.
+func historyPageURL(c echo.Context, offset int) string {
+	params := url.Values{}
+	for k, v := range c.QueryParams() {
+		params[k] = v
+	}
+	params.Set("offset", strconv.Itoa(offset))
+	return "/history?" + params.Encode()
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postForm(e *echo.Echo, url string, form url.Values) *http.Response {
+	rec := doRequest(e, http.MethodPost, url, form.Encode(), echo.HeaderContentType, echo.MIMEApplicationForm)
+	return rec.Result()
+}
+
+func TestHTTPServer_RulesPageHandler(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+
+	rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/rules", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=UTF-8", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "HeapAllocHigh")
+	assert.Contains(t, body, `gauge HeapAlloc &gt; 5e&#43;08`)
+	assert.Contains(t, body, `<td class="health-ok">ok</td>`)
+}
+
+func TestHTTPServer_AlertsPageHandler(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodGet, "/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, `<tr class="state-firing">`)
+	assert.Contains(t, body, `<tr class="state-pending">`)
+	assert.Contains(t, body, `action="/alerts/ack"`)
+
+	resp := postForm(e, "/alerts/ack", url.Values{"name": {"HeapAllocHigh"}, "metric_type": {"gauge"}, "metric_name": {"HeapAlloc"}, "acknowledged_by": {"jane"}})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Equal(t, "/alerts", resp.Header.Get("Location"))
+
+	rec = doRequest(e, http.MethodGet, "/alerts", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "jane at ")
+	assert.NotContains(t, rec.Body.String(), `action="/alerts/ack"`)
+
+	resp = postForm(e, "/alerts/ack", url.Values{"name": {"CPUHigh"}, "metric_type": {"gauge"}, "metric_name": {"CPUutilization1"}, "acknowledged_by": {"jane"}})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp = postForm(e, "/alerts/ack", url.Values{"name": {"HeapAllocHigh"}})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHTTPServer_SilencesPage(t *testing.T) {
+	s := prepareAlertingTestServer(t)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodGet, "/silences", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "No silences")
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			form    url.Values
+			wantErr string
+		}{
+			{"matchers", url.Values{"matchers": {`alertname`}, "duration": {"1h"}, "created_by": {"jane"}}, "invalid matcher"},
+			{"duration", url.Values{"matchers": {`alertname="CPUHigh"`}, "duration": {"soon"}, "created_by": {"jane"}}, "invalid duration"},
+			{"creator", url.Values{"matchers": {`alertname="CPUHigh"`}, "duration": {"1h"}}, "creator is required"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rec := doRequest(e, http.MethodPost, "/silences", tt.form.Encode(), echo.HeaderContentType, echo.MIMEApplicationForm)
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), tt.wantErr)
+				// entered values are kept
+				assert.Contains(t, rec.Body.String(), `value="`+tt.form.Get("duration")+`"`)
+			})
+		}
+		assert.Empty(t, s.Alerting.Silences().List())
+	})
+
+	resp := postForm(e, "/silences", url.Values{"matchers": {`alertname="CPUHigh", metric_name=~"CPU.*"`}, "duration": {"1h"}, "created_by": {"jane"}, "comment": {"deploy"}})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	silences := s.Alerting.Silences().List()
+	require.Len(t, silences, 1)
+	assert.Len(t, silences[0].Matchers, 2)
+	id := silences[0].ID
+
+	rec = doRequest(e, http.MethodGet, "/silences", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "deploy")
+	assert.Contains(t, rec.Body.String(), `action="/silences/`+id+`/expire"`)
+
+	resp = postForm(e, "/silences/"+id+"/expire", nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	silence, err := s.Alerting.Silences().Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, alerting.SilenceExpired, silence.Status(time.Now()))
+
+	resp = postForm(e, "/silences/unknown/expire", nil)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHTTPServer_HistoryPageHandler(t *testing.T) {
+	s := prepareAlertsTestServer(t)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodGet, "/history", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "3 transitions")
+	assert.NotContains(t, rec.Body.String(), "Older")
+
+	rec = doRequest(e, http.MethodGet, "/history?rule=CPUHigh&limit=1&offset=1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "2 transitions")
+	assert.Contains(t, body, `<a href="/history?limit=1&amp;offset=0&amp;rule=CPUHigh">Newer</a>`)
+	assert.NotContains(t, body, "Older")
+
+	rec = doRequest(e, http.MethodGet, "/history?limit=x", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/expr"
+	"github.com/labstack/echo/v4"
+)
+
+// QueryHandler handles an HTTP GET request that evaluates an expression
+// over the stored metrics and returns the result as dto.QueryResult.
+// See package expr for the syntax.
+//
+// Example request:
+//
+//	GET /api/query?expr=max(CPUutilization*)%20%3E%2095
+//
+// Example response:
+//
+//	{"expr": "max(CPUutilization*) > 95", "type": "bool", "value": true}
+//
+// Responses:
+//   - 200 OK: with the result
+//   - 400 Bad Request: if the expression is invalid, the error includes the column
+//   - 422 Unprocessable Entity: if the expression cannot be evaluated, e.g. a metric is missing
+//   - 500 Internal Server Error: if the metrics could not be retrieved
+func (s *HTTPServer) QueryHandler(c echo.Context) error {
+
+	ctx := c.Request().Context()
+
+	e, err := expr.Parse(c.QueryParam("expr"))
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err)
+	}
+
+	metrics, err := s.Storage.RetrieveAll(ctx)
+	if err != nil {
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	v, err := e.Eval(expr.MetricsEnv(metrics))
+	if err != nil {
+		if errors.Is(err, expr.ErrorNoData) || errors.Is(err, expr.ErrorAmbiguousRef) || errors.Is(err, expr.ErrorDivisionByZero) {
+			return jsonError(c, http.StatusUnprocessableEntity, err)
+		}
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	result := dto.QueryResult{Expr: e.String(), Type: v.Type.String(), Value: v.Num}
+	if v.Type == expr.TypeBool {
+		result.Value = v.Bool
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_QueryHandler(t *testing.T) {
+	ctx := context.Background()
+
+	st := memory.NewMemStorage()
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("TotalMemory", 1000)))
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("FreeMemory", 50)))
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("CPUutilization1", 99)))
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("CPUutilization2", 10)))
+
+	s, err := NewHTTPServer(":8080", "", st, logger.GetLogger(), "", "")
+	require.NoError(t, err)
+	e := s.ConfigureRoutes()
+
+	tests := []struct {
+		name   string
+		expr   string
+		code   int
+		want   dto.QueryResult
+		errMsg string
+	}{
+		{name: "number", expr: "(TotalMemory-FreeMemory)/TotalMemory", code: http.StatusOK,
+			want: dto.QueryResult{Expr: "(TotalMemory - FreeMemory) / TotalMemory", Type: "number", Value: 0.95}},
+		{name: "bool", expr: "max(CPUutilization*) > 95", code: http.StatusOK,
+			want: dto.QueryResult{Expr: "max(CPUutilization*) > 95", Type: "bool", Value: true}},
+		{name: "syntax error", expr: "max(CPUutilization*) >", code: http.StatusBadRequest, errMsg: "column 23"},
+		{name: "no data", expr: "Missing + 1", code: http.StatusUnprocessableEntity, errMsg: "no data"},
+		{name: "division by zero", expr: "1 / (FreeMemory - 50)", code: http.StatusUnprocessableEntity, errMsg: "division by zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodGet, "/api/query?expr="+url.QueryEscape(tt.expr), "")
+			require.Equal(t, tt.code, rec.Code, rec.Body.String())
+
+			if tt.errMsg != "" {
+				var resp dto.Error
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Contains(t, resp.Error, tt.errMsg)
+				return
+			}
+
+			var got dto.QueryResult
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
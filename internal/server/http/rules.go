@@ -0,0 +1,160 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/labstack/echo/v4"
+)
+
+// ruleErrorStatus maps rule management errors to HTTP status codes.
+func ruleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, alerting.ErrorInvalidRule):
+		return http.StatusBadRequest
+	case errors.Is(err, alerting.ErrorRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, alerting.ErrorDuplicateRuleName), errors.Is(err, alerting.ErrorRuleInConfig):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListRulesHandler handles an HTTP GET request that returns all alert rules,
+// including disabled ones, as a JSON array in evaluation order.
+//
+// Responses:
+//   - 200 OK: with the list of rules
+func (s *HTTPServer) ListRulesHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.Alerting.Rules())
+}
+
+// GetRuleHandler handles an HTTP GET request that returns the rule with the given name.
+//
+// Responses:
+//   - 200 OK: with the rule
+//   - 404 Not Found: if the rule does not exist
+func (s *HTTPServer) GetRuleHandler(c echo.Context) error {
+
+	r, err := s.Alerting.Rule(c.Param("name"))
+	if err != nil {
+		return jsonError(c, ruleErrorStatus(err), err)
+	}
+
+	return c.JSON(http.StatusOK, r)
+}
+
+// CreateRuleHandler handles an HTTP POST request that creates an alert rule.
+// The rule is evaluated starting with the next evaluation, no restart is needed.
+//
+// Example request:
+//
+//	POST /api/rules
+//	{
+//	  "name": "HeapAllocHigh",
+//	  "metric_type": "gauge",
+//	  "metric_name": "HeapAlloc",
+//	  "operator": ">",
+//	  "threshold": 500e6,
+//	  "for": "1m"
+//	}
+//
+// Responses:
+//   - 201 Created: with the created rule
+//   - 400 Bad Request: if the body is malformed or the rule is invalid
+//   - 409 Conflict: if a rule with the same name exists
+//   - 500 Internal Server Error: if the rule could not be persisted
+func (s *HTTPServer) CreateRuleHandler(c echo.Context) error {
+
+	ctx := c.Request().Context()
+
+	r := alerting.Rule{}
+	if err := c.Bind(&r); err != nil {
+		return jsonError(c, http.StatusBadRequest, errors.New("bad request"))
+	}
+
+	if err := s.Alerting.AddRule(ctx, r); err != nil {
+		return jsonError(c, ruleErrorStatus(err), err)
+	}
+
+	return c.JSON(http.StatusCreated, r)
+}
+
+// UpdateRuleHandler handles an HTTP PUT request that replaces the rule with the given name.
+// The name in the body can be omitted but must not differ from the one in the path.
+//
+// Responses:
+//   - 200 OK: with the updated rule
+//   - 400 Bad Request: if the body is malformed or the rule is invalid
+//   - 404 Not Found: if the rule does not exist
+//   - 500 Internal Server Error: if the rule could not be persisted
+func (s *HTTPServer) UpdateRuleHandler(c echo.Context) error {
+
+	ctx := c.Request().Context()
+	name := c.Param("name")
+
+	r := alerting.Rule{}
+	if err := c.Bind(&r); err != nil {
+		return jsonError(c, http.StatusBadRequest, errors.New("bad request"))
+	}
+
+	if err := s.Alerting.UpdateRule(ctx, name, r); err != nil {
+		return jsonError(c, ruleErrorStatus(err), err)
+	}
+
+	return s.GetRuleHandler(c)
+}
+
+// DisableRuleHandler handles an HTTP POST request that disables the rule with the given name.
+// Alerts of a disabled rule are dropped and it is not evaluated until enabled again.
+//
+// Responses:
+//   - 200 OK: with the updated rule
+//   - 404 Not Found: if the rule does not exist
+//   - 500 Internal Server Error: if the rule could not be persisted
+func (s *HTTPServer) DisableRuleHandler(c echo.Context) error {
+	return s.setRuleDisabled(c, true)
+}
+
+// EnableRuleHandler handles an HTTP POST request that enables the rule with the given name.
+//
+// Responses:
+//   - 200 OK: with the updated rule
+//   - 404 Not Found: if the rule does not exist
+//   - 500 Internal Server Error: if the rule could not be persisted
+func (s *HTTPServer) EnableRuleHandler(c echo.Context) error {
+	return s.setRuleDisabled(c, false)
+}
+
+func (s *HTTPServer) setRuleDisabled(c echo.Context, disabled bool) error {
+
+	ctx := c.Request().Context()
+
+	r, err := s.Alerting.SetRuleDisabled(ctx, c.Param("name"), disabled)
+	if err != nil {
+		return jsonError(c, ruleErrorStatus(err), err)
+	}
+
+	return c.JSON(http.StatusOK, r)
+}
+
+// DeleteRuleHandler handles an HTTP DELETE request that deletes the rule with the given name.
+// Rules defined in the config file cannot be deleted, only disabled.
+//
+// Responses:
+//   - 204 No Content: if the rule was deleted
+//   - 404 Not Found: if the rule does not exist
+//   - 409 Conflict: if the rule is defined in the config file
+//   - 500 Internal Server Error: if the change could not be persisted
+func (s *HTTPServer) DeleteRuleHandler(c echo.Context) error {
+
+	ctx := c.Request().Context()
+
+	if err := s.Alerting.DeleteRule(ctx, c.Param("name")); err != nil {
+		return jsonError(c, ruleErrorStatus(err), err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
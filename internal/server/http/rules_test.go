@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_Rules(t *testing.T) {
+	s := prepareAlertingTestServer(t,
+		alerting.Rule{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6},
+	)
+	e := s.ConfigureRoutes()
+
+	rec := doRequest(e, http.MethodPost, "/api/rules", `{"name":"PollCountLow","metric_type":"counter","metric_name":"PollCount","operator":"<","threshold":10,"for":"1m"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"name":"PollCountLow","metric_type":"counter","metric_name":"PollCount","operator":"<","threshold":10,"stale_after":"0s","window":"0s","for":"1m0s","keep_firing_for":"0s"}`, rec.Body.String())
+
+	rec = doRequest(e, http.MethodPut, "/api/rules/PollCountLow", `{"metric_type":"counter","metric_name":"PollCount","operator":"<","threshold":5}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var r alerting.Rule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	assert.Equal(t, "PollCountLow", r.Name)
+	assert.Equal(t, 5.0, r.Threshold)
+
+	rec = doRequest(e, http.MethodPost, "/api/rules/HeapAllocHigh/disable", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	assert.True(t, r.Disabled)
+
+	rec = doRequest(e, http.MethodGet, "/api/rules", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var rules []alerting.Rule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rules))
+	require.Len(t, rules, 2)
+	assert.True(t, rules[0].Disabled)
+
+	rec = doRequest(e, http.MethodPost, "/api/rules/HeapAllocHigh/enable", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(e, http.MethodGet, "/api/rules/HeapAllocHigh", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	r = alerting.Rule{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	assert.False(t, r.Disabled)
+
+	rec = doRequest(e, http.MethodDelete, "/api/rules/PollCountLow", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, s.Alerting.Rules(), 1)
+}
+
+func TestHTTPServer_Rules_Errors(t *testing.T) {
+	s := prepareAlertingTestServer(t,
+		alerting.Rule{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6},
+	)
+	e := s.ConfigureRoutes()
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		code   int
+		errMsg string
+	}{
+		{name: "malformed body", method: http.MethodPost, url: "/api/rules", body: `{`, code: http.StatusBadRequest, errMsg: "bad request"},
+		{name: "bad duration", method: http.MethodPost, url: "/api/rules", body: `{"name":"x","for":"soon"}`, code: http.StatusBadRequest, errMsg: "bad request"},
+		{name: "invalid operator", method: http.MethodPost, url: "/api/rules", body: `{"name":"x","metric_type":"gauge","metric_name":"HeapAlloc","operator":"~"}`, code: http.StatusBadRequest, errMsg: "invalid rule: invalid operator"},
+		{name: "invalid kind", method: http.MethodPost, url: "/api/rules", body: `{"name":"x","kind":"magic","metric_type":"gauge","metric_name":"HeapAlloc"}`, code: http.StatusBadRequest, errMsg: "invalid rule kind"},
+		{name: "invalid type", method: http.MethodPost, url: "/api/rules", body: `{"name":"x","metric_type":"hist","metric_name":"HeapAlloc","operator":">"}`, code: http.StatusBadRequest, errMsg: "invalid rule"},
+		{name: "duplicate", method: http.MethodPost, url: "/api/rules", body: `{"name":"HeapAllocHigh","metric_type":"gauge","metric_name":"HeapAlloc","operator":">"}`, code: http.StatusConflict, errMsg: "duplicate rule name"},
+		{name: "rename", method: http.MethodPut, url: "/api/rules/HeapAllocHigh", body: `{"name":"Other","metric_type":"gauge","metric_name":"HeapAlloc","operator":">"}`, code: http.StatusBadRequest, errMsg: "invalid rule"},
+		{name: "update missing", method: http.MethodPut, url: "/api/rules/Missing", body: `{"metric_type":"gauge","metric_name":"HeapAlloc","operator":">"}`, code: http.StatusNotFound, errMsg: "rule not found"},
+		{name: "get missing", method: http.MethodGet, url: "/api/rules/Missing", code: http.StatusNotFound, errMsg: "rule not found"},
+		{name: "disable missing", method: http.MethodPost, url: "/api/rules/Missing/disable", code: http.StatusNotFound, errMsg: "rule not found"},
+		{name: "delete config rule", method: http.MethodDelete, url: "/api/rules/HeapAllocHigh", code: http.StatusConflict, errMsg: "config file"},
+		{name: "delete missing", method: http.MethodDelete, url: "/api/rules/Missing", code: http.StatusNotFound, errMsg: "rule not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, tt.method, tt.url, tt.body)
+			require.Equal(t, tt.code, rec.Code, rec.Body.String())
+
+			var resp dto.Error
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Contains(t, resp.Error, tt.errMsg)
+		})
+	}
+}
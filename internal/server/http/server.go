@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/assets"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/secure"
@@ -18,6 +19,7 @@ import (
 )
 
 type HTTPServer struct {
+	Alerting       *alerting.Evaluator
 	GzipWriterPool *sync.Pool
 	Storage        storage.Storage
 	Saver          file.DumpSaver
@@ -30,6 +32,10 @@ type HTTPServer struct {
 	TemplatePath   string
 	TrustedSubnet  *net.IPNet
 	wg             sync.WaitGroup
+
+	// HistogramBounds are the bucket bounds of histograms created by a single
+	// observation, metric.DefaultHistogramBounds if nil.
+	HistogramBounds []float64
 }
 
 func NewHTTPServer(address string, key string, storage storage.Storage, logger logger.Logger, cryptoKey string, trustedSubnet string) (*HTTPServer, error) {
@@ -76,6 +82,14 @@ func (s *HTTPServer) getUpdateMiddlewares() []echo.MiddlewareFunc {
 	return mws
 }
 
+func (s *HTTPServer) getAPIMiddlewares() []echo.MiddlewareFunc {
+	var mws []echo.MiddlewareFunc
+	if s.TrustedSubnet != nil {
+		mws = append(mws, s.CheckTrustedSubnetMiddleware)
+	}
+	return mws
+}
+
 func (s *HTTPServer) ConfigureRoutes() *echo.Echo {
 
 	// Load templates
@@ -102,6 +116,52 @@ func (s *HTTPServer) ConfigureRoutes() *echo.Echo {
 	e.GET("/ping", s.PingHandler)
 	e.GET("/", s.ListHandler)
 
+	apiMws := s.getAPIMiddlewares()
+
+	e.GET("/api/query", s.QueryHandler, apiMws...)
+
+	if s.metadataStorage() != nil {
+		e.GET("/api/metadata", s.ListMetadataHandler, apiMws...)
+		e.GET("/api/metadata/:name", s.GetMetadataHandler, apiMws...)
+		e.PUT("/api/metadata/:name", s.SetMetadataHandler, apiMws...)
+		e.DELETE("/api/metadata/:name", s.DeleteMetadataHandler, apiMws...)
+	}
+
+	if s.Alerting != nil {
+
+		e.GET("/api/alerts", s.ListAlertsHandler, apiMws...)
+		e.GET("/api/alerts/history", s.AlertHistoryHandler, apiMws...)
+		e.POST("/api/alerts/ack", s.AcknowledgeAlertHandler, apiMws...)
+		e.POST("/api/routes/test", s.TestRouteHandler, apiMws...)
+
+		e.GET("/api/rules", s.ListRulesHandler, apiMws...)
+		e.POST("/api/rules", s.CreateRuleHandler, apiMws...)
+		e.GET("/api/rules/:name", s.GetRuleHandler, apiMws...)
+		e.PUT("/api/rules/:name", s.UpdateRuleHandler, apiMws...)
+		e.POST("/api/rules/:name/disable", s.DisableRuleHandler, apiMws...)
+		e.POST("/api/rules/:name/enable", s.EnableRuleHandler, apiMws...)
+		e.DELETE("/api/rules/:name", s.DeleteRuleHandler, apiMws...)
+
+		e.GET("/api/silences", s.ListSilencesHandler, apiMws...)
+		e.POST("/api/silences", s.CreateSilenceHandler, apiMws...)
+		e.DELETE("/api/silences/:id", s.ExpireSilenceHandler, apiMws...)
+
+		if s.Alerting.Outbox() != nil {
+			e.GET("/api/outbox", s.ListOutboxHandler, apiMws...)
+			e.GET("/api/outbox/dead", s.ListDeadLettersHandler, apiMws...)
+			e.POST("/api/outbox/:id/retry", s.RetryOutboxEntryHandler, apiMws...)
+			e.DELETE("/api/outbox/:id", s.DeleteOutboxEntryHandler, apiMws...)
+		}
+
+		e.GET("/rules", s.RulesPageHandler, apiMws...)
+		e.GET("/alerts", s.AlertsPageHandler, apiMws...)
+		e.POST("/alerts/ack", s.AcknowledgeAlertFormHandler, apiMws...)
+		e.GET("/silences", s.SilencesPageHandler, apiMws...)
+		e.POST("/silences", s.CreateSilenceFormHandler, apiMws...)
+		e.POST("/silences/:id/expire", s.ExpireSilenceFormHandler, apiMws...)
+		e.GET("/history", s.HistoryPageHandler, apiMws...)
+	}
+
 	e.Renderer = t
 	return e
 }
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/labstack/echo/v4"
+)
+
+// ListSilencesHandler handles an HTTP GET request that returns all silences,
+// including pending and recently expired ones, as a JSON array of dto.Silence.
+//
+// Responses:
+//   - 200 OK: with the list of silences
+func (s *HTTPServer) ListSilencesHandler(c echo.Context) error {
+
+	now := time.Now()
+	silences := s.Alerting.Silences().List()
+
+	result := make([]dto.Silence, len(silences))
+	for i := range silences {
+		result[i] = alerting.SilenceToDTO(&silences[i], now)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CreateSilenceHandler handles an HTTP POST request that creates a silence.
+//
+// Alerts matching all matchers of an active silence are still evaluated,
+// but no notifications are sent about them. If starts_at is omitted,
+// the silence starts immediately.
+//
+// Example request:
+//
+//	POST /api/silences
+//	{
+//	  "matchers": [
+//	    {"name": "metric_name", "value": "CPUutilization.*", "type": "=~"},
+//	    {"name": "severity", "value": "page"}
+//	  ],
+//	  "ends_at": "2025-01-01T12:00:00Z",
+//	  "created_by": "jane",
+//	  "comment": "deploy"
+//	}
+//
+// Responses:
+//   - 201 Created: with the created silence including its ID
+//   - 400 Bad Request: if the body is malformed or the silence is invalid
+//   - 500 Internal Server Error: if the silence could not be stored
+func (s *HTTPServer) CreateSilenceHandler(c echo.Context) error {
+
+	sDTO := new(dto.Silence)
+	if err := c.Bind(sDTO); err != nil {
+		return jsonError(c, http.StatusBadRequest, errors.New("bad request"))
+	}
+
+	now := time.Now()
+
+	silence, err := s.Alerting.Silences().Add(c.Request().Context(), alerting.SilenceFromDTO(sDTO), now)
+	if err != nil {
+		if errors.Is(err, alerting.ErrorInvalidSilence) || errors.Is(err, alerting.ErrorInvalidMatcher) {
+			return jsonError(c, http.StatusBadRequest, err)
+		}
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, alerting.SilenceToDTO(&silence, now))
+}
+
+// ExpireSilenceHandler handles an HTTP DELETE request that expires the silence
+// with the given ID. Expired silences are kept for a while and then garbage-collected.
+//
+// Example request:
+//
+//	DELETE /api/silences/4f2a9c1e7b3d5a60
+//
+// Responses:
+//   - 200 OK: with the expired silence
+//   - 404 Not Found: if the silence does not exist
+func (s *HTTPServer) ExpireSilenceHandler(c echo.Context) error {
+
+	now := time.Now()
+
+	silence, err := s.Alerting.Silences().Expire(c.Request().Context(), c.Param("id"), now)
+	if err != nil {
+		if errors.Is(err, alerting.ErrorSilenceNotFound) {
+			return jsonError(c, http.StatusNotFound, err)
+		}
+		return jsonError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, alerting.SilenceToDTO(&silence, now))
+}
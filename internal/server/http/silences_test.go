@@ -0,0 +1,125 @@
+package http
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/logger"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareAlertingTestServer(t *testing.T, rules ...alerting.Rule) *HTTPServer {
+	t.Helper()
+
+	st := memory.NewMemStorage()
+	e, err := alerting.NewEvaluator(st, rules, time.Second, logger.GetLogger())
+	require.NoError(t, err)
+
+	s, err := NewHTTPServer(":8080", "", st, logger.GetLogger(), "", "")
+	require.NoError(t, err)
+
+	s.Alerting = e
+	return s
+}
+
+func doRequest(e *echo.Echo, method, url, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHTTPServer_Silences(t *testing.T) {
+	s := prepareAlertingTestServer(t)
+	e := s.ConfigureRoutes()
+
+	endsAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	body := `{"matchers":[{"name":"metric_name","value":"CPU.*","type":"=~"}],"ends_at":"` + endsAt + `","created_by":"jane","comment":"deploy"}`
+
+	rec := doRequest(e, http.MethodPost, "/api/silences", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created dto.Silence
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "active", created.Status)
+	assert.Equal(t, "jane", created.CreatedBy)
+
+	rec = doRequest(e, http.MethodGet, "/api/silences", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var list []dto.Silence
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, created.ID, list[0].ID)
+
+	rec = doRequest(e, http.MethodDelete, "/api/silences/"+created.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var expired dto.Silence
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &expired))
+	assert.Equal(t, "expired", expired.Status)
+}
+
+func TestHTTPServer_Silences_Errors(t *testing.T) {
+	s := prepareAlertingTestServer(t)
+	e := s.ConfigureRoutes()
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		code   int
+		errMsg string
+	}{
+		{name: "malformed body", method: http.MethodPost, url: "/api/silences", body: `{`, code: http.StatusBadRequest, errMsg: "bad request"},
+		{name: "no matchers", method: http.MethodPost, url: "/api/silences", body: `{"ends_at":"2999-01-01T00:00:00Z","created_by":"jane"}`, code: http.StatusBadRequest, errMsg: "no matchers"},
+		{name: "bad regexp", method: http.MethodPost, url: "/api/silences", body: `{"matchers":[{"name":"alertname","value":"(","type":"=~"}],"ends_at":"2999-01-01T00:00:00Z","created_by":"jane"}`, code: http.StatusBadRequest, errMsg: "invalid matcher"},
+		{name: "unknown silence", method: http.MethodDelete, url: "/api/silences/unknown", code: http.StatusNotFound, errMsg: "silence not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, tt.method, tt.url, tt.body)
+			require.Equal(t, tt.code, rec.Code)
+
+			var resp dto.Error
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Contains(t, resp.Error, tt.errMsg)
+		})
+	}
+}
+
+func TestHTTPServer_Silences_Routes(t *testing.T) {
+	t.Run("not registered without alerting", func(t *testing.T) {
+		s := &HTTPServer{Storage: memory.NewMemStorage(), logger: logger.GetLogger()}
+		rec := doRequest(s.ConfigureRoutes(), http.MethodGet, "/api/silences", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("trusted subnet", func(t *testing.T) {
+		s := prepareAlertingTestServer(t)
+		_, s.TrustedSubnet, _ = net.ParseCIDR("192.168.0.0/24")
+		e := s.ConfigureRoutes()
+
+		rec := doRequest(e, http.MethodGet, "/api/silences", "", "X-Real-IP", "10.0.0.1")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = doRequest(e, http.MethodGet, "/api/silences", "", "X-Real-IP", "192.168.0.10")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -1,6 +1,14 @@
 package http
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/server/usecase"
+	"github.com/labstack/echo/v4"
+)
 
 func ContentTypeIsCompressable(contentType string) bool {
 	return contentType == "application/json" || strings.HasPrefix(contentType, "text/html")
@@ -12,3 +20,54 @@ func int64Ptr(i int64) *int64 {
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+// jsonError responds with the error message in a JSON body.
+func jsonError(c echo.Context, code int, err error) error {
+	return c.JSON(code, dto.Error{Error: err.Error()})
+}
+
+// labelQueryPrefix prefixes the names of query parameters holding labels,
+// e.g. label.host=web1 for the label host.
+const labelQueryPrefix = "label."
+
+// labelsFromQuery returns the labels passed as query parameters of the request,
+// using the first value of a repeated parameter. Parameters without
+// labelQueryPrefix, e.g. cache busters, are ignored.
+func labelsFromQuery(c echo.Context) metric.Labels {
+	var labels metric.Labels
+	for k, v := range c.QueryParams() {
+		name, ok := strings.CutPrefix(k, labelQueryPrefix)
+		if !ok {
+			continue
+		}
+		if labels == nil {
+			labels = make(metric.Labels)
+		}
+		labels[name] = v[0]
+	}
+	return labels
+}
+
+// histogramValueFromDto returns the value a histogram is updated with: the
+// whole histogram of the DTO if set, otherwise its single observed value.
+func histogramValueFromDto(mDTO dto.Metrics) (interface{}, error) {
+	if mDTO.Histogram != nil {
+		return usecase.HistogramFromDto(mDTO.Histogram), nil
+	}
+	if mDTO.Value != nil {
+		return *mDTO.Value, nil
+	}
+	return nil, fmt.Errorf("%w: wrong histogram", metric.ErrorInvalidMetricValue)
+}
+
+// summaryValueFromDto returns the value a summary is updated with: the whole
+// sketch of the DTO if set, otherwise its single observed value.
+func summaryValueFromDto(mDTO dto.Metrics) (interface{}, error) {
+	if mDTO.Summary != nil {
+		return usecase.SummaryFromDto(mDTO.Summary), nil
+	}
+	if mDTO.Value != nil {
+		return *mDTO.Value, nil
+	}
+	return nil, fmt.Errorf("%w: wrong summary", metric.ErrorInvalidMetricValue)
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
+)
+
+// AddMetadataIfMissing stores the metadata of the metric name unless the name
+// already has metadata, so that metadata sent by agents along with values does
+// not replace metadata set via the API. Empty metadata is ignored, and so is
+// all metadata if the storage does not keep it (see storage.MetadataStorage).
+func AddMetadataIfMissing(ctx context.Context, s storage.Storage, name string, md metric.Metadata) error {
+	ms, ok := s.(storage.MetadataStorage)
+	if !ok || md.IsEmpty() {
+		return nil
+	}
+
+	if err := md.Validate(); err != nil {
+		return err
+	}
+
+	err := ms.AddMetadata(ctx, name, md)
+	if errors.Is(err, common.ErrorMetadataAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// RetrieveMetadata returns the metadata of the metric name, or empty metadata
+// if the name has none or the storage does not keep metadata.
+func RetrieveMetadata(ctx context.Context, s storage.Storage, name string) (metric.Metadata, error) {
+	ms, ok := s.(storage.MetadataStorage)
+	if !ok {
+		return metric.Metadata{}, nil
+	}
+
+	md, err := ms.RetrieveMetadata(ctx, name)
+	if errors.Is(err, common.ErrorMetadataDoesNotExist) {
+		return metric.Metadata{}, nil
+	}
+	return md, err
+}
+
+// RetrieveAllMetadata returns the metadata of all metric names that have one,
+// or nil if the storage does not keep metadata.
+func RetrieveAllMetadata(ctx context.Context, s storage.Storage) (map[string]metric.Metadata, error) {
+	ms, ok := s.(storage.MetadataStorage)
+	if !ok {
+		return nil, nil
+	}
+	return ms.RetrieveAllMetadata(ctx)
+}
+
+// MetadataToDto converts the metadata of the metric name to its DTO.
+func MetadataToDto(name string, md metric.Metadata) *dto.Metadata {
+	return &dto.Metadata{Name: name, Help: md.Help, Unit: string(md.Unit), Owner: md.Owner}
+}
+
+// MetadataFromDto converts a metadata DTO to metadata, which is not validated.
+// A nil DTO is empty metadata.
+func MetadataFromDto(d *dto.Metadata) metric.Metadata {
+	if d == nil {
+		return metric.Metadata{}
+	}
+	return metric.Metadata{Help: d.Help, Unit: metric.Unit(d.Unit), Owner: d.Owner}
+}
+
+// FillMetadata sets the metadata of the metric in the DTO, unless it is empty.
+func FillMetadata(md metric.Metadata, r *dto.Metrics) {
+	if md.IsEmpty() {
+		return
+	}
+	r.Metadata = MetadataToDto("", md)
+}
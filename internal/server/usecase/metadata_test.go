@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddMetadataIfMissing(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewMemStorage()
+
+	md := metric.Metadata{Help: "Heap bytes in use.", Unit: metric.UnitBytes}
+	require.NoError(t, AddMetadataIfMissing(ctx, s, "HeapAlloc", md))
+	require.NoError(t, AddMetadataIfMissing(ctx, s, "HeapAlloc", metric.Metadata{Help: "other"}), "existing metadata is kept")
+	require.NoError(t, AddMetadataIfMissing(ctx, s, "PollCount", metric.Metadata{}), "empty metadata is ignored")
+	require.ErrorIs(t, AddMetadataIfMissing(ctx, s, "Sys", metric.Metadata{Unit: "Bytes"}), metric.ErrorInvalidMetricMetadata)
+
+	all, err := RetrieveAllMetadata(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]metric.Metadata{"HeapAlloc": md}, all)
+
+	got, err := RetrieveMetadata(ctx, s, "PollCount")
+	require.NoError(t, err)
+	assert.True(t, got.IsEmpty())
+
+	// storages without metadata are not an error
+	require.NoError(t, AddMetadataIfMissing(ctx, faultyStorage{}, "HeapAlloc", md))
+	all, err = RetrieveAllMetadata(ctx, faultyStorage{})
+	require.NoError(t, err)
+	assert.Nil(t, all)
+}
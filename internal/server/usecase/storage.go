@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
@@ -10,20 +12,65 @@ import (
 	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 )
 
-func RetrieveMetric(ctx context.Context, storage storage.Storage, metricType string, metricName string) (metric.Metric, error) {
-	return storage.Retrieve(ctx, metric.MetricType(metricType), metricName)
+func RetrieveMetric(ctx context.Context, storage storage.Storage, metricType string, metricName string, labels metric.Labels) (metric.Metric, error) {
+	return storage.Retrieve(ctx, metric.MetricType(metricType), metricName, labels)
 }
 
-func UpdateMetric(ctx context.Context, storage storage.Storage, m metric.Metric, metricValue any) error {
-	x := storage.Update(ctx, m, metricValue)
+// RetrieveTimestampedMetric fetches a metric with the times it was added and
+// last updated, if the storage tracks them (see storage.TimestampedStorage).
+func RetrieveTimestampedMetric(ctx context.Context, s storage.Storage, metricType string, metricName string, labels metric.Labels) (storage.TimestampedMetric, error) {
+	if ts, ok := s.(storage.TimestampedStorage); ok {
+		return ts.RetrieveTimestamped(ctx, metric.MetricType(metricType), metricName, labels)
+	}
+
+	m, err := RetrieveMetric(ctx, s, metricType, metricName, labels)
+	if err != nil {
+		return storage.TimestampedMetric{}, err
+	}
+	return storage.TimestampedMetric{Metric: m}, nil
+}
+
+// RetrieveAllTimestampedMetrics fetches all metrics with the times they were added
+// and last updated, if the storage tracks them (see storage.TimestampedStorage).
+func RetrieveAllTimestampedMetrics(ctx context.Context, s storage.Storage) ([]storage.TimestampedMetric, error) {
+	if ts, ok := s.(storage.TimestampedStorage); ok {
+		return ts.RetrieveAllTimestamped(ctx)
+	}
+
+	metrics, err := s.RetrieveAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.TimestampedMetric, len(metrics))
+	for i, m := range metrics {
+		result[i] = storage.TimestampedMetric{Metric: m}
+	}
+	return result, nil
+}
+
+// UpdateMetric updates the stored metric m with a value sampled by the client
+// at ts, or at an unknown time if ts is zero.
+func UpdateMetric(ctx context.Context, storage storage.Storage, m metric.Metric, metricValue any, ts time.Time) error {
+	sample, err := metric.NewLabeledMetric(m.GetType(), m.GetName(), m.GetLabels())
+	if err != nil {
+		return err
+	}
+	metric.SetTimestamp(sample, ts)
+
+	x := storage.Update(ctx, sample, metricValue)
 	return x
 }
 
-func AddNewMetric(ctx context.Context, storage storage.Storage, metricType string, metricName string, metricValue any) (metric.Metric, error) {
-	m, err := NewMetricWithValue(metricType, metricName, metricValue)
+// AddNewMetric adds the metric with the given value, sampled by the client at ts.
+// A histogram created by a single observation takes histogramBounds as its
+// bucket bounds, metric.DefaultHistogramBounds if nil.
+func AddNewMetric(ctx context.Context, storage storage.Storage, metricType string, metricName string, labels metric.Labels, metricValue any, ts time.Time, histogramBounds []float64) (metric.Metric, error) {
+	m, err := NewMetricWithValue(metricType, metricName, labels, metricValue, histogramBounds)
 	if err != nil {
 		return nil, err
 	}
+	metric.SetTimestamp(m, ts)
 	err = storage.Add(ctx, m)
 	if err != nil {
 		return nil, err
@@ -31,8 +78,9 @@ func AddNewMetric(ctx context.Context, storage storage.Storage, metricType strin
 	return m, nil
 }
 
-func NewMetricWithValue(metricType string, metricName string, metricValue any) (metric.Metric, error) {
-	m, err := metric.NewMetric(metric.MetricType(metricType), metricName)
+// NewMetricWithValue creates a metric with the given value, see AddNewMetric.
+func NewMetricWithValue(metricType string, metricName string, labels metric.Labels, metricValue any, histogramBounds []float64) (metric.Metric, error) {
+	m, err := metric.NewLabeledMetric(metric.MetricType(metricType), metricName, labels)
 	if err != nil {
 		return nil, err
 	}
@@ -45,26 +93,43 @@ func NewMetricWithValue(metricType string, metricName string, metricValue any) (
 		if err := counter.Update(metricValue); err != nil {
 			return nil, err
 		}
+	} else if histogram, ok := m.(*metric.Histogram); ok {
+		histogram.DefaultBounds = histogramBounds
+		if err := histogram.Update(metricValue); err != nil {
+			return nil, err
+		}
+	} else if summary, ok := m.(*metric.Summary); ok {
+		if err := summary.Update(metricValue); err != nil {
+			return nil, err
+		}
 	}
 
 	return m, nil
 }
 
-func UpdateMetricByValue(ctx context.Context, storage storage.Storage, metricType string, metricName string, metricValue interface{}) (metric.Metric, error) {
+// UpdateMetricByValue adds the metric with the given value, or updates it if it
+// is already stored, and returns the stored metric. ts is the client-side time the value was sampled at, zero if unknown.
+// histogramBounds are the bucket bounds of a new histogram, see AddNewMetric.
+func UpdateMetricByValue(ctx context.Context, storage storage.Storage, metricType string, metricName string, labels metric.Labels, metricValue interface{}, ts time.Time, histogramBounds []float64) (metric.Metric, error) {
 
-	m, err := RetrieveMetric(ctx, storage, metricType, metricName)
+	m, err := RetrieveMetric(ctx, storage, metricType, metricName, labels)
 
 	if err != nil {
 		if !errors.Is(err, common.ErrorMetricDoesNotExist) {
 			return nil, err
 		} else {
-			m, err = AddNewMetric(ctx, storage, metricType, metricName, metricValue)
+			m, err = AddNewMetric(ctx, storage, metricType, metricName, labels, metricValue, ts, histogramBounds)
 			if err != nil {
 				return nil, err
 			}
 		}
 	} else {
-		err = UpdateMetric(ctx, storage, m, metricValue)
+		err = UpdateMetric(ctx, storage, m, metricValue, ts)
+		if err != nil {
+			return nil, err
+		}
+		// m is a copy taken before the update
+		m, err = RetrieveMetric(ctx, storage, metricType, metricName, labels)
 		if err != nil {
 			return nil, err
 		}
@@ -76,6 +141,8 @@ func UpdateMetricByValue(ctx context.Context, storage storage.Storage, metricTyp
 
 // curl -v -X POST 'http://localhost:8080/update/' -H "Content-Type: application/json" -d '{"id":"g22","type":"gauge","value":123.12}'
 // curl -v -X POST 'http://localhost:8080/update/' -H "Content-Type: application/json" -d '{"id":"c33","type":"counter","delta":3}'
+// curl -v -X POST 'http://localhost:8080/update/' -H "Content-Type: application/json" -d '{"id":"h44","type":"histogram","value":0.3}'
+// curl -v -X POST 'http://localhost:8080/update/' -H "Content-Type: application/json" -d '{"id":"s55","type":"summary","value":0.3}'
 
 func FillValue(m metric.Metric, r *dto.Metrics) error {
 	switch m.GetType() {
@@ -93,8 +160,76 @@ func FillValue(m metric.Metric, r *dto.Metrics) error {
 		} else {
 			return common.ErrorTypeConversion
 		}
+	case metric.MetricTypeHistogram:
+		histogramVal, ok := m.GetValue().(metric.HistogramValue)
+		if ok {
+			r.Histogram = HistogramToDto(histogramVal)
+		} else {
+			return common.ErrorTypeConversion
+		}
+	case metric.MetricTypeSummary:
+		summaryVal, ok := m.GetValue().(metric.SummaryValue)
+		if ok {
+			r.Summary = SummaryToDto(summaryVal)
+			r.Quantiles = QuantilesToDto(summaryVal)
+		} else {
+			return common.ErrorTypeConversion
+		}
 	default:
 		return metric.ErrorInvalidMetricType
 	}
 	return nil
 }
+
+// FillTimestamps sets the client-side timestamp of the metric's value and the
+// times it was added and last updated, each if known, in the DTO.
+func FillTimestamps(tm storage.TimestampedMetric, r *dto.Metrics) {
+	r.Timestamp = timePtr(tm.Metric.GetTimestamp())
+	r.CreatedAt = timePtr(tm.CreatedAt)
+	r.UpdatedAt = timePtr(tm.UpdatedAt)
+}
+
+// timePtr returns a pointer to t, or nil if t is zero.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// HistogramToDto converts a histogram value to its DTO.
+func HistogramToDto(v metric.HistogramValue) *dto.Histogram {
+	return &dto.Histogram{Bounds: v.Bounds, Counts: v.Counts, Count: v.Count, Sum: v.Sum}
+}
+
+// HistogramFromDto converts a histogram DTO to a histogram value, which is not validated.
+func HistogramFromDto(h *dto.Histogram) metric.HistogramValue {
+	return metric.HistogramValue{Bounds: h.Bounds, Counts: h.Counts, Count: h.Count, Sum: h.Sum}
+}
+
+// SummaryToDto converts a summary value to its DTO.
+func SummaryToDto(v metric.SummaryValue) *dto.Summary {
+	return &dto.Summary{RelativeAccuracy: v.RelativeAccuracy, Positive: v.Positive, Negative: v.Negative, Zero: v.Zero,
+		Count: v.Count, Sum: v.Sum, Min: v.Min, Max: v.Max}
+}
+
+// SummaryFromDto converts a summary DTO to a summary value, which is not validated.
+func SummaryFromDto(s *dto.Summary) metric.SummaryValue {
+	return metric.SummaryValue{RelativeAccuracy: s.RelativeAccuracy, Positive: s.Positive, Negative: s.Negative, Zero: s.Zero,
+		Count: s.Count, Sum: s.Sum, Min: s.Min, Max: s.Max}
+}
+
+// QuantilesToDto returns the metric.DefaultSummaryQuantiles of a summary value
+// by their rank, e.g. "0.99", or nil if it has no observed values.
+func QuantilesToDto(v metric.SummaryValue) map[string]float64 {
+	quantiles := v.Quantiles()
+	if len(quantiles) == 0 {
+		return nil
+	}
+
+	result := make(map[string]float64, len(quantiles))
+	for _, q := range quantiles {
+		result[strconv.FormatFloat(q.Q, 'g', -1, 64)] = q.Value
+	}
+	return result
+}
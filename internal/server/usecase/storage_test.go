@@ -5,6 +5,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/dto"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
@@ -48,7 +49,7 @@ func TestHTTPServer_retrieveMetric(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			s := prepareTestStorage()
 
-			got, err := RetrieveMetric(ctx, s, tt.args.metricType, tt.args.metricName)
+			got, err := RetrieveMetric(ctx, s, tt.args.metricType, tt.args.metricName, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPServer.retrieveMetric() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -80,11 +81,11 @@ func TestHTTPServer_updateMetric(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := UpdateMetric(ctx, s, tt.args.m, tt.args.metricValue); (err != nil) != tt.wantErr {
+			if err := UpdateMetric(ctx, s, tt.args.m, tt.args.metricValue, time.Time{}); (err != nil) != tt.wantErr {
 				t.Errorf("HTTPServer.updateMetric() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			m, err := RetrieveMetric(ctx, s, string(tt.args.m.GetType()), tt.args.m.GetName())
+			m, err := RetrieveMetric(ctx, s, string(tt.args.m.GetType()), tt.args.m.GetName(), nil)
 			if err != nil {
 				t.Errorf("HTTPServer.updateMetric() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -117,7 +118,7 @@ func TestHTTPServer_addNewMetric(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := AddNewMetric(ctx, s, tt.args.metricType, tt.args.metricName, tt.args.metricValue)
+			got, err := AddNewMetric(ctx, s, tt.args.metricType, tt.args.metricName, nil, tt.args.metricValue, time.Time{}, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPServer.addNewMetric() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -128,7 +129,7 @@ func TestHTTPServer_addNewMetric(t *testing.T) {
 
 			if !tt.wantErr {
 
-				m, err := RetrieveMetric(ctx, s, tt.args.metricType, tt.args.metricName)
+				m, err := RetrieveMetric(ctx, s, tt.args.metricType, tt.args.metricName, nil)
 				if err != nil {
 					t.Errorf("HTTPServer.updateMetric() error = %v, wantErr %v", err, tt.wantErr)
 				}
@@ -145,6 +146,7 @@ func TestHTTPServer_newMetricWithValue(t *testing.T) {
 		metricValue any
 		metricType  string
 		metricName  string
+		labels      metric.Labels
 	}
 	tests := []struct {
 		args    args
@@ -155,10 +157,12 @@ func TestHTTPServer_newMetricWithValue(t *testing.T) {
 		{name: "Counter", args: args{metricType: "counter", metricName: "c1", metricValue: int64(1)}, wantErr: false, want: &metric.Counter{Name: "c1", Value: int64(1)}},
 		{name: "Gauge", args: args{metricType: "gauge", metricName: "g1", metricValue: float64(1.234)}, wantErr: false, want: &metric.Gauge{Name: "g1", Value: float64(1.234)}},
 		{name: "Gauge", args: args{metricType: "unknown", metricName: "g1", metricValue: float64(1.234)}, wantErr: true, want: nil},
+		{name: "Labeled", args: args{metricType: "gauge", metricName: "g1", labels: metric.Labels{"host": "web1"}, metricValue: float64(1)}, wantErr: false, want: &metric.Gauge{Name: "g1", Labels: metric.Labels{"host": "web1"}, Value: float64(1)}},
+		{name: "Invalid labels", args: args{metricType: "gauge", metricName: "g1", labels: metric.Labels{"host": ""}, metricValue: float64(1)}, wantErr: true, want: nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewMetricWithValue(tt.args.metricType, tt.args.metricName, tt.args.metricValue)
+			got, err := NewMetricWithValue(tt.args.metricType, tt.args.metricName, tt.args.labels, tt.args.metricValue, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPServer.newMetricWithValue() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -178,7 +182,7 @@ func (f faultyStorage) Add(ctx context.Context, m metric.Metric) error {
 func (f faultyStorage) Update(ctx context.Context, m metric.Metric, v interface{}) error {
 	return errors.New("forced error in Update")
 }
-func (f faultyStorage) Retrieve(ctx context.Context, t metric.MetricType, n string) (metric.Metric, error) {
+func (f faultyStorage) Retrieve(ctx context.Context, t metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
 	return nil, errors.New("forced error in Retrieve")
 }
 func (f faultyStorage) RetrieveAll(ctx context.Context) ([]metric.Metric, error) {
@@ -213,7 +217,7 @@ func TestHTTPServer_updateMetricByValue(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := UpdateMetricByValue(ctx, tt.storage, tt.args.metricType, tt.args.metricName, tt.args.metricValue)
+			got, err := UpdateMetricByValue(ctx, tt.storage, tt.args.metricType, tt.args.metricName, nil, tt.args.metricValue, time.Time{}, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPServer.updateMetricByValue() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -237,6 +241,14 @@ func (m *UnknownMetric) GetType() metric.MetricType {
 func (m *UnknownMetric) GetName() string {
 	return "unknown"
 }
+func (m *UnknownMetric) GetLabels() metric.Labels {
+	return nil
+}
+
+func (m *UnknownMetric) GetTimestamp() time.Time {
+	return time.Time{}
+}
+
 func (m *UnknownMetric) GetValue() interface{} {
 	return "unknown"
 }
@@ -256,6 +268,8 @@ func TestHTTPServer_fillValue(t *testing.T) {
 	}{
 		{name: "OK", args: args{m: &metric.Counter{Name: "c1", Value: int64(1)}, r: &dto.Metrics{}}, wantErr: false},
 		{name: "Error", args: args{m: &metric.Gauge{Name: "g1", Value: float64(1.234)}, r: &dto.Metrics{}}, wantErr: false},
+		{name: "Histogram", args: args{m: metric.NewHistogram("h1"), r: &dto.Metrics{}}, wantErr: false},
+		{name: "Summary", args: args{m: metric.NewSummary("s1"), r: &dto.Metrics{}}, wantErr: false},
 		{name: "Error", args: args{m: &UnknownMetric{}}, wantErr: true},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// SaveBaselines replaces the stored baselines of anomaly rules with the given ones in a single transaction.
+func (c *PostgresClient) SaveBaselines(ctx context.Context, baselines []alerting.Baseline) error {
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from alert_baselines"); err != nil {
+		return err
+	}
+
+	s := `insert into alert_baselines (rule_name, metric_type, metric_name, metric_labels, mean, variance, count, updated_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	for _, b := range baselines {
+		labels, err := labelsJSON(b.MetricLabels)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, s, b.Rule, b.MetricType, b.MetricName, labels, b.Mean, b.Variance, b.Count, b.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadBaselines returns all stored baselines of anomaly rules.
+func (c *PostgresClient) LoadBaselines(ctx context.Context) ([]alerting.Baseline, error) {
+
+	s := "select rule_name, metric_type, metric_name, metric_labels, mean, variance, count, updated_at from alert_baselines"
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		return c.db.QueryContext(ctx, s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]alerting.Baseline, 0)
+
+	for rows.Next() {
+		var b alerting.Baseline
+		var metricType string
+		var labels []byte
+
+		err := rows.Scan(&b.Rule, &metricType, &b.MetricName, &labels, &b.Mean, &b.Variance, &b.Count, &b.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		b.MetricLabels, err = labelsFromJSON(labels)
+		if err != nil {
+			return nil, err
+		}
+
+		b.MetricType = metric.MetricType(metricType)
+		result = append(result, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresClient_SaveBaselines(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	baselines := []alerting.Baseline{
+		{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Mean: 300e6, Variance: 1e12, Count: 20, UpdatedAt: now},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from alert_baselines").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into alert_baselines").
+			WithArgs("r1", metric.MetricTypeGauge, "HeapAlloc", "{}", 300e6, 1e12, 20, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, client.SaveBaselines(ctx, baselines))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error rolls back", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from alert_baselines").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into alert_baselines").WillReturnError(errors.New("forced"))
+		mock.ExpectRollback()
+
+		require.Error(t, client.SaveBaselines(ctx, baselines))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostgresClient_LoadBaselines(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	rows := sqlmock.NewRows([]string{"rule_name", "metric_type", "metric_name", "metric_labels", "mean", "variance", "count", "updated_at"}).
+		AddRow("r1", "gauge", "HeapAlloc", []byte("{}"), 300e6, 1e12, 20, now)
+
+	mock.ExpectQuery("select rule_name").WillReturnRows(rows)
+
+	baselines, err := client.LoadBaselines(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []alerting.Baseline{
+		{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Mean: 300e6, Variance: 1e12, Count: 20, UpdatedAt: now},
+	}, baselines)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+)
+
+// AppendHistory inserts the transitions into the history table in a single transaction.
+func (c *PostgresClient) AppendHistory(ctx context.Context, transitions []alerting.Transition) error {
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	s := `insert into alert_history (rule_name, metric_type, metric_name, metric_labels, from_state, to_state, at, alert)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	for _, t := range transitions {
+		alert, err := json.Marshal(t.Alert)
+		if err != nil {
+			return common.ErrorMarshallingJSON
+		}
+
+		labels, err := labelsJSON(t.Alert.MetricLabels)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, s, t.Alert.Rule, t.Alert.MetricType, t.Alert.MetricName, labels, t.From, t.To, t.At, alert)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// historyFilter builds the where clause and its arguments for a history query.
+func historyFilter(q alerting.HistoryQuery) (string, []any) {
+	var conds []string
+	var args []any
+
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if q.Rule != "" {
+		add("rule_name = $%d", q.Rule)
+	}
+	if q.MetricType != "" {
+		add("metric_type = $%d", string(q.MetricType))
+	}
+	if q.MetricName != "" {
+		add("metric_name = $%d", q.MetricName)
+	}
+	if !q.From.IsZero() {
+		add("at >= $%d", q.From)
+	}
+	if !q.To.IsZero() {
+		add("at < $%d", q.To)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " where " + strings.Join(conds, " and "), args
+}
+
+// QueryHistory returns the matching transitions, newest first,
+// and the total number of matching transitions before pagination.
+func (c *PostgresClient) QueryHistory(ctx context.Context, q alerting.HistoryQuery) ([]alerting.Transition, int, error) {
+
+	where, args := historyFilter(q)
+
+	var total int
+	_, err := common.RetryWithResult(ctx, func() (*sql.Row, error) {
+		r := c.db.QueryRowContext(ctx, "select count(*) from alert_history"+where, args...)
+		return r, r.Scan(&total)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s := "select from_state, to_state, at, alert from alert_history" + where + " order by at desc, id desc"
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		s += fmt.Sprintf(" limit $%d", len(args))
+	}
+	if q.Offset > 0 {
+		args = append(args, q.Offset)
+		s += fmt.Sprintf(" offset $%d", len(args))
+	}
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		return c.db.QueryContext(ctx, s, args...)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	result := make([]alerting.Transition, 0)
+
+	for rows.Next() {
+		var t alerting.Transition
+		var alert []byte
+
+		if err := rows.Scan(&t.From, &t.To, &t.At, &alert); err != nil {
+			return nil, 0, err
+		}
+
+		if err := json.Unmarshal(alert, &t.Alert); err != nil {
+			return nil, 0, err
+		}
+
+		result = append(result, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return result, total, nil
+}
+
+// PruneHistory removes transitions older than before, unless before is zero,
+// and the oldest transitions exceeding maxCount, unless maxCount is zero.
+// Returns the number of removed transitions.
+func (c *PostgresClient) PruneHistory(ctx context.Context, before time.Time, maxCount int) (int, error) {
+
+	removed := 0
+
+	if !before.IsZero() {
+		res, err := c.db.ExecContext(ctx, "delete from alert_history where at < $1", before)
+		if err != nil {
+			return removed, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return removed, err
+		}
+		removed += int(n)
+	}
+
+	if maxCount > 0 {
+		s := `delete from alert_history where id <= (select id from alert_history order by id desc offset $1 limit 1)`
+		res, err := c.db.ExecContext(ctx, s, maxCount)
+		if err != nil {
+			return removed, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return removed, err
+		}
+		removed += int(n)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresClient_AppendHistory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	transitions := []alerting.Transition{{
+		Alert: alerting.Alert{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", MetricLabels: metric.Labels{"host": "a"}, State: alerting.StateFiring, Value: 600e6},
+		From:  alerting.StatePending,
+		To:    alerting.StateFiring,
+		At:    now,
+	}}
+	alert, err := json.Marshal(transitions[0].Alert)
+	require.NoError(t, err)
+
+	t.Run("ok", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into alert_history").
+			WithArgs("r1", metric.MetricTypeGauge, "HeapAlloc", `{"host":"a"}`, alerting.StatePending, alerting.StateFiring, now, alert).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, client.AppendHistory(ctx, transitions))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error rolls back", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("insert into alert_history").WillReturnError(errors.New("forced"))
+		mock.ExpectRollback()
+
+		require.Error(t, client.AppendHistory(ctx, transitions))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostgresClient_QueryHistory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	q := alerting.HistoryQuery{Rule: "r1", MetricType: metric.MetricTypeGauge, From: now, To: now.Add(time.Hour), Offset: 10, Limit: 5}
+	where := " where rule_name = $1 and metric_type = $2 and at >= $3 and at < $4"
+
+	mock.ExpectQuery("select count(*) from alert_history"+where).
+		WithArgs("r1", "gauge", now, now.Add(time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+
+	mock.ExpectQuery("select from_state, to_state, at, alert from alert_history"+where+" order by at desc, id desc limit $5 offset $6").
+		WithArgs("r1", "gauge", now, now.Add(time.Hour), 5, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"from_state", "to_state", "at", "alert"}).
+			AddRow("firing", "resolved", now, []byte(`{"rule":"r1","metric_type":"gauge","metric_name":"HeapAlloc","state":"resolved"}`)))
+
+	got, total, err := client.QueryHistory(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, 11, total)
+	require.Len(t, got, 1)
+	assert.Equal(t, alerting.StateFiring, got[0].From)
+	assert.Equal(t, alerting.StateResolved, got[0].To)
+	assert.Equal(t, now, got[0].At)
+	assert.Equal(t, "HeapAlloc", got[0].Alert.MetricName)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresClient_PruneHistory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("by age and count", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectExec("delete from alert_history where at <").WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("delete from alert_history where id <=").WithArgs(100).WillReturnResult(sqlmock.NewResult(0, 2))
+
+		removed, err := client.PruneHistory(ctx, now, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 5, removed)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		removed, err := NewPostgresClientFromDB(sqlDB).PruneHistory(ctx, time.Time{}, 0)
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		mock.ExpectExec("delete from alert_history").WillReturnError(errors.New("forced"))
+
+		_, err = NewPostgresClientFromDB(sqlDB).PruneHistory(ctx, now, 0)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+)
+
+// SaveRules replaces the stored alert rules with the given ones in a single transaction.
+func (c *PostgresClient) SaveRules(ctx context.Context, rules []alerting.Rule) error {
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from alert_rules"); err != nil {
+		return err
+	}
+
+	s := "insert into alert_rules (name, position, definition) values ($1, $2, $3)"
+
+	for i, r := range rules {
+		definition, err := json.Marshal(r)
+		if err != nil {
+			return common.ErrorMarshallingJSON
+		}
+
+		if _, err := tx.ExecContext(ctx, s, r.Name, i, definition); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadRules returns all stored alert rules in evaluation order.
+func (c *PostgresClient) LoadRules(ctx context.Context) ([]alerting.Rule, error) {
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		return c.db.QueryContext(ctx, "select definition from alert_rules order by position")
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]alerting.Rule, 0)
+
+	for rows.Next() {
+		var definition []byte
+		if err := rows.Scan(&definition); err != nil {
+			return nil, err
+		}
+
+		var r alerting.Rule
+		if err := json.Unmarshal(definition, &r); err != nil {
+			return nil, err
+		}
+
+		result = append(result, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
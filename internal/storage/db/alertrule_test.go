@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresClient_SaveRules(t *testing.T) {
+	ctx := context.Background()
+
+	rules := []alerting.Rule{
+		{Name: "r1", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6},
+	}
+	definition := `{"name":"r1","metric_type":"gauge","metric_name":"HeapAlloc","operator":"\u003e","threshold":500000000,"stale_after":"0s","window":"0s","for":"0s","keep_firing_for":"0s"}`
+
+	t.Run("ok", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from alert_rules").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into alert_rules").
+			WithArgs("r1", 0, []byte(definition)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, client.SaveRules(ctx, rules))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error rolls back", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from alert_rules").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into alert_rules").WillReturnError(errors.New("forced"))
+		mock.ExpectRollback()
+
+		require.Error(t, client.SaveRules(ctx, rules))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostgresClient_LoadRules(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ok", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		rows := sqlmock.NewRows([]string{"definition"}).
+			AddRow([]byte(`{"name":"r1","metric_type":"gauge","metric_name":"HeapAlloc","operator":">","threshold":5e8,"for":"1m","disabled":true}`))
+		mock.ExpectQuery("select definition from alert_rules order by position").WillReturnRows(rows)
+
+		rules, err := client.LoadRules(ctx)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, "r1", rules[0].Name)
+		assert.Equal(t, common.Duration{Duration: time.Minute}, rules[0].For)
+		assert.True(t, rules[0].Disabled)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("bad definition", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectQuery("select definition").WillReturnRows(sqlmock.NewRows([]string{"definition"}).AddRow([]byte("{")))
+
+		_, err = client.LoadRules(ctx)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// nullTime converts a zero time to NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// SaveAlerts replaces the stored alert states with the given ones in a single transaction.
+func (c *PostgresClient) SaveAlerts(ctx context.Context, alerts []alerting.Alert) error {
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from alert_states"); err != nil {
+		return err
+	}
+
+	s := `insert into alert_states (rule_name, metric_type, metric_name, state, value, threshold,
+		active_at, fired_at, resolved_at, last_true_at, last_evaluated_at, acknowledged_by, acknowledged_at, metric_labels)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	for _, a := range alerts {
+		labels, err := labelsJSON(a.MetricLabels)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, s, a.Rule, a.MetricType, a.MetricName, a.State, a.Value, a.Threshold,
+			nullTime(a.ActiveAt), nullTime(a.FiredAt), nullTime(a.ResolvedAt), nullTime(a.LastTrueAt), nullTime(a.LastEvaluatedAt),
+			a.AcknowledgedBy, nullTime(a.AcknowledgedAt), labels)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadAlerts returns all stored alert states.
+func (c *PostgresClient) LoadAlerts(ctx context.Context) ([]alerting.Alert, error) {
+
+	s := `select rule_name, metric_type, metric_name, state, value, threshold,
+		active_at, fired_at, resolved_at, last_true_at, last_evaluated_at, acknowledged_by, acknowledged_at, metric_labels
+		from alert_states`
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		return c.db.QueryContext(ctx, s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]alerting.Alert, 0)
+
+	for rows.Next() {
+		var a alerting.Alert
+		var metricType string
+		var activeAt, firedAt, resolvedAt, lastTrueAt, lastEvaluatedAt, acknowledgedAt sql.NullTime
+		var labels []byte
+
+		err := rows.Scan(&a.Rule, &metricType, &a.MetricName, &a.State, &a.Value, &a.Threshold,
+			&activeAt, &firedAt, &resolvedAt, &lastTrueAt, &lastEvaluatedAt, &a.AcknowledgedBy, &acknowledgedAt, &labels)
+		if err != nil {
+			return nil, err
+		}
+
+		a.MetricLabels, err = labelsFromJSON(labels)
+		if err != nil {
+			return nil, err
+		}
+
+		a.MetricType = metric.MetricType(metricType)
+		a.ActiveAt = activeAt.Time
+		a.FiredAt = firedAt.Time
+		a.ResolvedAt = resolvedAt.Time
+		a.LastTrueAt = lastTrueAt.Time
+		a.LastEvaluatedAt = lastEvaluatedAt.Time
+		a.AcknowledgedAt = acknowledgedAt.Time
+
+		result = append(result, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
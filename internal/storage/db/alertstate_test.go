@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresClient_SaveAlerts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	alerts := []alerting.Alert{
+		{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", MetricLabels: metric.Labels{"host": "web1"},
+			State: alerting.StateFiring, Value: 600e6, Threshold: 500e6, ActiveAt: now, FiredAt: now, LastTrueAt: now, LastEvaluatedAt: now,
+			AcknowledgedBy: "jane", AcknowledgedAt: now},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from alert_states").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into alert_states").
+			WithArgs("r1", metric.MetricTypeGauge, "HeapAlloc", alerting.StateFiring, 600e6, 500e6,
+				nullTime(now), nullTime(now), nullTime(time.Time{}), nullTime(now), nullTime(now), "jane", nullTime(now), `{"host":"web1"}`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, client.SaveAlerts(ctx, alerts))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error rolls back", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from alert_states").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into alert_states").WillReturnError(errors.New("forced"))
+		mock.ExpectRollback()
+
+		require.Error(t, client.SaveAlerts(ctx, alerts))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostgresClient_LoadAlerts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	rows := sqlmock.NewRows([]string{"rule_name", "metric_type", "metric_name", "state", "value", "threshold",
+		"active_at", "fired_at", "resolved_at", "last_true_at", "last_evaluated_at",
+		"acknowledged_by", "acknowledged_at", "metric_labels"}).
+		AddRow("r1", "gauge", "HeapAlloc", "firing", 600e6, 500e6, now, now, nil, now, now, "jane", now, []byte(`{"host": "web1"}`))
+
+	mock.ExpectQuery("select rule_name").WillReturnRows(rows)
+
+	alerts, err := client.LoadAlerts(ctx)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	a := alerts[0]
+	assert.Equal(t, "r1", a.Rule)
+	assert.Equal(t, metric.MetricTypeGauge, a.MetricType)
+	assert.Equal(t, metric.Labels{"host": "web1"}, a.MetricLabels)
+	assert.Equal(t, alerting.StateFiring, a.State)
+	assert.Equal(t, now, a.FiredAt)
+	assert.True(t, a.ResolvedAt.IsZero())
+	assert.Equal(t, "jane", a.AcknowledgedBy)
+	assert.Equal(t, now, a.AcknowledgedAt)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,7 +5,16 @@
 //
 // This abstraction is useful for dependency injection and testability of database-related logic.
 // It defines the PostgresClient type which implements methods for persisting,
-// retrieving, and updating metrics using a relational database.
+// retrieving, and updating metrics using a relational database; labels of
+// metrics are stored in a JSONB column that is part of the primary key, and
+// values of histograms and summaries in JSONB columns; they are merged in a
+// transaction holding the lock of their row. The metadata of metric names
+// (storage.MetadataStorage) is kept in a table of its own.
+// PostgresClient also persists alert states, baselines of anomaly rules,
+// alert rules, silences, the alert transition history and the notification
+// outbox (alerting.StateStore, alerting.BaselineStore, alerting.RuleStore,
+// alerting.SilenceStore, alerting.HistoryStore, alerting.HistoryPruner and
+// alerting.OutboxStore).
 // This package also includes database migration support via goose,
 // and provides abstractions for executing queries within or outside transactions.
 //
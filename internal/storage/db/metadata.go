@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+)
+
+// SetMetadata stores the metadata of the metric name, replacing the existing one.
+func (c *PostgresClient) SetMetadata(ctx context.Context, name string, md metric.Metadata) error {
+
+	s := `insert into metric_metadata (metric_name, help, unit, owner) values ($1, $2, $3, $4)
+		on conflict (metric_name) do update set help = excluded.help, unit = excluded.unit, owner = excluded.owner`
+
+	_, err := common.RetryWithResult(ctx, func() (sql.Result, error) {
+		return c.db.ExecContext(ctx, s, name, md.Help, string(md.Unit), md.Owner)
+	})
+	return err
+}
+
+// AddMetadata stores the metadata of the metric name unless it has one.
+func (c *PostgresClient) AddMetadata(ctx context.Context, name string, md metric.Metadata) error {
+
+	s := `insert into metric_metadata (metric_name, help, unit, owner) values ($1, $2, $3, $4)
+		on conflict (metric_name) do nothing`
+
+	res, err := common.RetryWithResult(ctx, func() (sql.Result, error) {
+		return c.db.ExecContext(ctx, s, name, md.Help, string(md.Unit), md.Owner)
+	})
+	if err != nil {
+		return err
+	}
+
+	return affectedOrError(res, common.ErrorMetadataAlreadyExists)
+}
+
+// RetrieveMetadata returns the metadata of the metric name.
+func (c *PostgresClient) RetrieveMetadata(ctx context.Context, name string) (metric.Metadata, error) {
+
+	var md metric.Metadata
+	var unit string
+
+	row := c.db.QueryRowContext(ctx, "select help, unit, owner from metric_metadata where metric_name = $1", name)
+	err := row.Scan(&md.Help, &unit, &md.Owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return metric.Metadata{}, common.ErrorMetadataDoesNotExist
+	}
+	if err != nil {
+		return metric.Metadata{}, err
+	}
+
+	md.Unit = metric.Unit(unit)
+	return md, nil
+}
+
+// RetrieveAllMetadata returns the metadata of all metric names that have one.
+func (c *PostgresClient) RetrieveAllMetadata(ctx context.Context) (map[string]metric.Metadata, error) {
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		return c.db.QueryContext(ctx, "select metric_name, help, unit, owner from metric_metadata")
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]metric.Metadata)
+
+	for rows.Next() {
+		var name, unit string
+		var md metric.Metadata
+
+		if err := rows.Scan(&name, &md.Help, &unit, &md.Owner); err != nil {
+			return nil, err
+		}
+
+		md.Unit = metric.Unit(unit)
+		result[name] = md
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteMetadata removes the metadata of the metric name.
+func (c *PostgresClient) DeleteMetadata(ctx context.Context, name string) error {
+
+	res, err := common.RetryWithResult(ctx, func() (sql.Result, error) {
+		return c.db.ExecContext(ctx, "delete from metric_metadata where metric_name = $1", name)
+	})
+	if err != nil {
+		return err
+	}
+
+	return affectedOrError(res, common.ErrorMetadataDoesNotExist)
+}
+
+// affectedOrError returns errNone if the statement with the result res affected no rows.
+func affectedOrError(res sql.Result, errNone error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errNone
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresClient_AddMetadata(t *testing.T) {
+	md := metric.Metadata{Help: "Heap bytes in use.", Unit: metric.UnitBytes, Owner: "runtime"}
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{"added", 1, nil},
+		{"exists", 0, common.ErrorMetadataAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			client := NewPostgresClientFromDB(sqlDB)
+
+			mock.ExpectExec("insert into metric_metadata .* do nothing").
+				WithArgs("HeapAlloc", md.Help, "bytes", md.Owner).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err = client.AddMetadata(context.Background(), "HeapAlloc", md)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresClient_RetrieveMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	mock.ExpectQuery("select help, unit, owner from metric_metadata").WithArgs("HeapAlloc").
+		WillReturnRows(sqlmock.NewRows([]string{"help", "unit", "owner"}).AddRow("Heap bytes in use.", "bytes", "runtime"))
+	mock.ExpectQuery("select help, unit, owner from metric_metadata").WithArgs("Unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"help", "unit", "owner"}))
+
+	md, err := client.RetrieveMetadata(ctx, "HeapAlloc")
+	require.NoError(t, err)
+	assert.Equal(t, metric.Metadata{Help: "Heap bytes in use.", Unit: metric.UnitBytes, Owner: "runtime"}, md)
+
+	_, err = client.RetrieveMetadata(ctx, "Unknown")
+	require.ErrorIs(t, err, common.ErrorMetadataDoesNotExist)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresClient_RetrieveAllMetadata(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	mock.ExpectQuery("select metric_name, help, unit, owner from metric_metadata").
+		WillReturnRows(sqlmock.NewRows([]string{"metric_name", "help", "unit", "owner"}).
+			AddRow("HeapAlloc", "Heap bytes in use.", "bytes", "").
+			AddRow("PollCount", "Number of polls.", "", "agent"))
+
+	md, err := client.RetrieveAllMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]metric.Metadata{
+		"HeapAlloc": {Help: "Heap bytes in use.", Unit: metric.UnitBytes},
+		"PollCount": {Help: "Number of polls.", Owner: "agent"},
+	}, md)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresClient_DeleteMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	mock.ExpectExec("delete from metric_metadata").WithArgs("HeapAlloc").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("delete from metric_metadata").WithArgs("HeapAlloc").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("delete from metric_metadata").WithArgs("HeapAlloc").WillReturnError(errors.New("forced"))
+
+	require.NoError(t, client.DeleteMetadata(ctx, "HeapAlloc"))
+	require.ErrorIs(t, client.DeleteMetadata(ctx, "HeapAlloc"), common.ErrorMetadataDoesNotExist)
+	require.Error(t, client.DeleteMetadata(ctx, "HeapAlloc"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
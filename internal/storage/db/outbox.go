@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+)
+
+// SaveOutboxEntry inserts the outbox entry or updates the entry with the same ID.
+func (c *PostgresClient) SaveOutboxEntry(ctx context.Context, e alerting.OutboxEntry) error {
+
+	notification, err := json.Marshal(e.Notification)
+	if err != nil {
+		return err
+	}
+
+	s := `insert into notification_outbox (id, receiver, notification, created_at, attempts, next_attempt_at, last_error, dead_at)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		on conflict (id) do update set attempts = excluded.attempts, next_attempt_at = excluded.next_attempt_at,
+		last_error = excluded.last_error, dead_at = excluded.dead_at`
+
+	deadAt := sql.NullTime{Time: e.DeadAt, Valid: !e.DeadAt.IsZero()}
+
+	_, err = common.RetryWithResult(ctx, func() (sql.Result, error) {
+		return c.db.ExecContext(ctx, s, e.ID, e.Notification.Receiver, notification, e.CreatedAt, e.Attempts, e.NextAttemptAt, e.LastError, deadAt)
+	})
+	return err
+}
+
+// DeleteOutboxEntry removes the outbox entry with the given ID, if it exists.
+func (c *PostgresClient) DeleteOutboxEntry(ctx context.Context, id string) error {
+
+	_, err := common.RetryWithResult(ctx, func() (sql.Result, error) {
+		return c.db.ExecContext(ctx, "delete from notification_outbox where id = $1", id)
+	})
+	return err
+}
+
+// LoadOutbox returns all stored outbox entries, oldest first.
+func (c *PostgresClient) LoadOutbox(ctx context.Context) ([]alerting.OutboxEntry, error) {
+
+	s := `select id, notification, created_at, attempts, next_attempt_at, last_error, dead_at
+		from notification_outbox order by created_at, id`
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		return c.db.QueryContext(ctx, s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]alerting.OutboxEntry, 0)
+
+	for rows.Next() {
+		var e alerting.OutboxEntry
+		var notification []byte
+		var deadAt sql.NullTime
+
+		err := rows.Scan(&e.ID, &notification, &e.CreatedAt, &e.Attempts, &e.NextAttemptAt, &e.LastError, &deadAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(notification, &e.Notification); err != nil {
+			return nil, err
+		}
+		if deadAt.Valid {
+			e.DeadAt = deadAt.Time
+		}
+
+		result = append(result, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOutboxEntry(now time.Time) alerting.OutboxEntry {
+	alerts := []alerting.Alert{{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", State: alerting.StateFiring, FiredAt: now}}
+	return alerting.OutboxEntry{ID: "a1", Notification: alerting.NewNotification("ops", alerts), CreatedAt: now, NextAttemptAt: now}
+}
+
+func TestPostgresClient_SaveOutboxEntry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		dead   bool
+		deadAt sql.NullTime
+	}{
+		{"pending", false, sql.NullTime{}},
+		{"dead", true, sql.NullTime{Time: now, Valid: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			client := NewPostgresClientFromDB(sqlDB)
+
+			e := testOutboxEntry(now)
+			if tt.dead {
+				e.Attempts = 10
+				e.LastError = "forced"
+				e.DeadAt = now
+			}
+
+			mock.ExpectExec("insert into notification_outbox").
+				WithArgs("a1", "ops", sqlmock.AnyArg(), now, e.Attempts, now, e.LastError, tt.deadAt).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			require.NoError(t, client.SaveOutboxEntry(ctx, e))
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresClient_DeleteOutboxEntry(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	mock.ExpectExec("delete from notification_outbox").WithArgs("a1").WillReturnError(errors.New("forced"))
+
+	require.Error(t, client.DeleteOutboxEntry(context.Background(), "a1"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresClient_LoadOutbox(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	notification := `{"receiver":"ops","status":"firing","alerts":[{"rule":"r1","metric_type":"gauge","metric_name":"HeapAlloc","state":"firing"}],"group_key":""}`
+	rows := sqlmock.NewRows([]string{"id", "notification", "created_at", "attempts", "next_attempt_at", "last_error", "dead_at"}).
+		AddRow("a1", []byte(notification), now, 0, now, "", nil).
+		AddRow("b2", []byte(notification), now, 10, now, "forced", now)
+
+	mock.ExpectQuery("select id, notification").WillReturnRows(rows)
+
+	entries, err := client.LoadOutbox(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "ops", entries[0].Notification.Receiver)
+	assert.Equal(t, "HeapAlloc", entries[0].Notification.Alerts[0].MetricName)
+	assert.False(t, entries[0].Dead())
+	assert.Equal(t, 10, entries[1].Attempts)
+	assert.Equal(t, now, entries[1].DeadAt)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
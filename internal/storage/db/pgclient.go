@@ -3,10 +3,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 	"github.com/dmitrijs2005/metric-alerting-service/migrations"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -15,7 +18,8 @@ import (
 // PostgresClient provides a database-backed implementation of metric storage.
 // It uses *sql.DB internally and supports transactional operations via DBExecutor.
 type PostgresClient struct {
-	db *sql.DB
+	db         *sql.DB
+	OutOfOrder storage.OutOfOrderPolicy // what to do with out-of-order gauge samples
 }
 
 // NewPostgresClient creates a new PostgresClient using the given DSN (Data Source Name).
@@ -27,7 +31,7 @@ func NewPostgresClient(dsn string) (*PostgresClient, error) {
 		return nil, err
 	}
 
-	return &PostgresClient{db}, nil
+	return &PostgresClient{db: db}, nil
 }
 
 func NewPostgresClientFromDB(db *sql.DB) *PostgresClient {
@@ -63,10 +67,14 @@ func (c *PostgresClient) RetrieveAll(ctx context.Context) ([]metric.Metric, erro
 
 	var t metric.MetricType
 	var n string
+	var l []byte
 	var mvi sql.NullInt64
 	var mvf sql.NullFloat64
+	var mvh []byte
+	var mvs []byte
+	var sampledAt sql.NullTime
 
-	s := "select metric_type, metric_name, metric_value_int, metric_value_float from metrics"
+	s := "select metric_type, metric_name, labels, metric_value_int, metric_value_float, metric_value_histogram, metric_value_summary, sampled_at from metrics"
 
 	result := make([]metric.Metric, 0)
 
@@ -81,7 +89,7 @@ func (c *PostgresClient) RetrieveAll(ctx context.Context) ([]metric.Metric, erro
 
 	for rows.Next() {
 
-		err := rows.Scan(&t, &n, &mvi, &mvf)
+		err := rows.Scan(&t, &n, &l, &mvi, &mvf, &mvh, &mvs, &sampledAt)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return nil, common.ErrorMetricDoesNotExist
@@ -90,26 +98,11 @@ func (c *PostgresClient) RetrieveAll(ctx context.Context) ([]metric.Metric, erro
 			}
 		}
 
-		m, err := metric.NewMetric(t, n)
-
+		m, err := newMetricFromRow(t, n, l, mvi, mvf, mvh, mvs, sampledAt)
 		if err != nil {
 			return nil, err
 		}
 
-		if gauge, ok := m.(*metric.Gauge); ok {
-			err := gauge.Update(mvf.Float64)
-			if err != nil {
-				return nil, err
-			}
-		} else if counter, ok := m.(*metric.Counter); ok {
-			err := counter.Update(mvi.Int64)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, metric.ErrorInvalidMetricType
-		}
-
 		result = append(result, m)
 
 	}
@@ -123,10 +116,170 @@ func (c *PostgresClient) RetrieveAll(ctx context.Context) ([]metric.Metric, erro
 
 }
 
+// RetrieveAllTimestamped fetches all stored metrics together with the times
+// they were added and last updated.
+func (c *PostgresClient) RetrieveAllTimestamped(ctx context.Context) ([]storage.TimestampedMetric, error) {
+
+	var t metric.MetricType
+	var n string
+	var l []byte
+	var mvi sql.NullInt64
+	var mvf sql.NullFloat64
+	var mvh []byte
+	var mvs []byte
+	var createdAt, updatedAt time.Time
+	var sampledAt sql.NullTime
+
+	s := "select metric_type, metric_name, labels, metric_value_int, metric_value_float, metric_value_histogram, metric_value_summary, created_at, updated_at, sampled_at from metrics"
+
+	result := make([]storage.TimestampedMetric, 0)
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		r, err := c.db.QueryContext(ctx, s)
+		return r, err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+
+		if err := rows.Scan(&t, &n, &l, &mvi, &mvf, &mvh, &mvs, &createdAt, &updatedAt, &sampledAt); err != nil {
+			return nil, err
+		}
+
+		m, err := newMetricFromRow(t, n, l, mvi, mvf, mvh, mvs, sampledAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, storage.TimestampedMetric{Metric: m, CreatedAt: createdAt, UpdatedAt: updatedAt})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// labelsJSON encodes labels for a JSONB column; no labels are stored as an empty object.
+func labelsJSON(l metric.Labels) (string, error) {
+	if len(l) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(l)
+	return string(data), err
+}
+
+// labelsFromJSON decodes labels read from a JSONB column; an empty object means no labels.
+func labelsFromJSON(data []byte) (metric.Labels, error) {
+	var l metric.Labels
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, err
+		}
+	}
+	if len(l) == 0 {
+		return nil, nil
+	}
+	return l, nil
+}
+
+// jsonValueColumn returns the JSONB column holding the values of metrics of
+// the given type, which are merged in Go, or an empty string for gauges and counters.
+func jsonValueColumn(t metric.MetricType) string {
+	switch t {
+	case metric.MetricTypeHistogram:
+		return "metric_value_histogram"
+	case metric.MetricTypeSummary:
+		return "metric_value_summary"
+	default:
+		return ""
+	}
+}
+
+// valueFromJSON decodes the value of a histogram or summary read from its JSONB column.
+func valueFromJSON(t metric.MetricType, data []byte) (interface{}, error) {
+	switch t {
+	case metric.MetricTypeHistogram:
+		var v metric.HistogramValue
+		err := json.Unmarshal(data, &v)
+		return v, err
+	case metric.MetricTypeSummary:
+		var v metric.SummaryValue
+		err := json.Unmarshal(data, &v)
+		return v, err
+	default:
+		return nil, metric.ErrorInvalidMetricType
+	}
+}
+
+// newMetricFromRow builds a metric from the values of a row of the metrics table.
+func newMetricFromRow(t metric.MetricType, n string, l []byte, mvi sql.NullInt64, mvf sql.NullFloat64, mvh []byte, mvs []byte, sampledAt sql.NullTime) (metric.Metric, error) {
+
+	labels, err := labelsFromJSON(l)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := metric.NewLabeledMetric(t, n, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	if gauge, ok := m.(*metric.Gauge); ok {
+		err := gauge.Update(mvf.Float64)
+		if err != nil {
+			return nil, err
+		}
+	} else if counter, ok := m.(*metric.Counter); ok {
+		err := counter.Update(mvi.Int64)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := updateFromJSON(m, mvh, mvs); err != nil {
+		return nil, err
+	}
+
+	if sampledAt.Valid {
+		metric.SetTimestamp(m, sampledAt.Time)
+	}
+
+	return m, nil
+}
+
+// sampledAtColumn returns the client-side timestamp of the metric for the
+// sampled_at column, NULL if it has none.
+func sampledAtColumn(m metric.Metric) sql.NullTime {
+	ts := m.GetTimestamp()
+	return sql.NullTime{Time: ts, Valid: !ts.IsZero()}
+}
+
+// updateFromJSON sets the value of a new histogram or summary from the
+// JSONB column of its type, mvh for histograms and mvs for summaries.
+func updateFromJSON(m metric.Metric, mvh []byte, mvs []byte) error {
+	data := mvh
+	if m.GetType() == metric.MetricTypeSummary {
+		data = mvs
+	}
+
+	v, err := valueFromJSON(m.GetType(), data)
+	if err != nil {
+		return err
+	}
+	return m.Update(v)
+}
+
 // ExecuteAdd inserts a metric using the provided DBExecutor (e.g. tx or db).
 func (c *PostgresClient) ExecuteAdd(ctx context.Context, exec DBExecutor, m metric.Metric) error {
 	var mvi sql.NullInt64
 	var mvf sql.NullFloat64
+	var mvh sql.NullString
+	var mvs sql.NullString
 
 	if gauge, ok := m.(*metric.Gauge); ok {
 		mvi.Valid = false
@@ -136,11 +289,32 @@ func (c *PostgresClient) ExecuteAdd(ctx context.Context, exec DBExecutor, m metr
 		mvi.Int64 = counter.Value
 		mvi.Valid = true
 		mvf.Valid = false
+	} else if histogram, ok := m.(*metric.Histogram); ok {
+		data, err := json.Marshal(histogram.Value)
+		if err != nil {
+			return err
+		}
+		mvh.String = string(data)
+		mvh.Valid = true
+	} else if summary, ok := m.(*metric.Summary); ok {
+		data, err := json.Marshal(summary.Value)
+		if err != nil {
+			return err
+		}
+		mvs.String = string(data)
+		mvs.Valid = true
+	}
+	labels, err := labelsJSON(m.GetLabels())
+	if err != nil {
+		return err
 	}
-	s := "insert into metrics (metric_type, metric_name, metric_value_int, metric_value_float) values ($1, $2, $3, $4)"
 
-	_, err := common.RetryWithResult(ctx, func() (sql.Result, error) {
-		r, err := exec.ExecContext(ctx, s, m.GetType(), m.GetName(), mvi, mvf)
+	s := "insert into metrics (metric_type, metric_name, labels, metric_value_int, metric_value_float, metric_value_histogram, metric_value_summary, created_at, updated_at, sampled_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $8, $9)"
+
+	now := time.Now()
+
+	_, err = common.RetryWithResult(ctx, func() (sql.Result, error) {
+		r, err := exec.ExecContext(ctx, s, m.GetType(), m.GetName(), labels, mvi, mvf, mvh, mvs, now, sampledAtColumn(m))
 		return r, err
 	})
 
@@ -154,20 +328,41 @@ func (c *PostgresClient) Add(ctx context.Context, m metric.Metric) error {
 }
 
 // ExecuteUpdate updates a metric using the provided DBExecutor.
+// The timestamp of m, if set, is the client-side time v was sampled at.
+// Histograms and summaries are merged in Go, and the order of timestamped
+// gauge samples is checked in Go, so exec should be a transaction for them;
+// the row is locked until the new value is written.
 func (c *PostgresClient) ExecuteUpdate(ctx context.Context, exec DBExecutor, m metric.Metric, v interface{}) error {
 
+	labels, err := labelsJSON(m.GetLabels())
+	if err != nil {
+		return err
+	}
+
 	s := "update metrics set "
 
 	if _, ok := m.(*metric.Gauge); ok {
-		s += "metric_value_float = $1 "
+		accepted, err := c.acceptSample(ctx, exec, m, labels)
+		if err != nil || !accepted {
+			return err
+		}
+		s += "metric_value_float = $1, "
 	} else if _, ok := m.(*metric.Counter); ok {
-		s += "metric_value_int = metric_value_int + $1 "
+		s += "metric_value_int = metric_value_int + $1, "
+	} else if column := jsonValueColumn(m.GetType()); column != "" {
+		v, err = c.mergeJSONValue(ctx, exec, m, labels, v)
+		if err != nil {
+			return err
+		}
+		s += column + " = $1, "
 	}
 
-	s += "where metric_type = $2 and metric_name = $3"
+	s += "updated_at = $4, sampled_at = coalesce($6, sampled_at) where metric_type = $2 and metric_name = $3 and labels = $5"
+
+	now := time.Now()
 
-	_, err := common.RetryWithResult(ctx, func() (sql.Result, error) {
-		r, err := exec.ExecContext(ctx, s, v, m.GetType(), m.GetName())
+	_, err = common.RetryWithResult(ctx, func() (sql.Result, error) {
+		r, err := exec.ExecContext(ctx, s, v, m.GetType(), m.GetName(), now, labels, sampledAtColumn(m))
 		return r, err
 	})
 
@@ -175,55 +370,169 @@ func (c *PostgresClient) ExecuteUpdate(ctx context.Context, exec DBExecutor, m m
 
 }
 
+// acceptSample reports whether the gauge sample m may replace the stored value
+// according to c.OutOfOrder. A sample with a timestamp locks the row and
+// compares its timestamp with the stored one.
+func (c *PostgresClient) acceptSample(ctx context.Context, exec DBExecutor, m metric.Metric, labels string) (bool, error) {
+
+	if m.GetTimestamp().IsZero() {
+		return true, nil
+	}
+
+	var sampledAt sql.NullTime
+
+	s := "select sampled_at from metrics where metric_type=$1 and metric_name=$2 and labels=$3 for update"
+
+	_, err := common.RetryWithResult(ctx, func() (*sql.Row, error) {
+		r := exec.QueryRowContext(ctx, s, m.GetType(), m.GetName(), labels)
+		err := r.Scan(&sampledAt)
+		return r, err
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, common.ErrorMetricDoesNotExist
+		}
+		return false, err
+	}
+
+	return c.OutOfOrder.Accept(m, sampledAt.Time)
+}
+
+// mergeJSONValue locks the row of the histogram or summary, merges v into its
+// stored value and returns the merged value encoded for its JSONB column.
+func (c *PostgresClient) mergeJSONValue(ctx context.Context, exec DBExecutor, m metric.Metric, labels string, v interface{}) (string, error) {
+
+	var data []byte
+
+	s := "select " + jsonValueColumn(m.GetType()) + " from metrics where metric_type=$1 and metric_name=$2 and labels=$3 for update"
+
+	_, err := common.RetryWithResult(ctx, func() (*sql.Row, error) {
+		r := exec.QueryRowContext(ctx, s, m.GetType(), m.GetName(), labels)
+		err := r.Scan(&data)
+		return r, err
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", common.ErrorMetricDoesNotExist
+		}
+		return "", err
+	}
+
+	stored, err := valueFromJSON(m.GetType(), data)
+	if err != nil {
+		return "", err
+	}
+
+	merged, err := metric.NewMetric(m.GetType(), m.GetName())
+	if err != nil {
+		return "", err
+	}
+	if err := merged.Update(stored); err != nil {
+		return "", err
+	}
+	if err := merged.Update(v); err != nil {
+		return "", err
+	}
+
+	data, err = json.Marshal(merged.GetValue())
+	return string(data), err
+}
+
 // Update modifies the value of an existing metric.
-// Counters are incremented; gauges are overwritten.
+// Counters are incremented; gauges are overwritten; histograms and summaries
+// are merged in a transaction, as are timestamped gauge samples checked for their order.
 func (c *PostgresClient) Update(ctx context.Context, m metric.Metric, v interface{}) error {
-	return c.ExecuteUpdate(ctx, c.db, m, v)
+	if jsonValueColumn(m.GetType()) == "" && (m.GetType() != metric.MetricTypeGauge || m.GetTimestamp().IsZero()) {
+		return c.ExecuteUpdate(ctx, c.db, m, v)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err := c.ExecuteUpdate(ctx, tx, m, v); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // ExecuteRetrieve fetches a single metric using the provided DBExecutor.
-func (c *PostgresClient) ExecuteRetrieve(ctx context.Context, exec DBExecutor, t metric.MetricType, n string) (metric.Metric, error) {
+func (c *PostgresClient) ExecuteRetrieve(ctx context.Context, exec DBExecutor, t metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
+	tm, err := c.executeRetrieveTimestamped(ctx, exec, t, n, l)
+	return tm.Metric, err
+}
+
+// executeRetrieveTimestamped fetches a single metric with its times using the provided DBExecutor.
+func (c *PostgresClient) executeRetrieveTimestamped(ctx context.Context, exec DBExecutor, t metric.MetricType, n string, l metric.Labels) (storage.TimestampedMetric, error) {
 
 	var mvi sql.NullInt64
 	var mvf sql.NullFloat64
+	var mvh []byte
+	var mvs []byte
+	var createdAt, updatedAt time.Time
+	var sampledAt sql.NullTime
 
-	s := "select metric_value_int, metric_value_float from metrics where metric_type=$1 and metric_name=$2"
+	labels, err := labelsJSON(l)
+	if err != nil {
+		return storage.TimestampedMetric{}, err
+	}
 
-	_, err := common.RetryWithResult(ctx, func() (*sql.Row, error) {
-		r := exec.QueryRowContext(ctx, s, t, n)
-		err := r.Scan(&mvi, &mvf)
+	s := "select metric_value_int, metric_value_float, metric_value_histogram, metric_value_summary, created_at, updated_at, sampled_at from metrics where metric_type=$1 and metric_name=$2 and labels=$3"
+
+	_, err = common.RetryWithResult(ctx, func() (*sql.Row, error) {
+		r := exec.QueryRowContext(ctx, s, t, n, labels)
+		err := r.Scan(&mvi, &mvf, &mvh, &mvs, &createdAt, &updatedAt, &sampledAt)
 		return r, err
 	})
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, common.ErrorMetricDoesNotExist
+			return storage.TimestampedMetric{}, common.ErrorMetricDoesNotExist
 		} else {
-			return nil, err
+			return storage.TimestampedMetric{}, err
 		}
 	}
 
-	m, err := metric.NewMetric(t, n)
+	m, err := metric.NewLabeledMetric(t, n, l)
 	if err != nil {
-		return nil, err
+		return storage.TimestampedMetric{}, err
 	}
 
 	if gauge, ok := m.(*metric.Gauge); ok {
 		gauge.Value = mvf.Float64
 	} else if counter, ok := m.(*metric.Counter); ok {
 		counter.Value = mvi.Int64
+	} else if err := updateFromJSON(m, mvh, mvs); err != nil {
+		return storage.TimestampedMetric{}, err
 	}
 
-	return m, nil
+	if sampledAt.Valid {
+		metric.SetTimestamp(m, sampledAt.Time)
+	}
+
+	return storage.TimestampedMetric{Metric: m, CreatedAt: createdAt, UpdatedAt: updatedAt}, nil
+}
+
+// Retrieve fetches a single metric by type, name and labels.
+func (c *PostgresClient) Retrieve(ctx context.Context, t metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
+	return c.ExecuteRetrieve(ctx, c.db, t, n, l)
 }
 
-// Retrieve fetches a single metric by type and name.
-func (c *PostgresClient) Retrieve(ctx context.Context, t metric.MetricType, n string) (metric.Metric, error) {
-	return c.ExecuteRetrieve(ctx, c.db, t, n)
+// RetrieveTimestamped fetches a single metric by type, name and labels
+// together with the times it was added and last updated.
+func (c *PostgresClient) RetrieveTimestamped(ctx context.Context, t metric.MetricType, n string, l metric.Labels) (storage.TimestampedMetric, error) {
+	return c.executeRetrieveTimestamped(ctx, c.db, t, n, l)
 }
 
 // UpdateBatch updates a slice of metrics using a transaction.
 // New metrics are inserted; existing ones are updated appropriately.
+// An out-of-order gauge sample rejected by c.OutOfOrder rolls back the whole batch.
 func (c *PostgresClient) UpdateBatch(ctx context.Context, metrics *[]metric.Metric) error {
 
 	tx, err := c.db.Begin()
@@ -234,7 +543,7 @@ func (c *PostgresClient) UpdateBatch(ctx context.Context, metrics *[]metric.Metr
 	defer tx.Rollback()
 
 	for _, metric := range *metrics {
-		m, err := c.ExecuteRetrieve(ctx, tx, metric.GetType(), metric.GetName())
+		_, err := c.ExecuteRetrieve(ctx, tx, metric.GetType(), metric.GetName(), metric.GetLabels())
 
 		if err != nil {
 			if errors.Is(err, common.ErrorMetricDoesNotExist) {
@@ -246,7 +555,7 @@ func (c *PostgresClient) UpdateBatch(ctx context.Context, metrics *[]metric.Metr
 				return err
 			}
 		} else {
-			err := c.ExecuteUpdate(ctx, tx, m, metric.GetValue())
+			err := c.ExecuteUpdate(ctx, tx, metric, metric.GetValue())
 			if err != nil {
 				return err
 			}
@@ -2,10 +2,15 @@ package db
 
 import (
 	"context"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
@@ -72,14 +77,14 @@ func RunRepositoryTests(t *testing.T, ctx context.Context, client *PostgresClien
 	t.Run("Retrieve metrics", func(t *testing.T) {
 
 		for _, m := range metrics {
-			got, err := client.Retrieve(ctx, m.GetType(), m.GetName())
+			got, err := client.Retrieve(ctx, m.GetType(), m.GetName(), m.GetLabels())
 
 			assert.NoError(t, err, "Expected no error for existing metric")
 			assert.Equal(t, m, got, "Retrieved metric should match the stored value")
 
 		}
 
-		_, err := client.Retrieve(ctx, metric.MetricTypeCounter, "some_nonexisting_metric")
+		_, err := client.Retrieve(ctx, metric.MetricTypeCounter, "some_nonexisting_metric", nil)
 		assert.Error(t, err, "Expected an error for non-existing metric")
 
 	})
@@ -123,7 +128,7 @@ func RunRepositoryTests(t *testing.T, ctx context.Context, client *PostgresClien
 				err := client.Update(ctx, tt.args.metric, tt.args.value)
 				assert.NoError(t, err)
 
-				got, err := client.Retrieve(ctx, tt.args.metric.GetType(), tt.args.metric.GetName())
+				got, err := client.Retrieve(ctx, tt.args.metric.GetType(), tt.args.metric.GetName(), nil)
 				assert.NoError(t, err, "Expected no error for existing metric")
 				assert.Equal(t, tt.wantValue, got.GetValue(), "Retrieved metric should match the stored value")
 
@@ -169,34 +174,290 @@ func RunRepositoryTests(t *testing.T, ctx context.Context, client *PostgresClien
 
 	})
 
+	t.Run("RetrieveAllTimestamped", func(t *testing.T) {
+
+		before := time.Now().Add(-time.Second)
+		require.NoError(t, client.Update(ctx, &metric.Gauge{Name: "gauge1"}, float64(5)))
+
+		items, err := client.RetrieveAllTimestamped(ctx)
+		require.NoError(t, err)
+
+		found := false
+		for _, item := range items {
+			assert.False(t, item.UpdatedAt.IsZero())
+			if item.Metric.GetName() == "gauge1" {
+				assert.True(t, item.UpdatedAt.After(before))
+				found = true
+			}
+		}
+		assert.True(t, found)
+
+	})
+
+	t.Run("Labels", func(t *testing.T) {
+
+		web1 := &metric.Gauge{Name: "gauge1", Labels: metric.Labels{"host": "web1"}, Value: 1}
+		require.NoError(t, client.Add(ctx, web1))
+
+		batch := []metric.Metric{&metric.Gauge{Name: "gauge1", Labels: metric.Labels{"host": "web1"}, Value: 2}}
+		require.NoError(t, client.UpdateBatch(ctx, &batch))
+
+		got, err := client.Retrieve(ctx, metric.MetricTypeGauge, "gauge1", metric.Labels{"host": "web1"})
+		require.NoError(t, err)
+		assert.Equal(t, &metric.Gauge{Name: "gauge1", Labels: metric.Labels{"host": "web1"}, Value: 2}, got)
+
+		got, err = client.Retrieve(ctx, metric.MetricTypeGauge, "gauge1", nil)
+		require.NoError(t, err)
+		assert.Equal(t, float64(5), got.GetValue(), "the unlabeled metric is kept apart")
+
+	})
+
+	t.Run("Histogram", func(t *testing.T) {
+
+		h := metric.NewHistogram("latency")
+		require.NoError(t, h.Update(metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{2, 1}, Count: 3, Sum: 4.5}))
+		require.NoError(t, client.Add(ctx, h))
+
+		require.NoError(t, client.Update(ctx, metric.NewHistogram("latency"), 0.5))
+
+		batch := []metric.Metric{&metric.Histogram{Name: "latency",
+			Value: metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{0, 1}, Count: 1, Sum: 2}}}
+		require.NoError(t, client.UpdateBatch(ctx, &batch))
+
+		got, err := client.Retrieve(ctx, metric.MetricTypeHistogram, "latency", nil)
+		require.NoError(t, err)
+		assert.Equal(t, metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{3, 2}, Count: 5, Sum: 7}, got.GetValue())
+
+		err = client.Update(ctx, metric.NewHistogram("latency"), metric.HistogramValue{Bounds: []float64{2}, Counts: []int64{1, 0}, Count: 1, Sum: 1})
+		assert.ErrorIs(t, err, metric.ErrorInvalidMetricValue)
+
+	})
+
+	t.Run("Summary", func(t *testing.T) {
+
+		s := metric.NewSummary("latency")
+		require.NoError(t, s.Update(0.2))
+		require.NoError(t, client.Add(ctx, s))
+
+		require.NoError(t, client.Update(ctx, metric.NewSummary("latency"), 1.0))
+
+		other := metric.NewSummary("latency")
+		require.NoError(t, other.Update(-1.0))
+		batch := []metric.Metric{other}
+		require.NoError(t, client.UpdateBatch(ctx, &batch))
+
+		got, err := client.Retrieve(ctx, metric.MetricTypeSummary, "latency", nil)
+		require.NoError(t, err)
+		assert.Equal(t, metric.SummaryValue{RelativeAccuracy: 0.01, Positive: map[int32]int64{-80: 1, 0: 1}, Negative: map[int32]int64{0: 1},
+			Count: 3, Sum: 0.2, Min: -1, Max: 1}, got.GetValue())
+
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+
+		sampled := time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC)
+		require.NoError(t, client.Add(ctx, &metric.Gauge{Name: "temperature", Value: 20, Timestamp: sampled}))
+
+		require.NoError(t, client.Update(ctx, &metric.Gauge{Name: "temperature", Timestamp: sampled.Add(-time.Minute)}, float64(19)))
+
+		client.OutOfOrder = storage.OutOfOrderReject
+		err := client.Update(ctx, &metric.Gauge{Name: "temperature", Timestamp: sampled.Add(-time.Minute)}, float64(19))
+		assert.ErrorIs(t, err, common.ErrorOutOfOrderSample)
+		client.OutOfOrder = ""
+
+		batch := []metric.Metric{&metric.Gauge{Name: "temperature", Value: 21, Timestamp: sampled.Add(time.Minute)}}
+		require.NoError(t, client.UpdateBatch(ctx, &batch))
+
+		got, err := client.RetrieveTimestamped(ctx, metric.MetricTypeGauge, "temperature", nil)
+		require.NoError(t, err)
+		assert.Equal(t, float64(21), got.Metric.GetValue())
+		assert.True(t, sampled.Add(time.Minute).Equal(got.Metric.GetTimestamp()))
+		assert.False(t, got.CreatedAt.After(got.UpdatedAt))
+
+	})
+
+	t.Run("Save and load alerts", func(t *testing.T) {
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		alerts := []alerting.Alert{
+			{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "gauge1", MetricLabels: metric.Labels{"host": "web1"}, State: alerting.StateFiring,
+				Value: 4.15, Threshold: 1, ActiveAt: now, FiredAt: now, LastTrueAt: now, LastEvaluatedAt: now,
+				AcknowledgedBy: "jane", AcknowledgedAt: now},
+		}
+
+		require.NoError(t, client.SaveAlerts(ctx, alerts))
+		require.NoError(t, client.SaveAlerts(ctx, alerts))
+
+		got, err := client.LoadAlerts(ctx)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, alerts[0].Rule, got[0].Rule)
+		assert.Equal(t, alerts[0].MetricLabels, got[0].MetricLabels)
+		assert.Equal(t, alerts[0].State, got[0].State)
+		assert.True(t, alerts[0].FiredAt.Equal(got[0].FiredAt))
+		assert.True(t, got[0].ResolvedAt.IsZero())
+		assert.Equal(t, "jane", got[0].AcknowledgedBy)
+		assert.True(t, alerts[0].AcknowledgedAt.Equal(got[0].AcknowledgedAt))
+
+	})
+
+	t.Run("Save and load rules", func(t *testing.T) {
+
+		rules := []alerting.Rule{
+			{Name: "r2", MetricType: metric.MetricTypeGauge, MetricName: "gauge1", Operator: ">", Threshold: 1},
+			{Name: "r1", MetricType: metric.MetricTypeCounter, MetricName: "counter1", Operator: "<", Threshold: 10, Disabled: true},
+		}
+
+		require.NoError(t, client.SaveRules(ctx, rules))
+		require.NoError(t, client.SaveRules(ctx, rules))
+
+		got, err := client.LoadRules(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, rules, got)
+
+	})
+
+	t.Run("Save and load baselines", func(t *testing.T) {
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		baselines := []alerting.Baseline{
+			{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "gauge1", Mean: 4.15, Variance: 0.25, Count: 12, UpdatedAt: now},
+		}
+
+		require.NoError(t, client.SaveBaselines(ctx, baselines))
+		require.NoError(t, client.SaveBaselines(ctx, baselines))
+
+		got, err := client.LoadBaselines(ctx)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.True(t, now.Equal(got[0].UpdatedAt))
+		got[0].UpdatedAt = now
+		assert.Equal(t, baselines, got)
+
+	})
+
+	t.Run("Save, load and delete outbox entries", func(t *testing.T) {
+
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		alerts := []alerting.Alert{{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "gauge1", State: alerting.StateFiring}}
+		e := alerting.OutboxEntry{ID: "a1", Notification: alerting.NewNotification("ops", alerts), CreatedAt: now, NextAttemptAt: now}
+
+		require.NoError(t, client.SaveOutboxEntry(ctx, e))
+		e.Attempts = 3
+		e.DeadAt = now
+		require.NoError(t, client.SaveOutboxEntry(ctx, e))
+
+		got, err := client.LoadOutbox(ctx)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, 3, got[0].Attempts)
+		assert.True(t, now.Equal(got[0].DeadAt))
+		assert.Equal(t, "gauge1", got[0].Notification.Alerts[0].MetricName)
+
+		require.NoError(t, client.DeleteOutboxEntry(ctx, e.ID))
+		got, err = client.LoadOutbox(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+
+	})
+
+	t.Run("Set, load and delete metadata", func(t *testing.T) {
+
+		md := metric.Metadata{Help: "Heap bytes in use.", Unit: metric.UnitBytes}
+		require.NoError(t, client.AddMetadata(ctx, "gauge1", md))
+		require.ErrorIs(t, client.AddMetadata(ctx, "gauge1", metric.Metadata{Owner: "agent"}), common.ErrorMetadataAlreadyExists)
+
+		md.Owner = "runtime"
+		require.NoError(t, client.SetMetadata(ctx, "gauge1", md))
+
+		got, err := client.RetrieveMetadata(ctx, "gauge1")
+		require.NoError(t, err)
+		assert.Equal(t, md, got)
+
+		all, err := client.RetrieveAllMetadata(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]metric.Metadata{"gauge1": md}, all)
+
+		require.NoError(t, client.DeleteMetadata(ctx, "gauge1"))
+		require.ErrorIs(t, client.DeleteMetadata(ctx, "gauge1"), common.ErrorMetadataDoesNotExist)
+		_, err = client.RetrieveMetadata(ctx, "gauge1")
+		require.ErrorIs(t, err, common.ErrorMetadataDoesNotExist)
+
+	})
+
+	t.Run("Append, query and prune history", func(t *testing.T) {
+
+		t0 := time.Now().UTC().Truncate(time.Millisecond)
+		var transitions []alerting.Transition
+		for i := 0; i < 4; i++ {
+			transitions = append(transitions, alerting.Transition{
+				Alert: alerting.Alert{Rule: "r1", MetricType: metric.MetricTypeGauge, MetricName: "gauge1", State: alerting.StateFiring},
+				From:  alerting.StateInactive, To: alerting.StateFiring, At: t0.Add(time.Duration(i) * time.Minute),
+			})
+		}
+
+		require.NoError(t, client.AppendHistory(ctx, transitions))
+
+		got, total, err := client.QueryHistory(ctx, alerting.HistoryQuery{Rule: "r1", Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 4, total)
+		require.Len(t, got, 2)
+		assert.True(t, t0.Add(2*time.Minute).Equal(got[0].At))
+		assert.Equal(t, "gauge1", got[0].Alert.MetricName)
+
+		removed, err := client.PruneHistory(ctx, t0.Add(time.Minute), 2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, removed)
+
+		_, total, err = client.QueryHistory(ctx, alerting.HistoryQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+
+	})
+
 }
 
 func TestPostgresClient_RetrieveAll(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("happy path: returns counter and gauge", func(t *testing.T) {
+	t.Run("happy path: returns counter, gauge, histogram and summary", func(t *testing.T) {
 		sqlDB, mock, err := sqlmock.New()
 		require.NoError(t, err)
 		defer sqlDB.Close()
 
 		client := &PostgresClient{db: sqlDB}
 
-		rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "metric_value_int", "metric_value_float"}).
-			AddRow("counter", "requests", int64(42), nil).
-			AddRow("gauge", "cpu", nil, float64(12.34))
+		sampledAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "labels", "metric_value_int", "metric_value_float", "metric_value_histogram", "metric_value_summary", "sampled_at"}).
+			AddRow("counter", "requests", []byte("{}"), int64(42), nil, nil, nil, nil).
+			AddRow("gauge", "cpu", []byte(`{"host": "web1"}`), nil, float64(12.34), nil, nil, sampledAt).
+			AddRow("histogram", "latency", []byte("{}"), nil, nil, []byte(`{"bounds": [1], "counts": [2, 1], "count": 3, "sum": 4.5}`), nil, nil).
+			AddRow("summary", "latency", []byte("{}"), nil, nil, nil,
+				[]byte(`{"relative_accuracy": 0.01, "positive": {"0": 2}, "count": 2, "sum": 2, "min": 1, "max": 1}`), nil)
 
 		mock.ExpectQuery("select metric_type").
 			WillReturnRows(rows)
 
 		metrics, err := client.RetrieveAll(ctx)
 		require.NoError(t, err)
-		require.Len(t, metrics, 2)
+		require.Len(t, metrics, 4)
 
 		require.Equal(t, "requests", metrics[0].GetName())
 		require.EqualValues(t, int64(42), metrics[0].GetValue())
+		require.True(t, metrics[0].GetTimestamp().IsZero())
 
 		require.Equal(t, "cpu", metrics[1].GetName())
+		require.Equal(t, metric.Labels{"host": "web1"}, metrics[1].GetLabels())
 		require.InDelta(t, 12.34, metrics[1].GetValue().(float64), 0.001)
+		require.Equal(t, sampledAt, metrics[1].GetTimestamp())
+
+		require.Equal(t, "latency", metrics[2].GetName())
+		require.Equal(t, metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{2, 1}, Count: 3, Sum: 4.5}, metrics[2].GetValue())
+
+		require.Equal(t, metric.MetricTypeSummary, metrics[3].GetType())
+		require.Equal(t, metric.SummaryValue{RelativeAccuracy: 0.01, Positive: map[int32]int64{0: 2}, Count: 2, Sum: 2, Min: 1, Max: 1},
+			metrics[3].GetValue())
 
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -208,8 +469,8 @@ func TestPostgresClient_RetrieveAll(t *testing.T) {
 
 		client := &PostgresClient{db: sqlDB}
 
-		rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "metric_value_int", "metric_value_float"}).
-			AddRow("invalid_type", "broken", nil, nil)
+		rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "labels", "metric_value_int", "metric_value_float", "metric_value_histogram", "metric_value_summary", "sampled_at"}).
+			AddRow("invalid_type", "broken", []byte("{}"), nil, nil, nil, nil, nil)
 
 		mock.ExpectQuery("select metric_type").
 			WillReturnRows(rows)
@@ -223,6 +484,191 @@ func TestPostgresClient_RetrieveAll(t *testing.T) {
 
 }
 
+func TestPostgresClient_RetrieveAllTimestamped(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("happy path", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := &PostgresClient{db: sqlDB}
+
+		rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "labels", "metric_value_int", "metric_value_float", "metric_value_histogram", "metric_value_summary", "created_at", "updated_at", "sampled_at"}).
+			AddRow("counter", "requests", []byte("{}"), int64(42), nil, nil, nil, createdAt, updatedAt, nil).
+			AddRow("gauge", "cpu", []byte("{}"), nil, float64(12.34), nil, nil, createdAt, updatedAt.Add(time.Minute), updatedAt)
+
+		mock.ExpectQuery("select metric_type, metric_name, labels, metric_value_int, metric_value_float, metric_value_histogram, metric_value_summary, created_at, updated_at, sampled_at from metrics").
+			WillReturnRows(rows)
+
+		metrics, err := client.RetrieveAllTimestamped(ctx)
+		require.NoError(t, err)
+		require.Len(t, metrics, 2)
+
+		require.Equal(t, "requests", metrics[0].Metric.GetName())
+		require.EqualValues(t, int64(42), metrics[0].Metric.GetValue())
+		require.Equal(t, createdAt, metrics[0].CreatedAt)
+		require.Equal(t, updatedAt, metrics[0].UpdatedAt)
+		require.Equal(t, updatedAt.Add(time.Minute), metrics[1].UpdatedAt)
+		require.Equal(t, updatedAt, metrics[1].Metric.GetTimestamp())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("invalid metric type", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := &PostgresClient{db: sqlDB}
+
+		rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "labels", "metric_value_int", "metric_value_float", "metric_value_histogram", "metric_value_summary", "created_at", "updated_at", "sampled_at"}).
+			AddRow("invalid_type", "broken", []byte("{}"), nil, nil, nil, nil, createdAt, updatedAt, nil)
+
+		mock.ExpectQuery("select metric_type").WillReturnRows(rows)
+
+		metrics, err := client.RetrieveAllTimestamped(ctx)
+		require.ErrorIs(t, err, metric.ErrorInvalidMetricType)
+		require.Nil(t, metrics)
+	})
+}
+
+func TestPostgresClient_Update_SetsUpdatedAt(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := &PostgresClient{db: sqlDB}
+
+	mock.ExpectExec(regexp.QuoteMeta("update metrics set metric_value_float = $1, updated_at = $4, sampled_at = coalesce($6, sampled_at) where metric_type = $2 and metric_name = $3 and labels = $5")).
+		WithArgs(1.5, metric.MetricTypeGauge, "cpu", sqlmock.AnyArg(), `{"host":"web1"}`, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, client.Update(context.Background(), &metric.Gauge{Name: "cpu", Labels: metric.Labels{"host": "web1"}}, 1.5))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresClient_Update_MergesHistogram(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := &PostgresClient{db: sqlDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("select metric_value_histogram from metrics where metric_type=$1 and metric_name=$2 and labels=$3 for update")).
+		WithArgs(metric.MetricTypeHistogram, "latency", "{}").
+		WillReturnRows(sqlmock.NewRows([]string{"metric_value_histogram"}).
+			AddRow([]byte(`{"bounds": [1], "counts": [2, 1], "count": 3, "sum": 4.5}`)))
+	mock.ExpectExec(regexp.QuoteMeta("update metrics set metric_value_histogram = $1, updated_at = $4, sampled_at = coalesce($6, sampled_at) where metric_type = $2 and metric_name = $3 and labels = $5")).
+		WithArgs(`{"bounds":[1],"counts":[3,1],"count":4,"sum":5}`, metric.MetricTypeHistogram, "latency", sqlmock.AnyArg(), "{}", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, client.Update(context.Background(), metric.NewHistogram("latency"), 0.5))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select metric_value_histogram").
+		WillReturnRows(sqlmock.NewRows([]string{"metric_value_histogram"}).
+			AddRow([]byte(`{"bounds": [1], "counts": [2, 1], "count": 3, "sum": 4.5}`)))
+	mock.ExpectRollback()
+
+	err = client.Update(context.Background(), metric.NewHistogram("latency"),
+		metric.HistogramValue{Bounds: []float64{2}, Counts: []int64{1, 0}, Count: 1, Sum: 1})
+	require.ErrorIs(t, err, metric.ErrorInvalidMetricValue)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresClient_Update_MergesSummary(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := &PostgresClient{db: sqlDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("select metric_value_summary from metrics where metric_type=$1 and metric_name=$2 and labels=$3 for update")).
+		WithArgs(metric.MetricTypeSummary, "latency", `{"host":"web1"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"metric_value_summary"}).
+			AddRow([]byte(`{"relative_accuracy": 0.01, "positive": {"0": 2}, "count": 2, "sum": 2, "min": 1, "max": 1}`)))
+	mock.ExpectExec(regexp.QuoteMeta("update metrics set metric_value_summary = $1, updated_at = $4, sampled_at = coalesce($6, sampled_at) where metric_type = $2 and metric_name = $3 and labels = $5")).
+		WithArgs(`{"relative_accuracy":0.01,"positive":{"0":3},"count":3,"sum":3,"min":1,"max":1}`, metric.MetricTypeSummary, "latency",
+			sqlmock.AnyArg(), `{"host":"web1"}`, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, client.Update(context.Background(), &metric.Summary{Name: "latency", Labels: metric.Labels{"host": "web1"}}, "1"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresClient_Update_OutOfOrderGauge(t *testing.T) {
+	stored := time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		policy    storage.OutOfOrderPolicy
+		timestamp time.Time
+		written   bool
+		wantErr   error
+	}{
+		{"newer sample is written", storage.OutOfOrderReject, stored.Add(time.Second), true, nil},
+		{"older sample is ignored", "", stored.Add(-time.Second), false, nil},
+		{"older sample is rejected", storage.OutOfOrderReject, stored.Add(-time.Second), false, common.ErrorOutOfOrderSample},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			client := &PostgresClient{db: sqlDB, OutOfOrder: tt.policy}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("select sampled_at from metrics where metric_type=$1 and metric_name=$2 and labels=$3 for update")).
+				WithArgs(metric.MetricTypeGauge, "cpu", "{}").
+				WillReturnRows(sqlmock.NewRows([]string{"sampled_at"}).AddRow(stored))
+			if tt.written {
+				mock.ExpectExec(regexp.QuoteMeta("update metrics set metric_value_float = $1, updated_at = $4, sampled_at = coalesce($6, sampled_at)")).
+					WithArgs(1.5, metric.MetricTypeGauge, "cpu", sqlmock.AnyArg(), "{}", tt.timestamp).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			err = client.Update(context.Background(), &metric.Gauge{Name: "cpu", Timestamp: tt.timestamp}, 1.5)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// A sample without a timestamp must keep the stored one, so that later
+// samples are still checked against it.
+func TestPostgresClient_Update_UntimestampedGauge(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := &PostgresClient{db: sqlDB, OutOfOrder: storage.OutOfOrderReject}
+
+	mock.ExpectExec(regexp.QuoteMeta("update metrics set metric_value_float = $1, updated_at = $4, sampled_at = coalesce($6, sampled_at)")).
+		WithArgs(1.5, metric.MetricTypeGauge, "cpu", sqlmock.AnyArg(), "{}", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, client.Update(context.Background(), &metric.Gauge{Name: "cpu"}, 1.5))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestRetrieveAll_InvalidType(t *testing.T) {
 	sqlDB, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -230,8 +676,8 @@ func TestRetrieveAll_InvalidType(t *testing.T) {
 
 	client := &PostgresClient{db: sqlDB}
 
-	rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "metric_value_int", "metric_value_float"}).
-		AddRow("bad-type", "foo", 0, 0.0)
+	rows := sqlmock.NewRows([]string{"metric_type", "metric_name", "labels", "metric_value_int", "metric_value_float", "metric_value_histogram", "metric_value_summary", "sampled_at"}).
+		AddRow("bad-type", "foo", []byte("{}"), 0, 0.0, nil, nil, nil)
 
 	mock.ExpectQuery("select metric_type").
 		WillReturnRows(rows)
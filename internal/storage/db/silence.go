@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+)
+
+// SaveSilences replaces the stored silences with the given ones in a single transaction.
+func (c *PostgresClient) SaveSilences(ctx context.Context, silences []alerting.Silence) error {
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from silences"); err != nil {
+		return err
+	}
+
+	s := `insert into silences (id, matchers, starts_at, ends_at, created_by, comment)
+		values ($1, $2, $3, $4, $5, $6)`
+
+	for _, silence := range silences {
+		matchers, err := json.Marshal(silence.Matchers)
+		if err != nil {
+			return common.ErrorMarshallingJSON
+		}
+
+		_, err = tx.ExecContext(ctx, s, silence.ID, matchers, silence.StartsAt, silence.EndsAt, silence.CreatedBy, silence.Comment)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadSilences returns all stored silences.
+func (c *PostgresClient) LoadSilences(ctx context.Context) ([]alerting.Silence, error) {
+
+	s := "select id, matchers, starts_at, ends_at, created_by, comment from silences"
+
+	rows, err := common.RetryWithResult(ctx, func() (*sql.Rows, error) {
+		return c.db.QueryContext(ctx, s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]alerting.Silence, 0)
+
+	for rows.Next() {
+		var silence alerting.Silence
+		var matchers []byte
+
+		err := rows.Scan(&silence.ID, &matchers, &silence.StartsAt, &silence.EndsAt, &silence.CreatedBy, &silence.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(matchers, &silence.Matchers); err != nil {
+			return nil, err
+		}
+
+		result = append(result, silence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresClient_SaveSilences(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	silences := []alerting.Silence{
+		{
+			ID:        "4f2a9c1e7b3d5a60",
+			Matchers:  []alerting.Matcher{{Name: "team", Value: "infra"}},
+			StartsAt:  now,
+			EndsAt:    now.Add(time.Hour),
+			CreatedBy: "jane",
+			Comment:   "deploy",
+		},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from silences").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("insert into silences").
+			WithArgs("4f2a9c1e7b3d5a60", []byte(`[{"name":"team","value":"infra"}]`), now, now.Add(time.Hour), "jane", "deploy").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, client.SaveSilences(ctx, silences))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error rolls back", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		client := NewPostgresClientFromDB(sqlDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("delete from silences").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into silences").WillReturnError(errors.New("forced"))
+		mock.ExpectRollback()
+
+		require.Error(t, client.SaveSilences(ctx, silences))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostgresClient_LoadSilences(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	client := NewPostgresClientFromDB(sqlDB)
+
+	rows := sqlmock.NewRows([]string{"id", "matchers", "starts_at", "ends_at", "created_by", "comment"}).
+		AddRow("4f2a9c1e7b3d5a60", []byte(`[{"name":"metric_name","value":"CPU.*","type":"=~"}]`), now, now.Add(time.Hour), "jane", "")
+
+	mock.ExpectQuery("select id, matchers").WillReturnRows(rows)
+
+	silences, err := client.LoadSilences(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []alerting.Silence{
+		{
+			ID:        "4f2a9c1e7b3d5a60",
+			Matchers:  []alerting.Matcher{{Name: alerting.MatcherMetricName, Value: "CPU.*", Type: alerting.MatchRegexp}},
+			StartsAt:  now,
+			EndsAt:    now.Add(time.Hour),
+			CreatedBy: "jane",
+		},
+	}, silences)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,4 +5,9 @@
 //
 // The DBStorage interface extends Storage with database-specific lifecycle methods,
 // such as Ping and RunMigrations, useful for health checks and migrations in SQL-based systems.
+//
+// Backends that track when metrics were created and last updated implement the optional
+// TimestampedStorage interface. OutOfOrderPolicy decides what happens to gauge samples
+// older than the stored one. Backends keeping the help text, unit and owner of
+// metric names implement the optional MetadataStorage interface.
 package storage
@@ -6,16 +6,33 @@
 //
 // The dump format is line-based:
 //
-//	metric_name:metric_type:metric_value
+//	metric_name:metric_type:metric_value[:labels]
 //
+// where labels of a labeled metric are in the canonical form of metric.Labels,
+// and the value of a histogram or summary is in the text form of
+// metric.HistogramValue or metric.SummaryValue. Sample timestamps and the
+// creation and update times of metrics are saved in the "times" section of
+// memory.MemStorage, see below.
 // For example:
 //
 //	requests_total:counter:42
 //	temperature:gauge:36.6
+//	temperature:gauge:21.5:{room="kitchen"}
+//	latency:histogram:count=3,sum=1.7,0.1=1,1=2,+Inf=0
+//	latency:summary:accuracy=0.01,count=3,sum=2.2,min=0.2,max=1,zero=0,p-80=1,p0=2
+//
+// Additional state registered as DumpSection (e.g. alert states, or the
+// metadata of metric names and the times of metrics kept by
+// memory.MemStorage) is stored after the metrics, one line per section:
+//
+//	@section_name serialized_state
+//
+// Alert rules managed at runtime (RuleFile) and the notification outbox
+// (OutboxFile) are kept in separate files next to the dump file.
 //
 // Typical usage:
 //
-//	saver := file.NewFileSaver("metrics.dump", metricStorage)
+//	saver := file.NewFileSaver("metrics.dump", metricStorage, alertEvaluator)
 //	err := saver.RestoreDump(ctx)
 //	// ... application logic ...
 //	err := saver.SaveDump(ctx)
@@ -14,10 +14,18 @@ import (
 var openFile = os.Open
 var openFileWriter = os.OpenFile
 
+// sectionPrefix marks dump lines holding a DumpSection state.
+// Metric names cannot start with it, so such lines are never confused with metrics.
+const sectionPrefix = "@"
+
+// maxDumpLineSize limits the length of a single dump line.
+const maxDumpLineSize = 64 * 1024 * 1024
+
 // FileSaver is a file-based implementation of the DumpSaver interface.
 type FileSaver struct {
 	Storage         storage.Storage // Underlying metric storage
 	FileStoragePath string          // Path to the dump file
+	Sections        []DumpSection   // Additional state saved alongside the metrics
 }
 
 func (fs *FileSaver) SaveDump(ctx context.Context) error {
@@ -31,10 +39,21 @@ func (fs *FileSaver) SaveDump(ctx context.Context) error {
 	dump := ""
 	for _, m := range x {
 		ms := fmt.Sprintf("%s:%s:%v", m.GetName(), m.GetType(), m.GetValue())
+		if l := m.GetLabels(); len(l) > 0 {
+			ms += ":" + l.String()
+		}
 		dump += ms
 		dump += "\n"
 	}
 
+	for _, section := range fs.Sections {
+		data, err := section.DumpState(ctx)
+		if err != nil {
+			return fmt.Errorf("error dumping %s: %w", section.DumpName(), err)
+		}
+		dump += fmt.Sprintf("%s%s %s\n", sectionPrefix, section.DumpName(), data)
+	}
+
 	f, err := openFileWriter(fs.FileStoragePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
 
 	if err != nil {
@@ -67,14 +86,23 @@ func (fs *FileSaver) RestoreDump(ctx context.Context) error {
 
 	// Create a new Scanner for the file.
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxDumpLineSize)
 
 	// Read the file line by line.
 	for scanner.Scan() {
 		line := scanner.Text() // Get the current line.
 
-		parts := strings.Split(line, ":")
+		if strings.HasPrefix(line, sectionPrefix) {
+			if err := fs.restoreSection(ctx, line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// labels are the last field as their values may contain colons
+		parts := strings.SplitN(line, ":", 4)
 
-		if len(parts) != 3 {
+		if len(parts) < 3 {
 			return fmt.Errorf("invalid dump line: %s", line)
 		}
 
@@ -82,7 +110,15 @@ func (fs *FileSaver) RestoreDump(ctx context.Context) error {
 		metricType := parts[1]
 		metricValue := parts[2]
 
-		m, err := metric.NewMetric(metric.MetricType(metricType), metricName)
+		var labels metric.Labels
+		if len(parts) == 4 {
+			labels, err = metric.ParseLabels(parts[3])
+			if err != nil {
+				return fmt.Errorf("invalid dump line: %s", line)
+			}
+		}
+
+		m, err := metric.NewLabeledMetric(metric.MetricType(metricType), metricName, labels)
 		if err != nil {
 			return fmt.Errorf("error creating metric: %s", err.Error())
 		}
@@ -104,6 +140,23 @@ func (fs *FileSaver) RestoreDump(ctx context.Context) error {
 	return nil
 }
 
-func NewFileSaver(fileStoragePath string, storage storage.Storage) *FileSaver {
-	return &FileSaver{FileStoragePath: fileStoragePath, Storage: storage}
+// restoreSection passes the state stored in a section line to the matching DumpSection.
+// Sections without a registered DumpSection are skipped.
+func (fs *FileSaver) restoreSection(ctx context.Context, line string) error {
+	name, data, _ := strings.Cut(strings.TrimPrefix(line, sectionPrefix), " ")
+
+	for _, section := range fs.Sections {
+		if section.DumpName() == name {
+			if err := section.RestoreState(ctx, []byte(data)); err != nil {
+				return fmt.Errorf("error restoring %s: %w", name, err)
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func NewFileSaver(fileStoragePath string, storage storage.Storage, sections ...DumpSection) *FileSaver {
+	return &FileSaver{FileStoragePath: fileStoragePath, Storage: storage, Sections: sections}
 }
@@ -7,8 +7,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/storage/memory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,15 +52,111 @@ func TestSaveAndRestoreDump(t *testing.T) {
 
 	assert.Len(t, stor2.Data, len(stor.Data), "expected 2 added metrics")
 
-	m, err := stor2.Retrieve(ctx, metric.MetricTypeCounter, "counter1")
+	m, err := stor2.Retrieve(ctx, metric.MetricTypeCounter, "counter1", nil)
 	assert.NoError(t, err)
 	assert.Equal(t, m.GetValue(), int64(123))
 
-	m2, err := stor2.Retrieve(ctx, metric.MetricTypeGauge, "gauge1")
+	m2, err := stor2.Retrieve(ctx, metric.MetricTypeGauge, "gauge1", nil)
 	assert.NoError(t, err)
 	assert.Equal(t, m2.GetValue(), float64(1.234))
 }
 
+func TestSaveAndRestoreDump_Labels(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.sav")
+
+	stor := memory.NewMemStorage()
+	require.NoError(t, stor.Add(ctx, &metric.Gauge{Name: "temperature", Value: 36.6}))
+	require.NoError(t, stor.Add(ctx, &metric.Gauge{Name: "temperature", Labels: metric.Labels{"room": "kitchen", "path": "a:b"}, Value: 21.5}))
+
+	require.NoError(t, NewFileSaver(path, stor).SaveDump(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "temperature:gauge:36.6\n")
+	assert.Contains(t, string(data), `temperature:gauge:21.5:{path="a:b",room="kitchen"}`)
+
+	stor2 := memory.NewMemStorage()
+	require.NoError(t, NewFileSaver(path, stor2).RestoreDump(ctx))
+	assert.Equal(t, stor.Data, stor2.Data)
+
+	require.NoError(t, os.WriteFile(path, []byte("temperature:gauge:21.5:{room}\n"), 0644))
+	assert.Error(t, NewFileSaver(path, memory.NewMemStorage()).RestoreDump(ctx))
+}
+
+func TestSaveAndRestoreDump_Times(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.sav")
+	created := time.Date(2025, 9, 25, 10, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Minute)
+	sampled := created.Add(30 * time.Second)
+
+	stor := memory.NewMemStorage()
+	stor.Clock = func() time.Time { return created }
+	require.NoError(t, stor.Add(ctx, &metric.Gauge{Name: "temperature", Labels: metric.Labels{"room": "kitchen"}, Value: 21.5}))
+	require.NoError(t, stor.Add(ctx, metric.MustNewCounter("requests", 1)))
+	stor.Clock = func() time.Time { return updated }
+	require.NoError(t, stor.Update(ctx, &metric.Gauge{Name: "temperature", Labels: metric.Labels{"room": "kitchen"}, Timestamp: sampled}, 22.0))
+
+	require.NoError(t, NewFileSaver(path, stor, stor.Times()).SaveDump(ctx))
+
+	stor2 := memory.NewMemStorage()
+	stor2.Clock = func() time.Time { return updated.Add(time.Hour) }
+	stor2.OutOfOrder = storage.OutOfOrderReject
+	require.NoError(t, NewFileSaver(path, stor2, stor2.Times()).RestoreDump(ctx))
+
+	got, err := stor2.RetrieveTimestamped(ctx, metric.MetricTypeGauge, "temperature", metric.Labels{"room": "kitchen"})
+	require.NoError(t, err)
+	assert.Equal(t, 22.0, got.Metric.GetValue())
+	assert.Equal(t, created, got.CreatedAt)
+	assert.Equal(t, updated, got.UpdatedAt)
+	assert.True(t, sampled.Equal(got.Metric.GetTimestamp()))
+
+	got, err = stor2.RetrieveTimestamped(ctx, metric.MetricTypeCounter, "requests", nil)
+	require.NoError(t, err)
+	assert.Equal(t, created, got.CreatedAt)
+	assert.Equal(t, created, got.UpdatedAt)
+	assert.True(t, got.Metric.GetTimestamp().IsZero())
+
+	// the restored sample time still protects against out-of-order samples
+	err = stor2.Update(ctx, &metric.Gauge{Name: "temperature", Labels: metric.Labels{"room": "kitchen"}, Timestamp: created}, 1.0)
+	assert.ErrorIs(t, err, common.ErrorOutOfOrderSample)
+
+	t.Run("invalid times", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("requests:counter:1\n@times {\n"), 0644))
+		stor3 := memory.NewMemStorage()
+		err := NewFileSaver(path, stor3, stor3.Times()).RestoreDump(ctx)
+		assert.ErrorContains(t, err, "error restoring times")
+	})
+}
+
+func TestSaveAndRestoreDump_HistogramAndSummary(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.sav")
+
+	stor := memory.NewMemStorage()
+	h := &metric.Histogram{Name: "latency", Labels: metric.Labels{"host": "web1"}}
+	require.NoError(t, h.Update(metric.HistogramValue{Bounds: []float64{0.1, 1}, Counts: []int64{1, 2, 0}, Count: 3, Sum: 1.7}))
+	require.NoError(t, stor.Add(ctx, h))
+
+	s := metric.NewSummary("latency")
+	for _, x := range []float64{0.2, -1, 0} {
+		require.NoError(t, s.Update(x))
+	}
+	require.NoError(t, stor.Add(ctx, s))
+
+	require.NoError(t, NewFileSaver(path, stor).SaveDump(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `latency:histogram:count=3,sum=1.7,0.1=1,1=2,+Inf=0:{host="web1"}`)
+	assert.Contains(t, string(data), `latency:summary:accuracy=0.01,count=3,sum=-0.8,min=-1,max=0.2,zero=1,p-80=1,n0=1`)
+
+	stor2 := memory.NewMemStorage()
+	require.NoError(t, NewFileSaver(path, stor2).RestoreDump(ctx))
+	assert.Equal(t, stor.Data, stor2.Data)
+}
+
 func BenchmarkFileSaver_SaveDump(b *testing.B) {
 	ctx := context.Background()
 	tmpFile := "test_save.txt"
@@ -106,7 +205,7 @@ func (f faultyStorage) Add(ctx context.Context, m metric.Metric) error {
 func (f faultyStorage) Update(ctx context.Context, m metric.Metric, v interface{}) error {
 	return errors.New("forced error in Update")
 }
-func (f faultyStorage) Retrieve(ctx context.Context, t metric.MetricType, n string) (metric.Metric, error) {
+func (f faultyStorage) Retrieve(ctx context.Context, t metric.MetricType, n string, l metric.Labels) (metric.Metric, error) {
 	return nil, errors.New("forced error in Retrieve")
 }
 func (f faultyStorage) RetrieveAll(ctx context.Context) ([]metric.Metric, error) {
@@ -200,3 +299,60 @@ func TestFileSaver_SaveDump_WriteError(t *testing.T) {
 	err := fs.SaveDump(context.Background())
 	require.Error(t, err)
 }
+
+type fakeSection struct {
+	name     string
+	state    string
+	restored string
+	err      error
+}
+
+func (f *fakeSection) DumpName() string { return f.name }
+func (f *fakeSection) DumpState(ctx context.Context) ([]byte, error) {
+	return []byte(f.state), f.err
+}
+func (f *fakeSection) RestoreState(ctx context.Context, data []byte) error {
+	f.restored = string(data)
+	return f.err
+}
+
+func TestFileSaver_Sections(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dump.txt")
+
+	stor := memory.NewMemStorage()
+	require.NoError(t, stor.Add(ctx, metric.MustNewCounter("counter1", 5)))
+
+	section := &fakeSection{name: "alerts", state: `[{"rule":"r1"}]`}
+	require.NoError(t, NewFileSaver(path, stor, section).SaveDump(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "counter1:counter:5\n")
+	assert.Contains(t, string(data), "@alerts [{\"rule\":\"r1\"}]\n")
+
+	restored := &fakeSection{name: "alerts"}
+	unknown := &fakeSection{name: "other"}
+	stor2 := memory.NewMemStorage()
+	require.NoError(t, NewFileSaver(path, stor2, restored, unknown).RestoreDump(ctx))
+
+	assert.Equal(t, `[{"rule":"r1"}]`, restored.restored)
+	assert.Empty(t, unknown.restored)
+	assert.Len(t, stor2.Data, 1)
+
+	t.Run("sections without a handler are skipped", func(t *testing.T) {
+		require.NoError(t, NewFileSaver(path, memory.NewMemStorage()).RestoreDump(ctx))
+	})
+
+	t.Run("dump error", func(t *testing.T) {
+		failing := &fakeSection{name: "alerts", err: errors.New("forced")}
+		err := NewFileSaver(path, stor, failing).SaveDump(ctx)
+		require.ErrorContains(t, err, "error dumping alerts")
+	})
+
+	t.Run("restore error", func(t *testing.T) {
+		failing := &fakeSection{name: "alerts", err: errors.New("forced")}
+		err := NewFileSaver(path, memory.NewMemStorage(), failing).RestoreDump(ctx)
+		require.ErrorContains(t, err, "error restoring alerts")
+	})
+}
@@ -10,3 +10,16 @@ type DumpSaver interface {
 	// RestoreDump restores metrics from persistent storage.
 	RestoreDump(ctx context.Context) error
 }
+
+// DumpSection is an additional named block of state stored in the dump file
+// alongside the metrics, e.g. the states of alerts.
+type DumpSection interface {
+	// DumpName returns the unique name of the section.
+	DumpName() string
+
+	// DumpState serializes the section state. The result must not contain line breaks.
+	DumpState(ctx context.Context) ([]byte, error)
+
+	// RestoreState restores the section state from data previously returned by DumpState.
+	RestoreState(ctx context.Context, data []byte) error
+}
@@ -0,0 +1,177 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+)
+
+// OutboxFileSuffix is appended to the dump file path to get the path of the outbox file.
+const OutboxFileSuffix = ".outbox"
+
+// outboxRecord is a line of the outbox file: either a saved entry or the ID
+// of a deleted one.
+type outboxRecord struct {
+	Entry   *alerting.OutboxEntry `json:"entry,omitempty"`
+	Deleted string                `json:"deleted,omitempty"`
+}
+
+// OutboxFile persists the notification outbox as an append-only log of JSON
+// lines, typically next to the dump file. Every change is appended and synced
+// to disk before it is acknowledged; the log is compacted when it is loaded.
+// It implements alerting.OutboxStore.
+type OutboxFile struct {
+	Path string // Path to the outbox file
+	mu   sync.Mutex
+}
+
+// NewOutboxFile creates an outbox file kept next to the given dump file.
+func NewOutboxFile(dumpPath string) *OutboxFile {
+	return &OutboxFile{Path: dumpPath + OutboxFileSuffix}
+}
+
+// SaveOutboxEntry appends the entry, which replaces an earlier entry with the same ID.
+func (f *OutboxFile) SaveOutboxEntry(ctx context.Context, e alerting.OutboxEntry) error {
+	return f.append(outboxRecord{Entry: &e})
+}
+
+// DeleteOutboxEntry appends the deletion of the entry with the given ID.
+func (f *OutboxFile) DeleteOutboxEntry(ctx context.Context, id string) error {
+	return f.append(outboxRecord{Deleted: id})
+}
+
+func (f *OutboxFile) append(r outboxRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// LoadOutbox replays the outbox file and returns the entries that have not
+// been deleted, then rewrites the file with only these entries. A missing
+// file means no entries. An incomplete last line, left by a crash during an
+// append, is ignored.
+func (f *OutboxFile) LoadOutbox(ctx context.Context) ([]alerting.OutboxEntry, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	entries := make(map[string]alerting.OutboxEntry)
+
+	lines := bytes.Split(data, []byte{'\n'})
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var r outboxRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, err
+		}
+
+		switch {
+		case r.Entry != nil:
+			if _, ok := entries[r.Entry.ID]; !ok {
+				order = append(order, r.Entry.ID)
+			}
+			entries[r.Entry.ID] = *r.Entry
+		case r.Deleted != "":
+			delete(entries, r.Deleted)
+		}
+	}
+
+	var result []alerting.OutboxEntry
+	for _, id := range order {
+		if e, ok := entries[id]; ok {
+			result = append(result, e)
+		}
+	}
+
+	if err := f.compact(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// compact replaces the outbox file with one holding only the given entries,
+// or removes it if there are none. The caller must hold mu.
+func (f *OutboxFile) compact(entries []alerting.OutboxEntry) error {
+	if len(entries) == 0 {
+		err := os.Remove(f.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for i := range entries {
+		if err := enc.Encode(outboxRecord{Entry: &entries[i]}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxFile(t *testing.T) {
+	ctx := context.Background()
+	dump := filepath.Join(t.TempDir(), "metrics.sav")
+	now := time.Date(2025, 9, 21, 10, 0, 0, 0, time.UTC)
+
+	f := NewOutboxFile(dump)
+	assert.Equal(t, dump+".outbox", f.Path)
+
+	entries, err := f.LoadOutbox(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "missing file means no entries")
+
+	alerts := []alerting.Alert{{Rule: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc",
+		State: alerting.StateFiring, Value: 600e6, Threshold: 500e6, FiredAt: now}}
+	first := alerting.OutboxEntry{ID: "a1", Notification: alerting.NewNotification("ops", alerts), CreatedAt: now, NextAttemptAt: now}
+	second := alerting.OutboxEntry{ID: "b2", Notification: alerting.NewNotification("dev", alerts), CreatedAt: now, NextAttemptAt: now}
+
+	require.NoError(t, f.SaveOutboxEntry(ctx, first))
+	require.NoError(t, f.SaveOutboxEntry(ctx, second))
+
+	first.Attempts = 3
+	first.LastError = "connection refused"
+	first.DeadAt = now.Add(time.Minute)
+	require.NoError(t, f.SaveOutboxEntry(ctx, first))
+	require.NoError(t, f.DeleteOutboxEntry(ctx, second.ID))
+
+	// a crash in the middle of an append
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"entry":{"id":"c3","notif`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	entries, err = f.LoadOutbox(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, first, entries[0])
+
+	// the file is compacted
+	data, err := os.ReadFile(f.Path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+
+	require.NoError(t, f.DeleteOutboxEntry(ctx, first.ID))
+	entries, err = f.LoadOutbox(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.NoFileExists(t, f.Path)
+}
+
+func TestOutboxFile_Corrupted(t *testing.T) {
+	f := NewOutboxFile(filepath.Join(t.TempDir(), "metrics.sav"))
+	require.NoError(t, os.WriteFile(f.Path, []byte("garbage\n{}\n"), 0644))
+
+	_, err := f.LoadOutbox(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+)
+
+// RuleFileSuffix is appended to the dump file path to get the path of the rule file.
+const RuleFileSuffix = ".rules.json"
+
+// RuleFile persists alert rules managed at runtime as a JSON array in a file,
+// typically next to the dump file. It implements alerting.RuleStore.
+type RuleFile struct {
+	Path string // Path to the rule file
+}
+
+// NewRuleFile creates a rule file kept next to the given dump file.
+func NewRuleFile(dumpPath string) *RuleFile {
+	return &RuleFile{Path: dumpPath + RuleFileSuffix}
+}
+
+// SaveRules writes the rules to a temporary file and renames it over the rule file,
+// so the rule file is never left half-written.
+func (f *RuleFile) SaveRules(ctx context.Context, rules []alerting.Rule) error {
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// LoadRules reads the rules from the rule file. A missing file means no rules.
+func (f *RuleFile) LoadRules(ctx context.Context) ([]alerting.Rule, error) {
+
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []alerting.Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
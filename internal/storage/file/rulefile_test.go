@@ -0,0 +1,49 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/alerting"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleFile(t *testing.T) {
+	ctx := context.Background()
+	dump := filepath.Join(t.TempDir(), "metrics.sav")
+
+	f := NewRuleFile(dump)
+	assert.Equal(t, dump+".rules.json", f.Path)
+
+	rules, err := f.LoadRules(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, rules, "missing file means no rules")
+
+	saved := []alerting.Rule{
+		{Name: "HeapAllocHigh", MetricType: metric.MetricTypeGauge, MetricName: "HeapAlloc", Operator: ">", Threshold: 500e6,
+			For: common.Duration{Duration: time.Minute}, Labels: map[string]string{"severity": "page"}},
+		{Name: "PollCountLow", MetricType: metric.MetricTypeCounter, MetricName: "PollCount", Operator: "<", Threshold: 10, Disabled: true},
+	}
+	require.NoError(t, f.SaveRules(ctx, saved))
+
+	rules, err = f.LoadRules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, saved, rules)
+
+	entries, err := os.ReadDir(filepath.Dir(dump))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary file must be removed")
+
+	require.NoError(t, os.WriteFile(f.Path, []byte("not json"), 0666))
+	_, err = f.LoadRules(ctx)
+	require.Error(t, err)
+
+	bad := &RuleFile{Path: filepath.Join(t.TempDir(), "missing", "rules.json")}
+	require.Error(t, bad.SaveRules(ctx, saved))
+}
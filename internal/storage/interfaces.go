@@ -2,23 +2,29 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
 )
 
 // Storage defines a generic interface for storing and managing metrics.
 //
 // Implementations may store metrics in-memory, in a file, or in a database.
+// A metric is identified by its type, name and labels.
 // This interface abstracts metric operations such as add, update, retrieve, and batch update.
 type Storage interface {
 	// Add inserts a new metric into the storage.
 	Add(ctx context.Context, m metric.Metric) error
 
 	// Update modifies the value of an existing metric.
+	// The timestamp of m, if set, is the client-side time v was sampled at.
 	Update(ctx context.Context, m metric.Metric, v interface{}) error
 
-	// Retrieve fetches a single metric by type and name.
-	Retrieve(ctx context.Context, m metric.MetricType, n string) (metric.Metric, error)
+	// Retrieve fetches a single metric by type, name and labels.
+	// Nil labels retrieve the metric without labels.
+	Retrieve(ctx context.Context, m metric.MetricType, n string, l metric.Labels) (metric.Metric, error)
 
 	// RetrieveAll returns all stored metrics.
 	RetrieveAll(ctx context.Context) ([]metric.Metric, error)
@@ -43,3 +49,84 @@ type DBStorage interface {
 	// Ping checks if the storage backend is reachable.
 	Ping(ctx context.Context) error
 }
+
+// TimestampedMetric is a stored metric together with the times it was added and last updated.
+type TimestampedMetric struct {
+	Metric    metric.Metric
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TimestampedStorage is implemented by storages that track when every metric was added and last updated.
+//
+// It is used by the alert evaluator to detect metrics that are not reported
+// anymore, and by the server to show the times in the UI and the value endpoint.
+type TimestampedStorage interface {
+	// RetrieveTimestamped fetches a single metric by type, name and labels with its times.
+	RetrieveTimestamped(ctx context.Context, m metric.MetricType, n string, l metric.Labels) (TimestampedMetric, error)
+
+	// RetrieveAllTimestamped returns all stored metrics with their times.
+	RetrieveAllTimestamped(ctx context.Context) ([]TimestampedMetric, error)
+}
+
+// MetadataStorage is implemented by storages that keep the metadata of metric
+// names (see metric.Metadata) alongside the metrics.
+//
+// Metadata is attached to a name rather than to a stored metric, so it may
+// be set before the first metric with the name is reported.
+type MetadataStorage interface {
+	// SetMetadata stores the metadata of the metric name, replacing the existing one.
+	SetMetadata(ctx context.Context, name string, md metric.Metadata) error
+
+	// AddMetadata stores the metadata of the metric name unless it has one,
+	// in which case it returns common.ErrorMetadataAlreadyExists.
+	AddMetadata(ctx context.Context, name string, md metric.Metadata) error
+
+	// RetrieveMetadata returns the metadata of the metric name,
+	// or common.ErrorMetadataDoesNotExist if it has none.
+	RetrieveMetadata(ctx context.Context, name string) (metric.Metadata, error)
+
+	// RetrieveAllMetadata returns the metadata of all metric names that have one.
+	RetrieveAllMetadata(ctx context.Context) (map[string]metric.Metadata, error)
+
+	// DeleteMetadata removes the metadata of the metric name,
+	// or returns common.ErrorMetadataDoesNotExist if it has none.
+	DeleteMetadata(ctx context.Context, name string) error
+}
+
+// OutOfOrderPolicy defines what a storage does with a gauge sample whose
+// client-side timestamp is older than the timestamp of the stored value.
+// Samples without a timestamp, and stored values without one, are never out of order.
+type OutOfOrderPolicy string
+
+const (
+	// OutOfOrderIgnore drops the sample and reports success; it is the default.
+	OutOfOrderIgnore OutOfOrderPolicy = "ignore"
+
+	// OutOfOrderReject fails the update with common.ErrorOutOfOrderSample.
+	OutOfOrderReject OutOfOrderPolicy = "reject"
+)
+
+// Validate checks that the policy is known; an empty policy means OutOfOrderIgnore.
+func (p OutOfOrderPolicy) Validate() error {
+	switch p {
+	case "", OutOfOrderIgnore, OutOfOrderReject:
+		return nil
+	default:
+		return fmt.Errorf("unknown out-of-order policy %q", p)
+	}
+}
+
+// Accept reports whether the sample m may replace a stored value sampled at last.
+// Only gauges are checked; the values of other metric types are accumulated,
+// so their order does not matter.
+func (p OutOfOrderPolicy) Accept(m metric.Metric, last time.Time) (bool, error) {
+	ts := m.GetTimestamp()
+	if m.GetType() != metric.MetricTypeGauge || ts.IsZero() || last.IsZero() || !ts.Before(last) {
+		return true, nil
+	}
+	if p == OutOfOrderReject {
+		return false, common.ErrorOutOfOrderSample
+	}
+	return false, nil
+}
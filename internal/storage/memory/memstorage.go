@@ -3,40 +3,94 @@
 package memory
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 	"golang.org/x/net/context"
 )
 
 type MemStorage struct {
-	Data map[string]metric.Metric
-	mu   sync.Mutex
+	Data       map[string]metric.Metric
+	CreatedAt  map[string]time.Time       // time each metric was added
+	UpdatedAt  map[string]time.Time       // last time each metric was added or updated
+	Clock      func() time.Time           // time source, time.Now if nil
+	OutOfOrder storage.OutOfOrderPolicy   // what to do with out-of-order gauge samples
+	Metadata   map[string]metric.Metadata // metadata by metric name
+	mu         sync.Mutex
 }
 
-func getKey(metricType metric.MetricType, metricName string) string {
-	return fmt.Sprintf("%s|%s", metricType, metricName)
+// getKey builds the key identifying a metric by its type, name and labels.
+func getKey(metricType metric.MetricType, metricName string, labels metric.Labels) string {
+	return fmt.Sprintf("%s|%s%s", metricType, metricName, labels)
 }
 
 func NewMemStorage() *MemStorage {
-	return &MemStorage{Data: make(map[string]metric.Metric)}
+	return &MemStorage{Data: make(map[string]metric.Metric), CreatedAt: make(map[string]time.Time), UpdatedAt: make(map[string]time.Time),
+		Metadata: make(map[string]metric.Metadata)}
 }
 
-func (s *MemStorage) Retrieve(ctx context.Context, metricType metric.MetricType, metricName string) (metric.Metric, error) {
-	key := getKey(metricType, metricName)
+// touch records the current time as the last update time of the metric with
+// the given key, and as its creation time if it has none.
+// The caller must hold mu.
+func (s *MemStorage) touch(key string) {
+	now := time.Now
+	if s.Clock != nil {
+		now = s.Clock
+	}
+	if s.UpdatedAt == nil {
+		s.UpdatedAt = make(map[string]time.Time)
+	}
+	if s.CreatedAt == nil {
+		s.CreatedAt = make(map[string]time.Time)
+	}
+	t := now()
+	s.UpdatedAt[key] = t
+	if _, ok := s.CreatedAt[key]; !ok {
+		s.CreatedAt[key] = t
+	}
+}
+
+// update merges the value of the sample into the stored metric m with the
+// given key, unless the sample is out of order, and takes over its timestamp.
+// A sample without a timestamp keeps the stored one, so that later samples
+// are still checked against it. The caller must hold mu.
+func (s *MemStorage) update(key string, m metric.Metric, sample metric.Metric, value interface{}) error {
+	ok, err := s.OutOfOrder.Accept(sample, m.GetTimestamp())
+	if err != nil || !ok {
+		return err
+	}
+	if err := m.Update(value); err != nil {
+		return err
+	}
+	if ts := sample.GetTimestamp(); !ts.IsZero() {
+		metric.SetTimestamp(m, ts)
+	}
+	s.touch(key)
+	return nil
+}
+
+// Retrieve returns a copy of the metric with the given type, name and labels,
+// so that it may be read while the stored metric is updated.
+func (s *MemStorage) Retrieve(ctx context.Context, metricType metric.MetricType, metricName string, labels metric.Labels) (metric.Metric, error) {
+	key := getKey(metricType, metricName, labels)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if value, exists := s.Data[key]; exists {
-		return value, nil
+		return metric.Clone(value), nil
 	} else {
 		return nil, common.ErrorMetricDoesNotExist
 	}
 }
 
+// RetrieveAll returns copies of all stored metrics, see Retrieve.
 func (s *MemStorage) RetrieveAll(ctx context.Context) ([]metric.Metric, error) {
 
 	result := []metric.Metric{}
@@ -44,15 +98,47 @@ func (s *MemStorage) RetrieveAll(ctx context.Context) ([]metric.Metric, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, metric := range s.Data {
-		result = append(result, metric)
+	for _, m := range s.Data {
+		result = append(result, metric.Clone(m))
+	}
+
+	return result, nil
+}
+
+// RetrieveTimestamped returns a copy of the metric with the given type, name
+// and labels with the times it was added and last updated.
+func (s *MemStorage) RetrieveTimestamped(ctx context.Context, metricType metric.MetricType, metricName string, labels metric.Labels) (storage.TimestampedMetric, error) {
+	key := getKey(metricType, metricName, labels)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, exists := s.Data[key]
+	if !exists {
+		return storage.TimestampedMetric{}, common.ErrorMetricDoesNotExist
+	}
+	return storage.TimestampedMetric{Metric: metric.Clone(m), CreatedAt: s.CreatedAt[key], UpdatedAt: s.UpdatedAt[key]}, nil
+}
+
+// RetrieveAllTimestamped returns copies of all stored metrics with the times they were added and last updated.
+func (s *MemStorage) RetrieveAllTimestamped(ctx context.Context) ([]storage.TimestampedMetric, error) {
+
+	result := []storage.TimestampedMetric{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, m := range s.Data {
+		result = append(result, storage.TimestampedMetric{Metric: metric.Clone(m), CreatedAt: s.CreatedAt[key], UpdatedAt: s.UpdatedAt[key]})
 	}
 
 	return result, nil
 }
 
-func (s *MemStorage) Add(ctx context.Context, metric metric.Metric) error {
-	key := getKey(metric.GetType(), metric.GetName())
+// Add stores a copy of the metric, so that the caller may keep reading it
+// while the stored metric is updated.
+func (s *MemStorage) Add(ctx context.Context, m metric.Metric) error {
+	key := getKey(m.GetType(), m.GetName(), m.GetLabels())
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -61,7 +147,8 @@ func (s *MemStorage) Add(ctx context.Context, metric metric.Metric) error {
 	if exists {
 		return common.ErrorMetricAlreadyExists
 	}
-	s.Data[key] = metric
+	s.Data[key] = metric.Clone(m)
+	s.touch(key)
 	return nil
 }
 
@@ -70,10 +157,10 @@ func (s *MemStorage) Update(ctx context.Context, metric metric.Metric, value int
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	key := getKey(metric.GetType(), metric.GetName())
+	key := getKey(metric.GetType(), metric.GetName(), metric.GetLabels())
 	m, exists := s.Data[key]
 	if exists {
-		return m.Update(value)
+		return s.update(key, m, metric, value)
 	}
 	return common.ErrorMetricDoesNotExist
 
@@ -85,10 +172,13 @@ func (s *MemStorage) UpdateBatch(ctx context.Context, metrics *[]metric.Metric)
 	defer s.mu.Unlock()
 
 	for _, metric := range *metrics {
-		key := getKey(metric.GetType(), metric.GetName())
+		key := getKey(metric.GetType(), metric.GetName(), metric.GetLabels())
 		m, exists := s.Data[key]
 		if exists {
-			err := m.Update(metric.GetValue())
+			err := s.update(key, m, metric, metric.GetValue())
+			if errors.Is(err, common.ErrorOutOfOrderSample) {
+				return err
+			}
 			if err != nil {
 				return fmt.Errorf("error updating %s", metric.GetName())
 			}
@@ -98,3 +188,105 @@ func (s *MemStorage) UpdateBatch(ctx context.Context, metrics *[]metric.Metric)
 	return nil
 
 }
+
+// SetMetadata stores the metadata of the metric name, replacing the existing one.
+func (s *MemStorage) SetMetadata(ctx context.Context, name string, md metric.Metadata) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]metric.Metadata)
+	}
+	s.Metadata[name] = md
+	return nil
+}
+
+// AddMetadata stores the metadata of the metric name unless it has one.
+func (s *MemStorage) AddMetadata(ctx context.Context, name string, md metric.Metadata) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.Metadata[name]; exists {
+		return common.ErrorMetadataAlreadyExists
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]metric.Metadata)
+	}
+	s.Metadata[name] = md
+	return nil
+}
+
+// RetrieveMetadata returns the metadata of the metric name.
+func (s *MemStorage) RetrieveMetadata(ctx context.Context, name string) (metric.Metadata, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, exists := s.Metadata[name]
+	if !exists {
+		return metric.Metadata{}, common.ErrorMetadataDoesNotExist
+	}
+	return md, nil
+}
+
+// RetrieveAllMetadata returns the metadata of all metric names that have one.
+func (s *MemStorage) RetrieveAllMetadata(ctx context.Context) (map[string]metric.Metadata, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]metric.Metadata, len(s.Metadata))
+	for name, md := range s.Metadata {
+		result[name] = md
+	}
+	return result, nil
+}
+
+// DeleteMetadata removes the metadata of the metric name.
+func (s *MemStorage) DeleteMetadata(ctx context.Context, name string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.Metadata[name]; !exists {
+		return common.ErrorMetadataDoesNotExist
+	}
+	delete(s.Metadata, name)
+	return nil
+}
+
+// DumpName returns the name of the dump file section holding the metadata.
+func (s *MemStorage) DumpName() string {
+	return "metadata"
+}
+
+// DumpState serializes the metadata for the dump file.
+func (s *MemStorage) DumpState(ctx context.Context) ([]byte, error) {
+	md, err := s.RetrieveAllMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(md)
+}
+
+// RestoreState restores the metadata from the dump file.
+func (s *MemStorage) RestoreState(ctx context.Context, data []byte) error {
+	var md map[string]metric.Metadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		return err
+	}
+
+	for name, m := range md {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("metadata of %s: %w", name, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Metadata = md
+	return nil
+}
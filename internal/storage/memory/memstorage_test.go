@@ -7,9 +7,11 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dmitrijs2005/metric-alerting-service/internal/common"
 	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"github.com/dmitrijs2005/metric-alerting-service/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,6 +20,7 @@ func Test_getKey(t *testing.T) {
 	type args struct {
 		metricType metric.MetricType
 		metricName string
+		labels     metric.Labels
 	}
 	tests := []struct {
 		name string
@@ -26,10 +29,11 @@ func Test_getKey(t *testing.T) {
 	}{
 		{name: "Test1", args: args{metricType: metric.MetricTypeCounter, metricName: "counter1"}, want: "counter|counter1"},
 		{name: "Test2", args: args{metricType: metric.MetricTypeGauge, metricName: "gauge1"}, want: "gauge|gauge1"},
+		{name: "Labels", args: args{metricType: metric.MetricTypeGauge, metricName: "gauge1", labels: metric.Labels{"host": "web1"}}, want: `gauge|gauge1{host="web1"}`},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, getKey(tt.args.metricType, tt.args.metricName))
+			assert.Equal(t, tt.want, getKey(tt.args.metricType, tt.args.metricName, tt.args.labels))
 		})
 	}
 }
@@ -65,7 +69,7 @@ func TestMemStorage_Retrieve(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			got, err := s.Retrieve(ctx, tt.args.metricType, tt.args.metricName)
+			got, err := s.Retrieve(ctx, tt.args.metricType, tt.args.metricName, nil)
 
 			if !tt.wantErr {
 				assert.NoError(t, err, "Expected no error for existing metric")
@@ -153,7 +157,7 @@ func TestMemStorage_Add(t *testing.T) {
 				assert.ErrorContains(t, err, tt.err)
 			} else {
 				assert.NoError(t, err)
-				key := getKey(tt.args.metric.GetType(), tt.args.metric.GetName())
+				key := getKey(tt.args.metric.GetType(), tt.args.metric.GetName(), nil)
 				assert.Equal(t, tt.args.metric, s.Data[key])
 			}
 
@@ -192,7 +196,7 @@ func TestMemStorage_Update(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			err := s.Update(ctx, tt.args.metric, tt.args.value)
 			require.NoError(t, err)
-			key := getKey(tt.args.metric.GetType(), tt.args.metric.GetName())
+			key := getKey(tt.args.metric.GetType(), tt.args.metric.GetName(), nil)
 			assert.Equal(t, tt.wantValue, s.Data[key].GetValue())
 		})
 	}
@@ -238,7 +242,7 @@ func BenchmarkStore_GetMetric(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = stor.Retrieve(ctx, metric.MetricTypeCounter, "counter1")
+		_, _ = stor.Retrieve(ctx, metric.MetricTypeCounter, "counter1", nil)
 	}
 }
 
@@ -284,7 +288,7 @@ func BenchmarkMemStorage_ConcurrentRetrieve(b *testing.B) {
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			_, _ = store.Retrieve(ctx, metric.MetricTypeCounter, "counter500")
+			_, _ = store.Retrieve(ctx, metric.MetricTypeCounter, "counter500", nil)
 		}
 	})
 }
@@ -313,6 +317,8 @@ type fakeMetric struct {
 
 func (m *fakeMetric) GetName() string            { return m.name }
 func (m *fakeMetric) GetType() metric.MetricType { return m.typ }
+func (m *fakeMetric) GetLabels() metric.Labels   { return nil }
+func (m *fakeMetric) GetTimestamp() time.Time    { return time.Time{} }
 func (m *fakeMetric) GetValue() interface{}      { return m.value }
 func (m *fakeMetric) Update(v interface{}) error {
 	if m.err != nil {
@@ -333,7 +339,7 @@ func TestMemStorage(t *testing.T) {
 	err := st.Add(ctx, m1)
 	assert.NoError(t, err)
 
-	got, err := st.Retrieve(ctx, "gauge", "foo")
+	got, err := st.Retrieve(ctx, "gauge", "foo", nil)
 	assert.NoError(t, err)
 	assert.Equal(t, m1, got)
 
@@ -342,7 +348,7 @@ func TestMemStorage(t *testing.T) {
 	assert.ErrorIs(t, err, common.ErrorMetricAlreadyExists)
 
 	// Retrieve
-	_, err = st.Retrieve(ctx, "gauge", "unknown")
+	_, err = st.Retrieve(ctx, "gauge", "unknown", nil)
 	assert.ErrorIs(t, err, common.ErrorMetricDoesNotExist)
 
 	// RetrieveAll
@@ -373,3 +379,286 @@ func TestMemStorage(t *testing.T) {
 	err = st.UpdateBatch(ctx, &metricsWithErr)
 	assert.Error(t, err)
 }
+
+func TestMemStorage_RetrieveAllTimestamped(t *testing.T) {
+	ctx := context.Background()
+
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := t0
+
+	st := NewMemStorage()
+	st.Clock = func() time.Time { return now }
+
+	require.NoError(t, st.Add(ctx, metric.MustNewCounter("counter1", 1)))
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("gauge1", 1)))
+
+	now = t0.Add(time.Minute)
+	require.NoError(t, st.Update(ctx, metric.NewGauge("gauge1"), 2.0))
+
+	now = t0.Add(2 * time.Minute)
+	batch := []metric.Metric{metric.MustNewCounter("counter1", 5)}
+	require.NoError(t, st.UpdateBatch(ctx, &batch))
+
+	got, err := st.RetrieveAllTimestamped(ctx)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	for _, tm := range got {
+		assert.Equal(t, t0, tm.CreatedAt)
+		switch tm.Metric.GetName() {
+		case "counter1":
+			assert.Equal(t, t0.Add(2*time.Minute), tm.UpdatedAt)
+		case "gauge1":
+			assert.Equal(t, t0.Add(time.Minute), tm.UpdatedAt)
+		}
+	}
+
+	// a failed update does not change the update time
+	require.Error(t, st.Update(ctx, metric.NewGauge("gauge1"), "not a number"))
+	got, err = st.RetrieveAllTimestamped(ctx)
+	require.NoError(t, err)
+	for _, tm := range got {
+		if tm.Metric.GetName() == "gauge1" {
+			assert.Equal(t, t0.Add(time.Minute), tm.UpdatedAt)
+		}
+	}
+}
+
+func TestMemStorage_OutOfOrder(t *testing.T) {
+	ctx := context.Background()
+	sampled := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		policy    storage.OutOfOrderPolicy
+		timestamp time.Time
+		want      float64
+		wantErr   error
+	}{
+		{"newer sample", storage.OutOfOrderReject, sampled.Add(time.Second), 2, nil},
+		{"sample without timestamp", storage.OutOfOrderReject, time.Time{}, 2, nil},
+		{"older sample ignored", "", sampled.Add(-time.Second), 1, nil},
+		{"older sample rejected", storage.OutOfOrderReject, sampled.Add(-time.Second), 1, common.ErrorOutOfOrderSample},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := NewMemStorage()
+			st.OutOfOrder = tt.policy
+			require.NoError(t, st.Add(ctx, &metric.Gauge{Name: "temperature", Value: 1, Timestamp: sampled}))
+
+			err := st.Update(ctx, &metric.Gauge{Name: "temperature", Timestamp: tt.timestamp}, 2.0)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			batch := []metric.Metric{&metric.Gauge{Name: "temperature", Value: 2, Timestamp: tt.timestamp}}
+			assert.ErrorIs(t, st.UpdateBatch(ctx, &batch), tt.wantErr)
+
+			got, err := st.RetrieveTimestamped(ctx, metric.MetricTypeGauge, "temperature", nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Metric.GetValue())
+			if tt.want == 2 && !tt.timestamp.IsZero() {
+				assert.Equal(t, tt.timestamp, got.Metric.GetTimestamp())
+			} else {
+				assert.Equal(t, sampled, got.Metric.GetTimestamp())
+			}
+		})
+	}
+
+	t.Run("sample without timestamp keeps the stored one", func(t *testing.T) {
+		st := NewMemStorage()
+		st.OutOfOrder = storage.OutOfOrderReject
+		require.NoError(t, st.Add(ctx, &metric.Gauge{Name: "temperature", Value: 1, Timestamp: sampled.Add(time.Second)}))
+		require.NoError(t, st.Update(ctx, &metric.Gauge{Name: "temperature"}, 2.0))
+
+		err := st.Update(ctx, &metric.Gauge{Name: "temperature", Timestamp: sampled}, 3.0)
+		assert.ErrorIs(t, err, common.ErrorOutOfOrderSample)
+
+		got, err := st.RetrieveTimestamped(ctx, metric.MetricTypeGauge, "temperature", nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, got.Metric.GetValue())
+		assert.Equal(t, sampled.Add(time.Second), got.Metric.GetTimestamp())
+	})
+
+	_, err := NewMemStorage().RetrieveTimestamped(ctx, metric.MetricTypeGauge, "temperature", nil)
+	assert.ErrorIs(t, err, common.ErrorMetricDoesNotExist)
+}
+
+func TestMemStorage_Labels(t *testing.T) {
+	ctx := context.Background()
+	st := NewMemStorage()
+
+	require.NoError(t, st.Add(ctx, metric.MustNewGauge("Alloc", 1)))
+	require.NoError(t, st.Add(ctx, &metric.Gauge{Name: "Alloc", Value: 2, Labels: metric.Labels{"host": "web1"}}))
+	require.NoError(t, st.Add(ctx, &metric.Gauge{Name: "Alloc", Value: 3, Labels: metric.Labels{"host": "web2"}}))
+	assert.ErrorIs(t, st.Add(ctx, &metric.Gauge{Name: "Alloc", Labels: metric.Labels{"host": "web2"}}), common.ErrorMetricAlreadyExists)
+
+	require.NoError(t, st.Update(ctx, &metric.Gauge{Name: "Alloc", Labels: metric.Labels{"host": "web1"}}, 20.0))
+
+	tests := []struct {
+		name   string
+		labels metric.Labels
+		want   float64
+	}{
+		{"unlabeled", nil, 1},
+		{"web1", metric.Labels{"host": "web1"}, 20},
+		{"web2", metric.Labels{"host": "web2"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := st.Retrieve(ctx, metric.MetricTypeGauge, "Alloc", tt.labels)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m.GetValue())
+		})
+	}
+
+	_, err := st.Retrieve(ctx, metric.MetricTypeGauge, "Alloc", metric.Labels{"host": "web3"})
+	assert.ErrorIs(t, err, common.ErrorMetricDoesNotExist)
+
+	all, err := st.RetrieveAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestMemStorage_Histogram(t *testing.T) {
+	ctx := context.Background()
+	st := NewMemStorage()
+
+	h := metric.NewHistogram("latency")
+	require.NoError(t, h.Update(metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{1, 0}, Count: 1, Sum: 0.5}))
+	require.NoError(t, st.Add(ctx, h))
+
+	require.NoError(t, st.Update(ctx, metric.NewHistogram("latency"), 2.0))
+
+	batch := []metric.Metric{&metric.Histogram{Name: "latency",
+		Value: metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{2, 1}, Count: 3, Sum: 3}}}
+	require.NoError(t, st.UpdateBatch(ctx, &batch))
+
+	m, err := st.Retrieve(ctx, metric.MetricTypeHistogram, "latency", nil)
+	require.NoError(t, err)
+	assert.Equal(t, metric.HistogramValue{Bounds: []float64{1}, Counts: []int64{3, 2}, Count: 5, Sum: 5.5}, m.GetValue())
+
+	batch = []metric.Metric{&metric.Histogram{Name: "latency",
+		Value: metric.HistogramValue{Bounds: []float64{2}, Counts: []int64{1, 0}, Count: 1, Sum: 1}}}
+	assert.Error(t, st.UpdateBatch(ctx, &batch), "histograms with other bounds are not merged")
+}
+
+func TestMemStorage_Summary(t *testing.T) {
+	ctx := context.Background()
+	st := NewMemStorage()
+
+	s := metric.NewSummary("latency")
+	require.NoError(t, s.Update(1.0))
+	require.NoError(t, st.Add(ctx, s))
+
+	require.NoError(t, st.Update(ctx, metric.NewSummary("latency"), "1"))
+
+	other := metric.NewSummary("latency")
+	require.NoError(t, other.Update(0.0))
+	batch := []metric.Metric{other}
+	require.NoError(t, st.UpdateBatch(ctx, &batch))
+
+	m, err := st.Retrieve(ctx, metric.MetricTypeSummary, "latency", nil)
+	require.NoError(t, err)
+	v := m.GetValue().(metric.SummaryValue)
+	assert.Equal(t, int64(3), v.Count)
+	assert.Equal(t, float64(0.99), v.Quantile(0.5))
+	assert.Equal(t, float64(0), v.Quantile(0))
+}
+
+func TestMemStorage_Metadata(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemStorage()
+
+	_, err := s.RetrieveMetadata(ctx, "HeapAlloc")
+	require.ErrorIs(t, err, common.ErrorMetadataDoesNotExist)
+
+	md := metric.Metadata{Help: "Heap bytes in use.", Unit: metric.UnitBytes}
+	require.NoError(t, s.AddMetadata(ctx, "HeapAlloc", md))
+	require.ErrorIs(t, s.AddMetadata(ctx, "HeapAlloc", metric.Metadata{Owner: "agent"}), common.ErrorMetadataAlreadyExists)
+
+	got, err := s.RetrieveMetadata(ctx, "HeapAlloc")
+	require.NoError(t, err)
+	assert.Equal(t, md, got)
+
+	md.Owner = "runtime"
+	require.NoError(t, s.SetMetadata(ctx, "HeapAlloc", md))
+	require.NoError(t, s.SetMetadata(ctx, "PollCount", metric.Metadata{Help: "Number of polls."}))
+
+	all, err := s.RetrieveAllMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]metric.Metadata{"HeapAlloc": md, "PollCount": {Help: "Number of polls."}}, all)
+
+	// the dump section restores the metadata
+	data, err := s.DumpState(ctx)
+	require.NoError(t, err)
+	restored := NewMemStorage()
+	require.NoError(t, restored.RestoreState(ctx, data))
+	all, err = restored.RetrieveAllMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]metric.Metadata{"HeapAlloc": md, "PollCount": {Help: "Number of polls."}}, all)
+	assert.Error(t, restored.RestoreState(ctx, []byte(`{"HeapAlloc":{"unit":"Bytes"}}`)))
+
+	require.NoError(t, s.DeleteMetadata(ctx, "PollCount"))
+	require.ErrorIs(t, s.DeleteMetadata(ctx, "PollCount"), common.ErrorMetadataDoesNotExist)
+}
+
+// Retrieved metrics are read while the stored ones are updated; run with -race.
+func TestMemStorage_ConcurrentReads(t *testing.T) {
+	ctx := context.Background()
+	st := NewMemStorage()
+
+	metrics := []metric.Metric{
+		metric.MustNewGauge("temperature", 0),
+		metric.MustNewCounter("requests", 0),
+		metric.NewHistogram("latency"),
+		metric.NewSummary("size"),
+	}
+	values := []interface{}{1.5, int64(1), 0.2, 3.0}
+	for _, m := range metrics {
+		require.NoError(t, st.Add(ctx, m))
+	}
+
+	var wg sync.WaitGroup
+	for i, m := range metrics {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				assert.NoError(t, st.Update(ctx, m, values[i]))
+			}
+		}()
+	}
+
+	for range 100 {
+		all, err := st.RetrieveAll(ctx)
+		require.NoError(t, err)
+		for _, m := range all {
+			_ = fmt.Sprint(m.GetValue())
+		}
+
+		timestamped, err := st.RetrieveAllTimestamped(ctx)
+		require.NoError(t, err)
+		for _, tm := range timestamped {
+			_ = fmt.Sprint(tm.Metric.GetValue())
+		}
+
+		m, err := st.Retrieve(ctx, metric.MetricTypeHistogram, "latency", nil)
+		require.NoError(t, err)
+		if h := m.(*metric.Histogram).Value; h.Count > 0 {
+			require.NoError(t, h.Validate())
+		}
+
+		m, err = st.Retrieve(ctx, metric.MetricTypeSummary, "size", nil)
+		require.NoError(t, err)
+		if sv := m.(*metric.Summary).Value; sv.Count > 0 {
+			require.NoError(t, sv.Validate())
+		}
+	}
+	wg.Wait()
+
+	m, err := st.Retrieve(ctx, metric.MetricTypeHistogram, "latency", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), m.(*metric.Histogram).Value.Count)
+	m, err = st.Retrieve(ctx, metric.MetricTypeSummary, "size", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), m.(*metric.Summary).Value.Count)
+}
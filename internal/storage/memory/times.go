@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dmitrijs2005/metric-alerting-service/internal/metric"
+	"golang.org/x/net/context"
+)
+
+// Times is the dump file section of a MemStorage holding the times of its
+// metrics, so that creation times and the out-of-order protection of gauges
+// survive a restart. It must be restored after the metrics.
+type Times struct {
+	s *MemStorage
+}
+
+// metricTimes holds the times of a single metric in the dump file.
+type metricTimes struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	SampledAt time.Time `json:"sampled_at"` // client-side sample timestamp, zero if none
+}
+
+// Times returns the dump file section holding the times of the metrics.
+func (s *MemStorage) Times() *Times {
+	return &Times{s: s}
+}
+
+// DumpName returns the name of the dump file section holding the times.
+func (t *Times) DumpName() string {
+	return "times"
+}
+
+// DumpState serializes the times of all metrics, by metric key, for the dump file.
+func (t *Times) DumpState(ctx context.Context) ([]byte, error) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	times := make(map[string]metricTimes, len(t.s.Data))
+	for key, m := range t.s.Data {
+		times[key] = metricTimes{CreatedAt: t.s.CreatedAt[key], UpdatedAt: t.s.UpdatedAt[key], SampledAt: m.GetTimestamp()}
+	}
+	return json.Marshal(times)
+}
+
+// RestoreState sets the times of the restored metrics from the dump file.
+// Times of metrics that are not stored are ignored.
+func (t *Times) RestoreState(ctx context.Context, data []byte) error {
+	var times map[string]metricTimes
+	if err := json.Unmarshal(data, &times); err != nil {
+		return err
+	}
+
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	if t.s.CreatedAt == nil {
+		t.s.CreatedAt = make(map[string]time.Time)
+	}
+	if t.s.UpdatedAt == nil {
+		t.s.UpdatedAt = make(map[string]time.Time)
+	}
+
+	for key, mt := range times {
+		m, exists := t.s.Data[key]
+		if !exists {
+			continue
+		}
+		if !mt.CreatedAt.IsZero() {
+			t.s.CreatedAt[key] = mt.CreatedAt
+		}
+		if !mt.UpdatedAt.IsZero() {
+			t.s.UpdatedAt[key] = mt.UpdatedAt
+		}
+		if !mt.SampledAt.IsZero() {
+			metric.SetTimestamp(m, mt.SampledAt)
+		}
+	}
+	return nil
+}
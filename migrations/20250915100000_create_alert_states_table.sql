@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE alert_states (
+    rule_name TEXT NOT NULL,
+    metric_type TEXT NOT NULL,
+    metric_name TEXT NOT NULL,
+    state TEXT NOT NULL,  -- pending, firing or resolved
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    active_at TIMESTAMPTZ,
+    fired_at TIMESTAMPTZ,
+    resolved_at TIMESTAMPTZ,
+    last_true_at TIMESTAMPTZ,
+    last_evaluated_at TIMESTAMPTZ,
+
+    PRIMARY KEY (rule_name, metric_type, metric_name)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE alert_states
+-- +goose StatementEnd
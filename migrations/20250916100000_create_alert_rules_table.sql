@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE alert_rules (
+    name TEXT PRIMARY KEY,
+    position INTEGER NOT NULL,  -- evaluation order
+    definition JSONB NOT NULL   -- rule as in the JSON config
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE alert_rules
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();  -- last time the metric was added or updated
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE metrics DROP COLUMN updated_at
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE alert_history (
+    id BIGSERIAL PRIMARY KEY,      -- insertion order
+    rule_name TEXT NOT NULL,
+    metric_type TEXT NOT NULL,
+    metric_name TEXT NOT NULL,
+    from_state TEXT NOT NULL,
+    to_state TEXT NOT NULL,
+    at TIMESTAMPTZ NOT NULL,
+    alert JSONB NOT NULL           -- snapshot of the alert after the transition
+);
+
+CREATE INDEX alert_history_rule_at_idx ON alert_history (rule_name, at);
+CREATE INDEX alert_history_at_idx ON alert_history (at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE alert_history
+-- +goose StatementEnd
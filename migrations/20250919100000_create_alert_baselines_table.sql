@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE alert_baselines (
+    rule_name TEXT NOT NULL,
+    metric_type TEXT NOT NULL,
+    metric_name TEXT NOT NULL,
+    mean DOUBLE PRECISION NOT NULL,
+    variance DOUBLE PRECISION NOT NULL,
+    count INTEGER NOT NULL,  -- number of observed values
+    updated_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (rule_name, metric_type, metric_name)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE alert_baselines
+-- +goose StatementEnd
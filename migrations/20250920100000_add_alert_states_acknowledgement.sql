@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE alert_states
+    ADD COLUMN acknowledged_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN acknowledged_at TIMESTAMPTZ;  -- NULL if the alert is not acknowledged
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE alert_states
+    DROP COLUMN acknowledged_by,
+    DROP COLUMN acknowledged_at
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notification_outbox (
+    id TEXT PRIMARY KEY,
+    receiver TEXT NOT NULL,
+    notification JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,  -- failed delivery attempts
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    dead_at TIMESTAMPTZ  -- set once delivery has been given up
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE notification_outbox
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';  -- label set distinguishing metrics with the same name
+ALTER TABLE metrics DROP CONSTRAINT metrics_pkey;
+ALTER TABLE metrics ADD PRIMARY KEY (metric_name, metric_type, labels);
+
+ALTER TABLE alert_states ADD COLUMN metric_labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE alert_states DROP CONSTRAINT alert_states_pkey;
+ALTER TABLE alert_states ADD PRIMARY KEY (rule_name, metric_type, metric_name, metric_labels);
+
+ALTER TABLE alert_baselines ADD COLUMN metric_labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE alert_baselines DROP CONSTRAINT alert_baselines_pkey;
+ALTER TABLE alert_baselines ADD PRIMARY KEY (rule_name, metric_type, metric_name, metric_labels);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM alert_baselines WHERE metric_labels <> '{}';
+ALTER TABLE alert_baselines DROP CONSTRAINT alert_baselines_pkey;
+ALTER TABLE alert_baselines ADD PRIMARY KEY (rule_name, metric_type, metric_name);
+ALTER TABLE alert_baselines DROP COLUMN metric_labels;
+
+DELETE FROM alert_states WHERE metric_labels <> '{}';
+ALTER TABLE alert_states DROP CONSTRAINT alert_states_pkey;
+ALTER TABLE alert_states ADD PRIMARY KEY (rule_name, metric_type, metric_name);
+ALTER TABLE alert_states DROP COLUMN metric_labels;
+
+DELETE FROM metrics WHERE labels <> '{}';
+ALTER TABLE metrics DROP CONSTRAINT metrics_pkey;
+ALTER TABLE metrics ADD PRIMARY KEY (metric_name, metric_type);
+ALTER TABLE metrics DROP COLUMN labels
+-- +goose StatementEnd
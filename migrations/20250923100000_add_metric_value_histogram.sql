@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN metric_value_histogram JSONB;  -- value of a histogram: bounds, counts, count and sum
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM metrics WHERE metric_type = 'histogram';
+ALTER TABLE metrics DROP COLUMN metric_value_histogram
+-- +goose StatementEnd
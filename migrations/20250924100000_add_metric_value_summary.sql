@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN metric_value_summary JSONB;  -- value of a summary: DDSketch buckets, count, sum, min and max
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM metrics WHERE metric_type = 'summary';
+ALTER TABLE metrics DROP COLUMN metric_value_summary
+-- +goose StatementEnd
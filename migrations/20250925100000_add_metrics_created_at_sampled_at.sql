@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();  -- time the metric was added
+UPDATE metrics SET created_at = updated_at;
+ALTER TABLE metrics ADD COLUMN sampled_at TIMESTAMPTZ;  -- client-side time the current value was sampled at, if sent
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE metrics DROP COLUMN sampled_at;
+ALTER TABLE metrics DROP COLUMN created_at
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE metric_metadata (
+    metric_name TEXT PRIMARY KEY,
+    help TEXT NOT NULL DEFAULT '',
+    unit TEXT NOT NULL DEFAULT '',   -- e.g. bytes, seconds, percent
+    owner TEXT NOT NULL DEFAULT ''   -- owning team
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE metric_metadata
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE silences (
+    id TEXT PRIMARY KEY,
+    matchers JSONB NOT NULL,  -- matchers as in the silences API
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_by TEXT NOT NULL,
+    comment TEXT NOT NULL DEFAULT ''
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE silences
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE alert_history ADD COLUMN metric_labels JSONB NOT NULL DEFAULT '{}';  -- labels of the metric of the alert
+UPDATE alert_history SET metric_labels = alert->'metric_labels' WHERE alert ? 'metric_labels';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE alert_history DROP COLUMN metric_labels
+-- +goose StatementEnd